@@ -0,0 +1,22 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_category_relationships_parent;
+DROP INDEX IF EXISTS idx_category_relationships_descendant;
+
+ALTER TABLE category_relationships
+    DROP CONSTRAINT IF EXISTS chk_category_relationships_level;
+
+-- back to parent -> child rows only, level holds the absolute depth of the child
+CREATE TEMP TABLE category_depths ON COMMIT DROP AS
+SELECT descendant_id, MAX(level) AS depth
+FROM category_relationships
+GROUP BY descendant_id;
+
+DELETE FROM category_relationships WHERE level <> 1;
+
+UPDATE category_relationships cr
+SET level = d.depth
+FROM category_depths d
+WHERE cr.descendant_id = d.descendant_id;
+
+COMMIT;
@@ -0,0 +1,37 @@
+BEGIN;
+
+-- category_relationships stored only parent -> child rows with the absolute depth of the child in level,
+-- convert it to a closure table: a row for every (ancestor, descendant) pair, level is the distance between them,
+-- every category has a self row with level 0 and its parent row has level 1.
+CREATE TEMP TABLE category_parents ON COMMIT DROP AS
+SELECT ancestor_id AS parent_id, descendant_id AS child_id
+FROM category_relationships;
+
+DELETE FROM category_relationships;
+
+INSERT INTO category_relationships (ancestor_id, descendant_id, level)
+SELECT category_id, category_id, 0
+FROM categories;
+
+INSERT INTO category_relationships (ancestor_id, descendant_id, level)
+WITH RECURSIVE paths AS (
+    SELECT parent_id AS ancestor_id, child_id AS descendant_id, 1 AS level
+    FROM category_parents
+    UNION ALL
+    SELECT p.ancestor_id, cp.child_id, p.level + 1
+    FROM paths p
+             INNER JOIN category_parents cp ON cp.parent_id = p.descendant_id
+)
+SELECT ancestor_id, descendant_id, level
+FROM paths;
+
+ALTER TABLE category_relationships
+    ADD CONSTRAINT chk_category_relationships_level CHECK (level >= 0);
+
+-- ancestors of a category are looked up by descendant_id, the primary key already covers lookups by ancestor_id
+CREATE INDEX IF NOT EXISTS idx_category_relationships_descendant ON category_relationships (descendant_id, level);
+
+-- a category has at most one parent
+CREATE UNIQUE INDEX IF NOT EXISTS idx_category_relationships_parent ON category_relationships (descendant_id) WHERE level = 1;
+
+COMMIT;
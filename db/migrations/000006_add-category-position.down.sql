@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE categories
+    DROP COLUMN IF EXISTS position;
+
+COMMIT;
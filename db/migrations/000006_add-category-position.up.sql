@@ -0,0 +1,16 @@
+BEGIN;
+
+-- position orders a category among its siblings, lower comes first
+ALTER TABLE categories
+    ADD COLUMN IF NOT EXISTS position INT NOT NULL DEFAULT 0;
+
+-- keep existing order(category_id) within each sibling set
+UPDATE categories c
+SET position = ordered.position
+FROM (SELECT s.category_id,
+             ROW_NUMBER() OVER (PARTITION BY p.ancestor_id ORDER BY s.category_id) - 1 AS position
+      FROM categories s
+               LEFT JOIN category_relationships p ON p.descendant_id = s.category_id AND p.level = 1) ordered
+WHERE c.category_id = ordered.category_id;
+
+COMMIT;
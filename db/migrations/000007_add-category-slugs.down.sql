@@ -0,0 +1,10 @@
+BEGIN;
+
+DROP TABLE IF EXISTS category_slug_history;
+
+DROP INDEX IF EXISTS idx_category_slug;
+
+ALTER TABLE categories
+    DROP COLUMN IF EXISTS slug;
+
+COMMIT;
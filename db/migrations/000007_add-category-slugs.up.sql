@@ -0,0 +1,34 @@
+BEGIN;
+
+-- slug is unique among non deleted siblings(uq_category_sibling_slug, 000018), a category is addressed by the slugs of its ancestors and its own, e.g. phone/smartphone/gaming
+ALTER TABLE categories
+    ADD COLUMN IF NOT EXISTS slug VARCHAR(100);
+
+-- backfill from names, existing names are ascii, siblings with the same slug get -2, -3, ... suffixes
+UPDATE categories c
+SET slug = CASE WHEN numbered.n = 1 THEN numbered.base ELSE numbered.base || '-' || numbered.n END
+FROM (SELECT b.category_id, b.base,
+             ROW_NUMBER() OVER (PARTITION BY b.parent_id, b.base ORDER BY b.category_id) AS n
+      FROM (SELECT s.category_id, p.ancestor_id AS parent_id,
+                   COALESCE(NULLIF(BTRIM(REGEXP_REPLACE(LOWER(s.name), '[^a-z0-9]+', '-', 'g'), '-'), ''), 'category') AS base
+            FROM categories s
+                     LEFT JOIN category_relationships p ON p.descendant_id = s.category_id AND p.level = 1) b) numbered
+WHERE c.category_id = numbered.category_id;
+
+ALTER TABLE categories
+    ALTER COLUMN slug SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_category_slug ON categories (slug);
+
+-- previous slugs of a category, old urls resolve to the category and get redirected to the current path
+CREATE TABLE IF NOT EXISTS category_slug_history
+(
+    category_id INT          NOT NULL REFERENCES categories (category_id),
+    slug        VARCHAR(100) NOT NULL,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (category_id, slug)
+);
+
+CREATE INDEX IF NOT EXISTS idx_category_slug_history_slug ON category_slug_history (slug);
+
+COMMIT;
@@ -0,0 +1,20 @@
+BEGIN;
+
+-- typed attribute definitions(facets) of a category, e.g. screen_size(number, inch), anc(bool),
+-- descendants inherit them, a descendant defining the same code overrides the inherited definition.
+CREATE TABLE IF NOT EXISTS category_attributes
+(
+    attribute_id SERIAL PRIMARY KEY,
+    category_id  INT          NOT NULL REFERENCES categories (category_id),
+    code         VARCHAR(50)  NOT NULL,
+    name         VARCHAR(100) NOT NULL,
+    type         VARCHAR(10)  NOT NULL CHECK (type IN ('string', 'enum', 'number', 'bool')),
+    unit         VARCHAR(20),
+    enum_values  JSONB        NOT NULL DEFAULT '[]',
+    required     BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at   TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (category_id, code)
+);
+
+COMMIT;
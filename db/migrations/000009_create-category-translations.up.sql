@@ -0,0 +1,18 @@
+BEGIN;
+
+-- category names and descriptions in other locales, categories.name and description are the default locale(en) values
+-- and the fallback when a locale has no translation. names are unique per locale among siblings.
+CREATE TABLE IF NOT EXISTS category_translations
+(
+    category_id INT          NOT NULL REFERENCES categories (category_id),
+    locale      VARCHAR(10)  NOT NULL,
+    name        VARCHAR(255) NOT NULL,
+    description TEXT         NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (category_id, locale)
+);
+
+CREATE INDEX IF NOT EXISTS idx_category_translations_locale ON category_translations (locale);
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP INDEX IF EXISTS uq_category_sibling_name;
+DROP INDEX IF EXISTS idx_category_parent_id;
+
+ALTER TABLE categories
+    DROP COLUMN IF EXISTS parent_id;
+
+COMMIT;
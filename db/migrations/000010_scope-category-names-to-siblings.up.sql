@@ -0,0 +1,20 @@
+BEGIN;
+
+-- direct parent, duplicated from the level 1 closure row so sibling name uniqueness can be enforced by an index.
+ALTER TABLE categories
+    ADD COLUMN IF NOT EXISTS parent_id INT REFERENCES categories (category_id);
+
+UPDATE categories c
+SET parent_id = r.ancestor_id
+FROM category_relationships r
+WHERE r.descendant_id = c.category_id
+  AND r.level = 1;
+
+CREATE INDEX IF NOT EXISTS idx_category_parent_id ON categories (parent_id);
+
+-- names are unique case-insensitively among siblings instead of globally, e.g. Accessories under both Phone and
+-- Sound Equipment, root categories are siblings of each other. deleted categories don't hold their names.
+CREATE UNIQUE INDEX IF NOT EXISTS uq_category_sibling_name ON categories (COALESCE(parent_id, 0), LOWER(name))
+    WHERE status <> 'deleted';
+
+COMMIT;
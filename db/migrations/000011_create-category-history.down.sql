@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS trg_category_history ON categories;
+DROP FUNCTION IF EXISTS record_category_history();
+DROP FUNCTION IF EXISTS category_snapshot(categories);
+DROP TABLE IF EXISTS category_history;
+
+COMMIT;
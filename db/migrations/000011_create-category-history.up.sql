@@ -0,0 +1,90 @@
+BEGIN;
+
+-- history of category changes, one merged row per category and transaction, a later write in the same transaction
+-- updates the row's after snapshot and action, rows of committed transactions never change.
+-- before and after are snapshots of the category(before is NULL for create). rows are written by the trigger below,
+-- so every write is recorded, actor is read from the transaction local setting app.actor.
+CREATE TABLE IF NOT EXISTS category_history
+(
+    history_id  BIGSERIAL PRIMARY KEY,
+    category_id INT          NOT NULL REFERENCES categories (category_id),
+    action      VARCHAR(20)  NOT NULL CHECK (action IN ('create', 'update', 'move', 'status', 'reorder')),
+    actor       VARCHAR(255) NOT NULL,
+    before      JSONB,
+    after       JSONB        NOT NULL,
+    tx_id       BIGINT       NOT NULL DEFAULT txid_current(),
+    changed_at  TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_category_history_category ON category_history (category_id, history_id);
+CREATE INDEX IF NOT EXISTS idx_category_history_tx ON category_history (category_id, tx_id);
+CREATE INDEX IF NOT EXISTS idx_category_history_changed_at ON category_history (changed_at);
+
+CREATE OR REPLACE FUNCTION category_snapshot(c categories) RETURNS JSONB AS
+$$
+SELECT jsonb_build_object(
+               'name', c.name,
+               'description', COALESCE(c.description, ''),
+               'slug', c.slug,
+               'status', c.status,
+               'position', c.position,
+               'parentUuid', (SELECT p.category_uuid FROM categories p WHERE p.category_id = c.parent_id)
+       )
+$$ LANGUAGE sql STABLE;
+
+-- writes of the same transaction merge into one row, e.g. create then position, the most significant action wins.
+CREATE OR REPLACE FUNCTION record_category_history() RETURNS TRIGGER AS
+$$
+DECLARE
+    before_snapshot JSONB;
+    after_snapshot  JSONB       := category_snapshot(NEW);
+    change          TEXT        := 'create';
+    significance    TEXT[]      := ARRAY ['reorder', 'update', 'status', 'move', 'create'];
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        before_snapshot := category_snapshot(OLD);
+
+        IF before_snapshot = after_snapshot THEN
+            RETURN NEW;
+        END IF;
+
+        change := CASE
+                      WHEN OLD.parent_id IS DISTINCT FROM NEW.parent_id THEN 'move'
+                      WHEN OLD.status <> NEW.status THEN 'status'
+                      WHEN OLD.name <> NEW.name OR OLD.slug <> NEW.slug
+                          OR OLD.description IS DISTINCT FROM NEW.description THEN 'update'
+                      ELSE 'reorder'
+            END;
+    END IF;
+
+    UPDATE category_history h
+    SET after  = after_snapshot,
+        action = CASE
+                     WHEN array_position(significance, change) > array_position(significance, h.action::TEXT) THEN change
+                     ELSE h.action
+            END
+    WHERE h.category_id = NEW.category_id
+      AND h.tx_id = txid_current();
+
+    IF NOT FOUND THEN
+        INSERT INTO category_history (category_id, action, actor, before, after)
+        VALUES (NEW.category_id, change, COALESCE(NULLIF(current_setting('app.actor', true), ''), 'system'),
+                before_snapshot, after_snapshot);
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- existing categories get a baseline create row with their current state, dated when they were created.
+INSERT INTO category_history (category_id, action, actor, before, after, changed_at)
+SELECT c.category_id, 'create', 'system', NULL, category_snapshot(c), c.created_at
+FROM categories c;
+
+CREATE TRIGGER trg_category_history
+    AFTER INSERT OR UPDATE
+    ON categories
+    FOR EACH ROW
+EXECUTE FUNCTION record_category_history();
+
+COMMIT;
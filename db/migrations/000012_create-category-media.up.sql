@@ -0,0 +1,20 @@
+BEGIN;
+
+-- banner and icon images of categories, object keys point to the blob store, thumbnails is an array of
+-- {size, objectKey, width, height} generated on upload. uploading a kind again replaces it.
+CREATE TABLE IF NOT EXISTS category_media
+(
+    category_id  INT           NOT NULL REFERENCES categories (category_id),
+    kind         VARCHAR(20)   NOT NULL CHECK (kind IN ('banner', 'icon')),
+    object_key   VARCHAR(1024) NOT NULL,
+    content_type VARCHAR(50)   NOT NULL,
+    width        INT           NOT NULL,
+    height       INT           NOT NULL,
+    size_bytes   BIGINT        NOT NULL,
+    thumbnails   JSONB         NOT NULL DEFAULT '[]',
+    created_at   TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at   TIMESTAMPTZ   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (category_id, kind)
+);
+
+COMMIT;
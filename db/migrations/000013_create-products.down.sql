@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TABLE IF EXISTS product_variants;
+DROP TABLE IF EXISTS product_categories;
+DROP TABLE IF EXISTS products;
+DROP TYPE IF EXISTS product_status;
+
+COMMIT;
@@ -0,0 +1,48 @@
+BEGIN;
+
+CREATE TYPE product_status AS ENUM ('draft', 'active', 'archived');
+
+CREATE TABLE IF NOT EXISTS products
+(
+    product_id   SERIAL PRIMARY KEY,
+    product_uuid uuid           NOT NULL DEFAULT uuid_generate_v4() UNIQUE,
+    title        VARCHAR(255)   NOT NULL,
+    description  TEXT           NOT NULL DEFAULT '',
+    brand        VARCHAR(100)   NOT NULL DEFAULT '',
+    status       product_status NOT NULL DEFAULT 'draft',
+    created_at   TIMESTAMPTZ    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at   TIMESTAMPTZ    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_products_status ON products (status);
+CREATE INDEX IF NOT EXISTS idx_products_brand ON products (lower(brand));
+
+-- categories a product is listed under
+CREATE TABLE IF NOT EXISTS product_categories
+(
+    product_id  INT         NOT NULL REFERENCES products (product_id) ON DELETE CASCADE,
+    category_id INT         NOT NULL REFERENCES categories (category_id),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (product_id, category_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_categories_category ON product_categories (category_id);
+
+-- sellable SKUs of a product, options are the values that tell variants apart, e.g. {"color": "black", "storage": "128GB"}
+CREATE TABLE IF NOT EXISTS product_variants
+(
+    variant_id   SERIAL PRIMARY KEY,
+    variant_uuid uuid           NOT NULL DEFAULT uuid_generate_v4() UNIQUE,
+    product_id   INT            NOT NULL REFERENCES products (product_id) ON DELETE CASCADE,
+    sku          VARCHAR(64)    NOT NULL,
+    barcode      VARCHAR(14),
+    options      JSONB          NOT NULL DEFAULT '{}',
+    price        NUMERIC(12, 2) NOT NULL CHECK (price >= 0),
+    created_at   TIMESTAMPTZ    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at   TIMESTAMPTZ    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_product_variant_sku UNIQUE (sku),
+    CONSTRAINT uq_product_variant_barcode UNIQUE (barcode),
+    CONSTRAINT uq_product_variant_options UNIQUE (product_id, options)
+);
+
+COMMIT;
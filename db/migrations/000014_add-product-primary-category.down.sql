@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS uq_product_primary_category;
+
+ALTER TABLE product_categories
+    DROP COLUMN IF EXISTS is_primary;
+
+COMMIT;
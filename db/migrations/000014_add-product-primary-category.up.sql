@@ -0,0 +1,18 @@
+BEGIN;
+
+-- a product is listed under many categories, the primary one is canonical for its breadcrumb.
+ALTER TABLE product_categories
+    ADD COLUMN IF NOT EXISTS is_primary BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- first assigned category of existing products becomes primary
+UPDATE product_categories pc
+SET is_primary = TRUE
+FROM (SELECT DISTINCT ON (product_id) product_id, category_id
+      FROM product_categories
+      ORDER BY product_id, created_at, category_id) first
+WHERE pc.product_id = first.product_id
+  AND pc.category_id = first.category_id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_product_primary_category ON product_categories (product_id) WHERE is_primary;
+
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_product_variants_attributes;
+DROP INDEX IF EXISTS idx_products_attributes;
+
+ALTER TABLE product_variants
+    DROP COLUMN IF EXISTS attributes;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS attributes;
+
+COMMIT;
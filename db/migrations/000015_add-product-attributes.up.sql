@@ -0,0 +1,15 @@
+BEGIN;
+
+-- typed attribute values of a product and its variants as {code: value}, e.g. {"ram": 8, "anc": true, "color": "black"},
+-- codes are defined by category_attributes of the product's categories, variant values override product values.
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}';
+
+ALTER TABLE product_variants
+    ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}';
+
+-- containment filters, e.g. attributes @> '{"anc": true}'
+CREATE INDEX IF NOT EXISTS idx_products_attributes ON products USING GIN (attributes jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_product_variants_attributes ON product_variants USING GIN (attributes jsonb_path_ops);
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_product_variants_product_price;
+DROP INDEX IF EXISTS idx_products_search_vector;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS search_vector;
+
+COMMIT;
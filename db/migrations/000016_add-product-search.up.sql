@@ -0,0 +1,16 @@
+BEGIN;
+
+-- full-text search document of a product, title matches rank highest, then brand, then description.
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', title), 'A') ||
+        setweight(to_tsvector('english', brand), 'B') ||
+        setweight(to_tsvector('english', description), 'C')
+        ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector);
+
+-- price filters and sorting of a product's variants
+CREATE INDEX IF NOT EXISTS idx_product_variants_product_price ON product_variants (product_id, price);
+
+COMMIT;
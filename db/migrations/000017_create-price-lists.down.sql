@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS trg_sync_retail_base_price ON product_variants;
+DROP FUNCTION IF EXISTS sync_retail_base_price();
+DROP TABLE IF EXISTS prices;
+DROP TABLE IF EXISTS price_lists;
+
+COMMIT;
@@ -0,0 +1,73 @@
+BEGIN;
+
+-- price lists price SKUs in one ISO 4217 currency, e.g. retail for everyone and b2b for business customers.
+-- customer_group NULL applies to every customer, lists of the customer's own group are tried first, then by priority.
+CREATE TABLE IF NOT EXISTS price_lists
+(
+    price_list_id   SERIAL PRIMARY KEY,
+    price_list_uuid uuid         NOT NULL DEFAULT uuid_generate_v4() UNIQUE,
+    code            VARCHAR(50)  NOT NULL,
+    name            VARCHAR(255) NOT NULL,
+    currency        CHAR(3)      NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    customer_group  VARCHAR(50),
+    priority        INT          NOT NULL DEFAULT 0,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_price_list_code UNIQUE (code)
+);
+
+-- amounts are integer minor units of the list currency, e.g. 79999 is 799.99 USD.
+-- a price without starts_at and ends_at is the base price of the SKU in the list, one per list and variant,
+-- scheduled prices apply from starts_at inclusive to ends_at exclusive, a NULL bound is open.
+CREATE TABLE IF NOT EXISTS prices
+(
+    price_id      BIGSERIAL PRIMARY KEY,
+    price_uuid    uuid        NOT NULL DEFAULT uuid_generate_v4() UNIQUE,
+    price_list_id INT         NOT NULL REFERENCES price_lists (price_list_id) ON DELETE CASCADE,
+    variant_id    INT         NOT NULL REFERENCES product_variants (variant_id) ON DELETE CASCADE,
+    amount        BIGINT      NOT NULL CHECK (amount >= 0),
+    starts_at     TIMESTAMPTZ,
+    ends_at       TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_price_schedule CHECK (starts_at IS NULL OR ends_at IS NULL OR starts_at < ends_at)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_price_base ON prices (price_list_id, variant_id) WHERE starts_at IS NULL AND ends_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_prices_variant ON prices (variant_id, price_list_id);
+
+-- the retail list prices every customer in USD, its base prices follow variant prices.
+INSERT INTO price_lists (code, name, currency)
+VALUES ('retail', 'Retail', 'USD')
+ON CONFLICT (code) DO NOTHING;
+
+-- variant prices are NUMERIC(12, 2) retail prices, stored in cents as retail base prices.
+CREATE OR REPLACE FUNCTION sync_retail_base_price() RETURNS TRIGGER AS
+$$
+BEGIN
+    INSERT INTO prices (price_list_id, variant_id, amount)
+    SELECT pl.price_list_id, NEW.variant_id, ROUND(NEW.price * 100)::BIGINT
+    FROM price_lists pl
+    WHERE pl.code = 'retail'
+    ON CONFLICT (price_list_id, variant_id) WHERE starts_at IS NULL AND ends_at IS NULL
+        DO UPDATE SET amount     = EXCLUDED.amount,
+                      updated_at = CURRENT_TIMESTAMP;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+INSERT INTO prices (price_list_id, variant_id, amount)
+SELECT pl.price_list_id, v.variant_id, ROUND(v.price * 100)::BIGINT
+FROM product_variants v
+         CROSS JOIN price_lists pl
+WHERE pl.code = 'retail'
+ON CONFLICT DO NOTHING;
+
+CREATE TRIGGER trg_sync_retail_base_price
+    AFTER INSERT OR UPDATE OF price
+    ON product_variants
+    FOR EACH ROW
+EXECUTE FUNCTION sync_retail_base_price();
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP INDEX IF EXISTS uq_category_sibling_slug;
+
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN;
+
+-- non deleted siblings that share a slug(concurrent creates before this index) get a -<category_id> suffix,
+-- the oldest one keeps the slug.
+UPDATE categories c
+SET slug = c.slug || '-' || c.category_id
+FROM (SELECT category_id,
+             ROW_NUMBER() OVER (PARTITION BY COALESCE(parent_id, 0), slug ORDER BY category_id) AS n
+      FROM categories
+      WHERE status <> 'deleted') dup
+WHERE c.category_id = dup.category_id
+  AND dup.n > 1;
+
+-- slugs are unique among non deleted siblings, root categories are siblings of each other.
+CREATE UNIQUE INDEX IF NOT EXISTS uq_category_sibling_slug ON categories (COALESCE(parent_id, 0), slug)
+    WHERE status <> 'deleted';
+
+COMMIT;
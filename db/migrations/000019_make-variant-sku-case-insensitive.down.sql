@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS uq_product_variant_sku;
+
+ALTER TABLE product_variants ADD CONSTRAINT uq_product_variant_sku UNIQUE (sku);
+
+COMMIT;
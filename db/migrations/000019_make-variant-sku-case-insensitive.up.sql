@@ -0,0 +1,22 @@
+BEGIN;
+
+-- skus are unique case-insensitively, SKU-1 and sku-1 are the same sku, lookups compare upper(sku).
+-- skus that only differ in case are renamed by hand before this runs, they identify stock outside the catalog.
+DO
+$$
+    DECLARE
+        duplicate TEXT;
+    BEGIN
+        SELECT upper(sku) INTO duplicate FROM product_variants GROUP BY upper(sku) HAVING COUNT(*) > 1 LIMIT 1;
+
+        IF duplicate IS NOT NULL THEN
+            RAISE EXCEPTION 'skus differ only in case, rename them first: %', duplicate;
+        END IF;
+    END
+$$;
+
+ALTER TABLE product_variants DROP CONSTRAINT IF EXISTS uq_product_variant_sku;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_product_variant_sku ON product_variants (upper(sku));
+
+COMMIT;
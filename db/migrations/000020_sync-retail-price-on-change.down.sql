@@ -0,0 +1,12 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS trg_sync_retail_base_price_change ON product_variants;
+DROP TRIGGER IF EXISTS trg_sync_retail_base_price ON product_variants;
+
+CREATE TRIGGER trg_sync_retail_base_price
+    AFTER INSERT OR UPDATE OF price
+    ON product_variants
+    FOR EACH ROW
+EXECUTE FUNCTION sync_retail_base_price();
+
+COMMIT;
@@ -0,0 +1,21 @@
+BEGIN;
+
+-- transitional: product_variants.price stays the source of retail base prices until variant writes go through price
+-- lists, listings and search read variant prices meanwhile. Base prices are only rewritten when the price changes,
+-- not on every variant update.
+DROP TRIGGER IF EXISTS trg_sync_retail_base_price ON product_variants;
+
+CREATE TRIGGER trg_sync_retail_base_price
+    AFTER INSERT
+    ON product_variants
+    FOR EACH ROW
+EXECUTE FUNCTION sync_retail_base_price();
+
+CREATE TRIGGER trg_sync_retail_base_price_change
+    AFTER UPDATE OF price
+    ON product_variants
+    FOR EACH ROW
+    WHEN (OLD.price IS DISTINCT FROM NEW.price)
+EXECUTE FUNCTION sync_retail_base_price();
+
+COMMIT;
@@ -0,0 +1,53 @@
+package lib
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// ActorHeader is set by the caller, nothing verifies it, see Actor.
+	ActorHeader = "X-Actor"
+
+	// AnonymousActor is the actor of requests without X-Actor header.
+	AnonymousActor = "anonymous"
+
+	actorMaxLength = 255
+)
+
+type actorCtxKey struct{}
+
+// Actor middleware stores who claims to make the request in the request context, taken from X-Actor header,
+// e.g. a user email set by the gateway. Long values are cut at 255 characters.
+//
+// The header is untrusted: the apis have no authentication yet, any client can send any actor. Use it as
+// a hint in change history, not as an audit trail, until the actor is derived from an authenticated principal.
+func Actor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor := strings.TrimSpace(c.GetHeader(ActorHeader))
+		if actor == "" {
+			actor = AnonymousActor
+		}
+
+		if utf8.RuneCountInString(actor) > actorMaxLength {
+			actor = string([]rune(actor)[:actorMaxLength])
+		}
+
+		c.Request = c.Request.WithContext(WithActor(c.Request.Context(), actor))
+		c.Next()
+	}
+}
+
+// WithActor returns a copy of ctx with actor.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorCtxKey{}, actor)
+}
+
+// ActorFromContext returns the actor stored by Actor middleware or WithActor, empty if there is none.
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorCtxKey{}).(string)
+	return actor
+}
@@ -0,0 +1,41 @@
+package lib
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActor(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{"Missing header", "", AnonymousActor},
+		{"Trimmed", "  merchandiser@example.com ", "merchandiser@example.com"},
+		{"Cut at max length", strings.Repeat("a", actorMaxLength+10), strings.Repeat("a", actorMaxLength)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+
+			r := gin.New()
+			r.Use(Actor())
+			r.GET("/", func(c *gin.Context) { got = ActorFromContext(c.Request.Context()) })
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(ActorHeader, tt.header)
+			}
+
+			r.ServeHTTP(httptest.NewRecorder(), req)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package lib
+
+import "net/http"
+
+// Machine-readable error codes, these values are part of the public API contract,
+// never rename or reuse a code, add a new one instead.
+const (
+	CodeBadRequest       = "bad_request"
+	CodeInvalidJSONBody  = "invalid_json_body"
+	CodeValidationFailed = "validation_failed"
+	CodeUnauthorized     = "unauthorized"
+	CodeNotFound         = "not_found"
+	CodeConflict         = "conflict"
+	CodeInternal         = "internal_error"
+	CodeUnexpected       = "unexpected_error"
+)
+
+// codeFromStatus returns the generic error code for an HTTP status code.
+func codeFromStatus(status int) string {
+	switch {
+	case status == http.StatusBadRequest:
+		return CodeBadRequest
+	case status == http.StatusUnauthorized:
+		return CodeUnauthorized
+	case status == http.StatusNotFound:
+		return CodeNotFound
+	case status == http.StatusConflict:
+		return CodeConflict
+	case status >= http.StatusInternalServerError:
+		return CodeInternal
+	default:
+		return CodeUnexpected
+	}
+}
@@ -1,25 +1,51 @@
 package lib
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 )
 
-// APIError represents an error that provides an HTTP status code, message and causes.
+// Sentinel error kinds, every APIError matches the kind of its status code with errors.Is,
+// so callers don't need to compare status codes.
+//
+// Example usage:
+//
+//	if errors.Is(apiErr, lib.ErrNotFound) { ... }
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrValidation   = errors.New("validation failed")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrInternal     = errors.New("internal server error")
+)
+
+// APIError represents an error that provides an HTTP status code, a stable machine-readable code, message and causes.
 type APIError interface {
 	Error() string
 
 	Wrap(err error) APIError
 
+	WithCode(code string) APIError
+
 	StatusCode() int
 
+	ErrorCode() string
+
 	AsMessage() string
+
+	FieldErrors() ValidationErrors
+
+	Unwrap() []error
 }
 
 // apiError is a concrete implementation of the APIError interface.
 type apiError struct {
 	Message string        `json:"message"`
 	Code    int           `json:"status"`
+	ErrCode string        `json:"code"`
+	Args    Args          `json:"-"`
 	Causes  []interface{} `json:"causes"`
 }
 
@@ -27,25 +53,113 @@ func (e apiError) StatusCode() int {
 	return e.Code
 }
 
+// ErrorCode returns the machine-readable error code, falls back to the generic code of the status.
+func (e apiError) ErrorCode() string {
+	if e.ErrCode != "" {
+		return e.ErrCode
+	}
+
+	return codeFromStatus(e.Code)
+}
+
+// WithCode overrides the generic error code with a more specific one.
+//
+// Example usage:
+//
+//	err := NewDBFieldConflictError("user already exists").WithCode("user_email_exists")
+func (e apiError) WithCode(code string) APIError {
+	e.ErrCode = code
+	return e
+}
+
 func (e apiError) AsMessage() string {
 	return e.Message
 }
 
+// FieldErrors returns the field level validation errors among causes, nil if there is none.
+func (e apiError) FieldErrors() ValidationErrors {
+	var fieldErrs ValidationErrors
+
+	for _, cause := range e.Causes {
+		if fe, ok := cause.(FieldError); ok {
+			fieldErrs = append(fieldErrs, fe)
+		}
+	}
+
+	return fieldErrs
+}
+
 // Error implements the error interface.
 func (e apiError) Error() string {
-	return fmt.Sprintf("message: %s - status: %d - causes: %v",
-		e.Message, e.Code, e.Causes)
+	return fmt.Sprintf("message: %s - status: %d - code: %s - causes: %v",
+		e.Message, e.Code, e.ErrorCode(), e.Causes)
 }
 
-// Wrap wraps an existing error into an APIError.
+// Wrap wraps an existing error into an APIError, the original error value is kept,
+// so it can be matched with errors.Is and errors.As through the APIError.
 func (e apiError) Wrap(err error) APIError {
 	if err != nil {
-		e.Causes = append(e.Causes, err.Error())
+		// full slice expression, so copies of e never share the appended causes
+		e.Causes = append(e.Causes[:len(e.Causes):len(e.Causes)], err)
 	}
 
 	return e
 }
 
+// Unwrap returns the wrapped error values among causes, used by errors.Is and errors.As.
+func (e apiError) Unwrap() []error {
+	var errs []error
+
+	for _, cause := range e.Causes {
+		if err, ok := cause.(error); ok {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// Is reports whether target is the sentinel kind of this error's status code,
+// e.g. a 404 APIError matches ErrNotFound, a validation error matches both ErrValidation and ErrBadRequest.
+func (e apiError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.Code == http.StatusBadRequest
+	case ErrValidation:
+		return e.Code == http.StatusBadRequest && e.ErrorCode() == CodeValidationFailed
+	case ErrUnauthorized:
+		return e.Code == http.StatusUnauthorized
+	case ErrNotFound:
+		return e.Code == http.StatusNotFound
+	case ErrConflict:
+		return e.Code == http.StatusConflict
+	case ErrInternal:
+		return e.Code >= http.StatusInternalServerError
+	default:
+		return false
+	}
+}
+
+// NewError creates a new APIError from a catalog code and its template arguments,
+// the message is rendered in DefaultLocale, and re-rendered per request locale in WriteProblem.
+//
+// Example usage:
+//
+//	err := NewError(http.StatusConflict, "category_name_exists", Args{"name": name})
+func NewError(status int, code string, args Args) APIError {
+	message, ok := Localize(DefaultLocale, code, args)
+	if !ok {
+		message = code
+	}
+
+	return apiError{
+		Message: message,
+		Code:    status,
+		ErrCode: code,
+		Args:    args,
+	}
+}
+
 // NewBadRequestError creates a new APIError for bad requests.
 //
 // Example usage:
@@ -55,6 +169,7 @@ func NewBadRequestError(message string) APIError {
 	return apiError{
 		Message: message,
 		Code:    http.StatusBadRequest,
+		ErrCode: CodeBadRequest,
 	}
 }
 
@@ -67,6 +182,7 @@ func NewNotFoundError(message string) APIError {
 	return apiError{
 		Message: message,
 		Code:    http.StatusNotFound,
+		ErrCode: CodeNotFound,
 	}
 }
 
@@ -79,6 +195,7 @@ func NewUnauthorizedError(message string) APIError {
 	return apiError{
 		Message: message,
 		Code:    http.StatusUnauthorized,
+		ErrCode: CodeUnauthorized,
 	}
 }
 
@@ -91,6 +208,7 @@ func NewUnexpectedError(message string) APIError {
 	return apiError{
 		Message: message,
 		Code:    http.StatusInternalServerError,
+		ErrCode: CodeUnexpected,
 	}
 }
 
@@ -103,9 +221,10 @@ func NewInternalServerError(message string, err error) APIError {
 	result := apiError{
 		Message: message,
 		Code:    http.StatusInternalServerError,
+		ErrCode: CodeInternal,
 	}
 	if err != nil {
-		result.Causes = append(result.Causes, err.Error())
+		result.Causes = append(result.Causes, err)
 	}
 
 	return result
@@ -120,5 +239,6 @@ func NewDBFieldConflictError(message string) APIError {
 	return apiError{
 		Message: message,
 		Code:    http.StatusConflict,
+		ErrCode: CodeConflict,
 	}
 }
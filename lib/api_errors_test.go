@@ -0,0 +1,97 @@
+package lib
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIErrorIsSentinelKinds(t *testing.T) {
+	var fieldErrs ValidationErrors
+	fieldErrs.Add("name", FieldCodeRequired, "category name cannot be empty")
+
+	tests := []struct {
+		name    string
+		err     APIError
+		kinds   []error
+		notKind []error
+	}{
+		{"Bad request", NewBadRequestError("bad"), []error{ErrBadRequest}, []error{ErrValidation, ErrNotFound}},
+		{"Validation", NewValidationError("invalid", fieldErrs), []error{ErrValidation, ErrBadRequest}, []error{ErrConflict}},
+		{"Unauthorized", NewUnauthorizedError("who"), []error{ErrUnauthorized}, []error{ErrBadRequest}},
+		{"Not found", NewNotFoundError("missing"), []error{ErrNotFound}, []error{ErrInternal}},
+		{"Not found from code", NewError(http.StatusNotFound, "users_not_found", nil), []error{ErrNotFound}, []error{ErrConflict}},
+		{"Conflict", NewDBFieldConflictError("exists"), []error{ErrConflict}, []error{ErrNotFound}},
+		{"Internal", NewInternalServerError(UnexpectedDatabaseErr, nil), []error{ErrInternal}, []error{ErrNotFound}},
+		{"Unexpected", NewUnexpectedError("oops"), []error{ErrInternal}, []error{ErrBadRequest}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, kind := range tt.kinds {
+				assert.ErrorIs(t, tt.err, kind)
+			}
+
+			for _, kind := range tt.notKind {
+				assert.NotErrorIs(t, tt.err, kind)
+			}
+		})
+	}
+}
+
+func TestAPIErrorKeepsWrappedErrors(t *testing.T) {
+	t.Run("errors.Is through internal server error", func(t *testing.T) {
+		apiErr := NewInternalServerError(UnexpectedDatabaseErr, fmt.Errorf("scan user: %w", sql.ErrNoRows))
+
+		assert.ErrorIs(t, apiErr, sql.ErrNoRows)
+		assert.ErrorIs(t, apiErr, ErrInternal)
+	})
+
+	t.Run("errors.As through wrap", func(t *testing.T) {
+		pgErr := &pgconn.PgError{Code: "23505", ConstraintName: "users_email_key"}
+		apiErr := NewDBFieldConflictError("user already exists").Wrap(pgErr)
+
+		var target *pgconn.PgError
+		require.ErrorAs(t, apiErr, &target)
+		assert.Equal(t, "23505", target.Code)
+	})
+
+	t.Run("errors.As to APIError through fmt wrapping", func(t *testing.T) {
+		err := fmt.Errorf("create user: %w", NewNotFoundError("user not found"))
+
+		var apiErr APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("multiple causes", func(t *testing.T) {
+		first := errors.New("first")
+		second := errors.New("second")
+		apiErr := NewBadRequestError("bad").Wrap(first).Wrap(second)
+
+		assert.Equal(t, []error{first, second}, apiErr.Unwrap())
+		assert.ErrorIs(t, apiErr, first)
+		assert.ErrorIs(t, apiErr, second)
+	})
+
+	t.Run("wrapping copies never share causes", func(t *testing.T) {
+		base := NewBadRequestError("bad").Wrap(errors.New("base")).Wrap(errors.New("base2"))
+		a := base.Wrap(errors.New("a"))
+		b := base.Wrap(errors.New("b"))
+
+		assert.Len(t, base.Unwrap(), 2)
+		assert.Equal(t, "a", a.Unwrap()[2].Error())
+		assert.Equal(t, "b", b.Unwrap()[2].Error())
+	})
+
+	t.Run("nil is ignored", func(t *testing.T) {
+		assert.Empty(t, NewBadRequestError("bad").Wrap(nil).Unwrap())
+	})
+}
@@ -0,0 +1,85 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// ErrBlobNotFound is returned by BlobStore.Get if there is no blob with the key.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore is the port for object storage, keys are slash separated relative paths like
+// categories/<uuid>/banner/<id>/original.jpg. Adapters: FSBlobStore and S3BlobStore.
+type BlobStore interface {
+	// Put stores size bytes of r under key, replaces an existing blob.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get returns the blob under key, caller closes its Body, returns ErrBlobNotFound if missing.
+	Get(ctx context.Context, key string) (*Blob, error)
+	// Delete removes the blob under key, deleting a missing blob isn't an error, like S3.
+	Delete(ctx context.Context, key string) error
+	// URL returns the public url of key, the blob may not exist.
+	URL(key string) string
+}
+
+type Blob struct {
+	Body        io.ReadCloser
+	ContentType string
+	Size        int64
+}
+
+// NewBlobStoreFromEnv creates the blob store selected by BLOB_STORE env var, fs(default) or s3.
+//   - fs: BLOB_FS_ROOT directory(default data/media), BLOB_PUBLIC_URL url prefix(default /media).
+//   - s3: S3_ENDPOINT, S3_REGION(default us-east-1), S3_BUCKET, S3_ACCESS_KEY, S3_SECRET_KEY and optional
+//     BLOB_PUBLIC_URL(default endpoint/bucket), works with any S3-compatible storage like MinIO.
+func NewBlobStoreFromEnv(l *slog.Logger) (BlobStore, error) {
+	switch store := os.Getenv("BLOB_STORE"); store {
+	case "", "fs":
+		root := envOrDefault("BLOB_FS_ROOT", "data/media")
+		l.Info("using filesystem blob store", "root", root)
+
+		return NewFSBlobStore(root, envOrDefault("BLOB_PUBLIC_URL", "/media"))
+	case "s3":
+		cfg := S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    envOrDefault("S3_REGION", "us-east-1"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PublicURL: os.Getenv("BLOB_PUBLIC_URL"),
+		}
+		l.Info("using s3 blob store", "endpoint", cfg.Endpoint, "bucket", cfg.Bucket)
+
+		return NewS3BlobStore(cfg, nil)
+	default:
+		return nil, fmt.Errorf("unknown BLOB_STORE %q, must be fs or s3", store)
+	}
+}
+
+func envOrDefault(key, defaultValue string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+
+	return defaultValue
+}
+
+// ValidBlobKey reports whether key is a relative slash separated path without empty, "." or ".." segments,
+// so it can't escape the store root.
+func ValidBlobKey(key string) bool {
+	if key == "" || len(key) > 1024 {
+		return false
+	}
+
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." || strings.ContainsAny(segment, "\\\x00") {
+			return false
+		}
+	}
+
+	return true
+}
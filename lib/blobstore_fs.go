@@ -0,0 +1,132 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// FSBlobStore stores blobs as files under root, content type is derived from the key's extension.
+type FSBlobStore struct {
+	root      string
+	publicURL string
+}
+
+// NewFSBlobStore creates root if it doesn't exist, publicURL is the url prefix blobs are served from.
+func NewFSBlobStore(root string, publicURL string) (*FSBlobStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("unable to create blob store root: %w", err)
+	}
+
+	return &FSBlobStore{root: root, publicURL: strings.TrimSuffix(publicURL, "/")}, nil
+}
+
+// Put writes to a temporary file first and renames it, readers never see a partial blob.
+func (s *FSBlobStore) Put(ctx context.Context, key string, r io.Reader, _ int64, _ string) error {
+	filePath, err := s.filePath(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return fmt.Errorf("unable to create blob directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("unable to create blob file: %w", err)
+	}
+
+	defer os.Remove(tmp.Name()) // no-op after rename
+
+	if _, err = io.Copy(tmp, readerWithContext(ctx, r)); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("unable to write blob: %w", err)
+	}
+
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("unable to write blob: %w", err)
+	}
+
+	if err = os.Rename(tmp.Name(), filePath); err != nil {
+		return fmt.Errorf("unable to write blob: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FSBlobStore) Get(_ context.Context, key string) (*Blob, error) {
+	filePath, err := s.filePath(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to open blob: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("unable to stat blob: %w", err)
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return &Blob{Body: f, ContentType: contentType, Size: info.Size()}, nil
+}
+
+func (s *FSBlobStore) Delete(_ context.Context, key string) error {
+	filePath, err := s.filePath(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(filePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("unable to delete blob: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FSBlobStore) URL(key string) string {
+	return s.publicURL + "/" + key
+}
+
+func (s *FSBlobStore) filePath(key string) (string, error) {
+	if !ValidBlobKey(key) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// readerWithContext stops reading once ctx is done.
+func readerWithContext(ctx context.Context, r io.Reader) io.Reader {
+	return readerFunc(func(p []byte) (int, error) {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+
+		return r.Read(p)
+	})
+}
+
+type readerFunc func(p []byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) { return f(p) }
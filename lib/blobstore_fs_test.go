@@ -0,0 +1,59 @@
+package lib
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFSBlobStore(t *testing.T) {
+	store, err := NewFSBlobStore(t.TempDir(), "/media/")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	key := "categories/phone/banner/original.png"
+
+	require.NoError(t, store.Put(ctx, key, strings.NewReader("first"), 5, "image/png"))
+	require.NoError(t, store.Put(ctx, key, strings.NewReader("second"), 6, "image/png"), "put replaces")
+
+	blob, err := store.Get(ctx, key)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(blob.Body)
+	blob.Body.Close()
+	assert.Equal(t, "second", string(body))
+	assert.Equal(t, "image/png", blob.ContentType)
+	assert.Equal(t, int64(6), blob.Size)
+
+	assert.Equal(t, "/media/"+key, store.URL(key))
+
+	require.NoError(t, store.Delete(ctx, key))
+	require.NoError(t, store.Delete(ctx, key), "deleting a missing blob isn't an error")
+
+	_, err = store.Get(ctx, key)
+	assert.ErrorIs(t, err, ErrBlobNotFound)
+}
+
+func TestValidBlobKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"categories/phone/banner.png", true},
+		{"a", true},
+		{"", false},
+		{"/absolute.png", false},
+		{"categories//banner.png", false},
+		{"categories/../../etc/passwd", false},
+		{"./banner.png", false},
+		{`categories\banner.png`, false},
+		{strings.Repeat("a", 1025), false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ValidBlobKey(tt.key), tt.key)
+	}
+}
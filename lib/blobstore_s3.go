@@ -0,0 +1,270 @@
+package lib
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	s3Service         = "s3"
+	s3Algorithm       = "AWS4-HMAC-SHA256"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3EmptyPayload    = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" // sha256 of ""
+	s3AmzDateFormat   = "20060102T150405Z"
+	s3ErrorBodyLimit  = 1 << 10
+)
+
+type S3Config struct {
+	Endpoint  string // e.g. https://s3.us-east-1.amazonaws.com or http://localhost:9000 for MinIO
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PublicURL string // url prefix of public blobs, endpoint/bucket if empty
+}
+
+// S3BlobStore stores blobs in an S3-compatible bucket with path-style urls(endpoint/bucket/key), requests are signed
+// with AWS Signature Version 4. Uploads are streamed with an unsigned payload, so they aren't buffered in memory.
+type S3BlobStore struct {
+	cfg    S3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3BlobStore validates cfg, client is http.DefaultClient if nil.
+func NewS3BlobStore(cfg S3Config, client *http.Client) (*S3BlobStore, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" || cfg.Region == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, errors.New("s3 blob store needs endpoint, region, bucket, access key and secret key")
+	}
+
+	if _, err := url.Parse(cfg.Endpoint); err != nil {
+		return nil, fmt.Errorf("invalid s3 endpoint: %w", err)
+	}
+
+	cfg.Endpoint = strings.TrimSuffix(cfg.Endpoint, "/")
+	if cfg.PublicURL == "" {
+		cfg.PublicURL = cfg.Endpoint + "/" + cfg.Bucket
+	}
+
+	cfg.PublicURL = strings.TrimSuffix(cfg.PublicURL, "/")
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &S3BlobStore{cfg: cfg, client: client, now: time.Now}, nil
+}
+
+func (s *S3BlobStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	req, err := s.newRequest(ctx, http.MethodPut, key, r)
+	if err != nil {
+		return err
+	}
+
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := s.do(req, s3UnsignedPayload)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return s3Error(resp)
+	}
+
+	return nil
+}
+
+func (s *S3BlobStore) Get(ctx context.Context, key string) (*Blob, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(req, s3EmptyPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return &Blob{Body: resp.Body, ContentType: resp.Header.Get("Content-Type"), Size: resp.ContentLength}, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrBlobNotFound
+	default:
+		defer resp.Body.Close()
+		return nil, s3Error(resp)
+	}
+}
+
+func (s *S3BlobStore) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(req, s3EmptyPayload)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return s3Error(resp)
+	}
+
+	return nil
+}
+
+func (s *S3BlobStore) URL(key string) string {
+	return s.cfg.PublicURL + "/" + s3EscapePath(key)
+}
+
+func (s *S3BlobStore) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	if !ValidBlobKey(key) {
+		return nil, fmt.Errorf("invalid blob key %q", key)
+	}
+
+	objectURL := s.cfg.Endpoint + "/" + s3EscapePath(s.cfg.Bucket) + "/" + s3EscapePath(key)
+
+	req, err := http.NewRequestWithContext(ctx, method, objectURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create s3 request: %w", err)
+	}
+
+	return req, nil
+}
+
+func (s *S3BlobStore) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	signS3Request(req, payloadHash, s.cfg.Region, s.cfg.AccessKey, s.cfg.SecretKey, s.now().UTC())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3 %s request failed: %w", req.Method, err)
+	}
+
+	return resp, nil
+}
+
+// s3Error reads the start of an S3 error response, its XML body has the error code like AccessDenied.
+func s3Error(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, s3ErrorBodyLimit))
+	return fmt.Errorf("s3 %s request failed with status %d: %s", resp.Request.Method, resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// signS3Request adds x-amz-date, x-amz-content-sha256 and Authorization headers of AWS Signature Version 4,
+// host, content-type, range and x-amz-* headers are signed.
+func signS3Request(req *http.Request, payloadHash, region, accessKey, secretKey string, now time.Time) {
+	amzDate := now.Format(s3AmzDateFormat)
+	scope := strings.Join([]string{amzDate[:8], region, s3Service, "aws4_request"}, "/")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || lower == "range" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3EscapePath(req.URL.Path),
+		s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	stringToSign := strings.Join([]string{s3Algorithm, amzDate, scope, sha256Hex(canonicalRequest)}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+secretKey), amzDate[:8])
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, s3Service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, accessKey, scope, signedHeaders, signature))
+}
+
+// s3EscapePath escapes every path segment as RFC 3986 unreserved characters, slashes are kept.
+func s3EscapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = s3Escape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func s3Escape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+
+		for _, value := range values {
+			pairs = append(pairs, s3Escape(key)+"="+s3Escape(value))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,50 @@
+package lib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JSONWithETag writes obj as JSON with a strong ETag of the body and the given Cache-Control header,
+// responds 304 Not Modified without body when If-None-Match matches the ETag.
+func JSONWithETag(c *gin.Context, code int, obj interface{}, cacheControl string) {
+	body, err := json.Marshal(obj)
+	if err != nil {
+		_ = c.Error(NewUnexpectedError("unable to encode response").Wrap(err))
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", cacheControl)
+
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(code, "application/json; charset=utf-8", body)
+}
+
+// etagMatches reports whether an If-None-Match header value matches etag, uses weak comparison as RFC 9110 requires.
+func etagMatches(ifNoneMatch string, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
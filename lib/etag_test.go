@@ -0,0 +1,64 @@
+package lib
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONWithETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.GET("/categories", func(c *gin.Context) {
+		JSONWithETag(c, http.StatusOK, gin.H{"name": "Phone"}, "no-cache")
+	})
+
+	serve := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, "/categories", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		return resp
+	}
+
+	first := serve("")
+	etag := first.Header().Get("ETag")
+
+	require.Equal(t, http.StatusOK, first.Code)
+	require.NotEmpty(t, etag)
+	assert.Equal(t, "no-cache", first.Header().Get("Cache-Control"))
+	assert.JSONEq(t, `{"name":"Phone"}`, first.Body.String())
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		wantStatus  int
+	}{
+		{"Same etag", etag, http.StatusNotModified},
+		{"Weak etag", "W/" + etag, http.StatusNotModified},
+		{"One of many", `"stale", ` + etag, http.StatusNotModified},
+		{"Any", "*", http.StatusNotModified},
+		{"Stale etag", `"stale"`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := serve(tt.ifNoneMatch)
+			assert.Equal(t, tt.wantStatus, resp.Code)
+			assert.Equal(t, etag, resp.Header().Get("ETag"))
+
+			if tt.wantStatus == http.StatusNotModified {
+				assert.Empty(t, resp.Body.String())
+			}
+		})
+	}
+}
@@ -1,12 +1,22 @@
 package lib
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
-	"net/http"
+	"log/slog"
 
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	TraceIDKey    = "traceId"
+	TraceIDHeader = "X-Request-ID"
+
+	// maxTraceIDLength limits an incoming X-Request-ID, longer ones are replaced by a generated id.
+	maxTraceIDLength = 64
+)
+
 // Logger implements logger util for gin
 func Logger(param gin.LogFormatterParams) string {
 	return fmt.Sprintf("[%s] | %s | %s | %d | %s |%s\n",
@@ -22,11 +32,51 @@ func Logger(param gin.LogFormatterParams) string {
 	)
 }
 
-// Recover from any panics and writes a 500 if there was one.
-func Recover(c *gin.Context, recovered interface{}) {
-	if err, ok := recovered.(string); ok {
-		c.String(http.StatusInternalServerError, fmt.Sprintf("error: %s", err))
+// Recover returns the gin recovery handler, it writes a 500 problem response for a panic and logs it with l.
+// Register it with gin.CustomRecovery before routes, after TraceID so the problem has the trace id.
+func Recover(l *slog.Logger) gin.RecoveryFunc {
+	return func(c *gin.Context, recovered interface{}) {
+		WriteProblem(c, l, NewUnexpectedError("unexpected error").Wrap(fmt.Errorf("panic: %v", recovered)))
+	}
+}
+
+// TraceID middleware reuses the incoming X-Request-ID header or generates a new one,
+// stores it in gin context under TraceIDKey and echoes it back in the response header.
+// The header is client input, it's reused only if it's a token of at most 64 letters, digits, dots, underscores or hyphens.
+func TraceID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		traceID := c.GetHeader(TraceIDHeader)
+		if !validTraceID(traceID) {
+			traceID = newTraceID()
+		}
+
+		c.Set(TraceIDKey, traceID)
+		c.Header(TraceIDHeader, traceID)
+		c.Next()
+	}
+}
+
+func validTraceID(traceID string) bool {
+	if traceID == "" || len(traceID) > maxTraceIDLength {
+		return false
+	}
+
+	for _, r := range traceID {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func newTraceID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
 	}
 
-	c.AbortWithStatus(http.StatusInternalServerError)
+	return hex.EncodeToString(b)
 }
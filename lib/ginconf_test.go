@@ -0,0 +1,75 @@
+package lib
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceID(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		reused bool
+	}{
+		{"Missing header", "", false},
+		{"Token", "4f1c2b.req_01-A", true},
+		{"At max length", strings.Repeat("a", maxTraceIDLength), true},
+		{"Too long", strings.Repeat("a", maxTraceIDLength+1), false},
+		{"Unsafe characters", "id\" onload=alert(1)", false},
+		{"Non ascii", "ট্রেস", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+
+			r := gin.New()
+			r.Use(TraceID())
+			r.GET("/", func(c *gin.Context) { got = c.GetString(TraceIDKey) })
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(TraceIDHeader, tt.header)
+			}
+
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			if tt.reused {
+				assert.Equal(t, tt.header, got)
+			} else {
+				assert.Len(t, got, 32, "trace id should be generated")
+			}
+
+			assert.Equal(t, got, resp.Header().Get(TraceIDHeader))
+		})
+	}
+}
+
+func TestRecover(t *testing.T) {
+	r := gin.New()
+	r.Use(TraceID(), gin.CustomRecovery(Recover(testLogger)), ErrorHandler(testLogger))
+	r.GET("/panic", func(c *gin.Context) { panic("nil map") })
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set(TraceIDHeader, "trace-123")
+
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	var p Problem
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&p))
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Equal(t, ProblemContentType, resp.Header().Get("Content-Type"))
+	assert.Equal(t, CodeUnexpected, p.Code)
+	assert.Equal(t, "trace-123", p.TraceID)
+	assert.Empty(t, p.Causes)
+}
@@ -0,0 +1,100 @@
+package lib
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+
+	"golang.org/x/text/language"
+)
+
+// DefaultLocale is used when Accept-Language is missing or none of the requested locales is supported.
+const DefaultLocale = "en"
+
+// Args holds named template arguments of a catalog message, e.g. {{.name}}.
+type Args map[string]interface{}
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+var (
+	// supportedLocales lists the embedded locales, DefaultLocale must be the first one.
+	supportedLocales = []language.Tag{language.English, language.Bengali}
+	localeMatcher    = language.NewMatcher(supportedLocales)
+	catalog          = mustLoadCatalog()
+)
+
+// mustLoadCatalog parses every embedded locales/<locale>.json file into message templates,
+// panics on malformed files, so a broken catalog fails at startup instead of at request time.
+func mustLoadCatalog() map[string]map[string]*template.Template {
+	c := make(map[string]map[string]*template.Template, len(supportedLocales))
+
+	for _, tag := range supportedLocales {
+		locale := tag.String()
+
+		data, err := localeFS.ReadFile(path.Join("locales", locale+".json"))
+		if err != nil {
+			panic(fmt.Sprintf("missing message file for locale %s: %v", locale, err))
+		}
+
+		var messages map[string]string
+		if err = json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("invalid message file for locale %s: %v", locale, err))
+		}
+
+		c[locale] = make(map[string]*template.Template, len(messages))
+
+		for code, msg := range messages {
+			tmpl, tmplErr := template.New(code).Option("missingkey=zero").Parse(msg)
+			if tmplErr != nil {
+				panic(fmt.Sprintf("invalid message %s for locale %s: %v", code, locale, tmplErr))
+			}
+
+			c[locale][code] = tmpl
+		}
+	}
+
+	return c
+}
+
+// Localize renders the catalog message of code in locale with args,
+// returns false if the locale or code is not in the catalog.
+func Localize(locale, code string, args Args) (string, bool) {
+	tmpl, ok := catalog[locale][code]
+	if !ok {
+		return "", false
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, args); err != nil {
+		return "", false
+	}
+
+	return strings.TrimSpace(buf.String()), true
+}
+
+// NegotiateLocale picks the best supported locale for an Accept-Language header value,
+// e.g. "bn-BD,bn;q=0.9,en;q=0.8" returns "bn", falls back to DefaultLocale.
+func NegotiateLocale(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return DefaultLocale
+	}
+
+	_, index, confidence := localeMatcher.Match(tags...)
+	if confidence == language.No {
+		return DefaultLocale
+	}
+
+	return supportedLocales[index].String()
+}
+
+// IsSupportedLocale reports whether locale is one of the embedded catalog locales, e.g. "en", "bn".
+func IsSupportedLocale(locale string) bool {
+	_, ok := catalog[locale]
+	return ok
+}
@@ -0,0 +1,144 @@
+package lib
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateLocale(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		want           string
+	}{
+		{"Empty header", "", "en"},
+		{"English", "en-US,en;q=0.9", "en"},
+		{"Bengali Bangladesh", "bn-BD", "bn"},
+		{"Bengali preferred over English", "bn-BD,bn;q=0.9,en-US;q=0.8,en;q=0.7", "bn"},
+		{"English preferred by quality", "bn;q=0.5,en;q=0.9", "en"},
+		{"Unsupported falls back to default", "fr-FR,de;q=0.8", "en"},
+		{"Unsupported first then Bengali", "fr-FR,bn;q=0.5", "bn"},
+		{"Malformed header", ";;;q=abc", "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NegotiateLocale(tt.acceptLanguage))
+		})
+	}
+}
+
+func TestIsSupportedLocale(t *testing.T) {
+	assert.True(t, IsSupportedLocale("en"))
+	assert.True(t, IsSupportedLocale("bn"))
+	assert.False(t, IsSupportedLocale("bn-BD"))
+	assert.False(t, IsSupportedLocale("fr"))
+	assert.False(t, IsSupportedLocale(""))
+}
+
+func TestLocalize(t *testing.T) {
+	msg, ok := Localize("en", "category_name_exists", Args{"name": "Phone"})
+	require.True(t, ok)
+	assert.Equal(t, "category name already exists, input: Phone", msg)
+
+	msg, ok = Localize("bn", "field.required", Args{"field": "email"})
+	require.True(t, ok)
+	assert.Equal(t, "email আবশ্যক", msg)
+
+	_, ok = Localize("en", "no_such_code", nil)
+	assert.False(t, ok)
+
+	_, ok = Localize("fr", "not_found", nil)
+	assert.False(t, ok)
+}
+
+// TestCatalogCompleteness makes sure every locale translates exactly the same codes.
+func TestCatalogCompleteness(t *testing.T) {
+	defaultMessages := catalog[DefaultLocale]
+	require.NotEmpty(t, defaultMessages)
+
+	for locale, messages := range catalog {
+		assert.Len(t, messages, len(defaultMessages), "locale %s", locale)
+
+		for code := range defaultMessages {
+			assert.Contains(t, messages, code, "locale %s is missing code %s", locale, code)
+		}
+	}
+}
+
+func TestNewError(t *testing.T) {
+	apiErr := NewError(http.StatusConflict, "category_name_exists", Args{"name": "Phone"})
+
+	assert.Equal(t, http.StatusConflict, apiErr.StatusCode())
+	assert.Equal(t, "category_name_exists", apiErr.ErrorCode())
+	assert.Equal(t, "category name already exists, input: Phone", apiErr.AsMessage())
+
+	unknown := NewError(http.StatusBadRequest, "no_such_code", nil)
+	assert.Equal(t, "no_such_code", unknown.AsMessage())
+}
+
+func TestWriteProblemLocalized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var fieldErrs ValidationErrors
+	fieldErrs.Add("email", FieldCodeInvalidFormat, "invalid email, you entered john")
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		err            APIError
+		wantTitle      string
+		wantDetail     string
+		wantCause      string
+	}{
+		{
+			name:       "English keeps original message",
+			err:        NewError(http.StatusConflict, "category_name_exists", Args{"name": "Phone"}),
+			wantTitle:  "Conflict",
+			wantDetail: "category name already exists, input: Phone",
+		},
+		{
+			name:           "Bengali catalog message with args",
+			acceptLanguage: "bn-BD,en;q=0.5",
+			err:            NewError(http.StatusConflict, "category_name_exists", Args{"name": "Phone"}),
+			wantTitle:      "দ্বন্দ্ব",
+			wantDetail:     "ক্যাটাগরির নাম ইতিমধ্যে বিদ্যমান: Phone",
+		},
+		{
+			name:           "Bengali field errors",
+			acceptLanguage: "bn",
+			err:            NewValidationError("invalid create user input", fieldErrs),
+			wantTitle:      "অবৈধ অনুরোধ",
+			wantDetail:     "প্রদত্ত তথ্য সঠিক নয়",
+			wantCause:      "email এর ফরম্যাট সঠিক নয়",
+		},
+		{
+			name:           "English field errors",
+			acceptLanguage: "en",
+			err:            NewValidationError("invalid create user input", fieldErrs),
+			wantTitle:      "Bad Request",
+			wantDetail:     "invalid create user input",
+			wantCause:      "invalid email, you entered john",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProblem(tt.err, "/categories", "", NegotiateLocale(tt.acceptLanguage))
+
+			assert.Equal(t, tt.wantTitle, p.Title)
+			assert.Equal(t, tt.wantDetail, p.Detail)
+
+			if tt.wantCause != "" {
+				require.Len(t, p.Causes, 1)
+				fe, ok := p.Causes[0].(FieldError)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantCause, fe.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ProblemContentType = "application/problem+json"
+
+	// ProblemTypeBaseURI is prefixed to an error code to build the problem type URI,
+	// every code is documented on that page.
+	ProblemTypeBaseURI = "https://github.com/ashtishad/ecommerce/blob/main/docs/errors.md#"
+)
+
+// Problem is the RFC 7807 problem details body for an APIError.
+// Code and TraceID are extension members, Causes is only populated for 4xx responses.
+type Problem struct {
+	Type     string        `json:"type"`
+	Title    string        `json:"title"`
+	Status   int           `json:"status"`
+	Detail   string        `json:"detail,omitempty"`
+	Instance string        `json:"instance,omitempty"`
+	Code     string        `json:"code"`
+	TraceID  string        `json:"traceId,omitempty"`
+	Causes   []interface{} `json:"causes,omitempty"`
+}
+
+// NewProblem converts an APIError to Problem localized for locale, causes of 5xx errors are stripped
+// and wrapped error values are never public, so database errors never leak to clients.
+// Detail keeps the original message for DefaultLocale, other locales use the catalog message of the error code
+// and fall back to the original message if the code isn't translated.
+func NewProblem(err APIError, instance, traceID, locale string) Problem {
+	p := Problem{
+		Type:     ProblemTypeBaseURI + err.ErrorCode(),
+		Title:    http.StatusText(err.StatusCode()),
+		Status:   err.StatusCode(),
+		Detail:   err.AsMessage(),
+		Instance: instance,
+		Code:     err.ErrorCode(),
+		TraceID:  traceID,
+	}
+
+	if title, ok := Localize(locale, fmt.Sprintf("status.%d", err.StatusCode()), nil); ok {
+		p.Title = title
+	}
+
+	e, ok := err.(apiError)
+	if !ok {
+		return p
+	}
+
+	if locale != DefaultLocale {
+		if detail, found := Localize(locale, e.ErrorCode(), e.Args); found {
+			p.Detail = detail
+		}
+	}
+
+	if e.Code < http.StatusInternalServerError {
+		p.Causes = publicCauses(e.Causes, locale)
+	}
+
+	return p
+}
+
+// publicCauses drops wrapped error values, they're logged by WriteProblem instead,
+// and translates field error messages by their field error code for non default locales.
+func publicCauses(causes []interface{}, locale string) []interface{} {
+	var public []interface{}
+
+	for _, cause := range causes {
+		switch c := cause.(type) {
+		case FieldError:
+			if locale != DefaultLocale {
+				if msg, found := Localize(locale, "field."+c.Code, Args{"field": c.Field}); found {
+					c.Message = msg
+				}
+			}
+
+			public = append(public, c)
+		case error:
+			continue
+		default:
+			public = append(public, c)
+		}
+	}
+
+	return public
+}
+
+// WriteProblem writes err as application/problem+json in the locale negotiated from Accept-Language
+// and aborts the gin context, 5xx errors and 4xx errors wrapping an error value are logged
+// with their causes and trace id before stripping.
+//
+// Example usage:
+//
+//	if apiErr != nil {
+//		lib.WriteProblem(c, l, apiErr)
+//		return
+//	}
+func WriteProblem(c *gin.Context, l *slog.Logger, err APIError) {
+	traceID := c.GetString(TraceIDKey)
+	locale := NegotiateLocale(c.GetHeader("Accept-Language"))
+
+	if err.StatusCode() >= http.StatusInternalServerError {
+		l.Error("internal server error", "code", err.ErrorCode(), "traceId", traceID, "path", c.Request.URL.Path, "err", err.Error())
+	} else if wrapped, ok := err.(interface{ Unwrap() []error }); ok && len(wrapped.Unwrap()) > 0 {
+		l.Info("client error", "code", err.ErrorCode(), "traceId", traceID, "path", c.Request.URL.Path, "err", err.Error())
+	}
+
+	c.Header("Content-Type", ProblemContentType)
+	c.Header("Content-Language", locale)
+	c.AbortWithStatusJSON(err.StatusCode(), NewProblem(err, c.Request.URL.Path, traceID, locale))
+}
+
+// ErrorHandler middleware renders the last error attached with c.Error as a problem response,
+// so handlers only attach the error and return. Errors other than APIError are treated as internal errors.
+//
+// Example usage:
+//
+//	if apiErr != nil {
+//		_ = c.Error(apiErr)
+//		return
+//	}
+func ErrorHandler(l *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		lastErr := c.Errors.Last()
+		if lastErr == nil || c.Writer.Written() {
+			return
+		}
+
+		var apiErr APIError
+		if !errors.As(lastErr.Err, &apiErr) {
+			apiErr = NewUnexpectedError("unexpected error").Wrap(lastErr.Err)
+		}
+
+		WriteProblem(c, l, apiErr)
+	}
+}
@@ -0,0 +1,161 @@
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func serveProblem(t *testing.T, apiErr APIError, traceID string) (*httptest.ResponseRecorder, Problem) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(TraceID())
+	r.GET("/users", func(c *gin.Context) {
+		WriteProblem(c, testLogger, apiErr)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/users", nil)
+	if traceID != "" {
+		req.Header.Set(TraceIDHeader, traceID)
+	}
+
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	var p Problem
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&p))
+
+	return resp, p
+}
+
+func TestWriteProblemClientError(t *testing.T) {
+	var fieldErrs ValidationErrors
+	fieldErrs.Add("email", FieldCodeInvalidFormat, "invalid email")
+
+	resp, p := serveProblem(t, NewValidationError("invalid create user input", fieldErrs), "trace-123")
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, ProblemContentType, resp.Header().Get("Content-Type"))
+	assert.Equal(t, "trace-123", resp.Header().Get(TraceIDHeader))
+
+	assert.Equal(t, ProblemTypeBaseURI+CodeValidationFailed, p.Type)
+	assert.Equal(t, "Bad Request", p.Title)
+	assert.Equal(t, http.StatusBadRequest, p.Status)
+	assert.Equal(t, "invalid create user input", p.Detail)
+	assert.Equal(t, "/users", p.Instance)
+	assert.Equal(t, CodeValidationFailed, p.Code)
+	assert.Equal(t, "trace-123", p.TraceID)
+	require.Len(t, p.Causes, 1)
+}
+
+func TestWriteProblemStripsServerErrorCauses(t *testing.T) {
+	apiErr := NewInternalServerError(UnexpectedDatabaseErr, errors.New(`pq: relation "users" does not exist`))
+
+	resp, p := serveProblem(t, apiErr, "")
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Equal(t, CodeInternal, p.Code)
+	assert.Equal(t, UnexpectedDatabaseErr, p.Detail)
+	assert.Empty(t, p.Causes)
+	assert.NotEmpty(t, p.TraceID, "trace id should be generated when not provided")
+	assert.NotContains(t, resp.Body.String(), "relation")
+}
+
+func TestWriteProblemStripsClientErrorCauses(t *testing.T) {
+	pgErr := &pgconn.PgError{Code: "23505", ConstraintName: "uq_category_sibling_slug",
+		Message: `duplicate key value violates unique constraint "uq_category_sibling_slug"`}
+	apiErr := NewError(http.StatusConflict, CodeConflict, nil).Wrap(pgErr)
+
+	resp, p := serveProblem(t, apiErr, "")
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Empty(t, p.Causes)
+	assert.NotContains(t, resp.Body.String(), "uq_category_sibling_slug")
+	assert.NotContains(t, resp.Body.String(), "23505")
+	assert.ErrorAs(t, apiErr, &pgErr, "wrapped error is still matched")
+}
+
+func TestErrorCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		err  APIError
+		want string
+	}{
+		{"Bad request", NewBadRequestError("bad"), CodeBadRequest},
+		{"Not found", NewNotFoundError("missing"), CodeNotFound},
+		{"Unauthorized", NewUnauthorizedError("who"), CodeUnauthorized},
+		{"Conflict", NewDBFieldConflictError("exists"), CodeConflict},
+		{"Internal", NewInternalServerError("db", nil), CodeInternal},
+		{"Unexpected", NewUnexpectedError("oops"), CodeUnexpected},
+		{"Overridden", NewDBFieldConflictError("exists").WithCode("user_email_exists"), "user_email_exists"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.err.ErrorCode())
+		})
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(TraceID(), ErrorHandler(testLogger))
+	r.GET("/api-error", func(c *gin.Context) {
+		_ = c.Error(NewNotFoundError("user not found"))
+	})
+	r.GET("/plain-error", func(c *gin.Context) {
+		_ = c.Error(errors.New("dial tcp: connection refused"))
+	})
+	r.GET("/ok", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantCode   string
+	}{
+		{"/api-error", http.StatusNotFound, CodeNotFound},
+		{"/plain-error", http.StatusInternalServerError, CodeUnexpected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.wantStatus, resp.Code)
+			assert.Equal(t, ProblemContentType, resp.Header().Get("Content-Type"))
+			assert.NotContains(t, resp.Body.String(), "connection refused")
+
+			var p Problem
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&p))
+			assert.Equal(t, tt.wantCode, p.Code)
+		})
+	}
+
+	t.Run("/ok", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/ok", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.JSONEq(t, `{"ok":true}`, resp.Body.String())
+	})
+}
@@ -7,7 +7,41 @@ const (
 	TimeoutUpdateUser = 100 * time.Millisecond
 	TimeoutGetUsers   = 200 * time.Millisecond
 
-	TimeoutCreateCategory    = 100 * time.Millisecond
-	TimeoutCreateSubcategory = 200 * time.Millisecond
-	TimeoutGetAllCategories  = 500 * time.Millisecond
+	TimeoutCreateCategory     = 100 * time.Millisecond
+	TimeoutCreateSubcategory  = 200 * time.Millisecond
+	TimeoutGetAllCategories   = 500 * time.Millisecond
+	TimeoutGetCategory        = 100 * time.Millisecond
+	TimeoutUpdateCategory     = 100 * time.Millisecond
+	TimeoutUpdateCatStatus    = 300 * time.Millisecond
+	TimeoutMoveCategory       = 300 * time.Millisecond
+	TimeoutGetCatAncestors    = 100 * time.Millisecond
+	TimeoutGetCatSubtree      = 300 * time.Millisecond
+	TimeoutReorderCategories  = 300 * time.Millisecond
+	TimeoutUpdateCatSlug      = 200 * time.Millisecond
+	TimeoutGetCategoryByPath  = 200 * time.Millisecond
+	TimeoutGetCatAttributes   = 100 * time.Millisecond
+	TimeoutSaveCatAttribute   = 100 * time.Millisecond
+	TimeoutSaveCatTranslation = 200 * time.Millisecond
+	TimeoutImportCategories   = 5 * time.Second
+	TimeoutExportCategories   = 500 * time.Millisecond
+	TimeoutGetCatHistory      = 200 * time.Millisecond
+	TimeoutGetCatTreeAsOf     = 1 * time.Second
+	TimeoutGetCatMedia        = 100 * time.Millisecond
+	TimeoutUploadCatMedia     = 10 * time.Second
+	TimeoutDeleteCatMedia     = 2 * time.Second
+	TimeoutGetMedia           = 10 * time.Second
+	TimeoutDeleteBlobs        = 5 * time.Second
+
+	TimeoutCreateProduct      = 300 * time.Millisecond
+	TimeoutGetProduct         = 100 * time.Millisecond
+	TimeoutGetProducts        = 300 * time.Millisecond
+	TimeoutUpdateProduct      = 300 * time.Millisecond
+	TimeoutSaveProductVariant = 200 * time.Millisecond
+	TimeoutSearchProducts     = 500 * time.Millisecond
+
+	TimeoutCreatePriceList = 200 * time.Millisecond
+	TimeoutGetPriceLists   = 100 * time.Millisecond
+	TimeoutSavePrice       = 200 * time.Millisecond
+	TimeoutGetSKUPrices    = 100 * time.Millisecond
+	TimeoutResolvePrice    = 100 * time.Millisecond
 )
@@ -0,0 +1,74 @@
+package lib
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Field error codes, clients can rely on these values to map an error to a form field.
+const (
+	FieldCodeRequired      = "required"
+	FieldCodeInvalidFormat = "invalid_format"
+	FieldCodeInvalidValue  = "invalid_value"
+	FieldCodeTooShort      = "too_short"
+	FieldCodeTooLong       = "too_long"
+	FieldCodeOutOfRange    = "out_of_range"
+)
+
+// FieldError describes a single failed validation rule of a request field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors collects all field errors of a request, so they can be returned in one response.
+//
+// Example usage:
+//
+//	var fieldErrs lib.ValidationErrors
+//	fieldErrs.Add("email", lib.FieldCodeInvalidFormat, "invalid email")
+//	if fieldErrs.HasErrors() {
+//		return lib.NewValidationError("invalid input", fieldErrs)
+//	}
+type ValidationErrors []FieldError
+
+// Add appends a field error to the collection.
+func (v *ValidationErrors) Add(field, code, message string) {
+	*v = append(*v, FieldError{Field: field, Code: code, Message: message})
+}
+
+// HasErrors reports whether any field error was collected.
+func (v ValidationErrors) HasErrors() bool {
+	return len(v) > 0
+}
+
+// Error implements the error interface, joins all field messages with "; ".
+func (v ValidationErrors) Error() string {
+	messages := make([]string, 0, len(v))
+	for _, fe := range v {
+		messages = append(messages, fe.Message)
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// NewValidationError creates a new APIError for invalid request fields
+// returns http.StatusBadRequest 400, each field error is serialized as a cause.
+//
+// Example usage:
+//
+//	err := NewValidationError("invalid create user input", fieldErrs)
+func NewValidationError(message string, fieldErrs ValidationErrors) APIError {
+	result := apiError{
+		Message: message,
+		Code:    http.StatusBadRequest,
+		ErrCode: CodeValidationFailed,
+	}
+
+	for _, fe := range fieldErrs {
+		result.Causes = append(result.Causes, fe)
+	}
+
+	return result
+}
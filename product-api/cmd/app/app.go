@@ -1,11 +1,14 @@
 package app
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"log/slog"
 	"net/http"
+	"os"
 
+	"github.com/ashtishad/ecommerce/db/conn"
 	"github.com/ashtishad/ecommerce/lib"
 	"github.com/ashtishad/ecommerce/product-api/internal/domain"
 	"github.com/ashtishad/ecommerce/product-api/internal/service"
@@ -20,18 +23,39 @@ func Start(srv *http.Server, dbClient *sql.DB, l *slog.Logger) {
 
 	// wire up the handlers
 	categoryRepoDB := domain.NewCategoryRepoDB(dbClient, l)
+	categoryRepo := newCategoryRepoCache(srv, categoryRepoDB, dbClient, l)
+	translationRepo := domain.NewCategoryTranslationRepoCache(domain.NewCategoryTranslationRepoDB(dbClient, l), categoryRepo)
+	categoryService := service.NewCategoryService(categoryRepo, domain.NewCategoryAttributeRepoDB(dbClient, l),
+		translationRepo, domain.NewCategoryHistoryRepoDB(dbClient, l))
 	ch := CategoryHandlers{
-		service: service.NewCategoryService(categoryRepoDB),
+		service: categoryService,
 		l:       l,
 	}
-	// route url mappings
-	setProductAPIRoutes(r, ch)
 
-	// custom logger middleware
-	r.Use(gin.LoggerWithFormatter(lib.Logger))
+	blobStore, err := lib.NewBlobStoreFromEnv(l)
+	if err != nil {
+		l.Error("unable to create blob store", "err", err)
+		os.Exit(1)
+	}
+
+	mh := CategoryMediaHandlers{
+		service: service.NewCategoryMediaService(categoryRepo, domain.NewCategoryMediaRepoDB(dbClient, l), blobStore, l),
+		l:       l,
+	}
+	ph := ProductHandlers{
+		service: service.NewProductService(domain.NewProductRepoDB(dbClient, l)),
+		l:       l,
+	}
+	prh := PriceHandlers{
+		service: service.NewPriceService(domain.NewPriceRepoDB(dbClient, l)),
+		l:       l,
+	}
+	// trace id, logger, recovery and error rendering middlewares, registered before routes so every handler uses them,
+	// recovery comes after the trace id and the logger, so panics are rendered with the trace id and logged as 500s
+	r.Use(lib.TraceID(), gin.LoggerWithFormatter(lib.Logger), gin.CustomRecovery(lib.Recover(l)), lib.Actor(), lib.ErrorHandler(l))
 
-	// custom recovery middleware
-	r.Use(gin.CustomRecovery(lib.Recover))
+	// route url mappings
+	setProductAPIRoutes(r, ch, mh, ph, prh)
 
 	// start server
 	go func() {
@@ -41,11 +65,80 @@ func Start(srv *http.Server, dbClient *sql.DB, l *slog.Logger) {
 	}()
 }
 
-func setProductAPIRoutes(r *gin.Engine, ch CategoryHandlers) {
+// newCategoryRepoCache wraps category repository with the in-process tree cache,
+// CATEGORY_CACHE_NOTIFY=true enables cross instance invalidation with postgres LISTEN/NOTIFY,
+// listener stops when the server shuts down.
+func newCategoryRepoCache(srv *http.Server, repo domain.CategoryRepository, dbClient *sql.DB, l *slog.Logger) *domain.CategoryRepoCache {
+	if os.Getenv("CATEGORY_CACHE_NOTIFY") != "true" {
+		return domain.NewCategoryRepoCache(repo, nil, l)
+	}
+
+	notifier := domain.NewPGCategoryNotifier(dbClient, conn.GetDSNString(l).String(), l)
+	cache := domain.NewCategoryRepoCache(repo, notifier, l)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	srv.RegisterOnShutdown(cancel)
+
+	go notifier.Listen(ctx, cache.Invalidate)
+
+	return cache
+}
+
+func setProductAPIRoutes(r *gin.Engine, ch CategoryHandlers, mh CategoryMediaHandlers, ph ProductHandlers, prh PriceHandlers) {
 	categoriesRoutes := r.Group("/categories")
 	{
 		categoriesRoutes.GET("", ch.GetAllCategories)
+		categoriesRoutes.GET("/by-path/*path", ch.GetCategoryByPath)
+		categoriesRoutes.GET("/export", ch.ExportCategories)
+		categoriesRoutes.POST("/import", ch.ImportCategories)
 		categoriesRoutes.POST("", ch.CreateCategory)
 		categoriesRoutes.POST("/:category_id/subcategories", ch.CreateSubCategory)
+		categoriesRoutes.GET("/:category_id", ch.GetCategory)
+		categoriesRoutes.PUT("/:category_id", ch.UpdateCategory)
+		categoriesRoutes.PATCH("/:category_id/status", ch.UpdateCategoryStatus)
+		categoriesRoutes.POST("/:category_id/move", ch.MoveCategory)
+		categoriesRoutes.GET("/:category_id/ancestors", ch.GetCategoryAncestors)
+		categoriesRoutes.GET("/:category_id/descendants", ch.GetCategoryDescendants)
+		categoriesRoutes.GET("/:category_id/tree", ch.GetCategoryTree)
+		categoriesRoutes.PUT("/:category_id/children/order", ch.ReorderChildren)
+		categoriesRoutes.PUT("/:category_id/slug", ch.UpdateCategorySlug)
+		categoriesRoutes.GET("/:category_id/attributes", ch.GetCategoryAttributes)
+		categoriesRoutes.PUT("/:category_id/attributes/:code", ch.SaveCategoryAttribute)
+		categoriesRoutes.DELETE("/:category_id/attributes/:code", ch.DeleteCategoryAttribute)
+		categoriesRoutes.GET("/:category_id/translations", ch.GetCategoryTranslations)
+		categoriesRoutes.PUT("/:category_id/translations/:locale", ch.SaveCategoryTranslation)
+		categoriesRoutes.DELETE("/:category_id/translations/:locale", ch.DeleteCategoryTranslation)
+		categoriesRoutes.GET("/:category_id/history", ch.GetCategoryHistory)
+		categoriesRoutes.GET("/:category_id/media", mh.GetCategoryMedia)
+		categoriesRoutes.PUT("/:category_id/media/:kind", mh.UploadCategoryMedia)
+		categoriesRoutes.DELETE("/:category_id/media/:kind", mh.DeleteCategoryMedia)
+		categoriesRoutes.GET("/:category_id/products", ph.GetCategoryProducts)
+	}
+
+	r.GET("/media/*key", mh.GetMedia)
+
+	productsRoutes := r.Group("/products")
+	{
+		productsRoutes.GET("", ph.GetProducts)
+		productsRoutes.GET("/search", ph.SearchProducts)
+		productsRoutes.POST("", ph.CreateProduct)
+		productsRoutes.GET("/:product_id", ph.GetProduct)
+		productsRoutes.PUT("/:product_id", ph.UpdateProduct)
+		productsRoutes.POST("/:product_id/variants", ph.AddProductVariant)
+		productsRoutes.PUT("/:product_id/variants/:variant_id", ph.UpdateProductVariant)
+	}
+
+	priceListsRoutes := r.Group("/price-lists")
+	{
+		priceListsRoutes.GET("", prh.GetPriceLists)
+		priceListsRoutes.POST("", prh.CreatePriceList)
+		priceListsRoutes.POST("/:code/prices", prh.SavePrice)
+		priceListsRoutes.DELETE("/:code/prices/:price_id", prh.DeletePrice)
+	}
+
+	skusRoutes := r.Group("/skus")
+	{
+		skusRoutes.GET("/:sku/prices", prh.GetSKUPrices)
+		skusRoutes.GET("/:sku/price", prh.ResolvePrice)
 	}
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/ashtishad/ecommerce/lib"
 	"github.com/ashtishad/ecommerce/product-api/internal/domain"
@@ -11,6 +13,10 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// categoriesCacheControl lets clients store the category tree but revalidate with ETag on every use,
+// tree cache is invalidated on writes, so revalidation is cheap and never stale.
+const categoriesCacheControl = "no-cache"
+
 type CategoryHandlers struct {
 	service service.CategoryService
 	l       *slog.Logger
@@ -20,7 +26,7 @@ func (ch *CategoryHandlers) CreateCategory(c *gin.Context) {
 	var newCategoryReqDTO domain.NewCategoryRequestDTO
 	if err := c.ShouldBindJSON(&newCategoryReqDTO); err != nil {
 		ch.l.Error("failed to bind create category req dto", "err", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "bad request"})
+		_ = c.Error(lib.NewError(http.StatusBadRequest, lib.CodeInvalidJSONBody, nil).Wrap(err))
 
 		return
 	}
@@ -30,7 +36,7 @@ func (ch *CategoryHandlers) CreateCategory(c *gin.Context) {
 
 	createdCategory, apiErr := ch.service.NewCategory(timeoutCtx, newCategoryReqDTO)
 	if apiErr != nil {
-		c.JSON(apiErr.StatusCode(), apiErr)
+		_ = c.Error(apiErr)
 		return
 	}
 
@@ -40,14 +46,14 @@ func (ch *CategoryHandlers) CreateCategory(c *gin.Context) {
 func (ch *CategoryHandlers) CreateSubCategory(c *gin.Context) {
 	parentUUID := c.Param("category_id")
 	if parentUUID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "parent category id shouldn't be empty"})
+		_ = c.Error(lib.NewError(http.StatusBadRequest, domain.ErrCodeParentCategoryIDRequired, nil))
 		return
 	}
 
 	var newCategoryReqDTO domain.NewCategoryRequestDTO
 	if err := c.ShouldBindJSON(&newCategoryReqDTO); err != nil {
 		ch.l.Error("failed to bind create category req dto", "err", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "bad request"})
+		_ = c.Error(lib.NewError(http.StatusBadRequest, lib.CodeInvalidJSONBody, nil).Wrap(err))
 
 		return
 	}
@@ -57,24 +63,458 @@ func (ch *CategoryHandlers) CreateSubCategory(c *gin.Context) {
 
 	createdCategory, apiErr := ch.service.NewSubCategory(timeoutCtx, newCategoryReqDTO, parentUUID)
 	if apiErr != nil {
-		c.JSON(apiErr.StatusCode(), apiErr)
+		_ = c.Error(apiErr)
 		return
 	}
 
 	c.JSON(http.StatusOK, createdCategory)
 }
 
+// GetAllCategories handles GET /categories, names and descriptions are in the locale negotiated from Accept-Language,
+// untranslated categories fall back to the default locale. Optional query params:
+//   - status=active,inactive keeps categories of the statuses, others are dropped with their subtrees.
+//   - maxDepth=N limits levels, rootId=<uuid> returns the category with its subtree.
+//   - fields=categoryUuid,name returns only the selected fields, flat=true returns a flat list with
+//     parentCategoryUuid and slug path, parents before their subcategories.
+//   - asOf=<RFC 3339 timestamp> returns the tree as it was at that time, in default locale.
 func (ch *CategoryHandlers) GetAllCategories(c *gin.Context) {
-	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutGetAllCategories)
+	listReqDTO := domain.CategoryListRequestDTO{
+		Locale:      lib.NegotiateLocale(c.GetHeader("Accept-Language")),
+		AsOf:        c.Query("asOf"),
+		StatusStr:   c.Query("status"),
+		MaxDepthStr: c.Query("maxDepth"),
+		RootUUID:    c.Query("rootId"),
+		FieldsStr:   c.Query("fields"),
+		FlatStr:     c.Query("flat"),
+	}
+
+	timeout := lib.TimeoutGetAllCategories
+	if listReqDTO.AsOf != "" {
+		// past trees aren't cached or translated
+		timeout = lib.TimeoutGetCatTreeAsOf
+		listReqDTO.Locale = lib.DefaultLocale
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), timeout)
 	defer cancel()
 
-	categories, apiErr := ch.service.GetAllCategoriesByHierarchy(timeoutCtx)
+	categories, apiErr := ch.service.GetCategories(timeoutCtx, listReqDTO)
 	if apiErr != nil {
 		ch.l.Error("failed to fetch categories", "err", apiErr.Error())
-		c.JSON(apiErr.StatusCode(), apiErr)
+		_ = c.Error(apiErr)
+
+		return
+	}
+
+	c.Header("Content-Language", listReqDTO.Locale)
+	c.Header("Vary", "Accept-Language")
+	lib.JSONWithETag(c, http.StatusOK, categories, categoriesCacheControl)
+}
+
+// GetCategory handles GET /categories/:category_id, returns a single category without subcategories.
+func (ch *CategoryHandlers) GetCategory(c *gin.Context) {
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutGetCategory)
+	defer cancel()
+
+	category, apiErr := ch.service.GetCategory(timeoutCtx, c.Param("category_id"))
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// UpdateCategory handles PUT /categories/:category_id, renames a category or updates its description.
+func (ch *CategoryHandlers) UpdateCategory(c *gin.Context) {
+	var updateCategoryReqDTO domain.UpdateCategoryRequestDTO
+	if err := c.ShouldBindJSON(&updateCategoryReqDTO); err != nil {
+		ch.l.Error("failed to bind update category req dto", "err", err.Error())
+		_ = c.Error(lib.NewError(http.StatusBadRequest, lib.CodeInvalidJSONBody, nil).Wrap(err))
+
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutUpdateCategory)
+	defer cancel()
+
+	updateCategoryReqDTO.CategoryUUID = c.Param("category_id")
+
+	updatedCategory, apiErr := ch.service.UpdateCategory(timeoutCtx, updateCategoryReqDTO)
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedCategory)
+}
+
+// UpdateCategoryStatus handles PATCH /categories/:category_id/status,
+// query param cascade=true applies the status to all descendants.
+func (ch *CategoryHandlers) UpdateCategoryStatus(c *gin.Context) {
+	var statusReqDTO domain.UpdateCategoryStatusRequestDTO
+	if err := c.ShouldBindJSON(&statusReqDTO); err != nil {
+		ch.l.Error("failed to bind update category status req dto", "err", err.Error())
+		_ = c.Error(lib.NewError(http.StatusBadRequest, lib.CodeInvalidJSONBody, nil).Wrap(err))
+
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutUpdateCatStatus)
+	defer cancel()
+
+	statusReqDTO.CategoryUUID = c.Param("category_id")
+	statusReqDTO.Cascade = c.Query("cascade") == "true"
+
+	updatedCategory, apiErr := ch.service.UpdateCategoryStatus(timeoutCtx, statusReqDTO)
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedCategory)
+}
+
+// MoveCategory handles POST /categories/:category_id/move, re-parents a category with its whole subtree,
+// empty parentCategoryUuid in request body makes it a root category.
+func (ch *CategoryHandlers) MoveCategory(c *gin.Context) {
+	var moveReqDTO domain.MoveCategoryRequestDTO
+	if err := c.ShouldBindJSON(&moveReqDTO); err != nil {
+		ch.l.Error("failed to bind move category req dto", "err", err.Error())
+		_ = c.Error(lib.NewError(http.StatusBadRequest, lib.CodeInvalidJSONBody, nil).Wrap(err))
+
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutMoveCategory)
+	defer cancel()
+
+	moveReqDTO.CategoryUUID = c.Param("category_id")
+
+	movedCategory, apiErr := ch.service.MoveCategory(timeoutCtx, moveReqDTO)
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, movedCategory)
+}
+
+// GetCategoryAncestors handles GET /categories/:category_id/ancestors, returns ancestors ordered from root,
+// query param includeSelf=true appends the category itself, useful for breadcrumbs.
+func (ch *CategoryHandlers) GetCategoryAncestors(c *gin.Context) {
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutGetCatAncestors)
+	defer cancel()
+
+	ancestors, apiErr := ch.service.GetCategoryAncestors(timeoutCtx, c.Param("category_id"), c.Query("includeSelf") == "true")
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, ancestors)
+}
+
+// GetCategoryDescendants handles GET /categories/:category_id/descendants?depth=N, returns children with nested
+// subcategories, depth limits how many levels are returned.
+func (ch *CategoryHandlers) GetCategoryDescendants(c *gin.Context) {
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutGetCatSubtree)
+	defer cancel()
+
+	descendants, apiErr := ch.service.GetCategoryDescendants(timeoutCtx, subtreeRequest(c))
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, descendants)
+}
+
+// GetCategoryTree handles GET /categories/:category_id/tree?depth=N, returns the category with nested subcategories.
+func (ch *CategoryHandlers) GetCategoryTree(c *gin.Context) {
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutGetCatSubtree)
+	defer cancel()
+
+	tree, apiErr := ch.service.GetCategoryTree(timeoutCtx, subtreeRequest(c))
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, tree)
+}
+
+func subtreeRequest(c *gin.Context) domain.CategorySubtreeRequestDTO {
+	return domain.CategorySubtreeRequestDTO{
+		CategoryUUID: c.Param("category_id"),
+		DepthStr:     c.Query("depth"),
+	}
+}
+
+// ReorderChildren handles PUT /categories/:category_id/children/order, request body has the full ordered list
+// of subcategory uuids, returns the category with its reordered subcategories.
+func (ch *CategoryHandlers) ReorderChildren(c *gin.Context) {
+	var reorderReqDTO domain.ReorderChildrenRequestDTO
+	if err := c.ShouldBindJSON(&reorderReqDTO); err != nil {
+		ch.l.Error("failed to bind reorder children req dto", "err", err.Error())
+		_ = c.Error(lib.NewError(http.StatusBadRequest, lib.CodeInvalidJSONBody, nil).Wrap(err))
+
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutReorderCategories)
+	defer cancel()
+
+	reorderReqDTO.CategoryUUID = c.Param("category_id")
+
+	parent, apiErr := ch.service.ReorderChildren(timeoutCtx, reorderReqDTO)
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, parent)
+}
+
+// UpdateCategorySlug handles PUT /categories/:category_id/slug, old slug keeps resolving and redirects to the new one.
+func (ch *CategoryHandlers) UpdateCategorySlug(c *gin.Context) {
+	var slugReqDTO domain.UpdateCategorySlugRequestDTO
+	if err := c.ShouldBindJSON(&slugReqDTO); err != nil {
+		ch.l.Error("failed to bind update category slug req dto", "err", err.Error())
+		_ = c.Error(lib.NewError(http.StatusBadRequest, lib.CodeInvalidJSONBody, nil).Wrap(err))
+
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutUpdateCatSlug)
+	defer cancel()
 
+	slugReqDTO.CategoryUUID = c.Param("category_id")
+
+	updatedCategory, apiErr := ch.service.UpdateCategorySlug(timeoutCtx, slugReqDTO)
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedCategory)
+}
+
+// GetCategoryByPath handles GET /categories/by-path/*path, e.g. /categories/by-path/phone/smartphone/gaming,
+// responds 301 to the canonical path when an old slug or different letter case was used.
+func (ch *CategoryHandlers) GetCategoryByPath(c *gin.Context) {
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutGetCategoryByPath)
+	defer cancel()
+
+	requestedPath := strings.Trim(c.Param("path"), "/")
+
+	category, canonicalPath, apiErr := ch.service.GetCategoryByPath(timeoutCtx, requestedPath)
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	if canonicalPath != requestedPath {
+		segments := strings.Split(canonicalPath, "/")
+		for i := range segments {
+			segments[i] = url.PathEscape(segments[i])
+		}
+
+		c.Redirect(http.StatusMovedPermanently, "/categories/by-path/"+strings.Join(segments, "/"))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// GetCategoryAttributes handles GET /categories/:category_id/attributes, returns attributes the category defines
+// and the ones it inherits, inherited ones have definedByUuid of the ancestor.
+func (ch *CategoryHandlers) GetCategoryAttributes(c *gin.Context) {
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutGetCatAttributes)
+	defer cancel()
+
+	attributes, apiErr := ch.service.GetCategoryAttributes(timeoutCtx, c.Param("category_id"))
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, attributes)
+}
+
+// SaveCategoryAttribute handles PUT /categories/:category_id/attributes/:code, defines an attribute on the category
+// or overrides the inherited one with the same code.
+func (ch *CategoryHandlers) SaveCategoryAttribute(c *gin.Context) {
+	var attributeReqDTO domain.SaveCategoryAttributeRequestDTO
+	if err := c.ShouldBindJSON(&attributeReqDTO); err != nil {
+		ch.l.Error("failed to bind save category attribute req dto", "err", err.Error())
+		_ = c.Error(lib.NewError(http.StatusBadRequest, lib.CodeInvalidJSONBody, nil).Wrap(err))
+
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutSaveCatAttribute)
+	defer cancel()
+
+	attributeReqDTO.CategoryUUID = c.Param("category_id")
+	attributeReqDTO.Code = c.Param("code")
+
+	attribute, apiErr := ch.service.SaveCategoryAttribute(timeoutCtx, attributeReqDTO)
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, attribute)
+}
+
+// DeleteCategoryAttribute handles DELETE /categories/:category_id/attributes/:code, removes the category's own
+// definition, an inherited one with the same code applies again.
+func (ch *CategoryHandlers) DeleteCategoryAttribute(c *gin.Context) {
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutSaveCatAttribute)
+	defer cancel()
+
+	if apiErr := ch.service.DeleteCategoryAttribute(timeoutCtx, c.Param("category_id"), c.Param("code")); apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetCategoryTranslations handles GET /categories/:category_id/translations, lists names and descriptions
+// of the category in non default locales.
+func (ch *CategoryHandlers) GetCategoryTranslations(c *gin.Context) {
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutGetCategory)
+	defer cancel()
+
+	translations, apiErr := ch.service.GetCategoryTranslations(timeoutCtx, c.Param("category_id"))
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, translations)
+}
+
+// SaveCategoryTranslation handles PUT /categories/:category_id/translations/:locale, e.g. /translations/bn.
+func (ch *CategoryHandlers) SaveCategoryTranslation(c *gin.Context) {
+	var translationReqDTO domain.SaveCategoryTranslationRequestDTO
+	if err := c.ShouldBindJSON(&translationReqDTO); err != nil {
+		ch.l.Error("failed to bind save category translation req dto", "err", err.Error())
+		_ = c.Error(lib.NewError(http.StatusBadRequest, lib.CodeInvalidJSONBody, nil).Wrap(err))
+
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutSaveCatTranslation)
+	defer cancel()
+
+	translationReqDTO.CategoryUUID = c.Param("category_id")
+	translationReqDTO.Locale = c.Param("locale")
+
+	translation, apiErr := ch.service.SaveCategoryTranslation(timeoutCtx, translationReqDTO)
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, translation)
+}
+
+// DeleteCategoryTranslation handles DELETE /categories/:category_id/translations/:locale,
+// the locale shows default locale values afterwards.
+func (ch *CategoryHandlers) DeleteCategoryTranslation(c *gin.Context) {
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutSaveCatTranslation)
+	defer cancel()
+
+	if apiErr := ch.service.DeleteCategoryTranslation(timeoutCtx, c.Param("category_id"), c.Param("locale")); apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// maxImportBodyBytes limits the size of an import body, imports run in a single transaction.
+const maxImportBodyBytes = 10 << 20
+
+// ImportCategories handles POST /categories/import, body is a nested JSON tree of categories or a text/csv of
+// path,name,description rows. Categories are upserted by slug path, query param dryRun=true reports the changes
+// without committing them.
+func (ch *CategoryHandlers) ImportCategories(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBodyBytes)
+
+	importReqDTO := domain.CategoryImportRequestDTO{DryRun: c.Query("dryRun") == "true"}
+
+	if c.ContentType() == "text/csv" {
+		importReqDTO.CSV = c.Request.Body
+	} else if err := c.ShouldBindJSON(&importReqDTO.Tree); err != nil {
+		ch.l.Error("failed to bind import categories req dto", "err", err.Error())
+		_ = c.Error(lib.NewError(http.StatusBadRequest, lib.CodeInvalidJSONBody, nil).Wrap(err))
+
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutImportCategories)
+	defer cancel()
+
+	report, apiErr := ch.service.ImportCategories(timeoutCtx, importReqDTO)
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ExportCategories handles GET /categories/export?format=json|csv, returns the category tree in the import format,
+// so an export can be imported to another environment as it is.
+func (ch *CategoryHandlers) ExportCategories(c *gin.Context) {
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutExportCategories)
+	defer cancel()
+
+	switch format := c.DefaultQuery("format", "json"); format {
+	case "json":
+		tree, apiErr := ch.service.ExportCategories(timeoutCtx)
+		if apiErr != nil {
+			_ = c.Error(apiErr)
+			return
+		}
+
+		c.JSON(http.StatusOK, tree)
+	case "csv":
+		body, apiErr := ch.service.ExportCategoriesCSV(timeoutCtx)
+		if apiErr != nil {
+			_ = c.Error(apiErr)
+			return
+		}
+
+		c.Header("Content-Disposition", `attachment; filename="categories.csv"`)
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", body)
+	default:
+		var fieldErrs lib.ValidationErrors
+		fieldErrs.Add("format", lib.FieldCodeInvalidValue, "format must be json or csv, input: "+format)
+		_ = c.Error(lib.NewValidationError("invalid export format", fieldErrs))
+	}
+}
+
+// GetCategoryHistory handles GET /categories/:category_id/history?limit=N&before=<historyId>, returns recorded
+// changes of the category newest first, before pages through older entries.
+func (ch *CategoryHandlers) GetCategoryHistory(c *gin.Context) {
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutGetCatHistory)
+	defer cancel()
+
+	history, apiErr := ch.service.GetCategoryHistory(timeoutCtx, domain.CategoryHistoryRequestDTO{
+		CategoryUUID: c.Param("category_id"),
+		LimitStr:     c.Query("limit"),
+		BeforeStr:    c.Query("before"),
+	})
+	if apiErr != nil {
+		_ = c.Error(apiErr)
 		return
 	}
 
-	c.JSON(http.StatusOK, categories)
+	c.JSON(http.StatusOK, history)
 }
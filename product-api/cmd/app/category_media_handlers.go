@@ -0,0 +1,122 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/ashtishad/ecommerce/lib"
+	"github.com/ashtishad/ecommerce/product-api/internal/domain"
+	"github.com/ashtishad/ecommerce/product-api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// maxMediaBodyBytes limits a media upload body, the file itself is limited to 5MB by the service,
+// the rest leaves room for multipart headers.
+const maxMediaBodyBytes = 6 << 20
+
+// mediaCacheControl lets clients and CDNs cache media forever, every upload is stored under new keys.
+const mediaCacheControl = "public, max-age=31536000, immutable"
+
+type CategoryMediaHandlers struct {
+	service service.CategoryMediaService
+	l       *slog.Logger
+}
+
+// GetCategoryMedia handles GET /categories/:category_id/media, returns banner and icon with thumbnail urls.
+func (mh *CategoryMediaHandlers) GetCategoryMedia(c *gin.Context) {
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutGetCatMedia)
+	defer cancel()
+
+	media, apiErr := mh.service.GetCategoryMedia(timeoutCtx, c.Param("category_id"))
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, media)
+}
+
+// UploadCategoryMedia handles PUT /categories/:category_id/media/:kind, kind is banner or icon,
+// body is multipart/form-data with the image in field "file". Replaces existing media of the kind.
+func (mh *CategoryMediaHandlers) UploadCategoryMedia(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxMediaBodyBytes)
+
+	uploadReqDTO := domain.UploadCategoryMediaRequestDTO{
+		CategoryUUID: c.Param("category_id"),
+		Kind:         c.Param("kind"),
+	}
+
+	fh, err := c.FormFile("file")
+
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case err == nil:
+		file, err := fh.Open()
+		if err != nil {
+			mh.l.Error("failed to open uploaded file", "err", err)
+			_ = c.Error(lib.NewInternalServerError("failed to open uploaded file", err))
+
+			return
+		}
+
+		defer file.Close()
+
+		uploadReqDTO.File, uploadReqDTO.Size = file, fh.Size
+	case errors.As(err, &maxBytesErr):
+		// let the service report the size as a field error
+		uploadReqDTO.File, uploadReqDTO.Size = http.NoBody, maxBytesErr.Limit
+	case !errors.Is(err, http.ErrMissingFile):
+		mh.l.Warn("failed to parse media upload form", "err", err)
+		_ = c.Error(lib.NewError(http.StatusBadRequest, domain.ErrCodeCategoryMediaBodyInvalid, nil).Wrap(err))
+
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutUploadCatMedia)
+	defer cancel()
+
+	media, apiErr := mh.service.UploadCategoryMedia(timeoutCtx, uploadReqDTO)
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, media)
+}
+
+// DeleteCategoryMedia handles DELETE /categories/:category_id/media/:kind, removes the media and its blobs.
+func (mh *CategoryMediaHandlers) DeleteCategoryMedia(c *gin.Context) {
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutDeleteCatMedia)
+	defer cancel()
+
+	if apiErr := mh.service.DeleteCategoryMedia(timeoutCtx, c.Param("category_id"), c.Param("kind")); apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetMedia handles GET /media/*key, streams a stored media blob, e.g. /media/categories/<uuid>/icon/<id>/sm.png.
+// Public urls of the fs blob store point here, s3 urls point to the bucket.
+func (mh *CategoryMediaHandlers) GetMedia(c *gin.Context) {
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutGetMedia)
+	defer cancel()
+
+	blob, apiErr := mh.service.GetMediaBlob(timeoutCtx, strings.TrimPrefix(c.Param("key"), "/"))
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	defer blob.Body.Close()
+
+	c.DataFromReader(http.StatusOK, blob.Size, blob.ContentType, blob.Body, map[string]string{
+		"Cache-Control":          mediaCacheControl,
+		"X-Content-Type-Options": "nosniff",
+	})
+}
@@ -0,0 +1,131 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/ashtishad/ecommerce/lib"
+	"github.com/ashtishad/ecommerce/product-api/internal/domain"
+	"github.com/ashtishad/ecommerce/product-api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type PriceHandlers struct {
+	service service.PriceService
+	l       *slog.Logger
+}
+
+// CreatePriceList handles POST /price-lists, creates a price list of a currency, optionally for one customer group.
+func (prh *PriceHandlers) CreatePriceList(c *gin.Context) {
+	var newPriceListReqDTO domain.NewPriceListRequestDTO
+	if err := c.ShouldBindJSON(&newPriceListReqDTO); err != nil {
+		prh.l.Error("failed to bind create price list req dto", "err", err.Error())
+		_ = c.Error(lib.NewError(http.StatusBadRequest, lib.CodeInvalidJSONBody, nil).Wrap(err))
+
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutCreatePriceList)
+	defer cancel()
+
+	priceList, apiErr := prh.service.NewPriceList(timeoutCtx, newPriceListReqDTO)
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.JSON(http.StatusCreated, priceList)
+}
+
+// GetPriceLists handles GET /price-lists, lists of every customer first, then by customer group and priority.
+func (prh *PriceHandlers) GetPriceLists(c *gin.Context) {
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutGetPriceLists)
+	defer cancel()
+
+	priceLists, apiErr := prh.service.GetPriceLists(timeoutCtx)
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, priceLists)
+}
+
+// SavePrice handles POST /price-lists/:code/prices, replaces the base price of a SKU(200) without startsAt and endsAt,
+// otherwise adds a scheduled price(201).
+func (prh *PriceHandlers) SavePrice(c *gin.Context) {
+	var savePriceReqDTO domain.SavePriceRequestDTO
+	if err := c.ShouldBindJSON(&savePriceReqDTO); err != nil {
+		prh.l.Error("failed to bind save price req dto", "err", err.Error())
+		_ = c.Error(lib.NewError(http.StatusBadRequest, lib.CodeInvalidJSONBody, nil).Wrap(err))
+
+		return
+	}
+
+	savePriceReqDTO.PriceListCode = c.Param("code")
+
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutSavePrice)
+	defer cancel()
+
+	price, apiErr := prh.service.SavePrice(timeoutCtx, savePriceReqDTO)
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	if savePriceReqDTO.StartsAt == nil && savePriceReqDTO.EndsAt == nil {
+		c.JSON(http.StatusOK, price)
+		return
+	}
+
+	c.JSON(http.StatusCreated, price)
+}
+
+// DeletePrice handles DELETE /price-lists/:code/prices/:price_id, removes a scheduled price or a base price,
+// retail base prices follow variant prices and can't be deleted.
+func (prh *PriceHandlers) DeletePrice(c *gin.Context) {
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutSavePrice)
+	defer cancel()
+
+	if apiErr := prh.service.DeletePrice(timeoutCtx, c.Param("code"), c.Param("price_id")); apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetSKUPrices handles GET /skus/:sku/prices, returns base and scheduled prices of a SKU grouped by price list.
+func (prh *PriceHandlers) GetSKUPrices(c *gin.Context) {
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutGetSKUPrices)
+	defer cancel()
+
+	prices, apiErr := prh.service.GetSKUPrices(timeoutCtx, c.Param("sku"))
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, prices)
+}
+
+// ResolvePrice handles GET /skus/:sku/price?customerGroup=b2b&currency=USD&at=2024-11-29T00:00:00Z,
+// returns the price the customer group pays at that moment, now by default.
+func (prh *PriceHandlers) ResolvePrice(c *gin.Context) {
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutResolvePrice)
+	defer cancel()
+
+	price, apiErr := prh.service.ResolvePrice(timeoutCtx, domain.ResolvePriceRequestDTO{
+		SKU:           c.Param("sku"),
+		CustomerGroup: c.Query("customerGroup"),
+		Currency:      c.Query("currency"),
+		At:            c.Query("at"),
+	})
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, price)
+}
@@ -0,0 +1,197 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/ashtishad/ecommerce/lib"
+	"github.com/ashtishad/ecommerce/product-api/internal/domain"
+	"github.com/ashtishad/ecommerce/product-api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type ProductHandlers struct {
+	service service.ProductService
+	l       *slog.Logger
+}
+
+// CreateProduct handles POST /products, creates a product with its categories and variants.
+func (ph *ProductHandlers) CreateProduct(c *gin.Context) {
+	var newProductReqDTO domain.NewProductRequestDTO
+	if err := c.ShouldBindJSON(&newProductReqDTO); err != nil {
+		ph.l.Error("failed to bind create product req dto", "err", err.Error())
+		_ = c.Error(lib.NewError(http.StatusBadRequest, lib.CodeInvalidJSONBody, nil).Wrap(err))
+
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutCreateProduct)
+	defer cancel()
+
+	product, apiErr := ph.service.NewProduct(timeoutCtx, newProductReqDTO)
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.JSON(http.StatusCreated, product)
+}
+
+// GetProducts handles GET /products?status=active&categoryId=<uuid>&includeDescendants=true&brand=samsung&limit=20&after=<productUuid>,
+// returns a page of products newest first, nextCursor is the after param of the next page.
+func (ph *ProductHandlers) GetProducts(c *gin.Context) {
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutGetProducts)
+	defer cancel()
+
+	page, apiErr := ph.service.GetProducts(timeoutCtx, domain.ProductListRequestDTO{
+		Status:                c.Query("status"),
+		CategoryUUID:          c.Query("categoryId"),
+		IncludeDescendantsStr: c.Query("includeDescendants"),
+		Brand:                 c.Query("brand"),
+		LimitStr:              c.Query("limit"),
+		After:                 c.Query("after"),
+	})
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// GetCategoryProducts handles GET /categories/:category_id/products?includeDescendants=true&status=active&limit=20&after=<productUuid>,
+// returns a page of products listed under the category, or anywhere in its subtree with includeDescendants.
+func (ph *ProductHandlers) GetCategoryProducts(c *gin.Context) {
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutGetProducts)
+	defer cancel()
+
+	page, apiErr := ph.service.GetProducts(timeoutCtx, domain.ProductListRequestDTO{
+		Status:                c.Query("status"),
+		CategoryUUID:          c.Param("category_id"),
+		IncludeDescendantsStr: c.Query("includeDescendants"),
+		Brand:                 c.Query("brand"),
+		LimitStr:              c.Query("limit"),
+		After:                 c.Query("after"),
+	})
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// SearchProducts handles GET /products/search?q=gaming phone&categoryId=<uuid>&brand=asus&brand=samsung&minPrice=300
+// &maxPrice=900&attr[color]=black,blue&attr[ram]=8..16&sort=price_asc&limit=20&after=<nextCursor>,
+// returns a page of active products with facet counts, nextCursor is the after param of the next page.
+func (ph *ProductHandlers) SearchProducts(c *gin.Context) {
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutSearchProducts)
+	defer cancel()
+
+	page, apiErr := ph.service.SearchProducts(timeoutCtx, domain.ProductSearchRequestDTO{
+		Query:        c.Query("q"),
+		CategoryUUID: c.Query("categoryId"),
+		Brands:       c.QueryArray("brand"),
+		MinPrice:     c.Query("minPrice"),
+		MaxPrice:     c.Query("maxPrice"),
+		Attributes:   c.QueryMap("attr"),
+		Sort:         c.Query("sort"),
+		LimitStr:     c.Query("limit"),
+		After:        c.Query("after"),
+	})
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// GetProduct handles GET /products/:product_id, returns the product with its categories, breadcrumb and variants.
+func (ph *ProductHandlers) GetProduct(c *gin.Context) {
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutGetProduct)
+	defer cancel()
+
+	product, apiErr := ph.service.GetProduct(timeoutCtx, c.Param("product_id"))
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// UpdateProduct handles PUT /products/:product_id, replaces title, description, brand, status and categories.
+func (ph *ProductHandlers) UpdateProduct(c *gin.Context) {
+	var updateProductReqDTO domain.UpdateProductRequestDTO
+	if err := c.ShouldBindJSON(&updateProductReqDTO); err != nil {
+		ph.l.Error("failed to bind update product req dto", "err", err.Error())
+		_ = c.Error(lib.NewError(http.StatusBadRequest, lib.CodeInvalidJSONBody, nil).Wrap(err))
+
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutUpdateProduct)
+	defer cancel()
+
+	updateProductReqDTO.ProductUUID = c.Param("product_id")
+
+	product, apiErr := ph.service.UpdateProduct(timeoutCtx, updateProductReqDTO)
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// AddProductVariant handles POST /products/:product_id/variants.
+func (ph *ProductHandlers) AddProductVariant(c *gin.Context) {
+	var variantReqDTO domain.ProductVariantRequestDTO
+	if err := c.ShouldBindJSON(&variantReqDTO); err != nil {
+		ph.l.Error("failed to bind add product variant req dto", "err", err.Error())
+		_ = c.Error(lib.NewError(http.StatusBadRequest, lib.CodeInvalidJSONBody, nil).Wrap(err))
+
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutSaveProductVariant)
+	defer cancel()
+
+	variantReqDTO.ProductUUID = c.Param("product_id")
+	variantReqDTO.VariantUUID = ""
+
+	variant, apiErr := ph.service.AddProductVariant(timeoutCtx, variantReqDTO)
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.JSON(http.StatusCreated, variant)
+}
+
+// UpdateProductVariant handles PUT /products/:product_id/variants/:variant_id, replaces sku, barcode, options and price.
+func (ph *ProductHandlers) UpdateProductVariant(c *gin.Context) {
+	var variantReqDTO domain.ProductVariantRequestDTO
+	if err := c.ShouldBindJSON(&variantReqDTO); err != nil {
+		ph.l.Error("failed to bind update product variant req dto", "err", err.Error())
+		_ = c.Error(lib.NewError(http.StatusBadRequest, lib.CodeInvalidJSONBody, nil).Wrap(err))
+
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(c.Request.Context(), lib.TimeoutSaveProductVariant)
+	defer cancel()
+
+	variantReqDTO.ProductUUID = c.Param("product_id")
+	variantReqDTO.VariantUUID = c.Param("variant_id")
+
+	variant, apiErr := ph.service.UpdateProductVariant(timeoutCtx, variantReqDTO)
+	if apiErr != nil {
+		_ = c.Error(apiErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, variant)
+}
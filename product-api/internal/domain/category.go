@@ -5,23 +5,33 @@ import (
 	"time"
 )
 
+const (
+	CategoryStatusActive   = "active"
+	CategoryStatusInactive = "inactive"
+	CategoryStatusDeleted  = "deleted"
+
+	// defaultCategorySlug is used when a category name has no letters or digits.
+	defaultCategorySlug = "category"
+)
+
 type Category struct {
 	CategoryID         int            `json:"categoryId"`
 	CategoryUUID       string         `json:"categoryUuid"`
 	ParentCategoryUUID sql.NullString `json:"parentCategoryUuid"`
 	Name               string         `json:"name"`
+	Slug               string         `json:"slug"`
 	Description        string         `json:"description"`
 	Status             string         `json:"status"`
 	CreatedAt          time.Time      `json:"createdAt"`
 	UpdatedAt          time.Time      `json:"updatedAt"`
 	Level              int            `json:"level"`
-	Subcategories      []Category     `json:"subcategories"`
+	Subcategories      []*Category    `json:"subcategories"`
 }
 
 func (c *Category) ToCategoryResponseDTO() *CategoryResponseDTO {
-	subcategoriesDTO := make([]CategoryResponseDTO, len(c.Subcategories))
+	subcategoriesDTO := make([]*CategoryResponseDTO, len(c.Subcategories))
 	for i, subcat := range c.Subcategories {
-		subcategoriesDTO[i] = *subcat.ToCategoryResponseDTO()
+		subcategoriesDTO[i] = subcat.ToCategoryResponseDTO()
 	}
 
 	parentUUID := ""
@@ -32,6 +42,7 @@ func (c *Category) ToCategoryResponseDTO() *CategoryResponseDTO {
 	return &CategoryResponseDTO{
 		CategoryUUID:       c.CategoryUUID,
 		Name:               c.Name,
+		Slug:               c.Slug,
 		Description:        c.Description,
 		ParentCategoryUUID: parentUUID,
 		Status:             c.Status,
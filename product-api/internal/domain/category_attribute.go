@@ -0,0 +1,42 @@
+package domain
+
+import "database/sql"
+
+const (
+	AttributeTypeString = "string"
+	AttributeTypeEnum   = "enum"
+	AttributeTypeNumber = "number"
+	AttributeTypeBool   = "bool"
+)
+
+// CategoryAttribute is a typed attribute definition(facet) of a category, e.g. battery(number, mAh),
+// descendants inherit it unless they define an attribute with the same code.
+type CategoryAttribute struct {
+	AttributeID  int            `json:"attributeId"`
+	CategoryUUID string         `json:"categoryUuid"` // category that defines the attribute
+	Code         string         `json:"code"`
+	Name         string         `json:"name"`
+	Type         string         `json:"type"`
+	Unit         sql.NullString `json:"unit"`
+	Values       []string       `json:"values"` // allowed values of an enum attribute
+	Required     bool           `json:"required"`
+	Inherited    bool           `json:"inherited"`
+}
+
+func (a *CategoryAttribute) ToCategoryAttributeResponseDTO() *CategoryAttributeResponseDTO {
+	unit := ""
+	if a.Unit.Valid {
+		unit = a.Unit.String
+	}
+
+	return &CategoryAttributeResponseDTO{
+		Code:          a.Code,
+		Name:          a.Name,
+		Type:          a.Type,
+		Unit:          unit,
+		Values:        a.Values,
+		Required:      a.Required,
+		Inherited:     a.Inherited,
+		DefinedByUUID: a.CategoryUUID,
+	}
+}
@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/ashtishad/ecommerce/lib"
+)
+
+type CategoryAttributeRepoDB struct {
+	db *sql.DB
+	l  *slog.Logger
+}
+
+func NewCategoryAttributeRepoDB(db *sql.DB, l *slog.Logger) *CategoryAttributeRepoDB {
+	return &CategoryAttributeRepoDB{db, l}
+}
+
+// FindCategoryAttributes returns effective attributes of a category, its own definitions and the ones inherited
+// from ancestors, the closest definition of each code wins. Root definitions come first, then by code.
+// returns 404 if category doesn't exist.
+func (d *CategoryAttributeRepoDB) FindCategoryAttributes(ctx context.Context, categoryUUID string) ([]CategoryAttribute, lib.APIError) {
+	categoryID, apiErr := selectCategoryID(ctx, d.db, d.l, categoryUUID)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	rows, err := d.db.QueryContext(ctx, sqlSelectEffectiveAttributes, categoryID)
+	if err != nil {
+		d.l.Error("failed to query category attributes", "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	defer closeRows(rows, d.l)
+
+	attributes := make([]CategoryAttribute, 0)
+
+	for rows.Next() {
+		var (
+			a          CategoryAttribute
+			enumValues []byte
+		)
+
+		if err = rows.Scan(&a.AttributeID, &a.CategoryUUID, &a.Code, &a.Name, &a.Type, &a.Unit, &enumValues, &a.Required, &a.Inherited); err != nil {
+			d.l.Error("failed to scan rows:", "err", err)
+			return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+		}
+
+		if err = json.Unmarshal(enumValues, &a.Values); err != nil {
+			d.l.Error("failed to decode attribute enum values", "code", a.Code, "err", err)
+			return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+		}
+
+		attributes = append(attributes, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		d.l.Error("unexpected error on scanning category attribute rows", "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return attributes, nil
+}
+
+// SaveCategoryAttribute defines an attribute on a category, or replaces the category's own definition with the same code.
+// An attribute with the code of an inherited one overrides it for the category and its descendants.
+// returns 404 if category doesn't exist.
+func (d *CategoryAttributeRepoDB) SaveCategoryAttribute(ctx context.Context, categoryUUID string, attribute CategoryAttribute) (*CategoryAttribute, lib.APIError) {
+	categoryID, apiErr := selectCategoryID(ctx, d.db, d.l, categoryUUID)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if attribute.Values == nil {
+		attribute.Values = []string{}
+	}
+
+	enumValues, err := json.Marshal(attribute.Values)
+	if err != nil {
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	err = d.db.QueryRowContext(ctx, sqlUpsertCategoryAttribute,
+		categoryID,
+		attribute.Code,
+		attribute.Name,
+		attribute.Type,
+		attribute.Unit,
+		enumValues,
+		attribute.Required,
+	).Scan(&attribute.AttributeID)
+
+	if err != nil {
+		d.l.Error("failed to save category attribute", "code", attribute.Code, "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, fmt.Errorf("unable to upsert category attribute: %w", err))
+	}
+
+	attribute.CategoryUUID = categoryUUID
+	attribute.Inherited = false
+
+	return &attribute, nil
+}
+
+// DeleteCategoryAttribute removes a category's own attribute definition, an inherited definition with the same code
+// applies again afterwards.
+// returns 404 if category doesn't exist or doesn't define the attribute itself.
+func (d *CategoryAttributeRepoDB) DeleteCategoryAttribute(ctx context.Context, categoryUUID string, code string) lib.APIError {
+	categoryID, apiErr := selectCategoryID(ctx, d.db, d.l, categoryUUID)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	result, err := d.db.ExecContext(ctx, sqlDeleteCategoryAttribute, categoryID, code)
+	if err != nil {
+		d.l.Error("failed to delete category attribute", "code", code, "err", err)
+		return lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	if deleted == 0 {
+		d.l.Warn("category attribute not found", "category", categoryUUID, "code", code)
+		return lib.NewError(http.StatusNotFound, ErrCodeCategoryAttributeNotFound, lib.Args{"code": code})
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func mockAttributeRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"attribute_id", "category_uuid", "code", "name", "type", "unit", "enum_values", "required", "inherited"})
+}
+
+// TestFindCategoryAttributes tests the FindCategoryAttributes method of CategoryAttributeRepoDB.
+// It covers inherited and own attributes, a category without attributes and category not found.
+func TestFindCategoryAttributes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryAttributeRepoDB(db, testLogger)
+	c := mockCategoryObj()
+	idRows := func() *sqlmock.Rows { return sqlmock.NewRows([]string{"category_id"}).AddRow(c.CategoryID) }
+
+	t.Run("Own and inherited attributes", func(t *testing.T) {
+		expectQuery(mock, sqlSelectCategoryID).WithArgs(c.CategoryUUID).WillReturnRows(idRows())
+		expectQuery(mock, sqlSelectEffectiveAttributes).WithArgs(c.CategoryID).WillReturnRows(mockAttributeRows().
+			AddRow(1, c.ParentCategoryUUID.String, "battery", "Battery", AttributeTypeNumber, "mAh", []byte(`[]`), true, true).
+			AddRow(2, c.CategoryUUID, "cooling", "Cooling", AttributeTypeEnum, nil, []byte(`["fan","liquid"]`), false, false))
+
+		attributes, apiErr := repo.FindCategoryAttributes(context.Background(), c.CategoryUUID)
+		require.Nil(t, apiErr)
+		require.Len(t, attributes, 2)
+
+		require.Equal(t, CategoryAttribute{
+			AttributeID: 1, CategoryUUID: c.ParentCategoryUUID.String, Code: "battery", Name: "Battery", Type: AttributeTypeNumber,
+			Unit: sql.NullString{String: "mAh", Valid: true}, Values: []string{}, Required: true, Inherited: true,
+		}, attributes[0])
+		require.Equal(t, []string{"fan", "liquid"}, attributes[1].Values)
+		require.False(t, attributes[1].Inherited)
+		require.False(t, attributes[1].Unit.Valid)
+	})
+
+	t.Run("No attributes", func(t *testing.T) {
+		expectQuery(mock, sqlSelectCategoryID).WithArgs(c.CategoryUUID).WillReturnRows(idRows())
+		expectQuery(mock, sqlSelectEffectiveAttributes).WithArgs(c.CategoryID).WillReturnRows(mockAttributeRows())
+
+		attributes, apiErr := repo.FindCategoryAttributes(context.Background(), c.CategoryUUID)
+		require.Nil(t, apiErr)
+		require.NotNil(t, attributes)
+		require.Empty(t, attributes)
+	})
+
+	t.Run("Category not found", func(t *testing.T) {
+		expectQuery(mock, sqlSelectCategoryID).WithArgs("missing").WillReturnError(sql.ErrNoRows)
+
+		attributes, apiErr := repo.FindCategoryAttributes(context.Background(), "missing")
+		require.Nil(t, attributes)
+		require.Equal(t, ErrCodeCategoryNotFound, apiErr.ErrorCode())
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestSaveCategoryAttribute tests the SaveCategoryAttribute method of CategoryAttributeRepoDB.
+func TestSaveCategoryAttribute(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryAttributeRepoDB(db, testLogger)
+	c := mockCategoryObj()
+
+	t.Run("Attribute saved", func(t *testing.T) {
+		attribute := CategoryAttribute{Code: "anc", Name: "Active noise cancellation", Type: AttributeTypeBool, Required: true}
+
+		expectQuery(mock, sqlSelectCategoryID).WithArgs(c.CategoryUUID).WillReturnRows(sqlmock.NewRows([]string{"category_id"}).AddRow(c.CategoryID))
+		expectQuery(mock, sqlUpsertCategoryAttribute).
+			WithArgs(c.CategoryID, "anc", "Active noise cancellation", AttributeTypeBool, sql.NullString{}, []byte(`[]`), true).
+			WillReturnRows(sqlmock.NewRows([]string{"attribute_id"}).AddRow(7))
+
+		saved, apiErr := repo.SaveCategoryAttribute(context.Background(), c.CategoryUUID, attribute)
+		require.Nil(t, apiErr)
+		require.Equal(t, 7, saved.AttributeID)
+		require.Equal(t, c.CategoryUUID, saved.CategoryUUID)
+		require.False(t, saved.Inherited)
+	})
+
+	t.Run("Category not found", func(t *testing.T) {
+		expectQuery(mock, sqlSelectCategoryID).WithArgs("missing").WillReturnError(sql.ErrNoRows)
+
+		saved, apiErr := repo.SaveCategoryAttribute(context.Background(), "missing", CategoryAttribute{Code: "anc"})
+		require.Nil(t, saved)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestDeleteCategoryAttribute tests the DeleteCategoryAttribute method of CategoryAttributeRepoDB.
+// It covers deleted, attribute not defined by the category and category not found.
+func TestDeleteCategoryAttribute(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryAttributeRepoDB(db, testLogger)
+	c := mockCategoryObj()
+	idRows := func() *sqlmock.Rows { return sqlmock.NewRows([]string{"category_id"}).AddRow(c.CategoryID) }
+
+	t.Run("Attribute deleted", func(t *testing.T) {
+		expectQuery(mock, sqlSelectCategoryID).WithArgs(c.CategoryUUID).WillReturnRows(idRows())
+		expectExec(mock, sqlDeleteCategoryAttribute).WithArgs(c.CategoryID, "anc").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		require.Nil(t, repo.DeleteCategoryAttribute(context.Background(), c.CategoryUUID, "anc"))
+	})
+
+	t.Run("Attribute not defined by category", func(t *testing.T) {
+		expectQuery(mock, sqlSelectCategoryID).WithArgs(c.CategoryUUID).WillReturnRows(idRows())
+		expectExec(mock, sqlDeleteCategoryAttribute).WithArgs(c.CategoryID, "battery").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		apiErr := repo.DeleteCategoryAttribute(context.Background(), c.CategoryUUID, "battery")
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+		require.Equal(t, ErrCodeCategoryAttributeNotFound, apiErr.ErrorCode())
+	})
+
+	t.Run("Category not found", func(t *testing.T) {
+		expectQuery(mock, sqlSelectCategoryID).WithArgs("missing").WillReturnError(sql.ErrNoRows)
+
+		apiErr := repo.DeleteCategoryAttribute(context.Background(), "missing", "anc")
+		require.Equal(t, ErrCodeCategoryNotFound, apiErr.ErrorCode())
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,20 +1,220 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
 
 type CategoryResponseDTO struct {
-	CategoryUUID       string                `json:"categoryUuid"`
-	ParentCategoryUUID string                `json:"parentCategoryUuid,omitempty"`
-	Name               string                `json:"name"`
-	Description        string                `json:"description"`
-	Status             string                `json:"status"`
-	CreatedAt          time.Time             `json:"createdAt"`
-	UpdatedAt          time.Time             `json:"updatedAt"`
-	Level              int                   `json:"level,omitempty"`
-	Subcategories      []CategoryResponseDTO `json:"subcategories,omitempty"`
+	CategoryUUID       string                 `json:"categoryUuid"`
+	ParentCategoryUUID string                 `json:"parentCategoryUuid,omitempty"`
+	Name               string                 `json:"name"`
+	Slug               string                 `json:"slug"`
+	Description        string                 `json:"description"`
+	Status             string                 `json:"status"`
+	CreatedAt          time.Time              `json:"createdAt"`
+	UpdatedAt          time.Time              `json:"updatedAt"`
+	Level              int                    `json:"level,omitempty"`
+	Path               string                 `json:"path,omitempty"` // slug path, only in flat lists
+	Subcategories      []*CategoryResponseDTO `json:"subcategories,omitempty"`
+}
+
+// CategoryListRequestDTO filters and shapes GET /categories, every query param is optional.
+type CategoryListRequestDTO struct {
+	Locale      string // negotiated from Accept-Language
+	AsOf        string `json:"asOf"`     // query param, RFC 3339 timestamp
+	StatusStr   string `json:"status"`   // query param, comma separated e.g. active,inactive
+	MaxDepthStr string `json:"maxDepth"` // query param
+	RootUUID    string `json:"rootId"`   // query param
+	FieldsStr   string `json:"fields"`   // query param, comma separated e.g. categoryUuid,name
+	FlatStr     string `json:"flat"`     // query param
+}
+
+// CategoryListResponseDTO is the response of GET /categories, a tree or a flat list of categories, encoded as
+// a JSON array. Selected is set instead of Categories when fields are selected, with the selected fields only.
+type CategoryListResponseDTO struct {
+	Categories []*CategoryResponseDTO
+	Selected   []map[string]interface{}
+}
+
+func (r CategoryListResponseDTO) MarshalJSON() ([]byte, error) {
+	if r.Selected != nil {
+		return json.Marshal(r.Selected)
+	}
+
+	return json.Marshal(r.Categories)
 }
 
 type NewCategoryRequestDTO struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 }
+
+// UpdateCategoryRequestDTO has the editable fields of a category, used for renaming and fixing descriptions.
+type UpdateCategoryRequestDTO struct {
+	CategoryUUID string `json:"categoryUuid"` // path param
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+}
+
+// MoveCategoryRequestDTO re-parents a category with its whole subtree,
+// empty ParentCategoryUUID makes the category a root category.
+type MoveCategoryRequestDTO struct {
+	CategoryUUID       string `json:"categoryUuid"` // path param
+	ParentCategoryUUID string `json:"parentCategoryUuid"`
+}
+
+// UpdateCategoryStatusRequestDTO changes category status, Cascade applies the status to all descendants.
+type UpdateCategoryStatusRequestDTO struct {
+	CategoryUUID string `json:"categoryUuid"` // path param
+	Status       string `json:"status"`       // Enum 'active', 'inactive', 'deleted'
+	Cascade      bool   `json:"cascade"`      // query param
+}
+
+// CategorySubtreeRequestDTO selects a category subtree, DepthStr limits how many levels below the category
+// are returned, empty means the whole subtree.
+type CategorySubtreeRequestDTO struct {
+	CategoryUUID string `json:"categoryUuid"` // path param
+	DepthStr     string `json:"depth"`        // query param
+}
+
+// ReorderChildrenRequestDTO sets the order of a category's direct subcategories,
+// Order must list every subcategory uuid exactly once, first comes first.
+type ReorderChildrenRequestDTO struct {
+	CategoryUUID string   `json:"categoryUuid"` // path param
+	Order        []string `json:"order"`
+}
+
+// UpdateCategorySlugRequestDTO sets a category slug manually, the previous slug keeps redirecting to the category.
+type UpdateCategorySlugRequestDTO struct {
+	CategoryUUID string `json:"categoryUuid"` // path param
+	Slug         string `json:"slug"`
+}
+
+// CategoryAttributeResponseDTO is an effective attribute of a category, DefinedByUUID is the category that
+// defines it, Inherited is true when that's an ancestor.
+type CategoryAttributeResponseDTO struct {
+	Code          string   `json:"code"`
+	Name          string   `json:"name"`
+	Type          string   `json:"type"`
+	Unit          string   `json:"unit,omitempty"`
+	Values        []string `json:"values,omitempty"`
+	Required      bool     `json:"required"`
+	Inherited     bool     `json:"inherited"`
+	DefinedByUUID string   `json:"definedByUuid"`
+}
+
+// SaveCategoryAttributeRequestDTO defines an attribute on a category or overrides an inherited one with the same code.
+type SaveCategoryAttributeRequestDTO struct {
+	CategoryUUID string   `json:"categoryUuid"` // path param
+	Code         string   `json:"code"`         // path param
+	Name         string   `json:"name"`
+	Type         string   `json:"type"` // Enum 'string', 'enum', 'number', 'bool'
+	Unit         string   `json:"unit"` // only for number, e.g. 'mAh', 'inch'
+	Values       []string `json:"values"`
+	Required     bool     `json:"required"`
+}
+
+type CategoryTranslationDTO struct {
+	Locale      string `json:"locale"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// SaveCategoryTranslationRequestDTO sets name and description of a category in a non default locale.
+type SaveCategoryTranslationRequestDTO struct {
+	CategoryUUID string `json:"categoryUuid"` // path param
+	Locale       string `json:"locale"`       // path param, e.g. 'bn'
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+}
+
+// CategoryTreeNodeDTO is a category of an import or export tree, categories are matched by slug path on import,
+// a missing slug is generated from the name.
+type CategoryTreeNodeDTO struct {
+	Slug          string                 `json:"slug,omitempty"`
+	Name          string                 `json:"name"`
+	Description   string                 `json:"description"`
+	Subcategories []*CategoryTreeNodeDTO `json:"subcategories,omitempty"`
+}
+
+// CategoryImportRequestDTO has either a nested JSON tree or a CSV of path,name,description rows,
+// DryRun reports the changes without committing them.
+type CategoryImportRequestDTO struct {
+	Tree   []*CategoryTreeNodeDTO // JSON body
+	CSV    io.Reader              // text/csv body
+	DryRun bool                   // query param
+}
+
+type CategoryImportReportDTO struct {
+	DryRun    bool                      `json:"dryRun"`
+	Created   int                       `json:"created"`
+	Updated   int                       `json:"updated"`
+	Unchanged int                       `json:"unchanged"`
+	Changes   []CategoryImportChangeDTO `json:"changes"`
+}
+
+type CategoryImportChangeDTO struct {
+	Path        string                   `json:"path"`
+	Action      string                   `json:"action"` // Enum 'create', 'update'
+	Name        string                   `json:"name"`
+	Description string                   `json:"description"`
+	Before      *CategoryImportValuesDTO `json:"before,omitempty"`
+}
+
+type CategoryImportValuesDTO struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// CategoryHistoryRequestDTO lists history of a category newest first, Before is a historyId cursor of the next page.
+type CategoryHistoryRequestDTO struct {
+	CategoryUUID string `json:"categoryUuid"` // path param
+	LimitStr     string `json:"limit"`        // query param
+	BeforeStr    string `json:"before"`       // query param
+}
+
+type CategoryHistoryEntryDTO struct {
+	HistoryID int64                `json:"historyId"`
+	Action    string               `json:"action"` // Enum 'create', 'update', 'move', 'status', 'reorder'
+	Actor     string               `json:"actor"`  // claimed by the X-Actor header, not verified
+	Before    *CategorySnapshotDTO `json:"before"`
+	After     CategorySnapshotDTO  `json:"after"`
+	ChangedAt time.Time            `json:"changedAt"`
+}
+
+type CategorySnapshotDTO struct {
+	Name               string  `json:"name"`
+	Description        string  `json:"description"`
+	Slug               string  `json:"slug"`
+	Status             string  `json:"status"`
+	Position           int     `json:"position"`
+	ParentCategoryUUID *string `json:"parentCategoryUuid"`
+}
+
+// UploadCategoryMediaRequestDTO is a multipart file upload, Size is the declared size of File.
+type UploadCategoryMediaRequestDTO struct {
+	CategoryUUID string    // path param
+	Kind         string    // path param, Enum 'banner', 'icon'
+	File         io.Reader // multipart form field "file", nil if missing
+	Size         int64
+}
+
+type CategoryMediaDTO struct {
+	Kind        string              `json:"kind"` // Enum 'banner', 'icon'
+	URL         string              `json:"url"`
+	ContentType string              `json:"contentType"`
+	Width       int                 `json:"width"`
+	Height      int                 `json:"height"`
+	SizeBytes   int64               `json:"sizeBytes"`
+	Thumbnails  []MediaThumbnailDTO `json:"thumbnails"`
+	UpdatedAt   time.Time           `json:"updatedAt"`
+}
+
+type MediaThumbnailDTO struct {
+	Size   string `json:"size"` // Enum 'sm', 'md', 'lg'
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
@@ -0,0 +1,60 @@
+package domain
+
+import "time"
+
+// Category history actions, a transaction's changes to a category are one entry with the most significant action.
+const (
+	CategoryHistoryActionCreate  = "create"
+	CategoryHistoryActionUpdate  = "update"
+	CategoryHistoryActionMove    = "move"
+	CategoryHistoryActionStatus  = "status"
+	CategoryHistoryActionReorder = "reorder"
+)
+
+// CategoryHistoryEntry is a recorded change of a category, Before is nil for create.
+type CategoryHistoryEntry struct {
+	HistoryID int64
+	Action    string
+	Actor     string
+	Before    *CategorySnapshot
+	After     CategorySnapshot
+	ChangedAt time.Time
+}
+
+// CategorySnapshot is the state of a category in history, as written by the category history trigger.
+type CategorySnapshot struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Slug        string  `json:"slug"`
+	Status      string  `json:"status"`
+	Position    int     `json:"position"`
+	ParentUUID  *string `json:"parentUuid"`
+}
+
+func (e *CategoryHistoryEntry) ToCategoryHistoryEntryDTO() *CategoryHistoryEntryDTO {
+	dto := &CategoryHistoryEntryDTO{
+		HistoryID: e.HistoryID,
+		Action:    e.Action,
+		Actor:     e.Actor,
+		After:     e.After.toCategorySnapshotDTO(),
+		ChangedAt: e.ChangedAt,
+	}
+
+	if e.Before != nil {
+		before := e.Before.toCategorySnapshotDTO()
+		dto.Before = &before
+	}
+
+	return dto
+}
+
+func (s CategorySnapshot) toCategorySnapshotDTO() CategorySnapshotDTO {
+	return CategorySnapshotDTO{
+		Name:               s.Name,
+		Description:        s.Description,
+		Slug:               s.Slug,
+		Status:             s.Status,
+		Position:           s.Position,
+		ParentCategoryUUID: s.ParentUUID,
+	}
+}
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/ashtishad/ecommerce/lib"
+)
+
+type CategoryHistoryRepoDB struct {
+	db *sql.DB
+	l  *slog.Logger
+}
+
+func NewCategoryHistoryRepoDB(db *sql.DB, l *slog.Logger) *CategoryHistoryRepoDB {
+	return &CategoryHistoryRepoDB{db, l}
+}
+
+// FindCategoryHistory returns recorded changes of a category newest first, beforeID pages through older entries,
+// 0 starts from the newest. Entries are written by the category history trigger on every category write.
+// returns 404 if category doesn't exist.
+func (d *CategoryHistoryRepoDB) FindCategoryHistory(ctx context.Context, categoryUUID string, beforeID int64, limit int) ([]CategoryHistoryEntry, lib.APIError) {
+	categoryID, apiErr := selectCategoryID(ctx, d.db, d.l, categoryUUID)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	rows, err := d.db.QueryContext(ctx, sqlSelectCategoryHistory, categoryID, beforeID, limit)
+	if err != nil {
+		d.l.Error("failed to query category history", "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	defer closeRows(rows, d.l)
+
+	entries := make([]CategoryHistoryEntry, 0)
+
+	for rows.Next() {
+		var (
+			e             CategoryHistoryEntry
+			before, after []byte
+		)
+
+		if err = rows.Scan(&e.HistoryID, &e.Action, &e.Actor, &before, &after, &e.ChangedAt); err != nil {
+			d.l.Error("failed to scan rows:", "err", err)
+			return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+		}
+
+		if before != nil {
+			e.Before = &CategorySnapshot{}
+			err = json.Unmarshal(before, e.Before)
+		}
+
+		if err == nil {
+			err = json.Unmarshal(after, &e.After)
+		}
+
+		if err != nil {
+			d.l.Error("failed to decode category snapshot", "historyId", e.HistoryID, "err", err)
+			return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+		}
+
+		entries = append(entries, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		d.l.Error("unexpected error on scanning category history rows", "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return entries, nil
+}
+
+// GetCategoryTreeAsOf reconstructs the category tree as it was at asOf from category history,
+// names and descriptions are default locale values, translations aren't recorded in history.
+func (d *CategoryHistoryRepoDB) GetCategoryTreeAsOf(ctx context.Context, asOf time.Time) ([]*Category, lib.APIError) {
+	rows, err := d.db.QueryContext(ctx, sqlGetCategoryTreeAsOf, asOf)
+	if err != nil {
+		d.l.Error("failed to query category tree as of", "asOf", asOf, "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	defer closeRows(rows, d.l)
+
+	return buildCategoryTree(rows, d.l)
+}
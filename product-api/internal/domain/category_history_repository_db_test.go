@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ashtishad/ecommerce/lib"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFindCategoryHistory tests the FindCategoryHistory method of CategoryHistoryRepoDB.
+// It covers decoding snapshots of create and update entries and category not found.
+func TestFindCategoryHistory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryHistoryRepoDB(db, testLogger)
+	c := mockCategoryObj()
+	now := time.Now()
+
+	t.Run("Entries decoded", func(t *testing.T) {
+		expectQuery(mock, sqlSelectCategoryID).WithArgs(c.CategoryUUID).
+			WillReturnRows(sqlmock.NewRows([]string{"category_id"}).AddRow(c.CategoryID))
+		expectQuery(mock, sqlSelectCategoryHistory).WithArgs(c.CategoryID, int64(0), 50).
+			WillReturnRows(sqlmock.NewRows([]string{"history_id", "action", "actor", "before", "after", "changed_at"}).
+				AddRow(2, CategoryHistoryActionUpdate, "merchandiser@example.com",
+					[]byte(`{"name":"Gaming","description":"","slug":"gaming","status":"active","position":0,"parentUuid":"phone-uuid"}`),
+					[]byte(`{"name":"Gaming Phone","description":"","slug":"gaming","status":"active","position":0,"parentUuid":"phone-uuid"}`), now).
+				AddRow(1, CategoryHistoryActionCreate, "system", nil,
+					[]byte(`{"name":"Gaming","description":"","slug":"gaming","status":"active","position":0,"parentUuid":"phone-uuid"}`), now))
+
+		entries, apiErr := repo.FindCategoryHistory(context.Background(), c.CategoryUUID, 0, 50)
+		require.Nil(t, apiErr)
+		require.Len(t, entries, 2)
+		require.Equal(t, "Gaming", entries[0].Before.Name)
+		require.Equal(t, "Gaming Phone", entries[0].After.Name)
+		require.Equal(t, "phone-uuid", *entries[0].After.ParentUUID)
+		require.Nil(t, entries[1].Before)
+		require.Equal(t, "system", entries[1].Actor)
+	})
+
+	t.Run("Category not found", func(t *testing.T) {
+		expectQuery(mock, sqlSelectCategoryID).WithArgs("missing").WillReturnError(sql.ErrNoRows)
+
+		entries, apiErr := repo.FindCategoryHistory(context.Background(), "missing", 0, 50)
+		require.Nil(t, entries)
+		require.ErrorIs(t, apiErr, lib.ErrNotFound)
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestGetCategoryTreeAsOf makes sure snapshot rows are built into a tree like the current one.
+func TestGetCategoryTreeAsOf(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryHistoryRepoDB(db, testLogger)
+	asOf := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	expectQuery(mock, sqlGetCategoryTreeAsOf).WithArgs(asOf).WillReturnRows(hierarchyRows().
+		AddRow("phone", nil, 0, "Phone", "phone", "", CategoryStatusActive, asOf, asOf).
+		AddRow("gaming", "phone", 1, "Gaming", "gaming", "", CategoryStatusInactive, asOf, asOf))
+
+	tree, apiErr := repo.GetCategoryTreeAsOf(context.Background(), asOf)
+	require.Nil(t, apiErr)
+	require.Len(t, tree, 1)
+	require.Equal(t, CategoryStatusInactive, tree[0].Subcategories[0].Status)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestBeginCategoryTx makes sure the request's actor is set for the history trigger, and the transaction
+// is rolled back if it can't be.
+func TestBeginCategoryTx(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryRepoDB(db, testLogger)
+	ctx := lib.WithActor(context.Background(), "merchandiser@example.com")
+
+	mock.ExpectBegin()
+	expectExec(mock, sqlSetHistoryActor).WithArgs("merchandiser@example.com").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	tx, err := repo.beginCategoryTx(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	mock.ExpectBegin()
+	expectExec(mock, sqlSetHistoryActor).WithArgs("merchandiser@example.com").WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	tx, err = repo.beginCategoryTx(ctx, nil)
+	require.Nil(t, tx)
+	require.Error(t, err)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
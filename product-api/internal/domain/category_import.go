@@ -0,0 +1,61 @@
+package domain
+
+const (
+	CategoryImportActionCreate = "create"
+	CategoryImportActionUpdate = "update"
+)
+
+// CategoryImportItem is a category of an import, Path is the slug path from root with the category's own slug last,
+// e.g. [phone smartphone gaming]. Parents come before their subcategories.
+type CategoryImportItem struct {
+	Path        []string
+	Name        string
+	Description string
+}
+
+// CategoryImportReport is the diff of an import, with dry run nothing is committed, it shows what the import would change.
+type CategoryImportReport struct {
+	DryRun    bool
+	Created   int
+	Updated   int
+	Unchanged int
+	Changes   []CategoryImportChange
+}
+
+// CategoryImportChange is a created or updated category, Before has previous values of an updated one.
+type CategoryImportChange struct {
+	Path        string
+	Action      string
+	Name        string
+	Description string
+	Before      *CategoryImportValues
+}
+
+type CategoryImportValues struct {
+	Name        string
+	Description string
+}
+
+func (r *CategoryImportReport) ToCategoryImportReportDTO() *CategoryImportReportDTO {
+	changes := make([]CategoryImportChangeDTO, len(r.Changes))
+	for i, change := range r.Changes {
+		changes[i] = CategoryImportChangeDTO{
+			Path:        change.Path,
+			Action:      change.Action,
+			Name:        change.Name,
+			Description: change.Description,
+		}
+
+		if change.Before != nil {
+			changes[i].Before = &CategoryImportValuesDTO{Name: change.Before.Name, Description: change.Before.Description}
+		}
+	}
+
+	return &CategoryImportReportDTO{
+		DryRun:    r.DryRun,
+		Created:   r.Created,
+		Updated:   r.Updated,
+		Unchanged: r.Unchanged,
+		Changes:   changes,
+	}
+}
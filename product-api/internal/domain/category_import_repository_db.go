@@ -0,0 +1,220 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strings"
+
+	"github.com/ashtishad/ecommerce/lib"
+)
+
+// importKey identifies a category by its parent(0 for roots) and slug, slugs are unique among siblings.
+type importKey struct {
+	parentID int
+	slug     string
+}
+
+type importNode struct {
+	categoryID  int
+	name        string
+	description string
+}
+
+// ImportCategories upserts items by slug path in a single serializable transaction, existing categories get the
+// imported name and description, missing ones are created under their parent. Categories not in the import and
+// statuses are left untouched, so importing the same file again changes nothing.
+// With dryRun the transaction is rolled back after all changes are applied, the report shows what would change.
+//   - returns 400 if the parent of an item neither exists nor comes earlier in the import.
+//   - returns 409 if an imported name clashes with a sibling, in the default locale or a translated one.
+func (d *CategoryRepoDB) ImportCategories(ctx context.Context, items []CategoryImportItem, dryRun bool) (*CategoryImportReport, lib.APIError) {
+	tx, err := d.beginCategoryTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		d.l.Error(lib.ErrTxBegin, "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	defer rollBackOnError(tx, d.l, &err)
+
+	nodes, apiErr := d.selectImportNodes(ctx, tx)
+	if apiErr != nil {
+		err = apiErr // rollback
+		return nil, apiErr
+	}
+
+	report := &CategoryImportReport{DryRun: dryRun, Changes: make([]CategoryImportChange, 0)}
+
+	for _, item := range items {
+		parentID, apiErr := resolveImportParent(nodes, item.Path)
+		if apiErr != nil {
+			err = apiErr // rollback
+			return nil, apiErr
+		}
+
+		key := importKey{parentID: parentID, slug: item.Path[len(item.Path)-1]}
+		path := strings.Join(item.Path, "/")
+
+		node, exists := nodes[key]
+		if exists && node.name == item.Name && node.description == item.Description {
+			report.Unchanged++
+			continue
+		}
+
+		if exists {
+			if _, err = tx.ExecContext(ctx, sqlUpdateCategory, item.Name, item.Description, node.categoryID); err != nil {
+				if isSiblingNameViolation(err) {
+					return nil, d.siblingNameConflict(ctx, nullableParentID(parentID), item.Name, node.categoryID)
+				}
+
+				d.l.Error("failed to update imported category", "path", path, "err", err)
+
+				return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+			}
+
+			if apiErr = checkTranslatedSiblingNames(ctx, tx, d.l, node.categoryID); apiErr != nil {
+				err = apiErr // rollback
+				return nil, apiErr
+			}
+
+			report.Updated++
+			report.Changes = append(report.Changes, CategoryImportChange{
+				Path:        path,
+				Action:      CategoryImportActionUpdate,
+				Name:        item.Name,
+				Description: item.Description,
+				Before:      &CategoryImportValues{Name: node.name, Description: node.description},
+			})
+
+			node.name, node.description = item.Name, item.Description
+
+			continue
+		}
+
+		categoryID, apiErr := d.insertImportedCategory(ctx, tx, item, parentID)
+		if apiErr != nil {
+			err = apiErr // rollback
+			return nil, apiErr
+		}
+
+		nodes[key] = &importNode{categoryID: categoryID, name: item.Name, description: item.Description}
+
+		report.Created++
+		report.Changes = append(report.Changes, CategoryImportChange{
+			Path:        path,
+			Action:      CategoryImportActionCreate,
+			Name:        item.Name,
+			Description: item.Description,
+		})
+	}
+
+	if dryRun {
+		if err = tx.Rollback(); err != nil {
+			d.l.Error(lib.ErrTxRollback, "err", err)
+			return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+		}
+
+		return report, nil
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, txError(err)
+	}
+
+	return report, nil
+}
+
+// selectImportNodes loads categories that aren't deleted keyed by parent and slug, the sibling slug index only
+// covers them, an imported path matching a deleted category creates a new category next to it.
+func (d *CategoryRepoDB) selectImportNodes(ctx context.Context, tx *sql.Tx) (map[importKey]*importNode, lib.APIError) {
+	rows, err := tx.QueryContext(ctx, sqlSelectImportNodes)
+	if err != nil {
+		d.l.Error("failed to query categories for import", "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	defer closeRows(rows, d.l)
+
+	nodes := make(map[importKey]*importNode)
+
+	for rows.Next() {
+		var (
+			key  importKey
+			node importNode
+		)
+
+		if err = rows.Scan(&node.categoryID, &key.parentID, &key.slug, &node.name, &node.description); err != nil {
+			d.l.Error("failed to scan rows:", "err", err)
+			return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+		}
+
+		nodes[key] = &node
+	}
+
+	if err = rows.Err(); err != nil {
+		d.l.Error("unexpected error on scanning category rows", "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return nodes, nil
+}
+
+// resolveImportParent walks the parent slugs of path from root, returns parent id, 0 for a root category.
+func resolveImportParent(nodes map[importKey]*importNode, path []string) (int, lib.APIError) {
+	parentID := 0
+
+	for i, segment := range path[:len(path)-1] {
+		node, ok := nodes[importKey{parentID: parentID, slug: segment}]
+		if !ok {
+			return 0, lib.NewError(http.StatusBadRequest, ErrCodeCategoryImportParentNotFound,
+				lib.Args{"path": strings.Join(path[:i+1], "/")})
+		}
+
+		parentID = node.categoryID
+	}
+
+	return parentID, nil
+}
+
+// insertImportedCategory creates a category with the last segment of its path as slug, links it to its parent
+// and puts it after its siblings.
+func (d *CategoryRepoDB) insertImportedCategory(ctx context.Context, tx *sql.Tx, item CategoryImportItem, parentID int) (int, lib.APIError) {
+	category := Category{
+		Name:        item.Name,
+		Description: item.Description,
+		Slug:        item.Path[len(item.Path)-1],
+	}
+
+	if err := d.executeInsertCategory(ctx, tx, &category, nullableParentID(parentID)); err != nil {
+		if isSiblingNameViolation(err) {
+			return 0, d.siblingNameConflict(ctx, nullableParentID(parentID), item.Name, 0)
+		}
+
+		if isSiblingSlugViolation(err) {
+			return 0, d.slugConflict(category.Slug, err)
+		}
+
+		d.l.Error("failed to insert imported category", "path", strings.Join(item.Path, "/"), "err", err)
+
+		return 0, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	if parentID != 0 {
+		if apiErr := d.insertAncestorPaths(ctx, tx, parentID, category.CategoryID); apiErr != nil {
+			return 0, apiErr
+		}
+	}
+
+	if err := d.appendCategoryPosition(ctx, tx, category.CategoryID); err != nil {
+		return 0, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	if apiErr := checkTranslatedSiblingNames(ctx, tx, d.l, category.CategoryID); apiErr != nil {
+		return 0, apiErr
+	}
+
+	return category.CategoryID, nil
+}
+
+func nullableParentID(parentID int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(parentID), Valid: parentID != 0}
+}
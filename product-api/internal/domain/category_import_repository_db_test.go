@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ashtishad/ecommerce/lib"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func importNodeRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"category_id", "parent_id", "slug", "name", "description"}).
+		AddRow(1, 0, "phone", "Phone", "Mobile phones").
+		AddRow(2, 1, "smartphone", "Smartphone", "Touch screen phones")
+}
+
+// TestImportCategories tests the ImportCategories method of CategoryRepoDB.
+// It covers created, updated and unchanged categories, dry run rollback, missing parent and sibling name clash.
+func TestImportCategories(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryRepoDB(db, testLogger)
+
+	items := []CategoryImportItem{
+		{Path: []string{"phone"}, Name: "Phone", Description: "Mobile phones"},
+		{Path: []string{"phone", "smartphone"}, Name: "Smartphone", Description: "Android and iOS phones"},
+		{Path: []string{"phone", "smartphone", "gaming"}, Name: "Gaming", Description: ""},
+	}
+
+	expectImport := func() {
+		expectExec(mock, sqlUpdateCategory).WithArgs("Smartphone", "Android and iOS phones", 2).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectNoTranslatedNameClash(mock, 2)
+		expectQuery(mock, sqlInsertCategory).WithArgs("Gaming", "", "gaming", sql.NullInt64{Int64: 2, Valid: true}).
+			WillReturnRows(sqlmock.NewRows([]string{"category_id"}).AddRow(3))
+		expectExec(mock, sqlInsertAncestorPaths).WithArgs(2, 3).WillReturnResult(sqlmock.NewResult(0, 2))
+		expectExec(mock, sqlAppendCategoryPosition).WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectNoTranslatedNameClash(mock, 3)
+	}
+
+	t.Run("Import committed", func(t *testing.T) {
+		expectCategoryTx(mock)
+		expectQuery(mock, sqlSelectImportNodes).WillReturnRows(importNodeRows())
+		expectImport()
+		mock.ExpectCommit()
+
+		report, apiErr := repo.ImportCategories(context.Background(), items, false)
+		require.Nil(t, apiErr)
+		require.False(t, report.DryRun)
+		require.Equal(t, 1, report.Created)
+		require.Equal(t, 1, report.Updated)
+		require.Equal(t, 1, report.Unchanged)
+		require.Equal(t, []CategoryImportChange{
+			{
+				Path:        "phone/smartphone",
+				Action:      CategoryImportActionUpdate,
+				Name:        "Smartphone",
+				Description: "Android and iOS phones",
+				Before:      &CategoryImportValues{Name: "Smartphone", Description: "Touch screen phones"},
+			},
+			{Path: "phone/smartphone/gaming", Action: CategoryImportActionCreate, Name: "Gaming"},
+		}, report.Changes)
+	})
+
+	t.Run("Dry run rolled back", func(t *testing.T) {
+		expectCategoryTx(mock)
+		expectQuery(mock, sqlSelectImportNodes).WillReturnRows(importNodeRows())
+		expectImport()
+		mock.ExpectRollback()
+
+		report, apiErr := repo.ImportCategories(context.Background(), items, true)
+		require.Nil(t, apiErr)
+		require.True(t, report.DryRun)
+		require.Len(t, report.Changes, 2)
+	})
+
+	t.Run("Parent not found", func(t *testing.T) {
+		expectCategoryTx(mock)
+		expectQuery(mock, sqlSelectImportNodes).WillReturnRows(importNodeRows())
+		mock.ExpectRollback()
+
+		orphan := []CategoryImportItem{{Path: []string{"wearable", "smartwatch"}, Name: "SmartWatch"}}
+
+		report, apiErr := repo.ImportCategories(context.Background(), orphan, false)
+		require.Nil(t, report)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Equal(t, ErrCodeCategoryImportParentNotFound, apiErr.ErrorCode())
+		require.Contains(t, apiErr.AsMessage(), "wearable")
+	})
+
+	t.Run("Sibling name clash", func(t *testing.T) {
+		expectCategoryTx(mock)
+		expectQuery(mock, sqlSelectImportNodes).WillReturnRows(importNodeRows())
+		expectQuery(mock, sqlInsertCategory).WithArgs("smartphone", "", "smart-phone", sql.NullInt64{Int64: 1, Valid: true}).
+			WillReturnError(&pgconn.PgError{Code: pgUniqueViolation, ConstraintName: uniqueSiblingNameIndex})
+		expectQuery(mock, sqlSelectSiblingByName).WithArgs(sql.NullInt64{Int64: 1, Valid: true}, "smartphone", 0).
+			WillReturnRows(sqlmock.NewRows([]string{"category_uuid", "name"}).AddRow("smartphone-uuid", "Smartphone"))
+		mock.ExpectRollback()
+
+		clash := []CategoryImportItem{{Path: []string{"phone", "smart-phone"}, Name: "smartphone"}}
+
+		report, apiErr := repo.ImportCategories(context.Background(), clash, false)
+		require.Nil(t, report)
+		require.ErrorIs(t, apiErr, lib.ErrConflict)
+		require.Equal(t, ErrCodeCategoryNameExists, apiErr.ErrorCode())
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
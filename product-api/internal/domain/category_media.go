@@ -0,0 +1,57 @@
+package domain
+
+import "time"
+
+const (
+	MediaKindBanner = "banner"
+	MediaKindIcon   = "icon"
+)
+
+// CategoryMedia is an image of a category stored in the blob store, with thumbnails generated on upload.
+type CategoryMedia struct {
+	Kind        string
+	ObjectKey   string
+	ContentType string
+	Width       int
+	Height      int
+	SizeBytes   int64
+	Thumbnails  []MediaThumbnail
+	UpdatedAt   time.Time
+}
+
+// MediaThumbnail is a downscaled copy of a media, Size is its preset name like "sm".
+type MediaThumbnail struct {
+	Size      string `json:"size"`
+	ObjectKey string `json:"objectKey"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+}
+
+// ObjectKeys returns keys of the original and every thumbnail.
+func (m *CategoryMedia) ObjectKeys() []string {
+	keys := []string{m.ObjectKey}
+	for _, t := range m.Thumbnails {
+		keys = append(keys, t.ObjectKey)
+	}
+
+	return keys
+}
+
+// ToCategoryMediaDTO converts media to DTO, url returns the public url of an object key.
+func (m *CategoryMedia) ToCategoryMediaDTO(url func(key string) string) *CategoryMediaDTO {
+	thumbnails := make([]MediaThumbnailDTO, len(m.Thumbnails))
+	for i, t := range m.Thumbnails {
+		thumbnails[i] = MediaThumbnailDTO{Size: t.Size, URL: url(t.ObjectKey), Width: t.Width, Height: t.Height}
+	}
+
+	return &CategoryMediaDTO{
+		Kind:        m.Kind,
+		URL:         url(m.ObjectKey),
+		ContentType: m.ContentType,
+		Width:       m.Width,
+		Height:      m.Height,
+		SizeBytes:   m.SizeBytes,
+		Thumbnails:  thumbnails,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
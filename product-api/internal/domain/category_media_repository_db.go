@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/ashtishad/ecommerce/lib"
+)
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+type CategoryMediaRepoDB struct {
+	db *sql.DB
+	l  *slog.Logger
+}
+
+func NewCategoryMediaRepoDB(db *sql.DB, l *slog.Logger) *CategoryMediaRepoDB {
+	return &CategoryMediaRepoDB{db, l}
+}
+
+// FindCategoryMedia returns all media of a category ordered by kind.
+// returns 404 if category doesn't exist.
+func (d *CategoryMediaRepoDB) FindCategoryMedia(ctx context.Context, categoryUUID string) ([]CategoryMedia, lib.APIError) {
+	categoryID, apiErr := selectCategoryID(ctx, d.db, d.l, categoryUUID)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	rows, err := d.db.QueryContext(ctx, sqlSelectCategoryMedia, categoryID)
+	if err != nil {
+		d.l.Error("failed to query category media", "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	defer closeRows(rows, d.l)
+
+	media := make([]CategoryMedia, 0)
+
+	for rows.Next() {
+		var m *CategoryMedia
+		if m, err = scanCategoryMedia(rows); err != nil {
+			d.l.Error("failed to scan rows:", "err", err)
+			return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+		}
+
+		media = append(media, *m)
+	}
+
+	if err = rows.Err(); err != nil {
+		d.l.Error("unexpected error on scanning category media rows", "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return media, nil
+}
+
+// SaveCategoryMedia creates or replaces a category media of the same kind in a serializable transaction,
+// returns the saved media and the replaced one, nil if there was none, so the caller can delete its blobs.
+// returns 404 if category doesn't exist.
+func (d *CategoryMediaRepoDB) SaveCategoryMedia(ctx context.Context, categoryUUID string, media CategoryMedia) (*CategoryMedia, *CategoryMedia, lib.APIError) {
+	thumbnails, err := json.Marshal(media.Thumbnails)
+	if err != nil {
+		return nil, nil, lib.NewInternalServerError("failed to encode media thumbnails", err)
+	}
+
+	tx, err := d.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		d.l.Error(lib.ErrTxBegin, "err", err)
+		return nil, nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	defer rollBackOnError(tx, d.l, &err)
+
+	var categoryID int
+	if err = tx.QueryRowContext(ctx, sqlSelectCategoryID, categoryUUID).Scan(&categoryID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, lib.NewError(http.StatusNotFound, ErrCodeCategoryNotFound, nil).Wrap(err)
+		}
+
+		return nil, nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	previous, err := scanCategoryMedia(tx.QueryRowContext(ctx, sqlSelectCategoryMediaForUpdate, categoryID, media.Kind))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		previous, err = nil, nil
+	case err != nil:
+		d.l.Error("failed to select category media", "kind", media.Kind, "err", err)
+		return nil, nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	if err = tx.QueryRowContext(ctx, sqlUpsertCategoryMedia, categoryID, media.Kind, media.ObjectKey, media.ContentType,
+		media.Width, media.Height, media.SizeBytes, thumbnails).Scan(&media.UpdatedAt); err != nil {
+		d.l.Error("failed to save category media", "kind", media.Kind, "err", err)
+		return nil, nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return &media, previous, nil
+}
+
+// DeleteCategoryMedia removes a category media and returns it, so the caller can delete its blobs.
+// returns 404 if category or the media doesn't exist.
+func (d *CategoryMediaRepoDB) DeleteCategoryMedia(ctx context.Context, categoryUUID string, kind string) (*CategoryMedia, lib.APIError) {
+	categoryID, apiErr := selectCategoryID(ctx, d.db, d.l, categoryUUID)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	media, err := scanCategoryMedia(d.db.QueryRowContext(ctx, sqlDeleteCategoryMedia, categoryID, kind))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			d.l.Warn("category media not found", "category", categoryUUID, "kind", kind)
+			return nil, lib.NewError(http.StatusNotFound, ErrCodeCategoryMediaNotFound, lib.Args{"kind": kind}).Wrap(err)
+		}
+
+		d.l.Error("failed to delete category media", "kind", kind, "err", err)
+
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return media, nil
+}
+
+func scanCategoryMedia(row rowScanner) (*CategoryMedia, error) {
+	var (
+		m          CategoryMedia
+		thumbnails []byte
+	)
+
+	if err := row.Scan(&m.Kind, &m.ObjectKey, &m.ContentType, &m.Width, &m.Height, &m.SizeBytes, &thumbnails, &m.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(thumbnails, &m.Thumbnails); err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func mediaRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"kind", "object_key", "content_type", "width", "height", "size_bytes", "thumbnails", "updated_at"})
+}
+
+// TestSaveCategoryMedia covers saving a first media, replacing an existing one and category not found.
+func TestSaveCategoryMedia(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryMediaRepoDB(db, testLogger)
+	c := mockCategoryObj()
+	now := time.Now()
+	media := CategoryMedia{
+		Kind: MediaKindIcon, ObjectKey: "categories/x/icon/b/original.png", ContentType: "image/png", Width: 256, Height: 256, SizeBytes: 1024,
+		Thumbnails: []MediaThumbnail{{Size: "sm", ObjectKey: "categories/x/icon/b/sm.png", Width: 48, Height: 48}},
+	}
+	thumbnails := `[{"size":"sm","objectKey":"categories/x/icon/b/sm.png","width":48,"height":48}]`
+
+	idRows := func() *sqlmock.Rows { return sqlmock.NewRows([]string{"category_id"}).AddRow(c.CategoryID) }
+	expectUpsert := func() {
+		expectQuery(mock, sqlUpsertCategoryMedia).
+			WithArgs(c.CategoryID, media.Kind, media.ObjectKey, media.ContentType, 256, 256, int64(1024), []byte(thumbnails)).
+			WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(now))
+	}
+
+	t.Run("First media saved", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectQuery(mock, sqlSelectCategoryID).WithArgs(c.CategoryUUID).WillReturnRows(idRows())
+		expectQuery(mock, sqlSelectCategoryMediaForUpdate).WithArgs(c.CategoryID, MediaKindIcon).WillReturnError(sql.ErrNoRows)
+		expectUpsert()
+		mock.ExpectCommit()
+
+		saved, previous, apiErr := repo.SaveCategoryMedia(context.Background(), c.CategoryUUID, media)
+		require.Nil(t, apiErr)
+		require.Nil(t, previous)
+		require.Equal(t, now, saved.UpdatedAt)
+		require.Equal(t, media.Thumbnails, saved.Thumbnails)
+	})
+
+	t.Run("Existing media replaced", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectQuery(mock, sqlSelectCategoryID).WithArgs(c.CategoryUUID).WillReturnRows(idRows())
+		expectQuery(mock, sqlSelectCategoryMediaForUpdate).WithArgs(c.CategoryID, MediaKindIcon).WillReturnRows(mediaRows().
+			AddRow(MediaKindIcon, "categories/x/icon/a/original.png", "image/png", 64, 64, 512,
+				[]byte(`[{"size":"sm","objectKey":"categories/x/icon/a/sm.png","width":48,"height":48}]`), now))
+		expectUpsert()
+		mock.ExpectCommit()
+
+		_, previous, apiErr := repo.SaveCategoryMedia(context.Background(), c.CategoryUUID, media)
+		require.Nil(t, apiErr)
+		require.Equal(t, []string{"categories/x/icon/a/original.png", "categories/x/icon/a/sm.png"}, previous.ObjectKeys())
+	})
+
+	t.Run("Category not found", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectQuery(mock, sqlSelectCategoryID).WithArgs("missing").WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		saved, _, apiErr := repo.SaveCategoryMedia(context.Background(), "missing", media)
+		require.Nil(t, saved)
+		require.Equal(t, ErrCodeCategoryNotFound, apiErr.ErrorCode())
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestFindAndDeleteCategoryMedia covers listing media and deleting an existing and a missing one.
+func TestFindAndDeleteCategoryMedia(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryMediaRepoDB(db, testLogger)
+	c := mockCategoryObj()
+	now := time.Now()
+
+	idRows := func() *sqlmock.Rows { return sqlmock.NewRows([]string{"category_id"}).AddRow(c.CategoryID) }
+	bannerRows := func() *sqlmock.Rows {
+		return mediaRows().AddRow(MediaKindBanner, "categories/x/banner/a/original.jpg", "image/jpeg", 1600, 400, 2048, []byte(`[]`), now)
+	}
+
+	t.Run("List media", func(t *testing.T) {
+		expectQuery(mock, sqlSelectCategoryID).WithArgs(c.CategoryUUID).WillReturnRows(idRows())
+		expectQuery(mock, sqlSelectCategoryMedia).WithArgs(c.CategoryID).WillReturnRows(bannerRows())
+
+		media, apiErr := repo.FindCategoryMedia(context.Background(), c.CategoryUUID)
+		require.Nil(t, apiErr)
+		require.Len(t, media, 1)
+		require.Equal(t, 1600, media[0].Width)
+		require.Empty(t, media[0].Thumbnails)
+	})
+
+	t.Run("Delete media", func(t *testing.T) {
+		expectQuery(mock, sqlSelectCategoryID).WithArgs(c.CategoryUUID).WillReturnRows(idRows())
+		expectQuery(mock, sqlDeleteCategoryMedia).WithArgs(c.CategoryID, MediaKindBanner).WillReturnRows(bannerRows())
+
+		deleted, apiErr := repo.DeleteCategoryMedia(context.Background(), c.CategoryUUID, MediaKindBanner)
+		require.Nil(t, apiErr)
+		require.Equal(t, "categories/x/banner/a/original.jpg", deleted.ObjectKey)
+	})
+
+	t.Run("Delete missing media", func(t *testing.T) {
+		expectQuery(mock, sqlSelectCategoryID).WithArgs(c.CategoryUUID).WillReturnRows(idRows())
+		expectQuery(mock, sqlDeleteCategoryMedia).WithArgs(c.CategoryID, MediaKindIcon).WillReturnError(sql.ErrNoRows)
+
+		_, apiErr := repo.DeleteCategoryMedia(context.Background(), c.CategoryUUID, MediaKindIcon)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+		require.Equal(t, ErrCodeCategoryMediaNotFound, apiErr.ErrorCode())
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
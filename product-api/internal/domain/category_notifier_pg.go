@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	CategoryChangesChannel = "category_changes"
+
+	sqlNotifyCategoryChange = `SELECT pg_notify($1, '')`
+
+	listenRetryDelay = 5 * time.Second
+)
+
+// PGCategoryNotifier broadcasts category changes with postgres NOTIFY and receives them with LISTEN
+// on a dedicated connection, notifications are delivered to every instance including the sender.
+type PGCategoryNotifier struct {
+	db  *sql.DB
+	dsn string
+	l   *slog.Logger
+}
+
+func NewPGCategoryNotifier(db *sql.DB, dsn string, l *slog.Logger) *PGCategoryNotifier {
+	return &PGCategoryNotifier{db: db, dsn: dsn, l: l}
+}
+
+func (n *PGCategoryNotifier) Notify(ctx context.Context) error {
+	_, err := n.db.ExecContext(ctx, sqlNotifyCategoryChange, CategoryChangesChannel)
+	return err
+}
+
+// Listen calls onChange for every notification until ctx is done, it reconnects on connection errors
+// and calls onChange after each reconnect as notifications might have been missed meanwhile.
+func (n *PGCategoryNotifier) Listen(ctx context.Context, onChange func()) {
+	for ctx.Err() == nil {
+		if err := n.listen(ctx, onChange); err != nil && ctx.Err() == nil {
+			n.l.Warn("category changes listener disconnected, retrying", "err", err, "retryIn", listenRetryDelay)
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(listenRetryDelay):
+			}
+		}
+	}
+}
+
+func (n *PGCategoryNotifier) listen(ctx context.Context, onChange func()) error {
+	conn, err := pgx.Connect(ctx, n.dsn)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if cErr := conn.Close(context.Background()); cErr != nil {
+			n.l.Warn("unable to close listener connection", "err", cErr)
+		}
+	}()
+
+	if _, err = conn.Exec(ctx, "LISTEN "+CategoryChangesChannel); err != nil {
+		return err
+	}
+
+	onChange()
+
+	for {
+		if _, err = conn.WaitForNotification(ctx); err != nil {
+			return err
+		}
+
+		onChange()
+	}
+}
@@ -1,29 +1,292 @@
 package domain
 
 const (
-	sqlInsertCategory       = `INSERT INTO categories (name, description) VALUES ($1, $2) RETURNING category_id`
-	sqlSelectCategoryName   = `SELECT name FROM categories WHERE LOWER(name) = LOWER($1)`
-	sqlSelectCategoryByID   = `SELECT category_id,category_uuid,name, description,status,created_at,updated_at FROM categories where category_id= $1`
-	sqlValidateUUIDGetCatID = `SELECT category_id, EXISTS(SELECT 1 FROM categories WHERE name = $2 AND status = 'active') FROM categories WHERE category_uuid = $1`
-
-	sqlInsertWithLevelCalculation = `
-	WITH parent_level AS (
-    SELECT level FROM category_relationships WHERE descendant_id = $1 LIMIT 1
-	)
-	INSERT INTO category_relationships (ancestor_id, descendant_id, level)
-	VALUES ($2, $3, COALESCE((SELECT level FROM parent_level), 0) + 1);
+	sqlInsertCategory = `WITH new_category AS (
+    INSERT INTO categories (name, description, slug, parent_id) VALUES ($1, $2, $3, $4) RETURNING category_id
+), self_path AS (
+    INSERT INTO category_relationships (ancestor_id, descendant_id, level)
+    SELECT category_id, category_id, 0 FROM new_category
+)
+SELECT category_id FROM new_category`
+	sqlSelectCategoryByID = `SELECT category_id,category_uuid,name,slug, description,status,created_at,updated_at FROM categories where category_id= $1`
+
+	// non deleted sibling under parent $1(NULL for roots) named $2 case-insensitively, other than category $3.
+	sqlSelectSiblingByName = `SELECT category_uuid, name FROM categories
+	WHERE parent_id IS NOT DISTINCT FROM $1 AND LOWER(name) = LOWER($2) AND status <> 'deleted' AND category_id <> $3
+	LIMIT 1`
+
+	// a category of the subtree of category uuid $1(only itself when $2 is false) and a non deleted sibling with its name.
+	sqlSelectClashingSibling = `SELECT c.name, s.category_uuid, s.name
+FROM category_relationships t
+         INNER JOIN categories c ON c.category_id = t.descendant_id
+         INNER JOIN categories s ON s.parent_id IS NOT DISTINCT FROM c.parent_id AND s.category_id <> c.category_id
+    AND LOWER(s.name) = LOWER(c.name) AND s.status <> 'deleted'
+WHERE t.ancestor_id = (SELECT category_id FROM categories WHERE category_uuid = $1) AND (t.level = 0 OR $2)
+LIMIT 1`
+
+	sqlSelectCategoryByUUID = `SELECT c.category_id, c.category_uuid, p.category_uuid,
+       (SELECT COALESCE(MAX(d.level), 0) FROM category_relationships d WHERE d.descendant_id = c.category_id),
+       c.name, c.slug, c.description, c.status, c.created_at, c.updated_at
+	FROM categories c
+	LEFT JOIN category_relationships cr ON cr.descendant_id = c.category_id AND cr.level = 1
+	LEFT JOIN categories p ON p.category_id = cr.ancestor_id
+	WHERE c.category_uuid = $1`
+	sqlSelectCategoryIDAndParent = `SELECT category_id, parent_id FROM categories WHERE category_uuid = $1`
+	sqlUpdateCategory            = `UPDATE categories SET name = $1, description = $2, updated_at = CURRENT_TIMESTAMP
+	WHERE category_id = $3`
+	sqlUpdateCategoryStatus = `UPDATE categories SET status = $1, updated_at = CURRENT_TIMESTAMP
+	WHERE category_uuid = $2 RETURNING category_id`
+
+	sqlUpdateDescendantsStatus = `UPDATE categories SET status = $1, updated_at = CURRENT_TIMESTAMP
+	WHERE category_id IN (SELECT descendant_id FROM category_relationships WHERE ancestor_id = $2 AND level > 0)`
+
+	sqlSelectCategoryID        = `SELECT category_id FROM categories WHERE category_uuid = $1`
+	sqlSelectCategoryIDAndSlug = `SELECT category_id, slug FROM categories WHERE category_uuid = $1`
+	sqlSelectCategoryName      = `SELECT name FROM categories WHERE category_id = $1`
+
+	// slugs of category $1's siblings equal to $2 or $2 with a suffix, works for root categories too.
+	sqlSelectSiblingSlugs = `SELECT s.slug
+FROM categories s
+         LEFT JOIN category_relationships sp ON sp.descendant_id = s.category_id AND sp.level = 1
+WHERE s.category_id <> $1
+  AND sp.ancestor_id IS NOT DISTINCT FROM (SELECT ancestor_id FROM category_relationships WHERE descendant_id = $1 AND level = 1)
+  AND s.status <> 'deleted'
+  AND (s.slug = $2 OR s.slug LIKE $2 || '-%')`
+
+	// slugs of non deleted children of parent $1(NULL for roots) equal to $2 or $2 with a suffix, except category $3.
+	sqlSelectChildSlugs = `SELECT slug FROM categories
+	WHERE parent_id IS NOT DISTINCT FROM $1 AND status <> 'deleted' AND category_id <> $3 AND (slug = $2 OR slug LIKE $2 || '-%')`
+
+	// a category of the subtree of category uuid $1(only itself when $2 is false) that shares its slug with a non deleted sibling.
+	sqlSelectClashingSlug = `SELECT c.slug
+FROM category_relationships t
+         INNER JOIN categories c ON c.category_id = t.descendant_id
+         INNER JOIN categories s ON s.parent_id IS NOT DISTINCT FROM c.parent_id AND s.category_id <> c.category_id
+    AND s.slug = c.slug AND s.status <> 'deleted'
+WHERE t.ancestor_id = (SELECT category_id FROM categories WHERE category_uuid = $1) AND (t.level = 0 OR $2)
+LIMIT 1`
+	sqlUpdateCategorySlug = `UPDATE categories SET slug = $1, updated_at = CURRENT_TIMESTAMP WHERE category_id = $2`
+	sqlInsertSlugHistory  = `INSERT INTO category_slug_history (category_id, slug) VALUES ($1, $2)
+	ON CONFLICT (category_id, slug) DO UPDATE SET created_at = CURRENT_TIMESTAMP`
+	sqlDeleteSlugHistory = `DELETE FROM category_slug_history WHERE category_id = $1 AND slug = $2`
+
+	// non deleted child of parent $1 with slug $2, NULL parent looks up root categories.
+	sqlSelectChildBySlug = `SELECT c.category_id, c.category_uuid, c.slug
+FROM categories c
+         LEFT JOIN category_relationships p ON p.descendant_id = c.category_id AND p.level = 1
+WHERE p.ancestor_id IS NOT DISTINCT FROM $1 AND c.slug = $2 AND c.status <> 'deleted'`
+
+	// non deleted child of parent $1 that used slug $2 before, latest one wins.
+	sqlSelectChildByOldSlug = `SELECT c.category_id, c.category_uuid, c.slug
+FROM categories c
+         INNER JOIN category_slug_history h ON h.category_id = c.category_id
+         LEFT JOIN category_relationships p ON p.descendant_id = c.category_id AND p.level = 1
+WHERE p.ancestor_id IS NOT DISTINCT FROM $1 AND h.slug = $2 AND c.status <> 'deleted'
+ORDER BY h.created_at DESC
+LIMIT 1`
+
+	// puts category $1 after its current siblings, works for root categories too.
+	sqlAppendCategoryPosition = `UPDATE categories SET position = (
+    SELECT COALESCE(MAX(s.position) + 1, 0)
+    FROM categories s
+             LEFT JOIN category_relationships sp ON sp.descendant_id = s.category_id AND sp.level = 1
+    WHERE s.category_id <> $1
+      AND sp.ancestor_id IS NOT DISTINCT FROM (SELECT ancestor_id FROM category_relationships WHERE descendant_id = $1 AND level = 1)
+)
+WHERE category_id = $1`
+
+	sqlSelectChildUUIDs = `SELECT c.category_uuid FROM category_relationships r
+	INNER JOIN categories c ON c.category_id = r.descendant_id
+	WHERE r.ancestor_id = $1 AND r.level = 1`
+	sqlUpdateCategoryPosition = `UPDATE categories SET position = $1, updated_at = CURRENT_TIMESTAMP WHERE category_uuid = $2`
+
+	// $2 is a descendant of $1 or the category itself.
+	sqlIsDescendant = `SELECT EXISTS(SELECT 1 FROM category_relationships WHERE ancestor_id = $1 AND descendant_id = $2)`
+
+	// removes paths from the ancestors of subtree root $1 to every node of its subtree, paths inside the subtree are kept.
+	sqlDetachSubtree = `DELETE FROM category_relationships
+	WHERE descendant_id IN (SELECT descendant_id FROM category_relationships WHERE ancestor_id = $1)
+	AND ancestor_id NOT IN (SELECT descendant_id FROM category_relationships WHERE ancestor_id = $1)`
+
+	// $1 is the new parent of category $2, NULL makes it a root category.
+	sqlUpdateCategoryParent = `UPDATE categories SET parent_id = $1, slug = $2, updated_at = CURRENT_TIMESTAMP WHERE category_id = $3`
+
+	// links every ancestor of new parent $1(including itself) to every node of subtree rooted at $2.
+	sqlAttachSubtree = `INSERT INTO category_relationships (ancestor_id, descendant_id, level)
+	SELECT p.ancestor_id, s.descendant_id, p.level + s.level + 1
+	FROM category_relationships p
+	CROSS JOIN category_relationships s
+	WHERE p.descendant_id = $1 AND s.ancestor_id = $2`
+
+	// links every ancestor of parent $1(including itself) to the new category $2.
+	sqlInsertAncestorPaths = `INSERT INTO category_relationships (ancestor_id, descendant_id, level)
+	SELECT ancestor_id, $2, level + 1 FROM category_relationships WHERE descendant_id = $1`
+
+	// ancestors of category $1 from root, $2 is the category's own level, $3 is 0 to include the category itself, otherwise 1.
+	sqlSelectAncestors = `SELECT c.category_uuid, p.category_uuid AS parent_category_uuid, $2 - t.level AS level,
+       c.name, c.slug, c.description, c.status, c.created_at, c.updated_at
+FROM category_relationships t
+         INNER JOIN categories c ON c.category_id = t.ancestor_id
+         LEFT JOIN category_relationships pr ON pr.descendant_id = c.category_id AND pr.level = 1
+         LEFT JOIN categories p ON p.category_id = pr.ancestor_id
+WHERE t.descendant_id = $1 AND t.level >= $3
+ORDER BY t.level DESC;
+`
+
+	// subtree of category $1 including itself, $2 is the category's own level, $3 limits depth below the category, 0 is unlimited.
+	sqlSelectSubtree = `SELECT c.category_uuid, p.category_uuid AS parent_category_uuid, $2 + t.level AS level,
+       c.name, c.slug, c.description, c.status, c.created_at, c.updated_at
+FROM category_relationships t
+         INNER JOIN categories c ON c.category_id = t.descendant_id
+         LEFT JOIN category_relationships pr ON pr.descendant_id = c.category_id AND pr.level = 1
+         LEFT JOIN categories p ON p.category_id = pr.ancestor_id
+WHERE t.ancestor_id = $1 AND ($3 = 0 OR t.level <= $3)
+ORDER BY t.level, c.position, c.category_id;
+`
+
+	// all categories with names and descriptions in locale $1, falls back to default locale columns without a translation.
+	sqlGetAllCategoriesWithHierarchy = `SELECT c.category_uuid, p.category_uuid AS parent_category_uuid, COALESCE(d.depth, 0) AS level,
+       COALESCE(t.name, c.name) AS name, c.slug, COALESCE(t.description, c.description) AS description,
+       c.status, c.created_at, c.updated_at
+FROM categories c
+         LEFT JOIN category_translations t ON t.category_id = c.category_id AND t.locale = $1
+         LEFT JOIN (SELECT descendant_id, MAX(level) AS depth FROM category_relationships GROUP BY descendant_id) d
+                   ON d.descendant_id = c.category_id
+         LEFT JOIN category_relationships pr ON pr.descendant_id = c.category_id AND pr.level = 1
+         LEFT JOIN categories p ON p.category_id = pr.ancestor_id
+ORDER BY level, c.position, c.category_id;
 `
+)
+
+const (
+	// effective attributes of category $1, the closest definition of each code wins, root definitions come first.
+	sqlSelectEffectiveAttributes = `SELECT attribute_id, category_uuid, code, name, type, unit, enum_values, required, distance > 0 AS inherited
+FROM (SELECT DISTINCT ON (a.code) a.attribute_id, c.category_uuid, a.code, a.name, a.type, a.unit, a.enum_values, a.required,
+                                  t.level AS distance
+      FROM category_relationships t
+               INNER JOIN category_attributes a ON a.category_id = t.ancestor_id
+               INNER JOIN categories c ON c.category_id = t.ancestor_id
+      WHERE t.descendant_id = $1
+      ORDER BY a.code, t.level) effective
+ORDER BY distance DESC, code;
+`
+
+	sqlUpsertCategoryAttribute = `INSERT INTO category_attributes (category_id, code, name, type, unit, enum_values, required)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (category_id, code) DO UPDATE
+    SET name        = EXCLUDED.name,
+        type        = EXCLUDED.type,
+        unit        = EXCLUDED.unit,
+        enum_values = EXCLUDED.enum_values,
+        required    = EXCLUDED.required,
+        updated_at  = CURRENT_TIMESTAMP
+RETURNING attribute_id`
+
+	sqlDeleteCategoryAttribute = `DELETE FROM category_attributes WHERE category_id = $1 AND code = $2`
+)
+
+const (
+	sqlSelectCategoryTranslations = `SELECT locale, name, description FROM category_translations WHERE category_id = $1 ORDER BY locale`
+
+	// name of a non deleted sibling of category $1 that's shown as $3 in locale $2(translated or fallback name), case-insensitive.
+	sqlSelectSiblingNameInLocale = `SELECT COALESCE(t.name, s.name) AS name
+FROM categories s
+         LEFT JOIN category_translations t ON t.category_id = s.category_id AND t.locale = $2
+         LEFT JOIN category_relationships sp ON sp.descendant_id = s.category_id AND sp.level = 1
+WHERE s.category_id <> $1
+  AND s.status <> 'deleted'
+  AND sp.ancestor_id IS NOT DISTINCT FROM (SELECT ancestor_id FROM category_relationships WHERE descendant_id = $1 AND level = 1)
+  AND LOWER(COALESCE(t.name, s.name)) = LOWER($3)
+LIMIT 1`
+
+	// first locale in which category $1 is shown with the same name as a non deleted sibling, translated or fallback
+	// names, case-insensitive. only locales with a translation of the category or a sibling are compared,
+	// default locale names are covered by uq_category_sibling_name.
+	sqlSelectTranslatedSiblingNameClash = `WITH self AS (
+    SELECT category_id, parent_id, name FROM categories WHERE category_id = $1
+), siblings AS (
+    SELECT s.category_id, s.name
+    FROM categories s, self
+    WHERE s.category_id <> self.category_id
+      AND s.status <> 'deleted'
+      AND s.parent_id IS NOT DISTINCT FROM self.parent_id
+)
+SELECT l.locale, COALESCE(ct.name, self.name) AS name
+FROM (SELECT DISTINCT locale
+      FROM category_translations
+      WHERE category_id = $1 OR category_id IN (SELECT category_id FROM siblings)) l
+         CROSS JOIN self
+         CROSS JOIN siblings s
+         LEFT JOIN category_translations ct ON ct.category_id = self.category_id AND ct.locale = l.locale
+         LEFT JOIN category_translations st ON st.category_id = s.category_id AND st.locale = l.locale
+WHERE LOWER(COALESCE(ct.name, self.name)) = LOWER(COALESCE(st.name, s.name))
+ORDER BY l.locale
+LIMIT 1`
 
-	sqlGetAllCategoriesWithHierarchy = `WITH RECURSIVE CategoryTree AS (
-    SELECT c.category_id, c.category_uuid, CAST(NULL AS uuid) AS parent_category_uuid, c.name, c.description, c.status, c.created_at, c.updated_at, 0 AS level
-    FROM categories c
-    WHERE c.category_id NOT IN (SELECT descendant_id FROM category_relationships)
+	sqlUpsertCategoryTranslation = `INSERT INTO category_translations (category_id, locale, name, description)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (category_id, locale) DO UPDATE
+    SET name        = EXCLUDED.name,
+        description = EXCLUDED.description,
+        updated_at  = CURRENT_TIMESTAMP`
+
+	sqlDeleteCategoryTranslation = `DELETE FROM category_translations WHERE category_id = $1 AND locale = $2`
+)
+
+const (
+	// every category with its parent(0 for roots), import matches categories by parent and slug.
+	sqlSelectImportNodes = `SELECT category_id, COALESCE(parent_id, 0), slug, name, COALESCE(description, '')
+FROM categories
+WHERE status <> 'deleted'`
+)
+
+const (
+	// actor of the current transaction's category changes, read by the category history trigger.
+	sqlSetHistoryActor = `SELECT set_config('app.actor', $1, true)`
+
+	// history of category $1 newest first, $2 is a history id cursor(0 for the newest), $3 is the limit.
+	sqlSelectCategoryHistory = `SELECT history_id, action, actor, before, after, changed_at
+FROM category_history
+WHERE category_id = $1 AND ($2 = 0 OR history_id < $2)
+ORDER BY history_id DESC
+LIMIT $3`
+
+	// category tree as it was at $1 from the latest history snapshot of each category, categories created later are
+	// left out. same columns and order as sqlGetAllCategoriesWithHierarchy, updated_at is when the snapshot was taken.
+	sqlGetCategoryTreeAsOf = `WITH RECURSIVE snapshot AS (
+    SELECT DISTINCT ON (h.category_id) h.category_id, c.category_uuid::TEXT AS category_uuid, c.created_at, h.after, h.changed_at
+    FROM category_history h
+             INNER JOIN categories c ON c.category_id = h.category_id
+    WHERE h.changed_at <= $1
+    ORDER BY h.category_id, h.history_id DESC
+), tree AS (
+    SELECT s.*, 0 AS level FROM snapshot s WHERE s.after ->> 'parentUuid' IS NULL
     UNION ALL
-    SELECT c.category_id, c.category_uuid, ct.category_uuid AS parent_category_uuid, c.name, c.description, c.status, c.created_at,c.updated_at, ct.level + 1
-    FROM categories c
-             INNER JOIN category_relationships cr ON c.category_id = cr.descendant_id
-             INNER JOIN CategoryTree ct ON cr.ancestor_id = ct.category_id
+    SELECT s.*, t.level + 1 FROM snapshot s INNER JOIN tree t ON s.after ->> 'parentUuid' = t.category_uuid
 )
-SELECT category_uuid, parent_category_uuid, level, name, description,status,created_at,updated_at FROM CategoryTree ORDER BY level, category_id;
+SELECT category_uuid, after ->> 'parentUuid' AS parent_category_uuid, level,
+       after ->> 'name' AS name, after ->> 'slug' AS slug, after ->> 'description' AS description,
+       after ->> 'status' AS status, created_at, changed_at AS updated_at
+FROM tree
+ORDER BY level, (after ->> 'position')::INT, category_id;
 `
 )
+
+const (
+	sqlSelectCategoryMedia = `SELECT kind, object_key, content_type, width, height, size_bytes, thumbnails, updated_at
+FROM category_media WHERE category_id = $1 ORDER BY kind`
+	sqlSelectCategoryMediaForUpdate = `SELECT kind, object_key, content_type, width, height, size_bytes, thumbnails, updated_at
+FROM category_media WHERE category_id = $1 AND kind = $2 FOR UPDATE`
+	sqlUpsertCategoryMedia = `INSERT INTO category_media (category_id, kind, object_key, content_type, width, height, size_bytes, thumbnails)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (category_id, kind) DO UPDATE SET object_key   = EXCLUDED.object_key,
+                                              content_type = EXCLUDED.content_type,
+                                              width        = EXCLUDED.width,
+                                              height       = EXCLUDED.height,
+                                              size_bytes   = EXCLUDED.size_bytes,
+                                              thumbnails   = EXCLUDED.thumbnails,
+                                              updated_at   = CURRENT_TIMESTAMP
+RETURNING updated_at`
+	sqlDeleteCategoryMedia = `DELETE FROM category_media WHERE category_id = $1 AND kind = $2
+RETURNING kind, object_key, content_type, width, height, size_bytes, thumbnails, updated_at`
+)
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	"github.com/ashtishad/ecommerce/lib"
 )
@@ -9,8 +10,42 @@ import (
 type CategoryRepository interface {
 	CreateCategory(ctx context.Context, category Category) (*Category, lib.APIError)
 	CreateSubCategory(ctx context.Context, subCategory Category, parentCategoryUUID string) (*Category, lib.APIError)
-	GetAllCategoriesWithHierarchy(ctx context.Context) ([]*Category, lib.APIError)
+	GetAllCategoriesWithHierarchy(ctx context.Context, locale string) ([]*Category, lib.APIError)
+	FindCategoryByUUID(ctx context.Context, categoryUUID string) (*Category, lib.APIError)
+	UpdateCategory(ctx context.Context, category Category) (*Category, lib.APIError)
+	UpdateCategoryStatus(ctx context.Context, categoryUUID string, status string, cascade bool) (*Category, lib.APIError)
+	MoveCategory(ctx context.Context, categoryUUID string, newParentUUID string) (*Category, lib.APIError)
+	FindAncestors(ctx context.Context, categoryUUID string, includeSelf bool) ([]*Category, lib.APIError)
+	FindSubtree(ctx context.Context, categoryUUID string, maxDepth int) (*Category, lib.APIError)
+	ReorderChildren(ctx context.Context, parentUUID string, childUUIDs []string) (*Category, lib.APIError)
+	UpdateCategorySlug(ctx context.Context, categoryUUID string, newSlug string) (*Category, lib.APIError)
+	FindCategoryByPath(ctx context.Context, slugs []string) (*Category, []string, lib.APIError)
+	ImportCategories(ctx context.Context, items []CategoryImportItem, dryRun bool) (*CategoryImportReport, lib.APIError)
+}
+
+// CategoryAttributeRepository keeps the attribute definitions of categories.
+type CategoryAttributeRepository interface {
+	FindCategoryAttributes(ctx context.Context, categoryUUID string) ([]CategoryAttribute, lib.APIError)
+	SaveCategoryAttribute(ctx context.Context, categoryUUID string, attribute CategoryAttribute) (*CategoryAttribute, lib.APIError)
+	DeleteCategoryAttribute(ctx context.Context, categoryUUID string, code string) lib.APIError
+}
+
+// CategoryTranslationRepository keeps localized names and descriptions of categories.
+type CategoryTranslationRepository interface {
+	FindCategoryTranslations(ctx context.Context, categoryUUID string) ([]CategoryTranslation, lib.APIError)
+	SaveCategoryTranslation(ctx context.Context, categoryUUID string, translation CategoryTranslation) (*CategoryTranslation, lib.APIError)
+	DeleteCategoryTranslation(ctx context.Context, categoryUUID string, locale string) lib.APIError
+}
+
+// CategoryHistoryRepository reads the recorded changes of categories.
+type CategoryHistoryRepository interface {
+	FindCategoryHistory(ctx context.Context, categoryUUID string, beforeID int64, limit int) ([]CategoryHistoryEntry, lib.APIError)
+	GetCategoryTreeAsOf(ctx context.Context, asOf time.Time) ([]*Category, lib.APIError)
+}
 
-	checkCategoryNameExists(ctx context.Context, categoryName string) lib.APIError
-	findCategoryByID(ctx context.Context, categoryID int) (*Category, lib.APIError)
+// CategoryMediaRepository keeps metadata of category banners and icons, the images are in the blob store.
+type CategoryMediaRepository interface {
+	FindCategoryMedia(ctx context.Context, categoryUUID string) ([]CategoryMedia, lib.APIError)
+	SaveCategoryMedia(ctx context.Context, categoryUUID string, media CategoryMedia) (*CategoryMedia, *CategoryMedia, lib.APIError)
+	DeleteCategoryMedia(ctx context.Context, categoryUUID string, kind string) (*CategoryMedia, lib.APIError)
 }
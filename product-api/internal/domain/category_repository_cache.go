@@ -0,0 +1,186 @@
+package domain
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/ashtishad/ecommerce/lib"
+)
+
+// CategoryChangeNotifier broadcasts category changes between api instances, so each instance can drop its cache.
+type CategoryChangeNotifier interface {
+	Notify(ctx context.Context) error
+	Listen(ctx context.Context, onChange func())
+}
+
+// CategoryRepoCache is a read-through cache decorator of CategoryRepository, it keeps the assembled category tree
+// of each locale in memory, trees are dropped whenever a write succeeds through it or the notifier reports a change.
+// cached tree is shared between callers, callers must not modify it.
+type CategoryRepoCache struct {
+	CategoryRepository // reads other than the tree aren't cached, they pass through
+	notifier           CategoryChangeNotifier
+	l                  *slog.Logger
+
+	mu      sync.RWMutex
+	trees   map[string][]*Category // by locale
+	version uint64
+}
+
+// NewCategoryRepoCache wraps next with a tree cache, notifier is optional(nil) and only needed with multiple instances.
+func NewCategoryRepoCache(next CategoryRepository, notifier CategoryChangeNotifier, l *slog.Logger) *CategoryRepoCache {
+	return &CategoryRepoCache{CategoryRepository: next, notifier: notifier, l: l}
+}
+
+// Invalidate drops the cached trees, a load that started before it won't be stored.
+func (c *CategoryRepoCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.trees = nil
+	c.version++
+}
+
+// invalidate drops local cache and tells other instances to drop theirs.
+func (c *CategoryRepoCache) invalidate(ctx context.Context) {
+	c.Invalidate()
+
+	if c.notifier == nil {
+		return
+	}
+
+	if err := c.notifier.Notify(ctx); err != nil {
+		c.l.Warn("failed to notify category change", "err", err)
+	}
+}
+
+func (c *CategoryRepoCache) GetAllCategoriesWithHierarchy(ctx context.Context, locale string) ([]*Category, lib.APIError) {
+	c.mu.RLock()
+	if tree, ok := c.trees[locale]; ok {
+		c.mu.RUnlock()
+		return tree, nil
+	}
+
+	version := c.version
+	c.mu.RUnlock()
+
+	tree, apiErr := c.CategoryRepository.GetAllCategoriesWithHierarchy(ctx, locale)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	c.mu.Lock()
+	if c.version == version {
+		if c.trees == nil {
+			c.trees = make(map[string][]*Category)
+		}
+
+		c.trees[locale] = tree
+	}
+	c.mu.Unlock()
+
+	return tree, nil
+}
+
+func (c *CategoryRepoCache) CreateCategory(ctx context.Context, category Category) (*Category, lib.APIError) {
+	created, apiErr := c.CategoryRepository.CreateCategory(ctx, category)
+	if apiErr == nil {
+		c.invalidate(ctx)
+	}
+
+	return created, apiErr
+}
+
+func (c *CategoryRepoCache) CreateSubCategory(ctx context.Context, subCategory Category, parentCategoryUUID string) (*Category, lib.APIError) {
+	created, apiErr := c.CategoryRepository.CreateSubCategory(ctx, subCategory, parentCategoryUUID)
+	if apiErr == nil {
+		c.invalidate(ctx)
+	}
+
+	return created, apiErr
+}
+
+func (c *CategoryRepoCache) UpdateCategory(ctx context.Context, category Category) (*Category, lib.APIError) {
+	updated, apiErr := c.CategoryRepository.UpdateCategory(ctx, category)
+	if apiErr == nil {
+		c.invalidate(ctx)
+	}
+
+	return updated, apiErr
+}
+
+func (c *CategoryRepoCache) UpdateCategoryStatus(ctx context.Context, categoryUUID string, status string, cascade bool) (*Category, lib.APIError) {
+	updated, apiErr := c.CategoryRepository.UpdateCategoryStatus(ctx, categoryUUID, status, cascade)
+	if apiErr == nil {
+		c.invalidate(ctx)
+	}
+
+	return updated, apiErr
+}
+
+func (c *CategoryRepoCache) MoveCategory(ctx context.Context, categoryUUID string, newParentUUID string) (*Category, lib.APIError) {
+	moved, apiErr := c.CategoryRepository.MoveCategory(ctx, categoryUUID, newParentUUID)
+	if apiErr == nil {
+		c.invalidate(ctx)
+	}
+
+	return moved, apiErr
+}
+
+func (c *CategoryRepoCache) ReorderChildren(ctx context.Context, parentUUID string, childUUIDs []string) (*Category, lib.APIError) {
+	parent, apiErr := c.CategoryRepository.ReorderChildren(ctx, parentUUID, childUUIDs)
+	if apiErr == nil {
+		c.invalidate(ctx)
+	}
+
+	return parent, apiErr
+}
+
+func (c *CategoryRepoCache) UpdateCategorySlug(ctx context.Context, categoryUUID string, newSlug string) (*Category, lib.APIError) {
+	updated, apiErr := c.CategoryRepository.UpdateCategorySlug(ctx, categoryUUID, newSlug)
+	if apiErr == nil {
+		c.invalidate(ctx)
+	}
+
+	return updated, apiErr
+}
+
+// ImportCategories invalidates the tree only if a committed import changed categories, dry runs never do.
+func (c *CategoryRepoCache) ImportCategories(ctx context.Context, items []CategoryImportItem, dryRun bool) (*CategoryImportReport, lib.APIError) {
+	report, apiErr := c.CategoryRepository.ImportCategories(ctx, items, dryRun)
+	if apiErr == nil && !dryRun && len(report.Changes) > 0 {
+		c.invalidate(ctx)
+	}
+
+	return report, apiErr
+}
+
+// CategoryTranslationRepoCache decorates CategoryTranslationRepository, translated names and descriptions are part
+// of the cached trees, so translation writes drop them.
+type CategoryTranslationRepoCache struct {
+	CategoryTranslationRepository
+	cache *CategoryRepoCache
+}
+
+// NewCategoryTranslationRepoCache wraps next, its successful writes invalidate cache.
+func NewCategoryTranslationRepoCache(next CategoryTranslationRepository, cache *CategoryRepoCache) *CategoryTranslationRepoCache {
+	return &CategoryTranslationRepoCache{CategoryTranslationRepository: next, cache: cache}
+}
+
+func (c *CategoryTranslationRepoCache) SaveCategoryTranslation(ctx context.Context, categoryUUID string, translation CategoryTranslation) (*CategoryTranslation, lib.APIError) {
+	saved, apiErr := c.CategoryTranslationRepository.SaveCategoryTranslation(ctx, categoryUUID, translation)
+	if apiErr == nil {
+		c.cache.invalidate(ctx)
+	}
+
+	return saved, apiErr
+}
+
+func (c *CategoryTranslationRepoCache) DeleteCategoryTranslation(ctx context.Context, categoryUUID string, locale string) lib.APIError {
+	apiErr := c.CategoryTranslationRepository.DeleteCategoryTranslation(ctx, categoryUUID, locale)
+	if apiErr == nil {
+		c.cache.invalidate(ctx)
+	}
+
+	return apiErr
+}
@@ -0,0 +1,155 @@
+package domain
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ashtishad/ecommerce/lib"
+	"github.com/stretchr/testify/require"
+)
+
+// stubCategoryRepo counts tree loads, writes fail when failWrites is set.
+type stubCategoryRepo struct {
+	CategoryRepository
+
+	loads      int
+	failWrites bool
+	onLoad     func()
+}
+
+func (s *stubCategoryRepo) GetAllCategoriesWithHierarchy(_ context.Context, locale string) ([]*Category, lib.APIError) {
+	s.loads++
+	if s.onLoad != nil {
+		s.onLoad()
+	}
+
+	if locale == "bn" {
+		return []*Category{{CategoryUUID: "phone", Name: "ফোন"}}, nil
+	}
+
+	return []*Category{{CategoryUUID: "phone", Name: "Phone"}}, nil
+}
+
+func (s *stubCategoryRepo) CreateCategory(_ context.Context, category Category) (*Category, lib.APIError) {
+	if s.failWrites {
+		return nil, lib.NewError(http.StatusConflict, ErrCodeCategoryNameExists, lib.Args{"name": category.Name})
+	}
+
+	return &category, nil
+}
+
+func (s *stubCategoryRepo) MoveCategory(_ context.Context, categoryUUID string, _ string) (*Category, lib.APIError) {
+	return &Category{CategoryUUID: categoryUUID}, nil
+}
+
+type stubTranslationRepo struct {
+	CategoryTranslationRepository
+}
+
+func (s *stubTranslationRepo) SaveCategoryTranslation(_ context.Context, _ string, translation CategoryTranslation) (*CategoryTranslation, lib.APIError) {
+	return &translation, nil
+}
+
+type stubNotifier struct {
+	notified int
+}
+
+func (n *stubNotifier) Notify(_ context.Context) error {
+	n.notified++
+	return nil
+}
+
+func (n *stubNotifier) Listen(_ context.Context, _ func()) {}
+
+func TestCategoryRepoCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Tree is loaded once", func(t *testing.T) {
+		stub := &stubCategoryRepo{}
+		cache := NewCategoryRepoCache(stub, nil, testLogger)
+
+		for i := 0; i < 3; i++ {
+			tree, apiErr := cache.GetAllCategoriesWithHierarchy(ctx, lib.DefaultLocale)
+			require.Nil(t, apiErr)
+			require.Len(t, tree, 1)
+		}
+
+		require.Equal(t, 1, stub.loads)
+	})
+
+	t.Run("Each locale is cached separately", func(t *testing.T) {
+		stub := &stubCategoryRepo{}
+		cache := NewCategoryRepoCache(stub, nil, testLogger)
+
+		for i := 0; i < 2; i++ {
+			tree, apiErr := cache.GetAllCategoriesWithHierarchy(ctx, lib.DefaultLocale)
+			require.Nil(t, apiErr)
+			require.Equal(t, "Phone", tree[0].Name)
+
+			tree, apiErr = cache.GetAllCategoriesWithHierarchy(ctx, "bn")
+			require.Nil(t, apiErr)
+			require.Equal(t, "ফোন", tree[0].Name)
+		}
+
+		require.Equal(t, 2, stub.loads)
+
+		translations := NewCategoryTranslationRepoCache(&stubTranslationRepo{}, cache)
+		_, apiErr := translations.SaveCategoryTranslation(ctx, "phone", CategoryTranslation{Locale: "bn", Name: "মোবাইল"})
+		require.Nil(t, apiErr)
+
+		_, _ = cache.GetAllCategoriesWithHierarchy(ctx, lib.DefaultLocale)
+		_, _ = cache.GetAllCategoriesWithHierarchy(ctx, "bn")
+		require.Equal(t, 4, stub.loads)
+	})
+
+	t.Run("Successful writes invalidate and notify", func(t *testing.T) {
+		stub := &stubCategoryRepo{}
+		notifier := &stubNotifier{}
+		cache := NewCategoryRepoCache(stub, notifier, testLogger)
+
+		_, _ = cache.GetAllCategoriesWithHierarchy(ctx, lib.DefaultLocale)
+
+		_, apiErr := cache.CreateCategory(ctx, Category{Name: "Wearable"})
+		require.Nil(t, apiErr)
+
+		_, _ = cache.GetAllCategoriesWithHierarchy(ctx, lib.DefaultLocale)
+		require.Equal(t, 2, stub.loads)
+
+		_, apiErr = cache.MoveCategory(ctx, "phone", "")
+		require.Nil(t, apiErr)
+
+		_, _ = cache.GetAllCategoriesWithHierarchy(ctx, lib.DefaultLocale)
+		require.Equal(t, 3, stub.loads)
+		require.Equal(t, 2, notifier.notified)
+	})
+
+	t.Run("Failed writes keep cache", func(t *testing.T) {
+		stub := &stubCategoryRepo{failWrites: true}
+		notifier := &stubNotifier{}
+		cache := NewCategoryRepoCache(stub, notifier, testLogger)
+
+		_, _ = cache.GetAllCategoriesWithHierarchy(ctx, lib.DefaultLocale)
+
+		_, apiErr := cache.CreateCategory(ctx, Category{Name: "Phone"})
+		require.ErrorIs(t, apiErr, lib.ErrConflict)
+
+		_, _ = cache.GetAllCategoriesWithHierarchy(ctx, lib.DefaultLocale)
+		require.Equal(t, 1, stub.loads)
+		require.Zero(t, notifier.notified)
+	})
+
+	t.Run("Load racing with invalidation is not stored", func(t *testing.T) {
+		stub := &stubCategoryRepo{}
+		cache := NewCategoryRepoCache(stub, nil, testLogger)
+		stub.onLoad = func() {
+			stub.onLoad = nil
+			cache.Invalidate()
+		}
+
+		_, _ = cache.GetAllCategoriesWithHierarchy(ctx, lib.DefaultLocale)
+		_, _ = cache.GetAllCategoriesWithHierarchy(ctx, lib.DefaultLocale)
+		_, _ = cache.GetAllCategoriesWithHierarchy(ctx, lib.DefaultLocale)
+		require.Equal(t, 2, stub.loads)
+	})
+}
@@ -6,8 +6,24 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"strings"
 
 	"github.com/ashtishad/ecommerce/lib"
+	"github.com/ashtishad/ecommerce/product-api/pkg/slug"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	// uniqueSiblingNameIndex keeps category names unique case-insensitively among non deleted siblings.
+	uniqueSiblingNameIndex = "uq_category_sibling_name"
+	// uniqueSiblingSlugIndex keeps category slugs unique among non deleted siblings.
+	uniqueSiblingSlugIndex = "uq_category_sibling_slug"
+
+	// pgUniqueViolation is the postgres error code of a unique constraint violation.
+	pgUniqueViolation = "23505"
+	// pgSerializationFailure is the postgres error code of a serializable transaction that conflicts with a concurrent one.
+	pgSerializationFailure = "40001"
 )
 
 type CategoryRepoDB struct {
@@ -20,7 +36,7 @@ func NewCategoryRepoDB(db *sql.DB, l *slog.Logger) *CategoryRepoDB {
 }
 
 func (d *CategoryRepoDB) CreateCategory(ctx context.Context, category Category) (*Category, lib.APIError) {
-	tx, err := d.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	tx, err := d.beginCategoryTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
 	if err != nil {
 		d.l.Error(lib.ErrTxBegin, "err", err)
 		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
@@ -28,30 +44,49 @@ func (d *CategoryRepoDB) CreateCategory(ctx context.Context, category Category)
 
 	defer rollBackOnError(tx, d.l, &err)
 
-	if apiErr := d.checkCategoryNameExists(ctx, category.Name); apiErr != nil {
-		return nil, apiErr
+	if category.Slug, err = d.uniqueChildSlug(ctx, tx, sql.NullInt64{}, categorySlug(category.Name), 0); err != nil {
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
 	}
 
-	if err = d.executeInsertCategory(ctx, tx, &category); err != nil {
+	if err = d.executeInsertCategory(ctx, tx, &category, sql.NullInt64{}); err != nil {
+		if isSiblingNameViolation(err) {
+			return nil, d.siblingNameConflict(ctx, sql.NullInt64{}, category.Name, 0)
+		}
+
+		if isSiblingSlugViolation(err) {
+			return nil, d.slugConflict(category.Slug, err)
+		}
+
 		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
 	}
 
-	if err = tx.Commit(); err != nil {
+	if err = d.appendCategoryPosition(ctx, tx, category.CategoryID); err != nil {
 		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
 	}
 
+	if apiErr := checkTranslatedSiblingNames(ctx, tx, d.l, category.CategoryID); apiErr != nil {
+		err = apiErr // rollback
+		return nil, apiErr
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, txError(err)
+	}
+
 	return d.findCategoryByID(ctx, category.CategoryID)
 }
 
-// ExecuteInsertCategory executes the SQL query to insert a new category
+// ExecuteInsertCategory executes the SQL query to insert a new category under parentID, NULL for a root category.
 // Scans category id and assigns to category domain object
-func (d *CategoryRepoDB) executeInsertCategory(ctx context.Context, tx *sql.Tx, category *Category) error {
+func (d *CategoryRepoDB) executeInsertCategory(ctx context.Context, tx *sql.Tx, category *Category, parentID sql.NullInt64) error {
 	var categoryID int
 	err := tx.QueryRowContext(
 		ctx,
 		sqlInsertCategory,
 		category.Name,
 		category.Description,
+		category.Slug,
+		parentID,
 	).Scan(&categoryID)
 
 	if err != nil {
@@ -63,22 +98,52 @@ func (d *CategoryRepoDB) executeInsertCategory(ctx context.Context, tx *sql.Tx,
 	return nil
 }
 
-func (d *CategoryRepoDB) checkCategoryNameExists(ctx context.Context, categoryName string) lib.APIError {
-	var existingCategoryName string
+// isSiblingSlugViolation reports whether err is a violation of uq_category_sibling_slug, a concurrent write gave
+// a sibling the same slug after it was checked.
+func isSiblingSlugViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation && pgErr.ConstraintName == uniqueSiblingSlugIndex
+}
 
-	err := d.db.QueryRowContext(ctx, sqlSelectCategoryName, categoryName).Scan(&existingCategoryName)
+// slugConflict returns 409 for a slug a sibling already has.
+func (d *CategoryRepoDB) slugConflict(categorySlug string, err error) lib.APIError {
+	d.l.Warn("category slug already exists among siblings", "slug", categorySlug)
+	return lib.NewError(http.StatusConflict, ErrCodeCategorySlugExists, lib.Args{"slug": categorySlug}).Wrap(err)
+}
 
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		d.l.Error("error checking existing category:", "err", err.Error())
-		return lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+// isSiblingNameViolation reports whether err is a violation of uq_category_sibling_name,
+// the partial unique index on parent and lowercase name of non deleted categories.
+func isSiblingNameViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation && pgErr.ConstraintName == uniqueSiblingNameIndex
+}
+
+// siblingNameConflict returns 409 naming the sibling under parentID that already has name, categoryID is the category
+// being written, 0 for a new one. Transaction that hit the index is aborted, so the sibling is looked up outside it.
+func (d *CategoryRepoDB) siblingNameConflict(ctx context.Context, parentID sql.NullInt64, name string, categoryID int) lib.APIError {
+	var siblingUUID, siblingName string
+
+	err := d.db.QueryRowContext(ctx, sqlSelectSiblingByName, parentID, name, categoryID).Scan(&siblingUUID, &siblingName)
+	if err != nil {
+		d.l.Warn("unable to find clashing sibling", "input", name, "err", err)
+		return lib.NewError(http.StatusConflict, ErrCodeCategoryNameExists, lib.Args{"name": name})
 	}
 
-	if existingCategoryName != "" {
-		d.l.Warn("category name already exists", "input", categoryName, "existing", existingCategoryName)
-		return lib.NewDBFieldConflictError(fmt.Sprintf("category name already exists, input: %s existing:%s", categoryName, existingCategoryName))
+	d.l.Warn("category name already exists among siblings", "input", name, "sibling", siblingUUID)
+
+	return lib.NewError(http.StatusConflict, ErrCodeCategoryNameExists,
+		lib.Args{"name": name, "sibling": siblingName, "siblingUuid": siblingUUID})
+}
+
+// txError returns 409 if err is a serialization failure, a concurrent serializable transaction wrote rows this one
+// depends on and the request can be retried, other errors are unexpected.
+func txError(err error) lib.APIError {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgSerializationFailure {
+		return lib.NewError(http.StatusConflict, ErrCodeCategoryWriteConflict, nil).Wrap(err)
 	}
 
-	return nil
+	return lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
 }
 
 // findCategoryByID takes categoryID and returns a single category record
@@ -90,6 +155,7 @@ func (d *CategoryRepoDB) findCategoryByID(ctx context.Context, categoryID int) (
 	err := row.Scan(&category.CategoryID,
 		&category.CategoryUUID,
 		&category.Name,
+		&category.Slug,
 		&category.Description,
 		&category.Status,
 		&category.CreatedAt,
@@ -98,7 +164,7 @@ func (d *CategoryRepoDB) findCategoryByID(ctx context.Context, categoryID int) (
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			d.l.Error("category not found", "err", err.Error())
-			return nil, lib.NewNotFoundError(lib.UnexpectedDatabaseErr)
+			return nil, lib.NewError(http.StatusNotFound, ErrCodeCategoryNotFound, nil).Wrap(err)
 		}
 
 		d.l.Error(lib.ErrScanningRows, "err", err.Error())
@@ -110,7 +176,7 @@ func (d *CategoryRepoDB) findCategoryByID(ctx context.Context, categoryID int) (
 }
 
 func (d *CategoryRepoDB) CreateSubCategory(ctx context.Context, subCategory Category, parentCategoryUUID string) (*Category, lib.APIError) {
-	tx, err := d.db.BeginTx(ctx, nil)
+	tx, err := d.beginCategoryTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
 	if err != nil {
 		d.l.Error(lib.ErrTxBegin, "err", err)
 		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
@@ -118,29 +184,153 @@ func (d *CategoryRepoDB) CreateSubCategory(ctx context.Context, subCategory Cate
 
 	defer rollBackOnError(tx, d.l, &err)
 
-	// validate parentCategoryUUID and get its ID
-	parentCategoryID, apiErr := d.validateAndGetParentID(ctx, tx, parentCategoryUUID, subCategory.Name)
+	parentCategoryID, apiErr := d.selectParentID(ctx, tx, parentCategoryUUID)
 	if apiErr != nil {
+		err = apiErr // rollback
 		return nil, apiErr
 	}
 
-	// first insert sub-category
-	if err = d.executeInsertCategory(ctx, tx, &subCategory); err != nil {
+	// first insert sub-category, sibling name uniqueness is enforced by uq_category_sibling_name
+	parentID := sql.NullInt64{Int64: int64(parentCategoryID), Valid: true}
+
+	if subCategory.Slug, err = d.uniqueChildSlug(ctx, tx, parentID, categorySlug(subCategory.Name), 0); err != nil {
 		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
 	}
 
-	// calculate parent and insert category relationships
-	if err = d.insertCategoryRelationship(ctx, tx, parentCategoryID, subCategory.CategoryID); err != nil {
+	if err = d.executeInsertCategory(ctx, tx, &subCategory, parentID); err != nil {
+		if isSiblingNameViolation(err) {
+			return nil, d.siblingNameConflict(ctx, parentID, subCategory.Name, 0)
+		}
+
+		if isSiblingSlugViolation(err) {
+			return nil, d.slugConflict(subCategory.Slug, err)
+		}
+
 		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
 	}
 
-	if err = tx.Commit(); err != nil {
+	// insert paths from every ancestor, self path is inserted with the category
+	if err = d.insertAncestorPaths(ctx, tx, parentCategoryID, subCategory.CategoryID); err != nil {
 		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
 	}
 
+	if err = d.appendCategoryPosition(ctx, tx, subCategory.CategoryID); err != nil {
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	if apiErr := checkTranslatedSiblingNames(ctx, tx, d.l, subCategory.CategoryID); apiErr != nil {
+		err = apiErr // rollback
+		return nil, apiErr
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, txError(err)
+	}
+
 	return d.findCategoryByID(ctx, subCategory.CategoryID)
 }
 
+// appendCategoryPosition puts a category after its siblings, called after it's linked to its parent.
+func (d *CategoryRepoDB) appendCategoryPosition(ctx context.Context, tx *sql.Tx, categoryID int) error {
+	if _, err := tx.ExecContext(ctx, sqlAppendCategoryPosition, categoryID); err != nil {
+		d.l.Error("failed to set category position", "err", err)
+		return fmt.Errorf("unable to set category position: %w", err)
+	}
+
+	return nil
+}
+
+// categorySlug makes the initial slug of a category from its name.
+func categorySlug(name string) string {
+	if s := slug.Make(name); s != "" {
+		return s
+	}
+
+	return defaultCategorySlug
+}
+
+// uniqueChildSlug suffixes base if a non deleted child of parentID(NULL for roots) other than categoryID already has it,
+// called before the category is written under the parent, uq_category_sibling_slug catches concurrent writes.
+func (d *CategoryRepoDB) uniqueChildSlug(ctx context.Context, tx *sql.Tx, parentID sql.NullInt64, base string, categoryID int) (string, error) {
+	taken, err := d.selectSlugs(ctx, tx, sqlSelectChildSlugs, parentID, base, categoryID)
+	if err != nil {
+		return "", err
+	}
+
+	return slug.Unique(base, taken), nil
+}
+
+func (d *CategoryRepoDB) selectSiblingSlugs(ctx context.Context, tx *sql.Tx, categoryID int, base string) ([]string, error) {
+	return d.selectSlugs(ctx, tx, sqlSelectSiblingSlugs, categoryID, base)
+}
+
+func (d *CategoryRepoDB) selectSlugs(ctx context.Context, tx *sql.Tx, query string, args ...any) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		d.l.Error("failed to query sibling slugs", "err", err)
+		return nil, fmt.Errorf("unable to query sibling slugs: %w", err)
+	}
+
+	defer closeRows(rows, d.l)
+
+	var slugs []string
+
+	for rows.Next() {
+		var s string
+		if err = rows.Scan(&s); err != nil {
+			return nil, fmt.Errorf("unable to scan sibling slug: %w", err)
+		}
+
+		slugs = append(slugs, s)
+	}
+
+	return slugs, rows.Err()
+}
+
+// replaceSlug sets a new slug and keeps the old one for redirects.
+func (d *CategoryRepoDB) replaceSlug(ctx context.Context, tx *sql.Tx, categoryID int, oldSlug, newSlug string) error {
+	if _, err := tx.ExecContext(ctx, sqlUpdateCategorySlug, newSlug, categoryID); err != nil {
+		d.l.Error("failed to update category slug", "err", err)
+		return fmt.Errorf("unable to update category slug: %w", err)
+	}
+
+	return d.keepSlugHistory(ctx, tx, categoryID, oldSlug, newSlug)
+}
+
+// keepSlugHistory keeps the old slug of a category for redirects, new slug is removed from history as it's current again.
+func (d *CategoryRepoDB) keepSlugHistory(ctx context.Context, tx *sql.Tx, categoryID int, oldSlug, newSlug string) error {
+	if _, err := tx.ExecContext(ctx, sqlInsertSlugHistory, categoryID, oldSlug); err != nil {
+		d.l.Error("failed to insert slug history", "err", err)
+		return fmt.Errorf("unable to insert slug history: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, sqlDeleteSlugHistory, categoryID, newSlug); err != nil {
+		d.l.Error("failed to delete slug history", "err", err)
+		return fmt.Errorf("unable to delete slug history: %w", err)
+	}
+
+	return nil
+}
+
+// beginCategoryTx begins a transaction that writes categories, the history trigger records the request's actor
+// with every change made in it.
+func (d *CategoryRepoDB) beginCategoryTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	tx, err := d.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = tx.ExecContext(ctx, sqlSetHistoryActor, lib.ActorFromContext(ctx)); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			d.l.Warn("unable to rollback", "rollbackErr", rbErr)
+		}
+
+		return nil, fmt.Errorf("unable to set category history actor: %w", err)
+	}
+
+	return tx, nil
+}
+
 func rollBackOnError(tx *sql.Tx, l *slog.Logger, err *error) {
 	if *err != nil {
 		l.Error("unable to complete operation", "err", (*err).Error())
@@ -151,35 +341,25 @@ func rollBackOnError(tx *sql.Tx, l *slog.Logger, err *error) {
 	}
 }
 
-// validateAndGetParentID validates the given parent category UUID, checks for category name uniqueness,
-// and returns its ID, otherwise, it returns error 404,500 or 409.
-func (d *CategoryRepoDB) validateAndGetParentID(ctx context.Context, tx *sql.Tx, parentCategoryUUID string, categoryName string) (int, lib.APIError) {
-	var (
-		parentCategoryID int
-		nameExists       bool
-	)
-
-	err := tx.QueryRowContext(ctx,
-		sqlValidateUUIDGetCatID,
-		parentCategoryUUID, categoryName).Scan(&parentCategoryID, &nameExists)
+// selectParentID returns the id of the parent category, otherwise, it returns error 404 or 500.
+func (d *CategoryRepoDB) selectParentID(ctx context.Context, tx *sql.Tx, parentCategoryUUID string) (int, lib.APIError) {
+	var parentCategoryID int
 
+	err := tx.QueryRowContext(ctx, sqlSelectCategoryID, parentCategoryUUID).Scan(&parentCategoryID)
 	if errors.Is(err, sql.ErrNoRows) {
-		return 0, lib.NewNotFoundError("parent category not found")
+		return 0, lib.NewError(http.StatusNotFound, ErrCodeParentCategoryNotFound, nil)
 	} else if err != nil {
 		d.l.Error("database error:", "err", err)
 		return 0, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
 	}
 
-	if nameExists {
-		return 0, lib.NewDBFieldConflictError("category name already exists")
-	}
-
 	return parentCategoryID, nil
 }
 
-// insertCategoryRelationship inserts a new row into the category_relationships table and calculates the level.
-func (d *CategoryRepoDB) insertCategoryRelationship(ctx context.Context, tx *sql.Tx, parentCategoryID, subCategoryID int) lib.APIError {
-	_, err := tx.ExecContext(ctx, sqlInsertWithLevelCalculation, parentCategoryID, parentCategoryID, subCategoryID)
+// insertAncestorPaths links a new sub-category to its parent and every ancestor of the parent,
+// level of each row is the distance between the ancestor and the sub-category.
+func (d *CategoryRepoDB) insertAncestorPaths(ctx context.Context, tx *sql.Tx, parentCategoryID, subCategoryID int) lib.APIError {
+	_, err := tx.ExecContext(ctx, sqlInsertAncestorPaths, parentCategoryID, subCategoryID)
 	if err != nil {
 		d.l.Error("failed to insert into category_relationships:", "err", err)
 		return lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
@@ -188,8 +368,10 @@ func (d *CategoryRepoDB) insertCategoryRelationship(ctx context.Context, tx *sql
 	return nil
 }
 
-func (d *CategoryRepoDB) GetAllCategoriesWithHierarchy(ctx context.Context) ([]*Category, lib.APIError) {
-	rows, err := d.db.QueryContext(ctx, sqlGetAllCategoriesWithHierarchy)
+// GetAllCategoriesWithHierarchy returns the category tree with names and descriptions in locale,
+// categories without a translation keep their default locale values.
+func (d *CategoryRepoDB) GetAllCategoriesWithHierarchy(ctx context.Context, locale string) ([]*Category, lib.APIError) {
+	rows, err := d.db.QueryContext(ctx, sqlGetAllCategoriesWithHierarchy, locale)
 	if err != nil {
 		d.l.Error("failed to query get all categories:", "err", err)
 		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
@@ -197,18 +379,31 @@ func (d *CategoryRepoDB) GetAllCategoriesWithHierarchy(ctx context.Context) ([]*
 
 	defer closeRows(rows, d.l)
 
-	return d.BuildTree(rows)
+	return buildCategoryTree(rows, d.l)
+}
+
+// buildCategoryTree scans category rows and assembles them into the category tree.
+func buildCategoryTree(rows *sql.Rows, l *slog.Logger) ([]*Category, lib.APIError) {
+	categories, apiErr := scanCategoryRows(rows, l)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	emptyParent := sql.NullString{Valid: false}
+
+	return buildTree(categories, emptyParent), nil
 }
 
-func (d *CategoryRepoDB) BuildTree(rows *sql.Rows) ([]*Category, lib.APIError) {
+// scanCategoryRows scans category rows with parent uuid and level, without building hierarchy.
+func scanCategoryRows(rows *sql.Rows, l *slog.Logger) ([]*Category, lib.APIError) {
 	var categories []*Category
 
 	for rows.Next() {
 		var c Category
-		err := rows.Scan(&c.CategoryUUID, &c.ParentCategoryUUID, &c.Level, &c.Name, &c.Description, &c.Status, &c.CreatedAt, &c.UpdatedAt)
+		err := rows.Scan(&c.CategoryUUID, &c.ParentCategoryUUID, &c.Level, &c.Name, &c.Slug, &c.Description, &c.Status, &c.CreatedAt, &c.UpdatedAt)
 
 		if err != nil {
-			d.l.Error("failed to scan rows:", "err", err)
+			l.Error("failed to scan rows:", "err", err)
 			return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
 		}
 
@@ -216,49 +411,561 @@ func (d *CategoryRepoDB) BuildTree(rows *sql.Rows) ([]*Category, lib.APIError) {
 	}
 
 	if err := rows.Err(); err != nil {
-		d.l.Error("unexpected error on BuildTree", "err", err)
+		l.Error("unexpected error on scanning category rows", "err", err)
 		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
 	}
 
-	emptyParent := sql.NullString{Valid: false}
+	return categories, nil
+}
 
-	return buildTree(categories, emptyParent), nil
+// selectCategoryID returns id of a category, returns 404 if category doesn't exist.
+func selectCategoryID(ctx context.Context, db *sql.DB, l *slog.Logger, categoryUUID string) (int, lib.APIError) {
+	var categoryID int
+	if err := db.QueryRowContext(ctx, sqlSelectCategoryID, categoryUUID).Scan(&categoryID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			l.Warn("category not found", "uuid", categoryUUID)
+			return 0, lib.NewError(http.StatusNotFound, ErrCodeCategoryNotFound, nil).Wrap(err)
+		}
+
+		l.Error(lib.ErrScanningRows, "err", err.Error())
+
+		return 0, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return categoryID, nil
 }
 
-func buildTree(categories []*Category, parentUUID sql.NullString) []*Category {
-	var tree []*Category
+func closeRows(rows *sql.Rows, l *slog.Logger) {
+	if rcErr := rows.Close(); rcErr != nil {
+		l.Warn("error closing rows", "err", rcErr)
+	}
+}
 
-	for _, c := range categories {
-		if parentUUID.Valid && c.ParentCategoryUUID.Valid && parentUUID.String == c.ParentCategoryUUID.String {
-			children := buildTree(categories, sql.NullString{String: c.CategoryUUID, Valid: true})
-			if len(children) > 0 {
-				sub := make([]Category, len(children))
-				for i := range children {
-					sub[i] = *children[i]
-				}
+// FindCategoryByUUID returns a single category with its parent uuid and level,
+// returns 404 if category doesn't exist.
+func (d *CategoryRepoDB) FindCategoryByUUID(ctx context.Context, categoryUUID string) (*Category, lib.APIError) {
+	row := d.db.QueryRowContext(ctx, sqlSelectCategoryByUUID, categoryUUID)
 
-				c.Subcategories = sub
+	var category Category
+	err := row.Scan(&category.CategoryID,
+		&category.CategoryUUID,
+		&category.ParentCategoryUUID,
+		&category.Level,
+		&category.Name,
+		&category.Slug,
+		&category.Description,
+		&category.Status,
+		&category.CreatedAt,
+		&category.UpdatedAt)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			d.l.Warn("category not found", "uuid", categoryUUID)
+			return nil, lib.NewError(http.StatusNotFound, ErrCodeCategoryNotFound, nil).Wrap(err)
+		}
+
+		d.l.Error(lib.ErrScanningRows, "err", err.Error())
+
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return &category, nil
+}
+
+// UpdateCategory renames a category and updates its description in a serializable transaction,
+// returns 409 naming the sibling if a sibling has the same name, 404 if category doesn't exist.
+func (d *CategoryRepoDB) UpdateCategory(ctx context.Context, category Category) (*Category, lib.APIError) {
+	tx, err := d.beginCategoryTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		d.l.Error(lib.ErrTxBegin, "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	defer rollBackOnError(tx, d.l, &err)
+
+	var parentID sql.NullInt64
+
+	if err = tx.QueryRowContext(ctx, sqlSelectCategoryIDAndParent, category.CategoryUUID).Scan(&category.CategoryID, &parentID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, lib.NewError(http.StatusNotFound, ErrCodeCategoryNotFound, nil).Wrap(err)
+		}
+
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	if _, err = tx.ExecContext(ctx, sqlUpdateCategory, category.Name, category.Description, category.CategoryID); err != nil {
+		if isSiblingNameViolation(err) {
+			return nil, d.siblingNameConflict(ctx, parentID, category.Name, category.CategoryID)
+		}
+
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	if apiErr := checkTranslatedSiblingNames(ctx, tx, d.l, category.CategoryID); apiErr != nil {
+		err = apiErr // rollback
+		return nil, apiErr
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, txError(err)
+	}
+
+	return d.FindCategoryByUUID(ctx, category.CategoryUUID)
+}
+
+// UpdateCategoryStatus changes the status of a category, if cascade is true
+// all descendants get the same status in the same transaction.
+// returns 404 if category doesn't exist, 409 if restoring a deleted category clashes with a sibling name.
+func (d *CategoryRepoDB) UpdateCategoryStatus(ctx context.Context, categoryUUID string, status string, cascade bool) (*Category, lib.APIError) {
+	tx, err := d.beginCategoryTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		d.l.Error(lib.ErrTxBegin, "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	defer rollBackOnError(tx, d.l, &err)
+
+	var categoryID int
+	if err = tx.QueryRowContext(ctx, sqlUpdateCategoryStatus, status, categoryUUID).Scan(&categoryID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, lib.NewError(http.StatusNotFound, ErrCodeCategoryNotFound, nil).Wrap(err)
+		}
+
+		if isSiblingNameViolation(err) {
+			return nil, d.subtreeNameConflict(ctx, categoryUUID, false)
+		}
+
+		if isSiblingSlugViolation(err) {
+			return nil, d.subtreeSlugConflict(ctx, categoryUUID, false, err)
+		}
+
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	if cascade {
+		if _, err = tx.ExecContext(ctx, sqlUpdateDescendantsStatus, status, categoryID); err != nil {
+			if isSiblingNameViolation(err) {
+				return nil, d.subtreeNameConflict(ctx, categoryUUID, true)
 			}
 
-			tree = append(tree, c)
-		} else if !parentUUID.Valid && !c.ParentCategoryUUID.Valid {
-			children := buildTree(categories, sql.NullString{String: c.CategoryUUID, Valid: true})
-			if len(children) > 0 {
-				sub := make([]Category, len(children))
-				for i := range children {
-					sub[i] = *children[i]
-				}
-				c.Subcategories = sub
+			if isSiblingSlugViolation(err) {
+				return nil, d.subtreeSlugConflict(ctx, categoryUUID, true, err)
 			}
-			tree = append(tree, c)
+
+			d.l.Error("failed to update descendants status", "err", err)
+
+			return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
 		}
 	}
 
-	return tree
+	if err = tx.Commit(); err != nil {
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return d.FindCategoryByUUID(ctx, categoryUUID)
 }
 
-func closeRows(rows *sql.Rows, l *slog.Logger) {
-	if rcErr := rows.Close(); rcErr != nil {
-		l.Warn("error closing rows", "err", rcErr)
+// subtreeNameConflict returns 409 naming a category of the subtree(only the category itself without cascade)
+// and the non deleted sibling it clashes with, used when restoring deleted categories hits uq_category_sibling_name.
+func (d *CategoryRepoDB) subtreeNameConflict(ctx context.Context, categoryUUID string, cascade bool) lib.APIError {
+	var name, siblingUUID, siblingName string
+
+	err := d.db.QueryRowContext(ctx, sqlSelectClashingSibling, categoryUUID, cascade).Scan(&name, &siblingUUID, &siblingName)
+	if err != nil {
+		d.l.Warn("unable to find clashing sibling", "category", categoryUUID, "err", err)
+		return lib.NewError(http.StatusConflict, ErrCodeCategoryNameExists, lib.Args{"name": categoryUUID})
+	}
+
+	d.l.Warn("category name already exists among siblings", "input", name, "sibling", siblingUUID)
+
+	return lib.NewError(http.StatusConflict, ErrCodeCategoryNameExists,
+		lib.Args{"name": name, "sibling": siblingName, "siblingUuid": siblingUUID})
+}
+
+// subtreeSlugConflict returns 409 with the slug of a category of the subtree that a non deleted sibling has too,
+// used when restoring deleted categories hits uq_category_sibling_slug.
+func (d *CategoryRepoDB) subtreeSlugConflict(ctx context.Context, categoryUUID string, cascade bool, err error) lib.APIError {
+	var categorySlug string
+	if scanErr := d.db.QueryRowContext(ctx, sqlSelectClashingSlug, categoryUUID, cascade).Scan(&categorySlug); scanErr != nil {
+		d.l.Warn("unable to find clashing slug", "category", categoryUUID, "err", scanErr)
+		categorySlug = categoryUUID
+	}
+
+	return d.slugConflict(categorySlug, err)
+}
+
+// MoveCategory re-parents a category with its whole subtree in one serializable transaction,
+// empty newParentUUID makes it a root category.
+//   - returns 404 if category or new parent doesn't exist.
+//   - returns 400 if new parent is the category itself or one of its descendants(cycle).
+//   - returns 409 naming the sibling if a new sibling has the same name.
+//   - paths inside the subtree are kept, only paths from old ancestors are replaced, so levels stay consistent.
+//   - moved category is placed after its new siblings, its slug gets a suffix if a new sibling has the same slug.
+func (d *CategoryRepoDB) MoveCategory(ctx context.Context, categoryUUID string, newParentUUID string) (*Category, lib.APIError) {
+	tx, err := d.beginCategoryTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		d.l.Error(lib.ErrTxBegin, "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	defer rollBackOnError(tx, d.l, &err)
+
+	var (
+		categoryID  int
+		currentSlug string
+	)
+
+	if err = tx.QueryRowContext(ctx, sqlSelectCategoryIDAndSlug, categoryUUID).Scan(&categoryID, &currentSlug); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, lib.NewError(http.StatusNotFound, ErrCodeCategoryNotFound, nil).Wrap(err)
+		}
+
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	var (
+		parentID  int
+		newParent sql.NullInt64
+	)
+
+	if newParentUUID != "" {
+		if err = tx.QueryRowContext(ctx, sqlSelectCategoryID, newParentUUID).Scan(&parentID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, lib.NewError(http.StatusNotFound, ErrCodeParentCategoryNotFound, nil).Wrap(err)
+			}
+
+			return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+		}
+
+		var isDescendant bool
+		if err = tx.QueryRowContext(ctx, sqlIsDescendant, categoryID, parentID).Scan(&isDescendant); err != nil {
+			return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+		}
+
+		if isDescendant {
+			d.l.Warn("rejected category move cycle", "category", categoryUUID, "parent", newParentUUID)
+			apiErr := lib.NewError(http.StatusBadRequest, ErrCodeCategoryMoveCycle, nil)
+			err = apiErr // rollback
+
+			return nil, apiErr
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, sqlDetachSubtree, categoryID); err != nil {
+		d.l.Error("failed to detach category subtree", "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	if newParentUUID != "" {
+		if _, err = tx.ExecContext(ctx, sqlAttachSubtree, parentID, categoryID); err != nil {
+			d.l.Error("failed to attach category subtree", "err", err)
+			return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+		}
+
+		newParent = sql.NullInt64{Int64: int64(parentID), Valid: true}
+	}
+
+	var newSlug string
+	if newSlug, err = d.uniqueChildSlug(ctx, tx, newParent, currentSlug, categoryID); err != nil {
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	if _, err = tx.ExecContext(ctx, sqlUpdateCategoryParent, newParent, newSlug, categoryID); err != nil {
+		if isSiblingNameViolation(err) {
+			return nil, d.movedNameConflict(ctx, newParent, categoryID)
+		}
+
+		if isSiblingSlugViolation(err) {
+			return nil, d.slugConflict(newSlug, err)
+		}
+
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	if err = d.appendCategoryPosition(ctx, tx, categoryID); err != nil {
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	if newSlug != currentSlug {
+		if err = d.keepSlugHistory(ctx, tx, categoryID, currentSlug, newSlug); err != nil {
+			return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+		}
+	}
+
+	if apiErr := checkTranslatedSiblingNames(ctx, tx, d.l, categoryID); apiErr != nil {
+		err = apiErr // rollback
+		return nil, apiErr
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, txError(err)
+	}
+
+	return d.FindCategoryByUUID(ctx, categoryUUID)
+}
+
+// movedNameConflict returns 409 naming the sibling under the new parent with the moved category's name.
+func (d *CategoryRepoDB) movedNameConflict(ctx context.Context, newParent sql.NullInt64, categoryID int) lib.APIError {
+	var name string
+	if err := d.db.QueryRowContext(ctx, sqlSelectCategoryName, categoryID).Scan(&name); err != nil {
+		d.l.Warn("unable to find moved category name", "err", err)
+	}
+
+	return d.siblingNameConflict(ctx, newParent, name, categoryID)
+}
+
+// FindAncestors returns ancestors of a category ordered from root, includeSelf appends the category itself(breadcrumbs),
+// returns 404 if category doesn't exist.
+func (d *CategoryRepoDB) FindAncestors(ctx context.Context, categoryUUID string, includeSelf bool) ([]*Category, lib.APIError) {
+	category, apiErr := d.FindCategoryByUUID(ctx, categoryUUID)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	minDistance := 1
+	if includeSelf {
+		minDistance = 0
+	}
+
+	rows, err := d.db.QueryContext(ctx, sqlSelectAncestors, category.CategoryID, category.Level, minDistance)
+	if err != nil {
+		d.l.Error("failed to query category ancestors", "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	defer closeRows(rows, d.l)
+
+	return scanCategoryRows(rows, d.l)
+}
+
+// FindSubtree returns a category with its descendants nested as subcategories, maxDepth limits how many levels
+// below the category are returned, 0 returns the whole subtree.
+// returns 404 if category doesn't exist.
+func (d *CategoryRepoDB) FindSubtree(ctx context.Context, categoryUUID string, maxDepth int) (*Category, lib.APIError) {
+	category, apiErr := d.FindCategoryByUUID(ctx, categoryUUID)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	rows, err := d.db.QueryContext(ctx, sqlSelectSubtree, category.CategoryID, category.Level, maxDepth)
+	if err != nil {
+		d.l.Error("failed to query category subtree", "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	defer closeRows(rows, d.l)
+
+	categories, apiErr := scanCategoryRows(rows, d.l)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	// subtree rows start with the category itself, the only row pointing to its parent.
+	if roots := buildTree(categories, category.ParentCategoryUUID); len(roots) == 1 {
+		category.Subcategories = roots[0].Subcategories
+	}
+
+	return category, nil
+}
+
+// ReorderChildren sets sibling positions of parent's direct subcategories in one serializable transaction,
+// childUUIDs must list every subcategory exactly once, first one gets position 0.
+//   - returns 404 if parent doesn't exist.
+//   - returns 400 if childUUIDs doesn't match current subcategories.
+//   - returns parent with its reordered subcategories.
+func (d *CategoryRepoDB) ReorderChildren(ctx context.Context, parentUUID string, childUUIDs []string) (*Category, lib.APIError) {
+	tx, err := d.beginCategoryTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		d.l.Error(lib.ErrTxBegin, "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	defer rollBackOnError(tx, d.l, &err)
+
+	var parentID int
+	if err = tx.QueryRowContext(ctx, sqlSelectCategoryID, parentUUID).Scan(&parentID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, lib.NewError(http.StatusNotFound, ErrCodeCategoryNotFound, nil).Wrap(err)
+		}
+
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	currentChildren, apiErr := d.selectChildUUIDs(ctx, tx, parentID)
+	if apiErr != nil {
+		err = apiErr // rollback
+		return nil, apiErr
+	}
+
+	if !sameUUIDSet(currentChildren, childUUIDs) {
+		d.l.Warn("children order doesn't match subcategories", "parent", parentUUID)
+		apiErr = lib.NewError(http.StatusBadRequest, ErrCodeCategoryChildrenMismatch, nil)
+		err = apiErr // rollback
+
+		return nil, apiErr
+	}
+
+	for position, childUUID := range childUUIDs {
+		if _, err = tx.ExecContext(ctx, sqlUpdateCategoryPosition, position, childUUID); err != nil {
+			d.l.Error("failed to update category position", "err", err)
+			return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return d.FindSubtree(ctx, parentUUID, 1)
+}
+
+func (d *CategoryRepoDB) selectChildUUIDs(ctx context.Context, tx *sql.Tx, parentID int) ([]string, lib.APIError) {
+	rows, err := tx.QueryContext(ctx, sqlSelectChildUUIDs, parentID)
+	if err != nil {
+		d.l.Error("failed to query subcategories", "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
 	}
+
+	defer closeRows(rows, d.l)
+
+	var childUUIDs []string
+
+	for rows.Next() {
+		var childUUID string
+		if err = rows.Scan(&childUUID); err != nil {
+			d.l.Error(lib.ErrScanningRows, "err", err)
+			return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+		}
+
+		childUUIDs = append(childUUIDs, childUUID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return childUUIDs, nil
+}
+
+// sameUUIDSet reports whether both lists hold the same uuids, each exactly once, uuids compare case-insensitively.
+func sameUUIDSet(current []string, requested []string) bool {
+	if len(current) != len(requested) {
+		return false
+	}
+
+	remaining := make(map[string]bool, len(current))
+	for _, u := range current {
+		remaining[strings.ToLower(u)] = true
+	}
+
+	for _, u := range requested {
+		key := strings.ToLower(u)
+		if !remaining[key] {
+			return false
+		}
+
+		delete(remaining, key)
+	}
+
+	return true
+}
+
+// UpdateCategorySlug sets a new slug in a serializable transaction, the old slug is kept for redirects.
+//   - returns 404 if category doesn't exist.
+//   - returns 409 if a sibling already has the slug.
+func (d *CategoryRepoDB) UpdateCategorySlug(ctx context.Context, categoryUUID string, newSlug string) (*Category, lib.APIError) {
+	tx, err := d.beginCategoryTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		d.l.Error(lib.ErrTxBegin, "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	defer rollBackOnError(tx, d.l, &err)
+
+	var (
+		categoryID  int
+		currentSlug string
+	)
+
+	if err = tx.QueryRowContext(ctx, sqlSelectCategoryIDAndSlug, categoryUUID).Scan(&categoryID, &currentSlug); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, lib.NewError(http.StatusNotFound, ErrCodeCategoryNotFound, nil).Wrap(err)
+		}
+
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	if currentSlug != newSlug {
+		var taken []string
+		if taken, err = d.selectSiblingSlugs(ctx, tx, categoryID, newSlug); err != nil {
+			return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+		}
+
+		if slug.Unique(newSlug, taken) != newSlug {
+			apiErr := d.slugConflict(newSlug, nil)
+			err = apiErr // rollback
+
+			return nil, apiErr
+		}
+
+		if err = d.replaceSlug(ctx, tx, categoryID, currentSlug, newSlug); err != nil {
+			if isSiblingSlugViolation(err) {
+				return nil, d.slugConflict(newSlug, err)
+			}
+
+			return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return d.FindCategoryByUUID(ctx, categoryUUID)
+}
+
+// FindCategoryByPath resolves slugs from a root category down through the hierarchy,
+// a segment matches a child's current slug first, then one of its previous slugs.
+// returns the category and the canonical(current) slug path, they differ from input when an old slug was used.
+// returns 404 if any segment doesn't match.
+func (d *CategoryRepoDB) FindCategoryByPath(ctx context.Context, slugs []string) (*Category, []string, lib.APIError) {
+	var (
+		parentID     sql.NullInt64
+		categoryUUID string
+	)
+
+	canonical := make([]string, 0, len(slugs))
+
+	for _, segment := range slugs {
+		var (
+			categoryID  int64
+			currentSlug string
+		)
+
+		err := d.db.QueryRowContext(ctx, sqlSelectChildBySlug, parentID, segment).Scan(&categoryID, &categoryUUID, &currentSlug)
+		if errors.Is(err, sql.ErrNoRows) {
+			err = d.db.QueryRowContext(ctx, sqlSelectChildByOldSlug, parentID, segment).Scan(&categoryID, &categoryUUID, &currentSlug)
+		}
+
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				d.l.Warn("category path not found", "path", strings.Join(slugs, "/"), "segment", segment)
+				return nil, nil, lib.NewError(http.StatusNotFound, ErrCodeCategoryNotFound, nil).Wrap(err)
+			}
+
+			d.l.Error("failed to resolve category path", "err", err)
+
+			return nil, nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+		}
+
+		parentID = sql.NullInt64{Int64: categoryID, Valid: true}
+		canonical = append(canonical, currentSlug)
+	}
+
+	category, apiErr := d.FindCategoryByUUID(ctx, categoryUUID)
+	if apiErr != nil {
+		return nil, nil, apiErr
+	}
+
+	return category, canonical, nil
 }
@@ -0,0 +1,292 @@
+//go:build integration
+
+package domain
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ashtishad/ecommerce/db/conn"
+	"github.com/ashtishad/ecommerce/lib"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMoveCategoryIntegration runs MoveCategory against a migrated postgres database.
+// run with: go test -tags integration ./product-api/internal/domain/... (DB_* env vars must be set)
+func TestMoveCategoryIntegration(t *testing.T) {
+	if os.Getenv("DB_ADDR") == "" {
+		t.Skip("DB_ADDR is not set, skipping integration test")
+	}
+
+	db := conn.GetDBClient(testLogger)
+	defer db.Close()
+
+	repo := NewCategoryRepoDB(db, testLogger)
+	ctx := context.Background()
+	suffix := fmt.Sprint(time.Now().UnixNano())
+
+	create := func(name, parentUUID string) *Category {
+		t.Helper()
+
+		c := Category{Name: name + suffix, Description: "integration test", Status: CategoryStatusActive}
+
+		var (
+			created *Category
+			apiErr  lib.APIError
+		)
+
+		if parentUUID == "" {
+			created, apiErr = repo.CreateCategory(ctx, c)
+		} else {
+			created, apiErr = repo.CreateSubCategory(ctx, c, parentUUID)
+		}
+
+		require.Nil(t, apiErr)
+
+		return created
+	}
+
+	// a -> b -> c, d
+	a := create("a", "")
+	b := create("b", a.CategoryUUID)
+	c := create("c", b.CategoryUUID)
+	d := create("d", "")
+
+	t.Cleanup(func() {
+		ids := []int{c.CategoryID, b.CategoryID, a.CategoryID, d.CategoryID}
+		for _, id := range ids {
+			_, _ = db.Exec(`DELETE FROM category_relationships WHERE descendant_id = $1 OR ancestor_id = $1`, id)
+		}
+
+		for _, id := range ids {
+			_, _ = db.Exec(`DELETE FROM category_history WHERE category_id = $1`, id)
+			_, _ = db.Exec(`DELETE FROM categories WHERE category_id = $1`, id)
+		}
+	})
+
+	t.Run("Closure rows for every ancestor", func(t *testing.T) {
+		var paths int
+		require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM category_relationships WHERE descendant_id = $1`, c.CategoryID).Scan(&paths))
+		require.Equal(t, 3, paths, "self, parent and grandparent rows")
+	})
+
+	t.Run("Move subtree under another root", func(t *testing.T) {
+		moved, apiErr := repo.MoveCategory(ctx, b.CategoryUUID, d.CategoryUUID)
+		require.Nil(t, apiErr)
+		require.Equal(t, d.CategoryUUID, moved.ParentCategoryUUID.String)
+		require.Equal(t, 1, moved.Level)
+
+		child, apiErr := repo.FindCategoryByUUID(ctx, c.CategoryUUID)
+		require.Nil(t, apiErr)
+		require.Equal(t, 2, child.Level)
+	})
+
+	t.Run("Move under own descendant is rejected", func(t *testing.T) {
+		_, apiErr := repo.MoveCategory(ctx, d.CategoryUUID, c.CategoryUUID)
+		require.ErrorIs(t, apiErr, lib.ErrBadRequest)
+		require.Equal(t, ErrCodeCategoryMoveCycle, apiErr.ErrorCode())
+	})
+
+	t.Run("Move subtree to root", func(t *testing.T) {
+		moved, apiErr := repo.MoveCategory(ctx, b.CategoryUUID, "")
+		require.Nil(t, apiErr)
+		require.False(t, moved.ParentCategoryUUID.Valid)
+		require.Equal(t, 0, moved.Level)
+
+		child, apiErr := repo.FindCategoryByUUID(ctx, c.CategoryUUID)
+		require.Nil(t, apiErr)
+		require.Equal(t, 1, child.Level)
+	})
+}
+
+// TestPGCategoryNotifierIntegration makes sure NOTIFY from one connection reaches the LISTEN connection.
+func TestPGCategoryNotifierIntegration(t *testing.T) {
+	if os.Getenv("DB_ADDR") == "" {
+		t.Skip("DB_ADDR is not set, skipping integration test")
+	}
+
+	db := conn.GetDBClient(testLogger)
+	defer db.Close()
+
+	notifier := NewPGCategoryNotifier(db, conn.GetDSNString(testLogger).String(), testLogger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan struct{}, 10)
+	go notifier.Listen(ctx, func() { changes <- struct{}{} })
+
+	waitChange := func() {
+		t.Helper()
+
+		select {
+		case <-changes:
+		case <-time.After(5 * time.Second):
+			t.Fatal("no category change received")
+		}
+	}
+
+	waitChange() // initial call after LISTEN
+
+	require.NoError(t, notifier.Notify(ctx))
+	waitChange()
+}
+
+// TestSiblingNameUniquenessIntegration makes sure uq_category_sibling_name allows the same name under different
+// parents, rejects it among siblings with the clashing sibling in the error and ignores deleted categories.
+func TestSiblingNameUniquenessIntegration(t *testing.T) {
+	if os.Getenv("DB_ADDR") == "" {
+		t.Skip("DB_ADDR is not set, skipping integration test")
+	}
+
+	db := conn.GetDBClient(testLogger)
+	defer db.Close()
+
+	repo := NewCategoryRepoDB(db, testLogger)
+	ctx := context.Background()
+	suffix := fmt.Sprint(time.Now().UnixNano())
+
+	var created []*Category
+
+	t.Cleanup(func() {
+		for i := len(created) - 1; i >= 0; i-- {
+			_, _ = db.Exec(`DELETE FROM category_relationships WHERE descendant_id = $1 OR ancestor_id = $1`, created[i].CategoryID)
+		}
+
+		for i := len(created) - 1; i >= 0; i-- {
+			_, _ = db.Exec(`DELETE FROM category_slug_history WHERE category_id = $1`, created[i].CategoryID)
+			_, _ = db.Exec(`DELETE FROM category_history WHERE category_id = $1`, created[i].CategoryID)
+			_, _ = db.Exec(`DELETE FROM categories WHERE category_id = $1`, created[i].CategoryID)
+		}
+	})
+
+	create := func(name, parentUUID string) (*Category, lib.APIError) {
+		c := Category{Name: name, Description: "integration test"}
+
+		var (
+			category *Category
+			apiErr   lib.APIError
+		)
+
+		if parentUUID == "" {
+			category, apiErr = repo.CreateCategory(ctx, c)
+		} else {
+			category, apiErr = repo.CreateSubCategory(ctx, c, parentUUID)
+		}
+
+		if apiErr == nil {
+			created = append(created, category)
+		}
+
+		return category, apiErr
+	}
+
+	phone, apiErr := create("Phone "+suffix, "")
+	require.Nil(t, apiErr)
+	sound, apiErr := create("Sound "+suffix, "")
+	require.Nil(t, apiErr)
+
+	phoneAccessories, apiErr := create("Accessories", phone.CategoryUUID)
+	require.Nil(t, apiErr)
+	soundAccessories, apiErr := create("Accessories", sound.CategoryUUID)
+	require.Nil(t, apiErr, "same name under another parent")
+
+	t.Run("Same name among siblings names the sibling", func(t *testing.T) {
+		_, apiErr := create("ACCESSORIES", phone.CategoryUUID)
+		require.ErrorIs(t, apiErr, lib.ErrConflict)
+		require.Contains(t, apiErr.AsMessage(), phoneAccessories.CategoryUUID)
+	})
+
+	t.Run("Move next to a sibling with the same name", func(t *testing.T) {
+		_, apiErr := repo.MoveCategory(ctx, soundAccessories.CategoryUUID, phone.CategoryUUID)
+		require.ErrorIs(t, apiErr, lib.ErrConflict)
+		require.Contains(t, apiErr.AsMessage(), phoneAccessories.CategoryUUID)
+	})
+
+	t.Run("Deleted category doesn't hold its name", func(t *testing.T) {
+		_, apiErr := repo.UpdateCategoryStatus(ctx, phoneAccessories.CategoryUUID, CategoryStatusDeleted, false)
+		require.Nil(t, apiErr)
+
+		replacement, apiErr := create("Accessories", phone.CategoryUUID)
+		require.Nil(t, apiErr)
+
+		_, apiErr = repo.UpdateCategoryStatus(ctx, phoneAccessories.CategoryUUID, CategoryStatusActive, false)
+		require.ErrorIs(t, apiErr, lib.ErrConflict, "restoring clashes with the replacement")
+		require.Contains(t, apiErr.AsMessage(), replacement.CategoryUUID)
+	})
+}
+
+// TestCategoryHistoryIntegration makes sure the history trigger records one entry per change with the actor,
+// and the tree as of a past time has the names of that time.
+func TestCategoryHistoryIntegration(t *testing.T) {
+	if os.Getenv("DB_ADDR") == "" {
+		t.Skip("DB_ADDR is not set, skipping integration test")
+	}
+
+	db := conn.GetDBClient(testLogger)
+	defer db.Close()
+
+	repo := NewCategoryRepoDB(db, testLogger)
+	historyRepo := NewCategoryHistoryRepoDB(db, testLogger)
+	ctx := lib.WithActor(context.Background(), "merchandiser@example.com")
+	suffix := fmt.Sprint(time.Now().UnixNano())
+
+	root, apiErr := repo.CreateCategory(ctx, Category{Name: "History " + suffix})
+	require.Nil(t, apiErr)
+	child, apiErr := repo.CreateSubCategory(ctx, Category{Name: "Child"}, root.CategoryUUID)
+	require.Nil(t, apiErr)
+
+	t.Cleanup(func() {
+		_, _ = db.Exec(`DELETE FROM category_relationships WHERE descendant_id = $1 OR ancestor_id = $1`, child.CategoryID)
+		_, _ = db.Exec(`DELETE FROM category_relationships WHERE descendant_id = $1`, root.CategoryID)
+
+		for _, id := range []int{child.CategoryID, root.CategoryID} {
+			_, _ = db.Exec(`DELETE FROM category_history WHERE category_id = $1`, id)
+			_, _ = db.Exec(`DELETE FROM categories WHERE category_id = $1`, id)
+		}
+	})
+
+	var beforeRename time.Time
+	require.NoError(t, db.QueryRow(`SELECT clock_timestamp()`).Scan(&beforeRename))
+
+	child.Name = "Renamed"
+	_, apiErr = repo.UpdateCategory(ctx, *child)
+	require.Nil(t, apiErr)
+	_, apiErr = repo.MoveCategory(ctx, child.CategoryUUID, "")
+	require.Nil(t, apiErr)
+
+	t.Run("Entries newest first with actor", func(t *testing.T) {
+		entries, apiErr := historyRepo.FindCategoryHistory(ctx, child.CategoryUUID, 0, 10)
+		require.Nil(t, apiErr)
+		require.Len(t, entries, 3, "create with its position is a single entry")
+
+		require.Equal(t, CategoryHistoryActionMove, entries[0].Action)
+		require.Nil(t, entries[0].After.ParentUUID)
+		require.Equal(t, CategoryHistoryActionUpdate, entries[1].Action)
+		require.Equal(t, "Child", entries[1].Before.Name)
+		require.Equal(t, "Renamed", entries[1].After.Name)
+		require.Equal(t, CategoryHistoryActionCreate, entries[2].Action)
+		require.Nil(t, entries[2].Before)
+		require.Equal(t, "merchandiser@example.com", entries[2].Actor)
+	})
+
+	t.Run("Tree as of before the rename", func(t *testing.T) {
+		tree, apiErr := historyRepo.GetCategoryTreeAsOf(ctx, beforeRename)
+		require.Nil(t, apiErr)
+
+		var found *Category
+
+		for _, c := range tree {
+			if c.CategoryUUID == root.CategoryUUID {
+				found = c
+			}
+		}
+
+		require.NotNil(t, found)
+		require.Len(t, found.Subcategories, 1)
+		require.Equal(t, "Child", found.Subcategories[0].Name)
+	})
+}
@@ -0,0 +1,779 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ashtishad/ecommerce/lib"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// helper functions
+func mockCategoryObj() Category {
+	return Category{
+		CategoryID:         6,
+		CategoryUUID:       "e085c298-35b0-4b05-bcc1-a24d4fff4794",
+		ParentCategoryUUID: sql.NullString{String: "bd11d903-7549-42b2-bea6-dd8a7cb8821e", Valid: true},
+		Level:              1,
+		Name:               "Gaming",
+		Slug:               "gaming",
+		Description:        "Gaming phones",
+		Status:             CategoryStatusActive,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	}
+}
+
+func mockCategoryRows(c Category) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"category_id", "category_uuid", "parent_category_uuid", "level", "name", "slug", "description", "status", "created_at", "updated_at"}).
+		AddRow(c.CategoryID, c.CategoryUUID, c.ParentCategoryUUID, c.Level, c.Name, c.Slug, c.Description, c.Status, c.CreatedAt, c.UpdatedAt)
+}
+
+func expectQuery(mock sqlmock.Sqlmock, query string) *sqlmock.ExpectedQuery {
+	return mock.ExpectQuery(regexp.QuoteMeta(strings.TrimSpace(query)))
+}
+
+func expectExec(mock sqlmock.Sqlmock, query string) *sqlmock.ExpectedExec {
+	return mock.ExpectExec(regexp.QuoteMeta(strings.TrimSpace(query)))
+}
+
+// expectCategoryTx expects begin of a category write transaction, which sets the history actor(none in tests).
+func expectCategoryTx(mock sqlmock.Sqlmock) {
+	mock.ExpectBegin()
+	expectExec(mock, sqlSetHistoryActor).WithArgs("").WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+// expectNoTranslatedNameClash expects the check of categoryID's name against its siblings in other locales to pass.
+func expectNoTranslatedNameClash(mock sqlmock.Sqlmock, categoryID int) {
+	expectQuery(mock, sqlSelectTranslatedSiblingNameClash).WithArgs(categoryID).WillReturnRows(sqlmock.NewRows([]string{"locale", "name"}))
+}
+
+// TestFindCategoryByUUID tests the FindCategoryByUUID method of CategoryRepoDB.
+// It covers found, not found and internal server error scenarios.
+func TestFindCategoryByUUID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryRepoDB(db, testLogger)
+
+	t.Run("Find category by uuid successful", func(t *testing.T) {
+		mockCategory := mockCategoryObj()
+		expectQuery(mock, sqlSelectCategoryByUUID).WithArgs(mockCategory.CategoryUUID).WillReturnRows(mockCategoryRows(mockCategory))
+
+		category, apiErr := repo.FindCategoryByUUID(context.Background(), mockCategory.CategoryUUID)
+		require.Nil(t, apiErr)
+		require.Equal(t, mockCategory, *category)
+	})
+
+	t.Run("Category not found", func(t *testing.T) {
+		expectQuery(mock, sqlSelectCategoryByUUID).WithArgs("missing").WillReturnError(sql.ErrNoRows)
+
+		category, apiErr := repo.FindCategoryByUUID(context.Background(), "missing")
+		require.Nil(t, category)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+		require.Equal(t, ErrCodeCategoryNotFound, apiErr.ErrorCode())
+		require.ErrorIs(t, apiErr, sql.ErrNoRows)
+	})
+
+	t.Run("Internal Server Error", func(t *testing.T) {
+		expectQuery(mock, sqlSelectCategoryByUUID).WithArgs("any").WillReturnError(errors.New("db error"))
+
+		category, apiErr := repo.FindCategoryByUUID(context.Background(), "any")
+		require.Nil(t, category)
+		require.Equal(t, http.StatusInternalServerError, apiErr.StatusCode())
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestUpdateCategory tests the UpdateCategory method of CategoryRepoDB.
+// It covers successful rename, name of a sibling, category not found and rollback on database error.
+func TestUpdateCategory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryRepoDB(db, testLogger)
+
+	idAndParentRows := func(c Category) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"category_id", "parent_id"}).AddRow(c.CategoryID, 2)
+	}
+
+	t.Run("Category updated successfully", func(t *testing.T) {
+		update := mockCategoryObj()
+		update.Name = "Gaming Phones"
+
+		expectCategoryTx(mock)
+		expectQuery(mock, sqlSelectCategoryIDAndParent).WithArgs(update.CategoryUUID).WillReturnRows(idAndParentRows(update))
+		expectExec(mock, sqlUpdateCategory).WithArgs(update.Name, update.Description, update.CategoryID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectNoTranslatedNameClash(mock, update.CategoryID)
+		mock.ExpectCommit()
+		expectQuery(mock, sqlSelectCategoryByUUID).WithArgs(update.CategoryUUID).WillReturnRows(mockCategoryRows(update))
+
+		updated, apiErr := repo.UpdateCategory(context.Background(), update)
+		require.Nil(t, apiErr)
+		require.Equal(t, "Gaming Phones", updated.Name)
+	})
+
+	t.Run("Sibling has the name", func(t *testing.T) {
+		update := mockCategoryObj()
+		update.Name = "flip"
+
+		expectCategoryTx(mock)
+		expectQuery(mock, sqlSelectCategoryIDAndParent).WithArgs(update.CategoryUUID).WillReturnRows(idAndParentRows(update))
+		expectExec(mock, sqlUpdateCategory).WithArgs(update.Name, update.Description, update.CategoryID).
+			WillReturnError(&pgconn.PgError{Code: pgUniqueViolation, ConstraintName: uniqueSiblingNameIndex})
+		expectQuery(mock, sqlSelectSiblingByName).WithArgs(sql.NullInt64{Int64: 2, Valid: true}, update.Name, update.CategoryID).
+			WillReturnRows(sqlmock.NewRows([]string{"category_uuid", "name"}).AddRow("7c3f1a52-6b0e-4d8a-9f35-2e1b0c4d5a67", "Flip"))
+		mock.ExpectRollback()
+
+		updated, apiErr := repo.UpdateCategory(context.Background(), update)
+		require.Nil(t, updated)
+		require.ErrorIs(t, apiErr, lib.ErrConflict)
+		require.Equal(t, ErrCodeCategoryNameExists, apiErr.ErrorCode())
+		require.Equal(t, "category name already exists, input: flip, clashing sibling: Flip(7c3f1a52-6b0e-4d8a-9f35-2e1b0c4d5a67)", apiErr.AsMessage())
+	})
+
+	t.Run("Category not found", func(t *testing.T) {
+		update := mockCategoryObj()
+
+		expectCategoryTx(mock)
+		expectQuery(mock, sqlSelectCategoryIDAndParent).WithArgs(update.CategoryUUID).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		updated, apiErr := repo.UpdateCategory(context.Background(), update)
+		require.Nil(t, updated)
+		require.ErrorIs(t, apiErr, lib.ErrNotFound)
+	})
+
+	t.Run("Database error during update", func(t *testing.T) {
+		update := mockCategoryObj()
+
+		expectCategoryTx(mock)
+		expectQuery(mock, sqlSelectCategoryIDAndParent).WithArgs(update.CategoryUUID).WillReturnRows(idAndParentRows(update))
+		expectExec(mock, sqlUpdateCategory).WillReturnError(errors.New("db error"))
+		mock.ExpectRollback()
+
+		updated, apiErr := repo.UpdateCategory(context.Background(), update)
+		require.Nil(t, updated)
+		require.ErrorIs(t, apiErr, lib.ErrInternal)
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestUpdateCategoryStatus tests the UpdateCategoryStatus method of CategoryRepoDB.
+// It covers status change with and without cascading to descendants, and category not found.
+func TestUpdateCategoryStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryRepoDB(db, testLogger)
+
+	t.Run("Deactivate without cascade", func(t *testing.T) {
+		c := mockCategoryObj()
+		c.Status = CategoryStatusInactive
+
+		expectCategoryTx(mock)
+		expectQuery(mock, sqlUpdateCategoryStatus).WithArgs(CategoryStatusInactive, c.CategoryUUID).
+			WillReturnRows(sqlmock.NewRows([]string{"category_id"}).AddRow(c.CategoryID))
+		mock.ExpectCommit()
+		expectQuery(mock, sqlSelectCategoryByUUID).WithArgs(c.CategoryUUID).WillReturnRows(mockCategoryRows(c))
+
+		updated, apiErr := repo.UpdateCategoryStatus(context.Background(), c.CategoryUUID, CategoryStatusInactive, false)
+		require.Nil(t, apiErr)
+		require.Equal(t, CategoryStatusInactive, updated.Status)
+	})
+
+	t.Run("Deactivate with cascade", func(t *testing.T) {
+		c := mockCategoryObj()
+		c.Status = CategoryStatusInactive
+
+		expectCategoryTx(mock)
+		expectQuery(mock, sqlUpdateCategoryStatus).WithArgs(CategoryStatusInactive, c.CategoryUUID).
+			WillReturnRows(sqlmock.NewRows([]string{"category_id"}).AddRow(c.CategoryID))
+		expectExec(mock, sqlUpdateDescendantsStatus).WithArgs(CategoryStatusInactive, c.CategoryID).WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectCommit()
+		expectQuery(mock, sqlSelectCategoryByUUID).WithArgs(c.CategoryUUID).WillReturnRows(mockCategoryRows(c))
+
+		updated, apiErr := repo.UpdateCategoryStatus(context.Background(), c.CategoryUUID, CategoryStatusInactive, true)
+		require.Nil(t, apiErr)
+		require.Equal(t, CategoryStatusInactive, updated.Status)
+	})
+
+	t.Run("Category not found", func(t *testing.T) {
+		expectCategoryTx(mock)
+		expectQuery(mock, sqlUpdateCategoryStatus).WithArgs(CategoryStatusDeleted, "missing").WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		updated, apiErr := repo.UpdateCategoryStatus(context.Background(), "missing", CategoryStatusDeleted, true)
+		require.Nil(t, updated)
+		require.ErrorIs(t, apiErr, lib.ErrNotFound)
+	})
+
+	t.Run("Cascade failure rolls back", func(t *testing.T) {
+		c := mockCategoryObj()
+
+		expectCategoryTx(mock)
+		expectQuery(mock, sqlUpdateCategoryStatus).WithArgs(CategoryStatusDeleted, c.CategoryUUID).
+			WillReturnRows(sqlmock.NewRows([]string{"category_id"}).AddRow(c.CategoryID))
+		expectExec(mock, sqlUpdateDescendantsStatus).WillReturnError(errors.New("db error"))
+		mock.ExpectRollback()
+
+		updated, apiErr := repo.UpdateCategoryStatus(context.Background(), c.CategoryUUID, CategoryStatusDeleted, true)
+		require.Nil(t, updated)
+		require.ErrorIs(t, apiErr, lib.ErrInternal)
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestMoveCategory tests the MoveCategory method of CategoryRepoDB.
+// It covers moving under a new parent, moving to root, cycle rejection and missing category or parent.
+func TestMoveCategory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryRepoDB(db, testLogger)
+
+	const parentUUID = "bd11d903-7549-42b2-bea6-dd8a7cb8821e"
+
+	idRows := func(id int) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"category_id"}).AddRow(id)
+	}
+
+	idAndSlugRows := func(c Category) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"category_id", "slug"}).AddRow(c.CategoryID, c.Slug)
+	}
+
+	t.Run("Move under a new parent replaces subtree ancestor paths", func(t *testing.T) {
+		c := mockCategoryObj()
+		c.Level = 3
+
+		expectCategoryTx(mock)
+		expectQuery(mock, sqlSelectCategoryIDAndSlug).WithArgs(c.CategoryUUID).WillReturnRows(idAndSlugRows(c))
+		expectQuery(mock, sqlSelectCategoryID).WithArgs(parentUUID).WillReturnRows(idRows(4))
+		expectQuery(mock, sqlIsDescendant).WithArgs(c.CategoryID, 4).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		expectExec(mock, sqlDetachSubtree).WithArgs(c.CategoryID).WillReturnResult(sqlmock.NewResult(0, 3))
+		expectExec(mock, sqlAttachSubtree).WithArgs(4, c.CategoryID).WillReturnResult(sqlmock.NewResult(0, 9))
+		expectQuery(mock, sqlSelectChildSlugs).WithArgs(sql.NullInt64{Int64: 4, Valid: true}, c.Slug, c.CategoryID).
+			WillReturnRows(sqlmock.NewRows([]string{"slug"}))
+		expectExec(mock, sqlUpdateCategoryParent).WithArgs(sql.NullInt64{Int64: 4, Valid: true}, c.Slug, c.CategoryID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectExec(mock, sqlAppendCategoryPosition).WithArgs(c.CategoryID).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectNoTranslatedNameClash(mock, c.CategoryID)
+		mock.ExpectCommit()
+		expectQuery(mock, sqlSelectCategoryByUUID).WithArgs(c.CategoryUUID).WillReturnRows(mockCategoryRows(c))
+
+		moved, apiErr := repo.MoveCategory(context.Background(), c.CategoryUUID, parentUUID)
+		require.Nil(t, apiErr)
+		require.Equal(t, 3, moved.Level)
+	})
+
+	t.Run("Move to root", func(t *testing.T) {
+		c := mockCategoryObj()
+		c.Level = 0
+		c.ParentCategoryUUID = sql.NullString{}
+
+		expectCategoryTx(mock)
+		expectQuery(mock, sqlSelectCategoryIDAndSlug).WithArgs(c.CategoryUUID).WillReturnRows(idAndSlugRows(c))
+		expectExec(mock, sqlDetachSubtree).WithArgs(c.CategoryID).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectQuery(mock, sqlSelectChildSlugs).WithArgs(sql.NullInt64{}, c.Slug, c.CategoryID).WillReturnRows(sqlmock.NewRows([]string{"slug"}))
+		expectExec(mock, sqlUpdateCategoryParent).WithArgs(sql.NullInt64{}, c.Slug, c.CategoryID).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectExec(mock, sqlAppendCategoryPosition).WithArgs(c.CategoryID).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectNoTranslatedNameClash(mock, c.CategoryID)
+		mock.ExpectCommit()
+		expectQuery(mock, sqlSelectCategoryByUUID).WithArgs(c.CategoryUUID).WillReturnRows(mockCategoryRows(c))
+
+		moved, apiErr := repo.MoveCategory(context.Background(), c.CategoryUUID, "")
+		require.Nil(t, apiErr)
+		require.False(t, moved.ParentCategoryUUID.Valid)
+	})
+
+	t.Run("New sibling has the name", func(t *testing.T) {
+		c := mockCategoryObj()
+
+		expectCategoryTx(mock)
+		expectQuery(mock, sqlSelectCategoryIDAndSlug).WithArgs(c.CategoryUUID).WillReturnRows(idAndSlugRows(c))
+		expectQuery(mock, sqlSelectCategoryID).WithArgs(parentUUID).WillReturnRows(idRows(4))
+		expectQuery(mock, sqlIsDescendant).WithArgs(c.CategoryID, 4).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		expectExec(mock, sqlDetachSubtree).WithArgs(c.CategoryID).WillReturnResult(sqlmock.NewResult(0, 3))
+		expectExec(mock, sqlAttachSubtree).WithArgs(4, c.CategoryID).WillReturnResult(sqlmock.NewResult(0, 9))
+		expectQuery(mock, sqlSelectChildSlugs).WithArgs(sql.NullInt64{Int64: 4, Valid: true}, c.Slug, c.CategoryID).
+			WillReturnRows(sqlmock.NewRows([]string{"slug"}))
+		expectExec(mock, sqlUpdateCategoryParent).WithArgs(sql.NullInt64{Int64: 4, Valid: true}, c.Slug, c.CategoryID).
+			WillReturnError(&pgconn.PgError{Code: pgUniqueViolation, ConstraintName: uniqueSiblingNameIndex})
+		expectQuery(mock, sqlSelectCategoryName).WithArgs(c.CategoryID).WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow(c.Name))
+		expectQuery(mock, sqlSelectSiblingByName).WithArgs(sql.NullInt64{Int64: 4, Valid: true}, c.Name, c.CategoryID).
+			WillReturnRows(sqlmock.NewRows([]string{"category_uuid", "name"}).AddRow("7c3f1a52-6b0e-4d8a-9f35-2e1b0c4d5a67", "gaming"))
+		mock.ExpectRollback()
+
+		moved, apiErr := repo.MoveCategory(context.Background(), c.CategoryUUID, parentUUID)
+		require.Nil(t, moved)
+		require.ErrorIs(t, apiErr, lib.ErrConflict)
+		require.Equal(t, ErrCodeCategoryNameExists, apiErr.ErrorCode())
+	})
+
+	t.Run("Move under own descendant is rejected", func(t *testing.T) {
+		c := mockCategoryObj()
+
+		expectCategoryTx(mock)
+		expectQuery(mock, sqlSelectCategoryIDAndSlug).WithArgs(c.CategoryUUID).WillReturnRows(idAndSlugRows(c))
+		expectQuery(mock, sqlSelectCategoryID).WithArgs(parentUUID).WillReturnRows(idRows(9))
+		expectQuery(mock, sqlIsDescendant).WithArgs(c.CategoryID, 9).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectRollback()
+
+		moved, apiErr := repo.MoveCategory(context.Background(), c.CategoryUUID, parentUUID)
+		require.Nil(t, moved)
+		require.ErrorIs(t, apiErr, lib.ErrBadRequest)
+		require.Equal(t, ErrCodeCategoryMoveCycle, apiErr.ErrorCode())
+	})
+
+	t.Run("Parent category not found", func(t *testing.T) {
+		c := mockCategoryObj()
+
+		expectCategoryTx(mock)
+		expectQuery(mock, sqlSelectCategoryIDAndSlug).WithArgs(c.CategoryUUID).WillReturnRows(idAndSlugRows(c))
+		expectQuery(mock, sqlSelectCategoryID).WithArgs(parentUUID).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		moved, apiErr := repo.MoveCategory(context.Background(), c.CategoryUUID, parentUUID)
+		require.Nil(t, moved)
+		require.Equal(t, ErrCodeParentCategoryNotFound, apiErr.ErrorCode())
+	})
+
+	t.Run("Category not found", func(t *testing.T) {
+		expectCategoryTx(mock)
+		expectQuery(mock, sqlSelectCategoryIDAndSlug).WithArgs("missing").WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		moved, apiErr := repo.MoveCategory(context.Background(), "missing", parentUUID)
+		require.Nil(t, moved)
+		require.ErrorIs(t, apiErr, lib.ErrNotFound)
+		require.Equal(t, ErrCodeCategoryNotFound, apiErr.ErrorCode())
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestCreateSubCategory tests the CreateSubCategory method of CategoryRepoDB.
+// It makes sure a new sub-category is linked to every ancestor of its parent, not only the parent.
+func TestCreateSubCategory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryRepoDB(db, testLogger)
+	c := mockCategoryObj()
+	parentUUID := c.ParentCategoryUUID.String
+
+	expectCategoryTx(mock)
+	expectQuery(mock, sqlSelectCategoryID).WithArgs(parentUUID).WillReturnRows(sqlmock.NewRows([]string{"category_id"}).AddRow(2))
+	expectQuery(mock, sqlSelectChildSlugs).WithArgs(sql.NullInt64{Int64: 2, Valid: true}, c.Slug, 0).WillReturnRows(sqlmock.NewRows([]string{"slug"}))
+	expectQuery(mock, sqlInsertCategory).WithArgs(c.Name, c.Description, c.Slug, sql.NullInt64{Int64: 2, Valid: true}).
+		WillReturnRows(sqlmock.NewRows([]string{"category_id"}).AddRow(c.CategoryID))
+	expectExec(mock, sqlInsertAncestorPaths).WithArgs(2, c.CategoryID).WillReturnResult(sqlmock.NewResult(0, 2))
+	expectExec(mock, sqlAppendCategoryPosition).WithArgs(c.CategoryID).WillReturnResult(sqlmock.NewResult(0, 1))
+	expectNoTranslatedNameClash(mock, c.CategoryID)
+	mock.ExpectCommit()
+	expectQuery(mock, sqlSelectCategoryByID).WithArgs(c.CategoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"category_id", "category_uuid", "name", "slug", "description", "status", "created_at", "updated_at"}).
+			AddRow(c.CategoryID, c.CategoryUUID, c.Name, c.Slug, c.Description, c.Status, c.CreatedAt, c.UpdatedAt))
+
+	created, apiErr := repo.CreateSubCategory(context.Background(), Category{Name: c.Name, Description: c.Description}, parentUUID)
+	require.Nil(t, apiErr)
+	require.Equal(t, c.CategoryUUID, created.CategoryUUID)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func hierarchyRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"category_uuid", "parent_category_uuid", "level", "name", "slug", "description", "status", "created_at", "updated_at"})
+}
+
+// TestFindAncestors tests the FindAncestors method of CategoryRepoDB.
+// It covers breadcrumbs including the category itself and category not found.
+func TestFindAncestors(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryRepoDB(db, testLogger)
+
+	t.Run("Ancestors from root including self", func(t *testing.T) {
+		c := mockCategoryObj()
+		now := time.Now()
+
+		expectQuery(mock, sqlSelectCategoryByUUID).WithArgs(c.CategoryUUID).WillReturnRows(mockCategoryRows(c))
+		expectQuery(mock, sqlSelectAncestors).WithArgs(c.CategoryID, c.Level, 0).WillReturnRows(hierarchyRows().
+			AddRow(c.ParentCategoryUUID.String, nil, 0, "Phone", "phone", "", CategoryStatusActive, now, now).
+			AddRow(c.CategoryUUID, c.ParentCategoryUUID.String, 1, c.Name, c.Slug, c.Description, c.Status, now, now))
+
+		ancestors, apiErr := repo.FindAncestors(context.Background(), c.CategoryUUID, true)
+		require.Nil(t, apiErr)
+		require.Len(t, ancestors, 2)
+		require.Equal(t, "Phone", ancestors[0].Name)
+		require.False(t, ancestors[0].ParentCategoryUUID.Valid)
+		require.Equal(t, c.Name, ancestors[1].Name)
+	})
+
+	t.Run("Category not found", func(t *testing.T) {
+		expectQuery(mock, sqlSelectCategoryByUUID).WithArgs("missing").WillReturnError(sql.ErrNoRows)
+
+		ancestors, apiErr := repo.FindAncestors(context.Background(), "missing", false)
+		require.Nil(t, ancestors)
+		require.Equal(t, ErrCodeCategoryNotFound, apiErr.ErrorCode())
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestFindSubtree tests the FindSubtree method of CategoryRepoDB.
+// It makes sure descendants are nested under the requested category, not returned as separate roots.
+func TestFindSubtree(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryRepoDB(db, testLogger)
+
+	c := mockCategoryObj()
+	now := time.Now()
+
+	expectQuery(mock, sqlSelectCategoryByUUID).WithArgs(c.CategoryUUID).WillReturnRows(mockCategoryRows(c))
+	expectQuery(mock, sqlSelectSubtree).WithArgs(c.CategoryID, c.Level, 2).WillReturnRows(hierarchyRows().
+		AddRow(c.CategoryUUID, c.ParentCategoryUUID.String, 1, c.Name, c.Slug, c.Description, c.Status, now, now).
+		AddRow("child-1", c.CategoryUUID, 2, "Controllers", "controllers", "", CategoryStatusActive, now, now).
+		AddRow("child-2", c.CategoryUUID, 2, "Cooling", "cooling", "", CategoryStatusActive, now, now).
+		AddRow("grandchild-1", "child-1", 3, "Triggers", "triggers", "", CategoryStatusActive, now, now))
+
+	tree, apiErr := repo.FindSubtree(context.Background(), c.CategoryUUID, 2)
+	require.Nil(t, apiErr)
+	require.Equal(t, c.CategoryUUID, tree.CategoryUUID)
+	require.Len(t, tree.Subcategories, 2)
+	require.Equal(t, "Controllers", tree.Subcategories[0].Name)
+	require.Len(t, tree.Subcategories[0].Subcategories, 1)
+	require.Equal(t, 3, tree.Subcategories[0].Subcategories[0].Level)
+	require.Empty(t, tree.Subcategories[1].Subcategories)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestReorderChildren tests the ReorderChildren method of CategoryRepoDB.
+// It covers successful reorder, order not matching subcategories and parent not found.
+func TestReorderChildren(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryRepoDB(db, testLogger)
+
+	parent := mockCategoryObj()
+	childRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"category_uuid"}).AddRow("child-a").AddRow("child-b").AddRow("child-c")
+	}
+
+	t.Run("Children reordered", func(t *testing.T) {
+		now := time.Now()
+
+		expectCategoryTx(mock)
+		expectQuery(mock, sqlSelectCategoryID).WithArgs(parent.CategoryUUID).WillReturnRows(sqlmock.NewRows([]string{"category_id"}).AddRow(parent.CategoryID))
+		expectQuery(mock, sqlSelectChildUUIDs).WithArgs(parent.CategoryID).WillReturnRows(childRows())
+		expectExec(mock, sqlUpdateCategoryPosition).WithArgs(0, "child-c").WillReturnResult(sqlmock.NewResult(0, 1))
+		expectExec(mock, sqlUpdateCategoryPosition).WithArgs(1, "child-a").WillReturnResult(sqlmock.NewResult(0, 1))
+		expectExec(mock, sqlUpdateCategoryPosition).WithArgs(2, "child-b").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		expectQuery(mock, sqlSelectCategoryByUUID).WithArgs(parent.CategoryUUID).WillReturnRows(mockCategoryRows(parent))
+		expectQuery(mock, sqlSelectSubtree).WithArgs(parent.CategoryID, parent.Level, 1).WillReturnRows(hierarchyRows().
+			AddRow(parent.CategoryUUID, parent.ParentCategoryUUID.String, 1, parent.Name, parent.Slug, parent.Description, parent.Status, now, now).
+			AddRow("child-c", parent.CategoryUUID, 2, "C", "c", "", CategoryStatusActive, now, now).
+			AddRow("child-a", parent.CategoryUUID, 2, "A", "a", "", CategoryStatusActive, now, now).
+			AddRow("child-b", parent.CategoryUUID, 2, "B", "b", "", CategoryStatusActive, now, now))
+
+		reordered, apiErr := repo.ReorderChildren(context.Background(), parent.CategoryUUID, []string{"child-c", "child-a", "child-b"})
+		require.Nil(t, apiErr)
+		require.Len(t, reordered.Subcategories, 3)
+		require.Equal(t, "C", reordered.Subcategories[0].Name)
+		require.Equal(t, "B", reordered.Subcategories[2].Name)
+	})
+
+	t.Run("Order doesn't match subcategories", func(t *testing.T) {
+		orders := [][]string{
+			{"child-a", "child-b"},
+			{"child-a", "child-b", "child-x"},
+			{"child-a", "child-a", "child-b"},
+		}
+
+		for _, order := range orders {
+			expectCategoryTx(mock)
+			expectQuery(mock, sqlSelectCategoryID).WithArgs(parent.CategoryUUID).WillReturnRows(sqlmock.NewRows([]string{"category_id"}).AddRow(parent.CategoryID))
+			expectQuery(mock, sqlSelectChildUUIDs).WithArgs(parent.CategoryID).WillReturnRows(childRows())
+			mock.ExpectRollback()
+
+			reordered, apiErr := repo.ReorderChildren(context.Background(), parent.CategoryUUID, order)
+			require.Nil(t, reordered)
+			require.Equal(t, ErrCodeCategoryChildrenMismatch, apiErr.ErrorCode(), "order %v", order)
+		}
+	})
+
+	t.Run("Parent not found", func(t *testing.T) {
+		expectCategoryTx(mock)
+		expectQuery(mock, sqlSelectCategoryID).WithArgs("missing").WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		reordered, apiErr := repo.ReorderChildren(context.Background(), "missing", nil)
+		require.Nil(t, reordered)
+		require.ErrorIs(t, apiErr, lib.ErrNotFound)
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestMoveCategorySlugCollision makes sure a moved category gets a suffixed slug when a new sibling has its slug,
+// old slug is kept in history for redirects.
+func TestMoveCategorySlugCollision(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryRepoDB(db, testLogger)
+	c := mockCategoryObj()
+
+	expectCategoryTx(mock)
+	expectQuery(mock, sqlSelectCategoryIDAndSlug).WithArgs(c.CategoryUUID).WillReturnRows(sqlmock.NewRows([]string{"category_id", "slug"}).AddRow(c.CategoryID, c.Slug))
+	expectExec(mock, sqlDetachSubtree).WithArgs(c.CategoryID).WillReturnResult(sqlmock.NewResult(0, 1))
+	expectQuery(mock, sqlSelectChildSlugs).WithArgs(sql.NullInt64{}, c.Slug, c.CategoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"slug"}).AddRow("gaming").AddRow("gaming-2"))
+	expectExec(mock, sqlUpdateCategoryParent).WithArgs(sql.NullInt64{}, "gaming-3", c.CategoryID).WillReturnResult(sqlmock.NewResult(0, 1))
+	expectExec(mock, sqlAppendCategoryPosition).WithArgs(c.CategoryID).WillReturnResult(sqlmock.NewResult(0, 1))
+	expectExec(mock, sqlInsertSlugHistory).WithArgs(c.CategoryID, "gaming").WillReturnResult(sqlmock.NewResult(0, 1))
+	expectExec(mock, sqlDeleteSlugHistory).WithArgs(c.CategoryID, "gaming-3").WillReturnResult(sqlmock.NewResult(0, 0))
+	expectNoTranslatedNameClash(mock, c.CategoryID)
+	mock.ExpectCommit()
+	expectQuery(mock, sqlSelectCategoryByUUID).WithArgs(c.CategoryUUID).WillReturnRows(mockCategoryRows(c))
+
+	_, apiErr := repo.MoveCategory(context.Background(), c.CategoryUUID, "")
+	require.Nil(t, apiErr)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestUpdateCategorySlug tests the UpdateCategorySlug method of CategoryRepoDB.
+// It covers slug change with history, slug taken by a sibling and category not found.
+func TestUpdateCategorySlug(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryRepoDB(db, testLogger)
+
+	idAndSlugRows := func(c Category) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"category_id", "slug"}).AddRow(c.CategoryID, c.Slug)
+	}
+
+	t.Run("Slug updated and old one kept", func(t *testing.T) {
+		c := mockCategoryObj()
+		updated := c
+		updated.Slug = "gaming-phones"
+
+		expectCategoryTx(mock)
+		expectQuery(mock, sqlSelectCategoryIDAndSlug).WithArgs(c.CategoryUUID).WillReturnRows(idAndSlugRows(c))
+		expectQuery(mock, sqlSelectSiblingSlugs).WithArgs(c.CategoryID, updated.Slug).WillReturnRows(sqlmock.NewRows([]string{"slug"}).AddRow("gaming-phones-2"))
+		expectExec(mock, sqlUpdateCategorySlug).WithArgs(updated.Slug, c.CategoryID).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectExec(mock, sqlInsertSlugHistory).WithArgs(c.CategoryID, c.Slug).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectExec(mock, sqlDeleteSlugHistory).WithArgs(c.CategoryID, updated.Slug).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+		expectQuery(mock, sqlSelectCategoryByUUID).WithArgs(c.CategoryUUID).WillReturnRows(mockCategoryRows(updated))
+
+		category, apiErr := repo.UpdateCategorySlug(context.Background(), c.CategoryUUID, updated.Slug)
+		require.Nil(t, apiErr)
+		require.Equal(t, "gaming-phones", category.Slug)
+	})
+
+	t.Run("Slug taken by a sibling", func(t *testing.T) {
+		c := mockCategoryObj()
+
+		expectCategoryTx(mock)
+		expectQuery(mock, sqlSelectCategoryIDAndSlug).WithArgs(c.CategoryUUID).WillReturnRows(idAndSlugRows(c))
+		expectQuery(mock, sqlSelectSiblingSlugs).WithArgs(c.CategoryID, "flip").WillReturnRows(sqlmock.NewRows([]string{"slug"}).AddRow("flip"))
+		mock.ExpectRollback()
+
+		category, apiErr := repo.UpdateCategorySlug(context.Background(), c.CategoryUUID, "flip")
+		require.Nil(t, category)
+		require.ErrorIs(t, apiErr, lib.ErrConflict)
+		require.Equal(t, ErrCodeCategorySlugExists, apiErr.ErrorCode())
+	})
+
+	t.Run("Category not found", func(t *testing.T) {
+		expectCategoryTx(mock)
+		expectQuery(mock, sqlSelectCategoryIDAndSlug).WithArgs("missing").WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		category, apiErr := repo.UpdateCategorySlug(context.Background(), "missing", "flip")
+		require.Nil(t, category)
+		require.ErrorIs(t, apiErr, lib.ErrNotFound)
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestFindCategoryByPath tests the FindCategoryByPath method of CategoryRepoDB.
+// It covers current slugs, an old slug resolving to the canonical path and an unknown segment.
+func TestFindCategoryByPath(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryRepoDB(db, testLogger)
+
+	c := mockCategoryObj()
+	childRows := func(id int, uuid, slug string) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"category_id", "category_uuid", "slug"}).AddRow(id, uuid, slug)
+	}
+
+	t.Run("Current slugs", func(t *testing.T) {
+		expectQuery(mock, sqlSelectChildBySlug).WithArgs(sql.NullInt64{}, "phone").WillReturnRows(childRows(1, c.ParentCategoryUUID.String, "phone"))
+		expectQuery(mock, sqlSelectChildBySlug).WithArgs(sql.NullInt64{Int64: 1, Valid: true}, "gaming").WillReturnRows(childRows(c.CategoryID, c.CategoryUUID, "gaming"))
+		expectQuery(mock, sqlSelectCategoryByUUID).WithArgs(c.CategoryUUID).WillReturnRows(mockCategoryRows(c))
+
+		category, canonical, apiErr := repo.FindCategoryByPath(context.Background(), []string{"phone", "gaming"})
+		require.Nil(t, apiErr)
+		require.Equal(t, c.CategoryUUID, category.CategoryUUID)
+		require.Equal(t, []string{"phone", "gaming"}, canonical)
+	})
+
+	t.Run("Old slug resolves to canonical path", func(t *testing.T) {
+		expectQuery(mock, sqlSelectChildBySlug).WithArgs(sql.NullInt64{}, "phones").WillReturnError(sql.ErrNoRows)
+		expectQuery(mock, sqlSelectChildByOldSlug).WithArgs(sql.NullInt64{}, "phones").WillReturnRows(childRows(1, c.ParentCategoryUUID.String, "phone"))
+		expectQuery(mock, sqlSelectChildBySlug).WithArgs(sql.NullInt64{Int64: 1, Valid: true}, "gaming").WillReturnRows(childRows(c.CategoryID, c.CategoryUUID, "gaming"))
+		expectQuery(mock, sqlSelectCategoryByUUID).WithArgs(c.CategoryUUID).WillReturnRows(mockCategoryRows(c))
+
+		_, canonical, apiErr := repo.FindCategoryByPath(context.Background(), []string{"phones", "gaming"})
+		require.Nil(t, apiErr)
+		require.Equal(t, []string{"phone", "gaming"}, canonical)
+	})
+
+	t.Run("Unknown segment", func(t *testing.T) {
+		expectQuery(mock, sqlSelectChildBySlug).WithArgs(sql.NullInt64{}, "phone").WillReturnRows(childRows(1, c.ParentCategoryUUID.String, "phone"))
+		expectQuery(mock, sqlSelectChildBySlug).WithArgs(sql.NullInt64{Int64: 1, Valid: true}, "tablet").WillReturnError(sql.ErrNoRows)
+		expectQuery(mock, sqlSelectChildByOldSlug).WithArgs(sql.NullInt64{Int64: 1, Valid: true}, "tablet").WillReturnError(sql.ErrNoRows)
+
+		category, _, apiErr := repo.FindCategoryByPath(context.Background(), []string{"phone", "tablet"})
+		require.Nil(t, category)
+		require.Equal(t, ErrCodeCategoryNotFound, apiErr.ErrorCode())
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestSiblingSlugConflict makes sure unique violations of uq_category_sibling_slug from concurrent writes become 409
+// with the slug, for a new subcategory and for restoring a deleted category.
+func TestSiblingSlugConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryRepoDB(db, testLogger)
+	c := mockCategoryObj()
+	parentUUID := c.ParentCategoryUUID.String
+
+	violation := &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: uniqueSiblingSlugIndex}
+
+	t.Run("Sibling took the slug after it was checked", func(t *testing.T) {
+		expectCategoryTx(mock)
+		expectQuery(mock, sqlSelectCategoryID).WithArgs(parentUUID).WillReturnRows(sqlmock.NewRows([]string{"category_id"}).AddRow(2))
+		expectQuery(mock, sqlSelectChildSlugs).WithArgs(sql.NullInt64{Int64: 2, Valid: true}, c.Slug, 0).WillReturnRows(sqlmock.NewRows([]string{"slug"}))
+		expectQuery(mock, sqlInsertCategory).WithArgs(c.Name, c.Description, c.Slug, sql.NullInt64{Int64: 2, Valid: true}).WillReturnError(violation)
+		mock.ExpectRollback()
+
+		created, apiErr := repo.CreateSubCategory(context.Background(), Category{Name: c.Name, Description: c.Description}, parentUUID)
+		require.Nil(t, created)
+		require.ErrorIs(t, apiErr, lib.ErrConflict)
+		require.Equal(t, ErrCodeCategorySlugExists, apiErr.ErrorCode())
+	})
+
+	t.Run("Restoring a deleted category", func(t *testing.T) {
+		expectCategoryTx(mock)
+		expectQuery(mock, sqlUpdateCategoryStatus).WithArgs(CategoryStatusActive, c.CategoryUUID).WillReturnError(violation)
+		expectQuery(mock, sqlSelectClashingSlug).WithArgs(c.CategoryUUID, false).WillReturnRows(sqlmock.NewRows([]string{"slug"}).AddRow(c.Slug))
+		mock.ExpectRollback()
+
+		updated, apiErr := repo.UpdateCategoryStatus(context.Background(), c.CategoryUUID, CategoryStatusActive, false)
+		require.Nil(t, updated)
+		require.Equal(t, ErrCodeCategorySlugExists, apiErr.ErrorCode())
+		require.Contains(t, apiErr.AsMessage(), c.Slug)
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestSiblingNameConflict makes sure unique violations of uq_category_sibling_name become 409 naming the sibling,
+// for a new root category and for restoring a deleted category.
+func TestSiblingNameConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryRepoDB(db, testLogger)
+	c := mockCategoryObj()
+
+	const siblingUUID = "7c3f1a52-6b0e-4d8a-9f35-2e1b0c4d5a67"
+
+	violation := &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: uniqueSiblingNameIndex}
+
+	t.Run("Root category with the name of another root", func(t *testing.T) {
+		expectCategoryTx(mock)
+		expectQuery(mock, sqlSelectChildSlugs).WithArgs(sql.NullInt64{}, "phone", 0).WillReturnRows(sqlmock.NewRows([]string{"slug"}))
+		expectQuery(mock, sqlInsertCategory).WithArgs("phone", "", "phone", sql.NullInt64{}).WillReturnError(violation)
+		expectQuery(mock, sqlSelectSiblingByName).WithArgs(sql.NullInt64{}, "phone", 0).
+			WillReturnRows(sqlmock.NewRows([]string{"category_uuid", "name"}).AddRow(siblingUUID, "Phone"))
+		mock.ExpectRollback()
+
+		created, apiErr := repo.CreateCategory(context.Background(), Category{Name: "phone"})
+		require.Nil(t, created)
+		require.ErrorIs(t, apiErr, lib.ErrConflict)
+		require.Contains(t, apiErr.AsMessage(), siblingUUID)
+	})
+
+	t.Run("Restoring a deleted category", func(t *testing.T) {
+		expectCategoryTx(mock)
+		expectQuery(mock, sqlUpdateCategoryStatus).WithArgs(CategoryStatusActive, c.CategoryUUID).WillReturnError(violation)
+		expectQuery(mock, sqlSelectClashingSibling).WithArgs(c.CategoryUUID, false).
+			WillReturnRows(sqlmock.NewRows([]string{"name", "category_uuid", "name"}).AddRow(c.Name, siblingUUID, c.Name))
+		mock.ExpectRollback()
+
+		updated, apiErr := repo.UpdateCategoryStatus(context.Background(), c.CategoryUUID, CategoryStatusActive, false)
+		require.Nil(t, updated)
+		require.Equal(t, ErrCodeCategoryNameExists, apiErr.ErrorCode())
+		require.Contains(t, apiErr.AsMessage(), siblingUUID)
+	})
+
+	t.Run("Other unique violations are internal errors", func(t *testing.T) {
+		expectCategoryTx(mock)
+		expectQuery(mock, sqlSelectChildSlugs).WithArgs(sql.NullInt64{}, "phone", 0).WillReturnRows(sqlmock.NewRows([]string{"slug"}))
+		expectQuery(mock, sqlInsertCategory).WithArgs("phone", "", "phone", sql.NullInt64{}).
+			WillReturnError(&pgconn.PgError{Code: pgUniqueViolation, ConstraintName: "categories_pkey"})
+		mock.ExpectRollback()
+
+		_, apiErr := repo.CreateCategory(context.Background(), Category{Name: "phone"})
+		require.ErrorIs(t, apiErr, lib.ErrInternal)
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
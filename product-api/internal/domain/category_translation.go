@@ -0,0 +1,16 @@
+package domain
+
+// CategoryTranslation is the name and description of a category in a non default locale, e.g. "bn".
+type CategoryTranslation struct {
+	Locale      string `json:"locale"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (t *CategoryTranslation) ToCategoryTranslationDTO() *CategoryTranslationDTO {
+	return &CategoryTranslationDTO{
+		Locale:      t.Locale,
+		Name:        t.Name,
+		Description: t.Description,
+	}
+}
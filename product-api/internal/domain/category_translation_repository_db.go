@@ -0,0 +1,151 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/ashtishad/ecommerce/lib"
+)
+
+type CategoryTranslationRepoDB struct {
+	db *sql.DB
+	l  *slog.Logger
+}
+
+func NewCategoryTranslationRepoDB(db *sql.DB, l *slog.Logger) *CategoryTranslationRepoDB {
+	return &CategoryTranslationRepoDB{db, l}
+}
+
+// FindCategoryTranslations returns all translations of a category ordered by locale,
+// default locale values are the category's own name and description, they aren't listed.
+// returns 404 if category doesn't exist.
+func (d *CategoryTranslationRepoDB) FindCategoryTranslations(ctx context.Context, categoryUUID string) ([]CategoryTranslation, lib.APIError) {
+	categoryID, apiErr := selectCategoryID(ctx, d.db, d.l, categoryUUID)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	rows, err := d.db.QueryContext(ctx, sqlSelectCategoryTranslations, categoryID)
+	if err != nil {
+		d.l.Error("failed to query category translations", "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	defer closeRows(rows, d.l)
+
+	translations := make([]CategoryTranslation, 0)
+
+	for rows.Next() {
+		var t CategoryTranslation
+		if err = rows.Scan(&t.Locale, &t.Name, &t.Description); err != nil {
+			d.l.Error("failed to scan rows:", "err", err)
+			return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+		}
+
+		translations = append(translations, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		d.l.Error("unexpected error on scanning category translation rows", "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return translations, nil
+}
+
+// SaveCategoryTranslation creates or replaces a category translation in a serializable transaction,
+// name must be unique among siblings in the same locale, siblings without a translation count with their default name.
+//   - returns 404 if category doesn't exist.
+//   - returns 409 if a sibling has the same name in the locale, or a concurrent write conflicts with the transaction.
+func (d *CategoryTranslationRepoDB) SaveCategoryTranslation(ctx context.Context, categoryUUID string, translation CategoryTranslation) (*CategoryTranslation, lib.APIError) {
+	tx, err := d.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		d.l.Error(lib.ErrTxBegin, "err", err)
+		return nil, txError(err)
+	}
+
+	defer rollBackOnError(tx, d.l, &err)
+
+	var categoryID int
+	if err = tx.QueryRowContext(ctx, sqlSelectCategoryID, categoryUUID).Scan(&categoryID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, lib.NewError(http.StatusNotFound, ErrCodeCategoryNotFound, nil).Wrap(err)
+		}
+
+		return nil, txError(err)
+	}
+
+	var siblingName string
+
+	err = tx.QueryRowContext(ctx, sqlSelectSiblingNameInLocale, categoryID, translation.Locale, translation.Name).Scan(&siblingName)
+	switch {
+	case err == nil:
+		d.l.Warn("category translation already exists among siblings", "locale", translation.Locale, "name", translation.Name)
+		apiErr := lib.NewError(http.StatusConflict, ErrCodeCategoryTranslationExists, lib.Args{"name": siblingName, "locale": translation.Locale})
+		err = apiErr // rollback
+
+		return nil, apiErr
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, txError(err)
+	}
+
+	if _, err = tx.ExecContext(ctx, sqlUpsertCategoryTranslation, categoryID, translation.Locale, translation.Name, translation.Description); err != nil {
+		d.l.Error("failed to save category translation", "locale", translation.Locale, "err", err)
+		return nil, txError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, txError(err)
+	}
+
+	return &translation, nil
+}
+
+// DeleteCategoryTranslation removes a category translation, the locale falls back to default locale values afterwards.
+// returns 404 if category or the translation doesn't exist.
+func (d *CategoryTranslationRepoDB) DeleteCategoryTranslation(ctx context.Context, categoryUUID string, locale string) lib.APIError {
+	categoryID, apiErr := selectCategoryID(ctx, d.db, d.l, categoryUUID)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	result, err := d.db.ExecContext(ctx, sqlDeleteCategoryTranslation, categoryID, locale)
+	if err != nil {
+		d.l.Error("failed to delete category translation", "locale", locale, "err", err)
+		return lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	if deleted == 0 {
+		d.l.Warn("category translation not found", "category", categoryUUID, "locale", locale)
+		return lib.NewError(http.StatusNotFound, ErrCodeCategoryTranslationNotFound, lib.Args{"locale": locale})
+	}
+
+	return nil
+}
+
+// checkTranslatedSiblingNames returns 409 if category categoryID is shown with the same name as a non deleted sibling
+// in a locale, called in the transaction of a write that names or re-parents the category, after the write.
+func checkTranslatedSiblingNames(ctx context.Context, tx *sql.Tx, l *slog.Logger, categoryID int) lib.APIError {
+	var locale, name string
+
+	err := tx.QueryRowContext(ctx, sqlSelectTranslatedSiblingNameClash, categoryID).Scan(&locale, &name)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	case err != nil:
+		l.Error("failed to check sibling names in other locales", "err", err)
+		return txError(err)
+	}
+
+	l.Warn("category name already exists among siblings in locale", "locale", locale, "name", name)
+
+	return lib.NewError(http.StatusConflict, ErrCodeCategoryTranslationExists, lib.Args{"name": name, "locale": locale})
+}
@@ -0,0 +1,172 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ashtishad/ecommerce/lib"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetAllCategoriesWithHierarchyLocale makes sure the tree is queried in the requested locale,
+// names in rows are already translated or fallen back by the query.
+func TestGetAllCategoriesWithHierarchyLocale(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryRepoDB(db, testLogger)
+	now := time.Now()
+
+	expectQuery(mock, sqlGetAllCategoriesWithHierarchy).WithArgs("bn").WillReturnRows(hierarchyRows().
+		AddRow("phone", nil, 0, "ফোন", "phone", "", CategoryStatusActive, now, now).
+		AddRow("gaming", "phone", 1, "Gaming", "gaming", "", CategoryStatusActive, now, now))
+
+	tree, apiErr := repo.GetAllCategoriesWithHierarchy(context.Background(), "bn")
+	require.Nil(t, apiErr)
+	require.Len(t, tree, 1)
+	require.Equal(t, "ফোন", tree[0].Name)
+	require.Equal(t, "Gaming", tree[0].Subcategories[0].Name)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestSaveCategoryTranslation tests the SaveCategoryTranslation method of CategoryTranslationRepoDB.
+// It covers saved translation, a sibling with the same name in the locale, a concurrent save and category not found.
+func TestSaveCategoryTranslation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryTranslationRepoDB(db, testLogger)
+	c := mockCategoryObj()
+	translation := CategoryTranslation{Locale: "bn", Name: "গেমিং", Description: "গেমিং ফোন"}
+
+	idRows := func() *sqlmock.Rows { return sqlmock.NewRows([]string{"category_id"}).AddRow(c.CategoryID) }
+
+	t.Run("Translation saved", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectQuery(mock, sqlSelectCategoryID).WithArgs(c.CategoryUUID).WillReturnRows(idRows())
+		expectQuery(mock, sqlSelectSiblingNameInLocale).WithArgs(c.CategoryID, "bn", translation.Name).WillReturnError(sql.ErrNoRows)
+		expectExec(mock, sqlUpsertCategoryTranslation).WithArgs(c.CategoryID, "bn", translation.Name, translation.Description).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		saved, apiErr := repo.SaveCategoryTranslation(context.Background(), c.CategoryUUID, translation)
+		require.Nil(t, apiErr)
+		require.Equal(t, translation, *saved)
+	})
+
+	t.Run("Sibling has the name in locale", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectQuery(mock, sqlSelectCategoryID).WithArgs(c.CategoryUUID).WillReturnRows(idRows())
+		expectQuery(mock, sqlSelectSiblingNameInLocale).WithArgs(c.CategoryID, "bn", translation.Name).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("গেমিং"))
+		mock.ExpectRollback()
+
+		saved, apiErr := repo.SaveCategoryTranslation(context.Background(), c.CategoryUUID, translation)
+		require.Nil(t, saved)
+		require.ErrorIs(t, apiErr, lib.ErrConflict)
+		require.Equal(t, ErrCodeCategoryTranslationExists, apiErr.ErrorCode())
+	})
+
+	t.Run("Concurrent save is a conflict", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectQuery(mock, sqlSelectCategoryID).WithArgs(c.CategoryUUID).WillReturnRows(idRows())
+		expectQuery(mock, sqlSelectSiblingNameInLocale).WithArgs(c.CategoryID, "bn", translation.Name).WillReturnError(sql.ErrNoRows)
+		expectExec(mock, sqlUpsertCategoryTranslation).WithArgs(c.CategoryID, "bn", translation.Name, translation.Description).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit().WillReturnError(&pgconn.PgError{Code: pgSerializationFailure})
+
+		saved, apiErr := repo.SaveCategoryTranslation(context.Background(), c.CategoryUUID, translation)
+		require.Nil(t, saved)
+		require.ErrorIs(t, apiErr, lib.ErrConflict)
+		require.Equal(t, ErrCodeCategoryWriteConflict, apiErr.ErrorCode())
+	})
+
+	t.Run("Category not found", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectQuery(mock, sqlSelectCategoryID).WithArgs("missing").WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		saved, apiErr := repo.SaveCategoryTranslation(context.Background(), "missing", translation)
+		require.Nil(t, saved)
+		require.Equal(t, ErrCodeCategoryNotFound, apiErr.ErrorCode())
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTranslatedSiblingNames makes sure category writes check the name against siblings in every translated locale,
+// a rename to the bn name of a sibling without its own bn translation is a conflict.
+func TestTranslatedSiblingNames(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryRepoDB(db, testLogger)
+	update := mockCategoryObj()
+	update.Name = "গেমিং"
+
+	expectCategoryTx(mock)
+	expectQuery(mock, sqlSelectCategoryIDAndParent).WithArgs(update.CategoryUUID).
+		WillReturnRows(sqlmock.NewRows([]string{"category_id", "parent_id"}).AddRow(update.CategoryID, 2))
+	expectExec(mock, sqlUpdateCategory).WithArgs(update.Name, update.Description, update.CategoryID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectQuery(mock, sqlSelectTranslatedSiblingNameClash).WithArgs(update.CategoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"locale", "name"}).AddRow("bn", update.Name))
+	mock.ExpectRollback()
+
+	updated, apiErr := repo.UpdateCategory(context.Background(), update)
+	require.Nil(t, updated)
+	require.ErrorIs(t, apiErr, lib.ErrConflict)
+	require.Equal(t, ErrCodeCategoryTranslationExists, apiErr.ErrorCode())
+	require.Equal(t, "a sibling category already has this name in locale bn, input: গেমিং", apiErr.AsMessage())
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestFindAndDeleteCategoryTranslations covers listing translations and deleting an existing and a missing one.
+func TestFindAndDeleteCategoryTranslations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCategoryTranslationRepoDB(db, testLogger)
+	c := mockCategoryObj()
+
+	idRows := func() *sqlmock.Rows { return sqlmock.NewRows([]string{"category_id"}).AddRow(c.CategoryID) }
+
+	t.Run("List translations", func(t *testing.T) {
+		expectQuery(mock, sqlSelectCategoryID).WithArgs(c.CategoryUUID).WillReturnRows(idRows())
+		expectQuery(mock, sqlSelectCategoryTranslations).WithArgs(c.CategoryID).
+			WillReturnRows(sqlmock.NewRows([]string{"locale", "name", "description"}).AddRow("bn", "গেমিং", ""))
+
+		translations, apiErr := repo.FindCategoryTranslations(context.Background(), c.CategoryUUID)
+		require.Nil(t, apiErr)
+		require.Equal(t, []CategoryTranslation{{Locale: "bn", Name: "গেমিং"}}, translations)
+	})
+
+	t.Run("Delete translation", func(t *testing.T) {
+		expectQuery(mock, sqlSelectCategoryID).WithArgs(c.CategoryUUID).WillReturnRows(idRows())
+		expectExec(mock, sqlDeleteCategoryTranslation).WithArgs(c.CategoryID, "bn").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		require.Nil(t, repo.DeleteCategoryTranslation(context.Background(), c.CategoryUUID, "bn"))
+	})
+
+	t.Run("Delete missing translation", func(t *testing.T) {
+		expectQuery(mock, sqlSelectCategoryID).WithArgs(c.CategoryUUID).WillReturnRows(idRows())
+		expectExec(mock, sqlDeleteCategoryTranslation).WithArgs(c.CategoryID, "bn").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		apiErr := repo.DeleteCategoryTranslation(context.Background(), c.CategoryUUID, "bn")
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+		require.Equal(t, ErrCodeCategoryTranslationNotFound, apiErr.ErrorCode())
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,34 @@
+package domain
+
+import "database/sql"
+
+// buildTree assembles flat category rows into a forest in a single pass, children are linked by pointer
+// and keep the order of the input rows.
+// parentUUID selects the roots: invalid for top level categories, or the parent uuid of a subtree root.
+// rows whose parent is neither a root parent nor present in the input are dropped.
+func buildTree(categories []*Category, parentUUID sql.NullString) []*Category {
+	nodes := make(map[string]*Category, len(categories))
+	for _, c := range categories {
+		c.Subcategories = nil
+		nodes[c.CategoryUUID] = c
+	}
+
+	var tree []*Category
+
+	for _, c := range categories {
+		if c.ParentCategoryUUID.Valid == parentUUID.Valid && c.ParentCategoryUUID.String == parentUUID.String {
+			tree = append(tree, c)
+			continue
+		}
+
+		if !c.ParentCategoryUUID.Valid {
+			continue
+		}
+
+		if parent, ok := nodes[c.ParentCategoryUUID.String]; ok {
+			parent.Subcategories = append(parent.Subcategories, c)
+		}
+	}
+
+	return tree
+}
@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func nullUUID(uuid string) sql.NullString {
+	return sql.NullString{String: uuid, Valid: uuid != ""}
+}
+
+// flatCategories returns n categories ordered by level like sqlGetAllCategoriesWithHierarchy rows,
+// every category has up to fanout children.
+func flatCategories(n, fanout int) []*Category {
+	categories := make([]*Category, n)
+	for i := range categories {
+		parent := ""
+		level := 0
+
+		if i >= fanout {
+			p := categories[i/fanout-1]
+			parent = p.CategoryUUID
+			level = p.Level + 1
+		}
+
+		categories[i] = &Category{CategoryID: i + 1, CategoryUUID: fmt.Sprintf("uuid-%d", i), ParentCategoryUUID: nullUUID(parent), Level: level}
+	}
+
+	return categories
+}
+
+func countNodes(tree []*Category) int {
+	n := 0
+	for _, c := range tree {
+		n += 1 + countNodes(c.Subcategories)
+	}
+
+	return n
+}
+
+func TestBuildTree(t *testing.T) {
+	t.Run("Nests children under parents keeping row order", func(t *testing.T) {
+		categories := []*Category{
+			{CategoryUUID: "phone", Name: "Phone"},
+			{CategoryUUID: "sound", Name: "Sound"},
+			{CategoryUUID: "smart", Name: "Smartphone", ParentCategoryUUID: nullUUID("phone")},
+			{CategoryUUID: "flip", Name: "Flip", ParentCategoryUUID: nullUUID("phone")},
+			{CategoryUUID: "gaming", Name: "Gaming", ParentCategoryUUID: nullUUID("smart")},
+		}
+
+		tree := buildTree(categories, sql.NullString{})
+		require.Len(t, tree, 2)
+		require.Equal(t, "Phone", tree[0].Name)
+		require.Len(t, tree[0].Subcategories, 2)
+		require.Equal(t, "Smartphone", tree[0].Subcategories[0].Name)
+		require.Equal(t, "Flip", tree[0].Subcategories[1].Name)
+		require.Equal(t, "Gaming", tree[0].Subcategories[0].Subcategories[0].Name)
+		require.Empty(t, tree[1].Subcategories)
+	})
+
+	t.Run("Subtree roots selected by parent uuid", func(t *testing.T) {
+		categories := []*Category{
+			{CategoryUUID: "smart", ParentCategoryUUID: nullUUID("phone")},
+			{CategoryUUID: "gaming", ParentCategoryUUID: nullUUID("smart")},
+		}
+
+		tree := buildTree(categories, nullUUID("phone"))
+		require.Len(t, tree, 1)
+		require.Len(t, tree[0].Subcategories, 1)
+	})
+
+	t.Run("Orphans are dropped", func(t *testing.T) {
+		categories := []*Category{
+			{CategoryUUID: "phone"},
+			{CategoryUUID: "lost", ParentCategoryUUID: nullUUID("missing")},
+		}
+
+		require.Equal(t, 1, countNodes(buildTree(categories, sql.NullString{})))
+	})
+
+	t.Run("Every node assembled once", func(t *testing.T) {
+		categories := flatCategories(10_000, 10)
+
+		tree := buildTree(categories, sql.NullString{})
+		require.Len(t, tree, 10)
+		require.Equal(t, len(categories), countNodes(tree))
+	})
+}
+
+// recursiveBuildTree is the previous implementation, it rescans all categories for every node
+// and copies children, kept only to compare benchmarks.
+func recursiveBuildTree(categories []*Category, parentUUID sql.NullString) []*Category {
+	var tree []*Category
+
+	for _, c := range categories {
+		if c.ParentCategoryUUID.Valid == parentUUID.Valid && c.ParentCategoryUUID.String == parentUUID.String {
+			children := recursiveBuildTree(categories, sql.NullString{String: c.CategoryUUID, Valid: true})
+			if len(children) > 0 {
+				c.Subcategories = make([]*Category, len(children))
+				copy(c.Subcategories, children)
+			}
+
+			tree = append(tree, c)
+		}
+	}
+
+	return tree
+}
+
+// BenchmarkBuildTree compares single pass assembly with the previous recursive one,
+// recursive assembly is quadratic so it only runs at 10k, at 100k it takes minutes.
+//
+//	go test -run ^$ -bench BuildTree -benchmem ./product-api/internal/domain/
+func BenchmarkBuildTree(b *testing.B) {
+	for _, n := range []int{10_000, 100_000} {
+		categories := flatCategories(n, 10)
+
+		b.Run(fmt.Sprintf("single_pass_%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				buildTree(categories, sql.NullString{})
+			}
+		})
+
+		if n > 10_000 {
+			continue
+		}
+
+		b.Run(fmt.Sprintf("recursive_%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				recursiveBuildTree(categories, sql.NullString{})
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package domain
+
+// stable machine-readable error codes, messages are in lib/locales catalog and documented in docs/errors.md
+const (
+	ErrCodeCategoryNameExists           = "category_name_exists"
+	ErrCodeParentCategoryIDRequired     = "parent_category_id_required"
+	ErrCodeParentCategoryNotFound       = "parent_category_not_found"
+	ErrCodeCategoryNotFound             = "category_not_found"
+	ErrCodeCategoryMoveCycle            = "category_move_cycle"
+	ErrCodeCategoryChildrenMismatch     = "category_children_mismatch"
+	ErrCodeCategorySlugExists           = "category_slug_exists"
+	ErrCodeCategoryAttributeNotFound    = "category_attribute_not_found"
+	ErrCodeCategoryTranslationExists    = "category_translation_exists"
+	ErrCodeCategoryTranslationNotFound  = "category_translation_not_found"
+	ErrCodeCategoryImportParentNotFound = "category_import_parent_not_found"
+	ErrCodeCategoryMediaNotFound        = "category_media_not_found"
+	ErrCodeCategoryMediaBodyInvalid     = "category_media_body_invalid"
+	ErrCodeCategoryWriteConflict        = "category_write_conflict"
+
+	ErrCodeProductNotFound            = "product_not_found"
+	ErrCodeProductVariantNotFound     = "product_variant_not_found"
+	ErrCodeProductCategoryNotFound    = "product_category_not_found"
+	ErrCodeProductVariantsRequired    = "product_variants_required"
+	ErrCodeProductSKUExists           = "product_sku_exists"
+	ErrCodeProductBarcodeExists       = "product_barcode_exists"
+	ErrCodeProductVariantOptionsExist = "product_variant_options_exist"
+
+	ErrCodePriceListNotFound   = "price_list_not_found"
+	ErrCodePriceListCodeExists = "price_list_code_exists"
+	ErrCodePriceSKUNotFound    = "price_sku_not_found"
+	ErrCodePriceNotFound       = "price_not_found"
+	ErrCodePriceBaseManaged    = "price_base_managed"
+	ErrCodePriceUnavailable    = "price_unavailable"
+)
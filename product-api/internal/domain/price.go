@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/ashtishad/ecommerce/product-api/pkg/money"
+)
+
+// RetailPriceList is the code of the price list of every customer, its base prices follow variant prices,
+// trg_sync_retail_base_price copies them until variant writes go through price lists.
+const RetailPriceList = "retail"
+
+// PriceList prices SKUs in one currency, CustomerGroup is empty if the list applies to every customer,
+// Priority orders lists of the same customer group, higher first.
+type PriceList struct {
+	PriceListID   int
+	PriceListUUID string
+	Code          string
+	Name          string
+	Currency      string
+	CustomerGroup string
+	Priority      int
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Price is an amount of a SKU in a price list, in minor units of the list's currency.
+// A base price has neither StartsAt nor EndsAt, a scheduled price applies from StartsAt inclusive to EndsAt exclusive,
+// a nil bound is open.
+type Price struct {
+	PriceUUID     string
+	PriceListCode string
+	SKU           string
+	Amount        int64
+	Currency      string
+	StartsAt      *time.Time
+	EndsAt        *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// IsBase reports whether p is the unscheduled base price of its SKU in the list.
+func (p *Price) IsBase() bool {
+	return p.StartsAt == nil && p.EndsAt == nil
+}
+
+// ActiveAt reports whether a scheduled price applies at t, base prices are never active, they are the fallback.
+func (p *Price) ActiveAt(t time.Time) bool {
+	if p.IsBase() {
+		return false
+	}
+
+	return (p.StartsAt == nil || !t.Before(*p.StartsAt)) && (p.EndsAt == nil || t.Before(*p.EndsAt))
+}
+
+// SKUPriceList is a price list with the prices of one SKU in it, in the order they were added.
+type SKUPriceList struct {
+	PriceList PriceList
+	Prices    []Price
+}
+
+// EffectivePrice is the price a customer group pays for a SKU at a moment,
+// Regular is the list's base price when a scheduled price applies, nil otherwise.
+type EffectivePrice struct {
+	SKU       string
+	PriceList PriceList
+	Price     Price
+	Regular   *Price
+	At        time.Time
+}
+
+func (pl *PriceList) ToPriceListDTO() *PriceListDTO {
+	return &PriceListDTO{
+		PriceListUUID: pl.PriceListUUID,
+		Code:          pl.Code,
+		Name:          pl.Name,
+		Currency:      pl.Currency,
+		CustomerGroup: pl.CustomerGroup,
+		Priority:      pl.Priority,
+		CreatedAt:     pl.CreatedAt,
+		UpdatedAt:     pl.UpdatedAt,
+	}
+}
+
+func (p *Price) ToPriceDTO() *PriceDTO {
+	return &PriceDTO{
+		PriceUUID: p.PriceUUID,
+		PriceList: p.PriceListCode,
+		SKU:       p.SKU,
+		Price:     toMoneyDTO(p.Amount, p.Currency),
+		StartsAt:  p.StartsAt,
+		EndsAt:    p.EndsAt,
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
+
+func (l *SKUPriceList) ToSKUPriceListDTO() *SKUPriceListDTO {
+	prices := make([]*PriceDTO, len(l.Prices))
+	for i := range l.Prices {
+		prices[i] = l.Prices[i].ToPriceDTO()
+	}
+
+	return &SKUPriceListDTO{
+		PriceList: l.PriceList.ToPriceListDTO(),
+		Prices:    prices,
+	}
+}
+
+func (e *EffectivePrice) ToEffectivePriceDTO() *EffectivePriceDTO {
+	dto := &EffectivePriceDTO{
+		SKU:       e.SKU,
+		PriceList: e.PriceList.Code,
+		PriceUUID: e.Price.PriceUUID,
+		Price:     toMoneyDTO(e.Price.Amount, e.Price.Currency),
+		StartsAt:  e.Price.StartsAt,
+		EndsAt:    e.Price.EndsAt,
+		At:        e.At,
+	}
+
+	if e.Regular != nil {
+		regular := toMoneyDTO(e.Regular.Amount, e.Regular.Currency)
+		dto.RegularPrice = &regular
+	}
+
+	return dto
+}
+
+func toMoneyDTO(amount int64, currency string) MoneyDTO {
+	return MoneyDTO{
+		Amount:    amount,
+		Currency:  currency,
+		Formatted: money.Money{Amount: amount, Currency: currency}.Decimal(),
+	}
+}
@@ -0,0 +1,78 @@
+package domain
+
+import "time"
+
+// MoneyDTO is an amount in minor units of an ISO 4217 currency, formatted is the decimal amount,
+// e.g. {"amount": 79999, "currency": "USD", "formatted": "799.99"}.
+type MoneyDTO struct {
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency"`
+	Formatted string `json:"formatted"`
+}
+
+type PriceListDTO struct {
+	PriceListUUID string    `json:"priceListUuid"`
+	Code          string    `json:"code"`
+	Name          string    `json:"name"`
+	Currency      string    `json:"currency"`
+	CustomerGroup string    `json:"customerGroup,omitempty"` // every customer if empty
+	Priority      int       `json:"priority"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// NewPriceListRequestDTO creates a price list, code and currency can't change later.
+type NewPriceListRequestDTO struct {
+	Code          string `json:"code"`          // lowercase, e.g. b2b
+	Name          string `json:"name"`          // e.g. Business customers
+	Currency      string `json:"currency"`      // ISO 4217, e.g. USD
+	CustomerGroup string `json:"customerGroup"` // lowercase, every customer if empty
+	Priority      int    `json:"priority"`      // higher wins among lists of the same customer group
+}
+
+type PriceDTO struct {
+	PriceUUID string     `json:"priceUuid"`
+	PriceList string     `json:"priceList"` // price list code
+	SKU       string     `json:"sku"`
+	Price     MoneyDTO   `json:"price"`
+	StartsAt  *time.Time `json:"startsAt,omitempty"`
+	EndsAt    *time.Time `json:"endsAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+// SavePriceRequestDTO sets the base price of a SKU in a price list if it has neither startsAt nor endsAt,
+// otherwise adds a scheduled price from startsAt inclusive to endsAt exclusive, a missing bound is open.
+type SavePriceRequestDTO struct {
+	PriceListCode string     `json:"-"`
+	SKU           string     `json:"sku"`
+	Amount        *int64     `json:"amount"` // minor units of the list's currency, e.g. 79999 for 799.99 USD
+	StartsAt      *time.Time `json:"startsAt"`
+	EndsAt        *time.Time `json:"endsAt"`
+}
+
+type SKUPriceListDTO struct {
+	PriceList *PriceListDTO `json:"priceList"`
+	Prices    []*PriceDTO   `json:"prices"`
+}
+
+// ResolvePriceRequestDTO asks for the price a customer group pays for a SKU at a moment, query params are strings.
+type ResolvePriceRequestDTO struct {
+	SKU           string
+	CustomerGroup string // every customer if empty
+	Currency      string // any currency if empty
+	At            string // RFC 3339, now if empty
+}
+
+// EffectivePriceDTO is the resolved price of a SKU, regularPrice is the list's base price while a scheduled price applies,
+// startsAt and endsAt are the schedule of the applied price.
+type EffectivePriceDTO struct {
+	SKU          string     `json:"sku"`
+	PriceList    string     `json:"priceList"`
+	PriceUUID    string     `json:"priceUuid"`
+	Price        MoneyDTO   `json:"price"`
+	RegularPrice *MoneyDTO  `json:"regularPrice,omitempty"`
+	StartsAt     *time.Time `json:"startsAt,omitempty"`
+	EndsAt       *time.Time `json:"endsAt,omitempty"`
+	At           time.Time  `json:"at"`
+}
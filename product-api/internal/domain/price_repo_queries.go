@@ -0,0 +1,33 @@
+package domain
+
+const (
+	sqlInsertPriceList = `INSERT INTO price_lists (code, name, currency, customer_group, priority)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING price_list_id, price_list_uuid, created_at, updated_at`
+	sqlSelectPriceLists = `SELECT price_list_id, price_list_uuid, code, name, currency, COALESCE(customer_group, ''), priority,
+       created_at, updated_at
+FROM price_lists
+ORDER BY customer_group NULLS FIRST, priority DESC, code`
+	sqlSelectPriceListByCode = `SELECT price_list_id, currency FROM price_lists WHERE code = $1`
+	sqlSelectVariantIDBySKU  = `SELECT variant_id, sku FROM product_variants WHERE upper(sku) = upper($1)`
+
+	// scheduled prices never conflict, the unique index only covers base prices.
+	sqlSavePrice = `INSERT INTO prices (price_list_id, variant_id, amount, starts_at, ends_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (price_list_id, variant_id) WHERE starts_at IS NULL AND ends_at IS NULL
+    DO UPDATE SET amount = EXCLUDED.amount, updated_at = CURRENT_TIMESTAMP
+RETURNING price_uuid, created_at, updated_at`
+	sqlSelectPriceForDelete = `SELECT p.price_id, p.starts_at IS NULL AND p.ends_at IS NULL
+FROM prices p
+         JOIN price_lists pl ON pl.price_list_id = p.price_list_id
+WHERE pl.code = $1 AND p.price_uuid = $2`
+	sqlDeletePrice = `DELETE FROM prices WHERE price_id = $1`
+
+	// prices of a variant grouped by price list, in the order they were added.
+	sqlSelectSKUPrices = `SELECT pl.price_list_id, pl.price_list_uuid, pl.code, pl.name, pl.currency, COALESCE(pl.customer_group, ''),
+       pl.priority, pl.created_at, pl.updated_at, p.price_uuid, p.amount, p.starts_at, p.ends_at, p.created_at, p.updated_at
+FROM prices p
+         JOIN price_lists pl ON pl.price_list_id = p.price_list_id
+WHERE p.variant_id = $1
+ORDER BY pl.code, p.price_id`
+)
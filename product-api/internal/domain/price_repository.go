@@ -0,0 +1,15 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/ashtishad/ecommerce/lib"
+)
+
+type PriceRepository interface {
+	CreatePriceList(ctx context.Context, priceList PriceList) (*PriceList, lib.APIError)
+	FindPriceLists(ctx context.Context) ([]PriceList, lib.APIError)
+	SavePrice(ctx context.Context, price Price) (*Price, lib.APIError)
+	DeletePrice(ctx context.Context, priceListCode string, priceUUID string) lib.APIError
+	FindSKUPrices(ctx context.Context, sku string) ([]SKUPriceList, lib.APIError)
+}
@@ -0,0 +1,195 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/ashtishad/ecommerce/lib"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// uniquePriceListCode is the unique constraint of price list codes, violations are reported as conflicts.
+const uniquePriceListCode = "uq_price_list_code"
+
+type PriceRepoDB struct {
+	db *sql.DB
+	l  *slog.Logger
+}
+
+func NewPriceRepoDB(db *sql.DB, l *slog.Logger) *PriceRepoDB {
+	return &PriceRepoDB{db, l}
+}
+
+// CreatePriceList inserts a price list, returns 409 if the code already exists.
+func (d *PriceRepoDB) CreatePriceList(ctx context.Context, priceList PriceList) (*PriceList, lib.APIError) {
+	err := d.db.QueryRowContext(ctx, sqlInsertPriceList, priceList.Code, priceList.Name, priceList.Currency,
+		nullableString(priceList.CustomerGroup), priceList.Priority).
+		Scan(&priceList.PriceListID, &priceList.PriceListUUID, &priceList.CreatedAt, &priceList.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation && pgErr.ConstraintName == uniquePriceListCode {
+			d.l.Warn("price list code already exists", "code", priceList.Code)
+			return nil, lib.NewError(http.StatusConflict, ErrCodePriceListCodeExists, lib.Args{"code": priceList.Code}).Wrap(err)
+		}
+
+		d.l.Error("failed to insert price list", "code", priceList.Code, "err", err)
+
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return &priceList, nil
+}
+
+// FindPriceLists returns all price lists, lists of every customer first, then by customer group, priority and code.
+func (d *PriceRepoDB) FindPriceLists(ctx context.Context) ([]PriceList, lib.APIError) {
+	rows, err := d.db.QueryContext(ctx, sqlSelectPriceLists)
+	if err != nil {
+		d.l.Error("failed to query price lists", "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	defer closeRows(rows, d.l)
+
+	priceLists := make([]PriceList, 0)
+
+	for rows.Next() {
+		var pl PriceList
+		if err = rows.Scan(&pl.PriceListID, &pl.PriceListUUID, &pl.Code, &pl.Name, &pl.Currency, &pl.CustomerGroup, &pl.Priority,
+			&pl.CreatedAt, &pl.UpdatedAt); err != nil {
+			d.l.Error("failed to scan rows:", "err", err)
+			return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+		}
+
+		priceLists = append(priceLists, pl)
+	}
+
+	if err = rows.Err(); err != nil {
+		d.l.Error("unexpected error on scanning price list rows", "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return priceLists, nil
+}
+
+// SavePrice replaces the base price of a SKU in a price list if price has no schedule, otherwise adds a scheduled price,
+// the amount is in minor units of the list's currency.
+//   - returns 404 if the price list or the SKU doesn't exist.
+func (d *PriceRepoDB) SavePrice(ctx context.Context, price Price) (*Price, lib.APIError) {
+	var priceListID int
+	if err := d.db.QueryRowContext(ctx, sqlSelectPriceListByCode, price.PriceListCode).Scan(&priceListID, &price.Currency); err != nil {
+		return nil, d.notFoundOrInternal(err, ErrCodePriceListNotFound, lib.Args{"code": price.PriceListCode})
+	}
+
+	variantID, apiErr := d.selectVariantID(ctx, &price.SKU)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if err := d.db.QueryRowContext(ctx, sqlSavePrice, priceListID, variantID, price.Amount, price.StartsAt, price.EndsAt).
+		Scan(&price.PriceUUID, &price.CreatedAt, &price.UpdatedAt); err != nil {
+		d.l.Error("failed to save price", "priceList", price.PriceListCode, "sku", price.SKU, "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return &price, nil
+}
+
+// DeletePrice deletes a base or scheduled price of a price list.
+//   - returns 404 if the price list has no such price.
+//   - returns 409 for base prices of the retail list, they follow variant prices.
+func (d *PriceRepoDB) DeletePrice(ctx context.Context, priceListCode string, priceUUID string) lib.APIError {
+	var (
+		priceID int64
+		isBase  bool
+	)
+
+	if err := d.db.QueryRowContext(ctx, sqlSelectPriceForDelete, priceListCode, priceUUID).Scan(&priceID, &isBase); err != nil {
+		return d.notFoundOrInternal(err, ErrCodePriceNotFound, nil)
+	}
+
+	if isBase && priceListCode == RetailPriceList {
+		d.l.Warn("retail base price can't be deleted", "price", priceUUID)
+		return lib.NewError(http.StatusConflict, ErrCodePriceBaseManaged, nil)
+	}
+
+	if _, err := d.db.ExecContext(ctx, sqlDeletePrice, priceID); err != nil {
+		d.l.Error("failed to delete price", "price", priceUUID, "err", err)
+		return lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return nil
+}
+
+// FindSKUPrices returns the price lists that price a SKU with its prices in each, ordered by price list code,
+// SKUs are matched case-insensitively, returns 404 if the SKU doesn't exist.
+func (d *PriceRepoDB) FindSKUPrices(ctx context.Context, sku string) ([]SKUPriceList, lib.APIError) {
+	variantID, apiErr := d.selectVariantID(ctx, &sku)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	rows, err := d.db.QueryContext(ctx, sqlSelectSKUPrices, variantID)
+	if err != nil {
+		d.l.Error("failed to query sku prices", "sku", sku, "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	defer closeRows(rows, d.l)
+
+	priceLists := make([]SKUPriceList, 0)
+
+	for rows.Next() {
+		var (
+			pl PriceList
+			p  Price
+		)
+
+		if err = rows.Scan(&pl.PriceListID, &pl.PriceListUUID, &pl.Code, &pl.Name, &pl.Currency, &pl.CustomerGroup, &pl.Priority,
+			&pl.CreatedAt, &pl.UpdatedAt, &p.PriceUUID, &p.Amount, &p.StartsAt, &p.EndsAt, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			d.l.Error("failed to scan rows:", "err", err)
+			return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+		}
+
+		p.PriceListCode, p.SKU, p.Currency = pl.Code, sku, pl.Currency
+
+		if n := len(priceLists); n == 0 || priceLists[n-1].PriceList.PriceListID != pl.PriceListID {
+			priceLists = append(priceLists, SKUPriceList{PriceList: pl})
+		}
+
+		last := &priceLists[len(priceLists)-1]
+		last.Prices = append(last.Prices, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		d.l.Error("unexpected error on scanning sku price rows", "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return priceLists, nil
+}
+
+// selectVariantID returns the variant id of a SKU matched case-insensitively and sets sku to the variant's SKU,
+// returns 404 if no variant has it.
+func (d *PriceRepoDB) selectVariantID(ctx context.Context, sku *string) (int, lib.APIError) {
+	var variantID int
+	if err := d.db.QueryRowContext(ctx, sqlSelectVariantIDBySKU, *sku).Scan(&variantID, sku); err != nil {
+		return 0, d.notFoundOrInternal(err, ErrCodePriceSKUNotFound, lib.Args{"sku": *sku})
+	}
+
+	return variantID, nil
+}
+
+// notFoundOrInternal maps sql.ErrNoRows of a single row lookup to a 404 with code, other errors to 500.
+func (d *PriceRepoDB) notFoundOrInternal(err error, code string, args lib.Args) lib.APIError {
+	if errors.Is(err, sql.ErrNoRows) {
+		d.l.Warn("price lookup found nothing", "code", code, "args", args)
+		return lib.NewError(http.StatusNotFound, code, args).Wrap(err)
+	}
+
+	d.l.Error(lib.ErrScanningRows, "err", err.Error())
+
+	return lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+}
@@ -0,0 +1,93 @@
+//go:build integration
+
+package domain
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ashtishad/ecommerce/db/conn"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPriceIntegration saves and deletes prices of a product's skus against a migrated postgres database,
+// checks retail base prices follow variant prices and base prices are replaced per list.
+func TestPriceIntegration(t *testing.T) {
+	if os.Getenv("DB_ADDR") == "" {
+		t.Skip("DB_ADDR is not set, skipping integration test")
+	}
+
+	db := conn.GetDBClient(testLogger)
+	t.Cleanup(func() { _ = db.Close() }) // after the fixture cleanup, cleanups run last in first out
+
+	productRepo := NewProductRepoDB(db, testLogger)
+	priceRepo := NewPriceRepoDB(db, testLogger)
+	ctx := context.Background()
+	suffix := fmt.Sprint(time.Now().UnixNano())
+	_, _, created := createProductFixture(t, db, suffix)
+
+	t.Run("Retail base prices follow variant prices", func(t *testing.T) {
+		variant := created.Variants[1]
+		variant.Price = "649.5"
+
+		_, apiErr := productRepo.UpdateProductVariant(ctx, created.ProductUUID, variant)
+		require.Nil(t, apiErr)
+
+		priceLists, apiErr := priceRepo.FindSKUPrices(ctx, variant.SKU)
+		require.Nil(t, apiErr)
+		require.Len(t, priceLists, 1)
+		require.Equal(t, RetailPriceList, priceLists[0].PriceList.Code)
+		require.Len(t, priceLists[0].Prices, 1)
+		require.Equal(t, int64(64950), priceLists[0].Prices[0].Amount)
+
+		apiErr = priceRepo.DeletePrice(ctx, RetailPriceList, priceLists[0].Prices[0].PriceUUID)
+		require.Equal(t, ErrCodePriceBaseManaged, apiErr.ErrorCode())
+	})
+
+	t.Run("Base price per list and scheduled prices", func(t *testing.T) {
+		priceList, apiErr := priceRepo.CreatePriceList(ctx, PriceList{Code: "it-" + suffix, Name: "Integration", Currency: "EUR",
+			CustomerGroup: "b2b"})
+		require.Nil(t, apiErr)
+
+		t.Cleanup(func() {
+			_, _ = db.Exec(`DELETE FROM price_lists WHERE price_list_id = $1`, priceList.PriceListID)
+		})
+
+		_, apiErr = priceRepo.CreatePriceList(ctx, PriceList{Code: priceList.Code, Name: "Again", Currency: "EUR"})
+		require.Equal(t, ErrCodePriceListCodeExists, apiErr.ErrorCode())
+
+		sku := created.Variants[0].SKU
+		starts := time.Now().Truncate(time.Second)
+		ends := starts.Add(time.Hour)
+
+		for _, price := range []Price{
+			{PriceListCode: priceList.Code, SKU: sku, Amount: 45000},
+			{PriceListCode: priceList.Code, SKU: sku, Amount: 44000},
+			{PriceListCode: priceList.Code, SKU: sku, Amount: 40000, StartsAt: &starts, EndsAt: &ends},
+		} {
+			saved, apiErr := priceRepo.SavePrice(ctx, price)
+			require.Nil(t, apiErr)
+			require.Equal(t, "EUR", saved.Currency)
+		}
+
+		priceLists, apiErr := priceRepo.FindSKUPrices(ctx, sku)
+		require.Nil(t, apiErr)
+		require.Len(t, priceLists, 2) // retail and the new list
+
+		prices := priceLists[0].Prices
+		if priceLists[0].PriceList.Code != priceList.Code {
+			prices = priceLists[1].Prices
+		}
+
+		require.Len(t, prices, 2)
+		require.Equal(t, int64(44000), prices[0].Amount) // base price replaced
+		require.True(t, prices[1].StartsAt.Equal(starts))
+		require.True(t, prices[1].EndsAt.Equal(ends))
+
+		require.Nil(t, priceRepo.DeletePrice(ctx, priceList.Code, prices[1].PriceUUID))
+		require.Equal(t, ErrCodePriceNotFound, priceRepo.DeletePrice(ctx, priceList.Code, prices[1].PriceUUID).ErrorCode())
+	})
+}
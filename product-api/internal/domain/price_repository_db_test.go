@@ -0,0 +1,222 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ashtishad/ecommerce/lib"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPriceUUID     = "8f3c2a1e-6b4d-4e5f-9a8b-7c6d5e4f3a2b"
+	testPriceListUUID = "0c9d8e7f-1a2b-4c3d-8e5f-6a7b8c9d0e1f"
+)
+
+// TestCreatePriceList tests the CreatePriceList method of PriceRepoDB.
+// It covers created list and existing code.
+func TestCreatePriceList(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPriceRepoDB(db, testLogger)
+	now := time.Now()
+	priceList := PriceList{Code: "b2b", Name: "Business", Currency: "USD", CustomerGroup: "b2b", Priority: 10}
+
+	t.Run("Price list created", func(t *testing.T) {
+		expectQuery(mock, sqlInsertPriceList).WithArgs("b2b", "Business", "USD", "b2b", 10).
+			WillReturnRows(sqlmock.NewRows([]string{"price_list_id", "price_list_uuid", "created_at", "updated_at"}).
+				AddRow(2, testPriceListUUID, now, now))
+
+		created, apiErr := repo.CreatePriceList(context.Background(), priceList)
+		require.Nil(t, apiErr)
+		require.Equal(t, testPriceListUUID, created.PriceListUUID)
+		require.Equal(t, "b2b", created.CustomerGroup)
+	})
+
+	t.Run("Every customer has NULL group", func(t *testing.T) {
+		expectQuery(mock, sqlInsertPriceList).WithArgs("outlet", "Outlet", "EUR", nil, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"price_list_id", "price_list_uuid", "created_at", "updated_at"}).
+				AddRow(3, testPriceListUUID, now, now))
+
+		_, apiErr := repo.CreatePriceList(context.Background(), PriceList{Code: "outlet", Name: "Outlet", Currency: "EUR"})
+		require.Nil(t, apiErr)
+	})
+
+	t.Run("Code exists", func(t *testing.T) {
+		expectQuery(mock, sqlInsertPriceList).WithArgs("b2b", "Business", "USD", "b2b", 10).
+			WillReturnError(&pgconn.PgError{Code: pgUniqueViolation, ConstraintName: uniquePriceListCode})
+
+		created, apiErr := repo.CreatePriceList(context.Background(), priceList)
+		require.Nil(t, created)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+		require.Equal(t, ErrCodePriceListCodeExists, apiErr.ErrorCode())
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestSavePrice tests the SavePrice method of PriceRepoDB.
+// It covers a scheduled price, price list not found and sku not found.
+func TestSavePrice(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPriceRepoDB(db, testLogger)
+	now := time.Now()
+	starts, ends := now.Add(time.Hour), now.Add(48*time.Hour)
+	price := Price{PriceListCode: RetailPriceList, SKU: "SM-S921B-128-BLK", Amount: 69999, StartsAt: &starts, EndsAt: &ends}
+
+	listRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"price_list_id", "currency"}).AddRow(1, "USD")
+	}
+
+	t.Run("Scheduled price added", func(t *testing.T) {
+		expectQuery(mock, sqlSelectPriceListByCode).WithArgs(RetailPriceList).WillReturnRows(listRows())
+		expectQuery(mock, sqlSelectVariantIDBySKU).WithArgs(price.SKU).WillReturnRows(sqlmock.NewRows([]string{"variant_id", "sku"}).AddRow(7, price.SKU))
+		expectQuery(mock, sqlSavePrice).WithArgs(1, 7, int64(69999), starts, ends).
+			WillReturnRows(sqlmock.NewRows([]string{"price_uuid", "created_at", "updated_at"}).AddRow(testPriceUUID, now, now))
+
+		saved, apiErr := repo.SavePrice(context.Background(), price)
+		require.Nil(t, apiErr)
+		require.Equal(t, testPriceUUID, saved.PriceUUID)
+		require.Equal(t, "USD", saved.Currency)
+	})
+
+	t.Run("Price list not found", func(t *testing.T) {
+		expectQuery(mock, sqlSelectPriceListByCode).WithArgs(RetailPriceList).WillReturnError(sql.ErrNoRows)
+
+		saved, apiErr := repo.SavePrice(context.Background(), price)
+		require.Nil(t, saved)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+		require.Equal(t, ErrCodePriceListNotFound, apiErr.ErrorCode())
+	})
+
+	t.Run("SKU not found", func(t *testing.T) {
+		expectQuery(mock, sqlSelectPriceListByCode).WithArgs(RetailPriceList).WillReturnRows(listRows())
+		expectQuery(mock, sqlSelectVariantIDBySKU).WithArgs(price.SKU).WillReturnError(sql.ErrNoRows)
+
+		saved, apiErr := repo.SavePrice(context.Background(), price)
+		require.Nil(t, saved)
+		require.Equal(t, ErrCodePriceSKUNotFound, apiErr.ErrorCode())
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestDeletePrice tests the DeletePrice method of PriceRepoDB.
+// It covers deleted price, retail base price and price not found.
+func TestDeletePrice(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPriceRepoDB(db, testLogger)
+
+	priceRows := func(isBase bool) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"price_id", "is_base"}).AddRow(int64(42), isBase)
+	}
+
+	t.Run("Base price of b2b deleted", func(t *testing.T) {
+		expectQuery(mock, sqlSelectPriceForDelete).WithArgs("b2b", testPriceUUID).WillReturnRows(priceRows(true))
+		expectExec(mock, sqlDeletePrice).WithArgs(int64(42)).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		require.Nil(t, repo.DeletePrice(context.Background(), "b2b", testPriceUUID))
+	})
+
+	t.Run("Retail base price is managed", func(t *testing.T) {
+		expectQuery(mock, sqlSelectPriceForDelete).WithArgs(RetailPriceList, testPriceUUID).WillReturnRows(priceRows(true))
+
+		apiErr := repo.DeletePrice(context.Background(), RetailPriceList, testPriceUUID)
+		require.ErrorIs(t, apiErr, lib.ErrConflict)
+		require.Equal(t, ErrCodePriceBaseManaged, apiErr.ErrorCode())
+	})
+
+	t.Run("Retail scheduled price deleted", func(t *testing.T) {
+		expectQuery(mock, sqlSelectPriceForDelete).WithArgs(RetailPriceList, testPriceUUID).WillReturnRows(priceRows(false))
+		expectExec(mock, sqlDeletePrice).WithArgs(int64(42)).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		require.Nil(t, repo.DeletePrice(context.Background(), RetailPriceList, testPriceUUID))
+	})
+
+	t.Run("Price not found", func(t *testing.T) {
+		expectQuery(mock, sqlSelectPriceForDelete).WithArgs("b2b", testPriceUUID).WillReturnError(sql.ErrNoRows)
+
+		apiErr := repo.DeletePrice(context.Background(), "b2b", testPriceUUID)
+		require.ErrorIs(t, apiErr, lib.ErrNotFound)
+		require.Equal(t, ErrCodePriceNotFound, apiErr.ErrorCode())
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestFindSKUPrices makes sure price rows are grouped by price list, open schedule bounds scan as nil
+// and prices have the variant's SKU when looked up in another case.
+func TestFindSKUPrices(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPriceRepoDB(db, testLogger)
+	now := time.Now()
+	starts := now.Add(-time.Hour)
+
+	expectQuery(mock, sqlSelectVariantIDBySKU).WithArgs("sku-1").WillReturnRows(sqlmock.NewRows([]string{"variant_id", "sku"}).AddRow(7, "SKU-1"))
+	expectQuery(mock, sqlSelectSKUPrices).WithArgs(7).WillReturnRows(sqlmock.NewRows([]string{"price_list_id", "price_list_uuid", "code",
+		"name", "currency", "customer_group", "priority", "created_at", "updated_at", "price_uuid", "amount", "starts_at", "ends_at",
+		"created_at", "updated_at"}).
+		AddRow(2, testPriceListUUID, "b2b", "Business", "USD", "b2b", 10, now, now, testPriceUUID, int64(60000), nil, nil, now, now).
+		AddRow(1, testPriceListUUID, "retail", "Retail", "USD", "", 0, now, now, testPriceUUID, int64(79999), nil, nil, now, now).
+		AddRow(1, testPriceListUUID, "retail", "Retail", "USD", "", 0, now, now, testPriceUUID, int64(69999), starts, nil, now, now))
+
+	priceLists, apiErr := repo.FindSKUPrices(context.Background(), "sku-1")
+	require.Nil(t, apiErr)
+	require.Len(t, priceLists, 2)
+	require.Equal(t, "b2b", priceLists[0].PriceList.Code)
+	require.Len(t, priceLists[1].Prices, 2)
+
+	sale := priceLists[1].Prices[1]
+	require.Equal(t, int64(69999), sale.Amount)
+	require.Equal(t, "SKU-1", sale.SKU)
+	require.Equal(t, "USD", sale.Currency)
+	require.Equal(t, RetailPriceList, sale.PriceListCode)
+	require.True(t, sale.StartsAt.Equal(starts))
+	require.Nil(t, sale.EndsAt)
+	require.True(t, priceLists[1].Prices[0].IsBase())
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestPriceActiveAt checks that schedules start inclusive and end exclusive, and base prices are never active.
+func TestPriceActiveAt(t *testing.T) {
+	starts := time.Date(2024, 11, 29, 0, 0, 0, 0, time.UTC)
+	ends := starts.Add(72 * time.Hour)
+
+	tests := []struct {
+		name   string
+		price  Price
+		at     time.Time
+		active bool
+	}{
+		{"Just before start", Price{StartsAt: &starts, EndsAt: &ends}, starts.Add(-time.Nanosecond), false},
+		{"At start", Price{StartsAt: &starts, EndsAt: &ends}, starts, true},
+		{"Just before end", Price{StartsAt: &starts, EndsAt: &ends}, ends.Add(-time.Nanosecond), true},
+		{"At end", Price{StartsAt: &starts, EndsAt: &ends}, ends, false},
+		{"Open start", Price{EndsAt: &ends}, starts.AddDate(-10, 0, 0), true},
+		{"Open end", Price{StartsAt: &starts}, starts.AddDate(10, 0, 0), true},
+		{"Base price", Price{}, starts, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.active, tt.price.ActiveAt(tt.at))
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	ProductStatusDraft    = "draft"
+	ProductStatusActive   = "active"
+	ProductStatusArchived = "archived"
+)
+
+// product search sorts, relevance needs a search query.
+const (
+	ProductSortRelevance = "relevance"
+	ProductSortNewest    = "newest"
+	ProductSortPriceAsc  = "price_asc"
+	ProductSortPriceDesc = "price_desc"
+
+	FacetBrand     = "brand"
+	FacetAttribute = "attribute"
+)
+
+// Product is a catalog item listed under one or more categories, shoppers buy one of its variants.
+// PrimaryCategoryUUID is one of CategoryUUIDs, the canonical category of the product,
+// Breadcrumb is the path from the root category to the primary category.
+// Attributes are values of attributes defined by the product's categories, shared by all variants.
+type Product struct {
+	ProductID           int
+	ProductUUID         string
+	Title               string
+	Description         string
+	Brand               string
+	Status              string
+	CategoryUUIDs       []string
+	PrimaryCategoryUUID string
+	Breadcrumb          []CategoryRef
+	Attributes          []ProductAttribute
+	Variants            []ProductVariant
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+// CategoryRef is a category in a product breadcrumb, json tags match the objects built by product queries.
+type CategoryRef struct {
+	CategoryUUID string `json:"categoryUuid"`
+	Name         string `json:"name"`
+	Slug         string `json:"slug"`
+}
+
+// ProductVariant is a sellable SKU of a product, Options tell variants of the same product apart,
+// e.g. {"color": "black", "storage": "128GB"}. Price is a decimal string with up to 2 fraction digits.
+// json tags match the objects built by product queries.
+type ProductVariant struct {
+	VariantUUID string             `json:"variantUuid"`
+	SKU         string             `json:"sku"`
+	Barcode     string             `json:"barcode"`
+	Options     map[string]string  `json:"options"`
+	Price       string             `json:"price"`
+	Attributes  []ProductAttribute `json:"attributes"` // override product attributes with the same code
+	CreatedAt   time.Time          `json:"createdAt"`
+	UpdatedAt   time.Time          `json:"updatedAt"`
+}
+
+// ProductAttribute is a typed value of a category attribute, e.g. ram: 8 GB. Value is a string, number or bool
+// as the attribute type says, name, type and unit come from the closest definition in the primary category's tree,
+// then the other categories. json tags match the objects built by product queries.
+type ProductAttribute struct {
+	Code  string `json:"code"`
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Unit  string `json:"unit"`
+	Value any    `json:"value"`
+}
+
+// attributeValues encodes attributes as the {code: value} object stored in attributes columns.
+func attributeValues(attributes []ProductAttribute) ([]byte, error) {
+	values := make(map[string]any, len(attributes))
+	for _, a := range attributes {
+		values[a.Code] = a.Value
+	}
+
+	return json.Marshal(values)
+}
+
+func (p *Product) ToProductResponseDTO() *ProductResponseDTO {
+	variants := make([]*ProductVariantResponseDTO, len(p.Variants))
+	for i := range p.Variants {
+		variants[i] = p.Variants[i].ToProductVariantResponseDTO()
+	}
+
+	attributes := make([]*ProductAttributeDTO, len(p.Attributes))
+	for i := range p.Attributes {
+		attributes[i] = p.Attributes[i].ToProductAttributeDTO()
+	}
+
+	breadcrumb := make([]*CategoryRefDTO, len(p.Breadcrumb))
+	for i, c := range p.Breadcrumb {
+		breadcrumb[i] = &CategoryRefDTO{CategoryUUID: c.CategoryUUID, Name: c.Name, Slug: c.Slug}
+	}
+
+	return &ProductResponseDTO{
+		ProductUUID:         p.ProductUUID,
+		Title:               p.Title,
+		Description:         p.Description,
+		Brand:               p.Brand,
+		Status:              p.Status,
+		CategoryUUIDs:       p.CategoryUUIDs,
+		PrimaryCategoryUUID: p.PrimaryCategoryUUID,
+		Breadcrumb:          breadcrumb,
+		Attributes:          attributes,
+		Variants:            variants,
+		CreatedAt:           p.CreatedAt,
+		UpdatedAt:           p.UpdatedAt,
+	}
+}
+
+func (v *ProductVariant) ToProductVariantResponseDTO() *ProductVariantResponseDTO {
+	attributes := make([]*ProductAttributeDTO, len(v.Attributes))
+	for i := range v.Attributes {
+		attributes[i] = v.Attributes[i].ToProductAttributeDTO()
+	}
+
+	return &ProductVariantResponseDTO{
+		VariantUUID: v.VariantUUID,
+		SKU:         v.SKU,
+		Barcode:     v.Barcode,
+		Options:     v.Options,
+		Price:       v.Price,
+		Attributes:  attributes,
+		CreatedAt:   v.CreatedAt,
+		UpdatedAt:   v.UpdatedAt,
+	}
+}
+
+func (a *ProductAttribute) ToProductAttributeDTO() *ProductAttributeDTO {
+	return &ProductAttributeDTO{
+		Code:  a.Code,
+		Name:  a.Name,
+		Type:  a.Type,
+		Unit:  a.Unit,
+		Value: a.Value,
+	}
+}
@@ -0,0 +1,130 @@
+package domain
+
+import "time"
+
+type ProductResponseDTO struct {
+	ProductUUID         string                       `json:"productUuid"`
+	Title               string                       `json:"title"`
+	Description         string                       `json:"description"`
+	Brand               string                       `json:"brand"`
+	Status              string                       `json:"status"`
+	CategoryUUIDs       []string                     `json:"categoryUuids"` // primary category first
+	PrimaryCategoryUUID string                       `json:"primaryCategoryUuid"`
+	Breadcrumb          []*CategoryRefDTO            `json:"breadcrumb"` // root to primary category
+	Attributes          []*ProductAttributeDTO       `json:"attributes"`
+	Variants            []*ProductVariantResponseDTO `json:"variants"`
+	CreatedAt           time.Time                    `json:"createdAt"`
+	UpdatedAt           time.Time                    `json:"updatedAt"`
+}
+
+type CategoryRefDTO struct {
+	CategoryUUID string `json:"categoryUuid"`
+	Name         string `json:"name"`
+	Slug         string `json:"slug"`
+}
+
+type ProductVariantResponseDTO struct {
+	VariantUUID string                 `json:"variantUuid"`
+	SKU         string                 `json:"sku"`
+	Barcode     string                 `json:"barcode,omitempty"`
+	Options     map[string]string      `json:"options"`
+	Price       string                 `json:"price"`
+	Attributes  []*ProductAttributeDTO `json:"attributes"`
+	CreatedAt   time.Time              `json:"createdAt"`
+	UpdatedAt   time.Time              `json:"updatedAt"`
+}
+
+// ProductAttributeDTO is an attribute value with its definition, e.g. {"code": "ram", "name": "RAM", "type": "number",
+// "unit": "GB", "value": 8}.
+type ProductAttributeDTO struct {
+	Code  string `json:"code"`
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Unit  string `json:"unit,omitempty"`
+	Value any    `json:"value"`
+}
+
+// NewProductRequestDTO creates a product with its variants, an active product needs at least one variant.
+type NewProductRequestDTO struct {
+	Title               string                     `json:"title"`
+	Description         string                     `json:"description"`
+	Brand               string                     `json:"brand"`
+	Status              string                     `json:"status"` // Enum 'draft'(default), 'active', 'archived'
+	CategoryUUIDs       []string                   `json:"categoryUuids"`
+	PrimaryCategoryUUID string                     `json:"primaryCategoryUuid"` // one of categoryUuids, first one by default
+	Attributes          map[string]any             `json:"attributes"`          // {code: value}, e.g. {"ram": 8, "anc": true}
+	Variants            []ProductVariantRequestDTO `json:"variants"`
+}
+
+// UpdateProductRequestDTO replaces the editable fields and categories of a product, variants are updated on their own.
+type UpdateProductRequestDTO struct {
+	ProductUUID         string         `json:"productUuid"` // path param
+	Title               string         `json:"title"`
+	Description         string         `json:"description"`
+	Brand               string         `json:"brand"`
+	Status              string         `json:"status"` // Enum 'draft', 'active', 'archived'
+	CategoryUUIDs       []string       `json:"categoryUuids"`
+	PrimaryCategoryUUID string         `json:"primaryCategoryUuid"` // one of categoryUuids, first one by default
+	Attributes          map[string]any `json:"attributes"`          // replaces product attributes, {code: value}
+}
+
+// ProductVariantRequestDTO is a variant in a new product request, or the body of adding and updating a variant.
+type ProductVariantRequestDTO struct {
+	ProductUUID string            `json:"productUuid"` // path param, adding and updating only
+	VariantUUID string            `json:"variantUuid"` // path param, updating only
+	SKU         string            `json:"sku"`
+	Barcode     string            `json:"barcode"` // optional GTIN, 8, 12, 13 or 14 digits
+	Options     map[string]string `json:"options"`
+	Price       string            `json:"price"`      // decimal string, e.g. "499.99"
+	Attributes  map[string]any    `json:"attributes"` // {code: value}, overrides product attributes
+}
+
+// ProductListRequestDTO filters GET /products and GET /categories/:category_id/products, newest first,
+// every query param is optional.
+type ProductListRequestDTO struct {
+	Status                string `json:"status"`             // query param
+	CategoryUUID          string `json:"categoryId"`         // query param, path param of category products
+	IncludeDescendantsStr string `json:"includeDescendants"` // query param, 'true' lists products of the whole subtree
+	Brand                 string `json:"brand"`              // query param, case-insensitive
+	LimitStr              string `json:"limit"`              // query param
+	After                 string `json:"after"`              // query param, productUuid of the last product of previous page
+}
+
+// ProductListResponseDTO is a page of products, NextCursor is the after param of the next page, empty on the last page.
+type ProductListResponseDTO struct {
+	Products   []*ProductResponseDTO `json:"products"`
+	NextCursor string                `json:"nextCursor,omitempty"`
+}
+
+// ProductSearchRequestDTO searches active products, GET /products/search query params, every one is optional.
+type ProductSearchRequestDTO struct {
+	Query        string            `json:"q"`          // websearch query, e.g. "gaming phone" -refurbished
+	CategoryUUID string            `json:"categoryId"` // products of the category's subtree
+	Brands       []string          `json:"brand"`      // repeatable, case-insensitive
+	MinPrice     string            `json:"minPrice"`   // inclusive, price of any variant
+	MaxPrice     string            `json:"maxPrice"`   // inclusive, price of any variant
+	Attributes   map[string]string `json:"attr"`       // attr[color]=black,blue or attr[ram]=8..16
+	Sort         string            `json:"sort"`       // Enum 'relevance'(default with q), 'newest'(default), 'price_asc', 'price_desc'
+	LimitStr     string            `json:"limit"`
+	After        string            `json:"after"` // nextCursor of the previous page
+}
+
+// ProductSearchResponseDTO is a page of search results with facet counts of every matching product,
+// NextCursor is the after param of the next page, empty on the last page.
+type ProductSearchResponseDTO struct {
+	Products   []*ProductResponseDTO   `json:"products"`
+	Facets     *ProductSearchFacetsDTO `json:"facets"`
+	NextCursor string                  `json:"nextCursor,omitempty"`
+}
+
+// ProductSearchFacetsDTO counts matching products per brand and attribute value, a facet's counts ignore
+// the facet's own filter, so clients can show other values to pick.
+type ProductSearchFacetsDTO struct {
+	Brands     []*FacetValueDTO            `json:"brands"`
+	Attributes map[string][]*FacetValueDTO `json:"attributes"` // by attribute code
+}
+
+type FacetValueDTO struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
@@ -0,0 +1,191 @@
+package domain
+
+// product and variant attributes are selected as json arrays of ProductAttribute objects, the definition of
+// attribute kv.key is the closest one in the primary category's tree, then in the other categories of product p.
+const (
+	sqlAttributeDefinition = `
+         LEFT JOIN LATERAL (SELECT a.name, a.type, a.unit
+                            FROM product_categories pc
+                                     JOIN category_relationships cr ON cr.descendant_id = pc.category_id
+                                     JOIN category_attributes a ON a.category_id = cr.ancestor_id AND a.code = kv.key
+                            WHERE pc.product_id = p.product_id
+                            ORDER BY pc.is_primary DESC, cr.level, pc.category_id
+                            LIMIT 1) d ON TRUE`
+	sqlAttributesJSONAgg = `json_agg(json_build_object('code', kv.key, 'name', COALESCE(d.name, kv.key), 'type', d.type,
+                                 'unit', d.unit, 'value', kv.value) ORDER BY kv.key)`
+
+	sqlProductAttributes = `(SELECT ` + sqlAttributesJSONAgg + `
+ FROM jsonb_each(p.attributes) kv` + sqlAttributeDefinition + `)`
+	sqlVariantAttributes = `(SELECT ` + sqlAttributesJSONAgg + `
+ FROM jsonb_each(v.attributes) kv` + sqlAttributeDefinition + `)`
+)
+
+const (
+	// sqlProductColumns selects product p with its category uuids(primary first), the primary category uuid,
+	// the breadcrumb from the root to the primary category, attributes and variants as json,
+	// variant, attribute and breadcrumb objects match json tags of ProductVariant, ProductAttribute and CategoryRef.
+	sqlProductColumns = `SELECT p.product_id, p.product_uuid, p.title, p.description, p.brand, p.status, p.created_at, p.updated_at,
+       COALESCE((SELECT json_agg(c.category_uuid ORDER BY pc.is_primary DESC, pc.created_at, pc.category_id)
+                 FROM product_categories pc
+                          JOIN categories c ON c.category_id = pc.category_id
+                 WHERE pc.product_id = p.product_id), '[]'),
+       COALESCE((SELECT c.category_uuid::text
+                 FROM product_categories pc
+                          JOIN categories c ON c.category_id = pc.category_id
+                 WHERE pc.product_id = p.product_id
+                   AND pc.is_primary), ''),
+       COALESCE((SELECT json_agg(json_build_object('categoryUuid', a.category_uuid, 'name', a.name, 'slug', a.slug)
+                                 ORDER BY cr.level DESC)
+                 FROM product_categories pc
+                          JOIN category_relationships cr ON cr.descendant_id = pc.category_id
+                          JOIN categories a ON a.category_id = cr.ancestor_id
+                 WHERE pc.product_id = p.product_id
+                   AND pc.is_primary), '[]'),
+       COALESCE(` + sqlProductAttributes + `, '[]'),
+       COALESCE((SELECT json_agg(json_build_object('variantUuid', v.variant_uuid, 'sku', v.sku, 'barcode', v.barcode,
+                                                   'options', v.options, 'price', v.price::text,
+                                                   'attributes', COALESCE(` + sqlVariantAttributes + `, '[]'),
+                                                   'createdAt', v.created_at, 'updatedAt', v.updated_at) ORDER BY v.variant_id)
+                 FROM product_variants v
+                 WHERE v.product_id = p.product_id), '[]')`
+
+	sqlSelectProductColumns = sqlProductColumns + `
+FROM products p`
+
+	sqlSelectProductByUUID = sqlSelectProductColumns + `
+WHERE p.product_uuid = $1`
+
+	// sqlSelectProducts filters by status, category uuid and brand, empty params don't filter,
+	// $4 is the product uuid of the previous page's last product, newest first.
+	// with $6 products listed under any descendant of the category match too, through the closure table.
+	sqlSelectProducts = sqlSelectProductColumns + `
+WHERE ($1 = '' OR p.status::text = $1)
+  AND ($2 = '' OR EXISTS (SELECT 1
+                          FROM product_categories pc
+                                   JOIN category_relationships cr ON cr.descendant_id = pc.category_id
+                                   JOIN categories c ON c.category_id = cr.ancestor_id
+                          WHERE pc.product_id = p.product_id
+                            AND c.category_uuid::text = $2
+                            AND (cr.level = 0 OR $6)))
+  AND ($3 = '' OR lower(p.brand) = lower($3))
+  AND ($4 = '' OR p.product_id < (SELECT product_id FROM products WHERE product_uuid::text = $4))
+ORDER BY p.product_id DESC
+LIMIT $5`
+
+	sqlInsertProduct = `INSERT INTO products (title, description, brand, status, attributes) VALUES ($1, $2, $3, $4, $5)
+RETURNING product_id, product_uuid`
+	sqlSelectProductIDForUpdate = `SELECT product_id FROM products WHERE product_uuid = $1 FOR UPDATE`
+	sqlUpdateProduct            = `UPDATE products
+SET title = $1, description = $2, brand = $3, status = $4, attributes = $5, updated_at = CURRENT_TIMESTAMP
+WHERE product_id = $6`
+
+	// deleted categories can't be assigned, existing assignments stay until the product is updated.
+	sqlSelectAssignableCategoryID = `SELECT category_id FROM categories WHERE category_uuid = $1 AND status <> 'deleted'`
+	sqlInsertProductCategory      = `INSERT INTO product_categories (product_id, category_id, is_primary) VALUES ($1, $2, $3)`
+	sqlDeleteProductCategories    = `DELETE FROM product_categories WHERE product_id = $1`
+
+	sqlInsertProductVariant = `INSERT INTO product_variants (product_id, sku, barcode, options, price, attributes)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING variant_uuid, created_at, updated_at`
+	sqlUpdateProductVariant = `UPDATE product_variants
+SET sku = $1, barcode = $2, options = $3, price = $4, attributes = $5, updated_at = CURRENT_TIMESTAMP
+WHERE product_id = $6 AND variant_uuid = $7
+RETURNING created_at, updated_at`
+
+	// sqlSelectProductAttributeDefinitions selects effective attributes of categories $1(json array of uuids, primary first),
+	// the closest definition of each code in the primary category's tree wins, then the other categories,
+	// same order as sqlAttributeDefinition.
+	sqlSelectProductAttributeDefinitions = `SELECT DISTINCT ON (a.code) a.attribute_id, c.category_uuid, a.code, a.name, a.type, a.unit,
+                                  a.enum_values, a.required, t.level > 0 AS inherited
+FROM jsonb_array_elements_text($1::jsonb) WITH ORDINALITY AS pc(category_uuid, position)
+         INNER JOIN categories pcat ON pcat.category_uuid = pc.category_uuid::uuid
+         INNER JOIN category_relationships t ON t.descendant_id = pcat.category_id
+         INNER JOIN category_attributes a ON a.category_id = t.ancestor_id
+         INNER JOIN categories c ON c.category_id = t.ancestor_id
+ORDER BY a.code, pc.position = 1 DESC, t.level, pcat.category_id`
+	sqlCountProductVariants = `SELECT COUNT(*) FROM product_variants WHERE product_id = $1`
+	// sqlTouchProduct bumps updated_at of a product when one of its variants changes.
+	sqlTouchProduct = `UPDATE products SET updated_at = CURRENT_TIMESTAMP WHERE product_id = $1`
+)
+
+const (
+	// sqlCandidateOffers selects variants of active products matching the search query, category and price filters,
+	// with the variant attributes merged over the product attributes and whether the brand filter matches.
+	//   - $1 is a websearch query over the product's search vector, rank is 0 without a query.
+	//   - $2 is a category uuid, products listed under any category of its subtree match.
+	//   - $3 is a json array of lowercase brands.
+	//   - $4 and $5 are the min and max price of a variant, inclusive.
+	// empty params don't filter.
+	sqlCandidateOffers = `candidates AS (SELECT p.product_id, p.brand, v.price, p.attributes || v.attributes AS attributes,
+                           CASE
+                               WHEN $1 = '' THEN 0
+                               ELSE ts_rank(p.search_vector, websearch_to_tsquery('english', $1))::numeric END AS rank,
+                           ($3 = '[]' OR lower(p.brand) IN (SELECT jsonb_array_elements_text($3::jsonb))) AS brand_matched
+                    FROM products p
+                             JOIN product_variants v ON v.product_id = p.product_id
+                    WHERE p.status = 'active'
+                      AND ($1 = '' OR p.search_vector @@ websearch_to_tsquery('english', $1))
+                      AND ($2 = '' OR EXISTS (SELECT 1
+                                              FROM product_categories pc
+                                                       JOIN category_relationships cr ON cr.descendant_id = pc.category_id
+                                                       JOIN categories c ON c.category_id = cr.ancestor_id
+                                              WHERE pc.product_id = p.product_id
+                                                AND c.category_uuid::text = $2))
+                      AND ($4 = '' OR v.price >= $4::numeric)
+                      AND ($5 = '' OR v.price <= $5::numeric))`
+
+	// sqlMatchedOffers selects candidate offers matching every filter,
+	// $6 is a jsonpath over the merged attributes with its variables in $7, '$' matches every variant.
+	sqlMatchedOffers = sqlCandidateOffers + `,
+     offers AS (SELECT product_id, price, rank
+                FROM candidates
+                WHERE brand_matched
+                  AND jsonb_path_exists(attributes, $6::jsonpath, $7::jsonb))`
+
+	// sqlSearchProducts selects a page of products with a matching variant, sorted by sort_key then newest first,
+	// sort $11 is 'relevance'(rank), 'price_asc'(negated lowest matching price), 'price_desc'(lowest matching price)
+	// or 'newest'(0). $8 and $9 are the product uuid and sort key of the previous page's last product, $10 is the limit.
+	sqlSearchProducts = `WITH ` + sqlMatchedOffers + `,
+     matched AS (SELECT product_id,
+                        CASE $11
+                            WHEN 'relevance' THEN MAX(rank)
+                            WHEN 'price_asc' THEN -MIN(price)
+                            WHEN 'price_desc' THEN MIN(price)
+                            ELSE 0 END AS sort_key
+                 FROM offers
+                 GROUP BY product_id)
+` + sqlProductColumns + `, m.sort_key::text
+FROM products p
+         JOIN matched m ON m.product_id = p.product_id
+WHERE ($8 = '' OR (m.sort_key, p.product_id) <
+                  ($9::numeric, (SELECT product_id FROM products WHERE product_uuid::text = $8)))
+ORDER BY m.sort_key DESC, p.product_id DESC
+LIMIT $10`
+
+	// sqlSelectProductSearchFacets counts matching products per brand and per scalar attribute value in one pass,
+	// $1 to $5 are the params of sqlCandidateOffers, $6 is a json array of {"code", "path"} attribute filters,
+	// a jsonpath per filter with the variables of every path in $7. failed lists the filters an offer doesn't match.
+	// A facet's counts ignore its own filter: brands are counted over offers matching every attribute filter,
+	// values of an attribute over offers matching the brands and every other attribute filter.
+	// Brands are grouped case-insensitive like the brand filter.
+	sqlSelectProductSearchFacets = `WITH ` + sqlCandidateOffers + `,
+     offers AS (SELECT c.product_id, c.brand, c.attributes, c.brand_matched,
+                       ARRAY(SELECT f.code
+                             FROM jsonb_to_recordset($6::jsonb) AS f(code TEXT, path TEXT)
+                             WHERE NOT jsonb_path_exists(c.attributes, f.path::jsonpath, $7::jsonb)) AS failed
+                FROM candidates c)
+SELECT 'brand', '', MIN(brand), COUNT(DISTINCT product_id)
+FROM offers
+WHERE brand <> ''
+  AND cardinality(failed) = 0
+GROUP BY lower(brand)
+UNION ALL
+SELECT 'attribute', kv.key, kv.value #>> '{}', COUNT(DISTINCT o.product_id)
+FROM offers o,
+     jsonb_each(o.attributes) kv
+WHERE jsonb_typeof(kv.value) IN ('string', 'number', 'boolean')
+  AND o.brand_matched
+  AND (cardinality(o.failed) = 0 OR o.failed = ARRAY [kv.key])
+GROUP BY kv.key, kv.value #>> '{}'
+ORDER BY 1, 2, 4 DESC, 3`
+)
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/ashtishad/ecommerce/lib"
+)
+
+type ProductRepository interface {
+	CreateProduct(ctx context.Context, product Product) (*Product, lib.APIError)
+	FindProductByUUID(ctx context.Context, productUUID string) (*Product, lib.APIError)
+	FindProducts(ctx context.Context, filter ProductFilter) ([]*Product, lib.APIError)
+	UpdateProduct(ctx context.Context, product Product) (*Product, lib.APIError)
+	AddProductVariant(ctx context.Context, productUUID string, variant ProductVariant) (*ProductVariant, lib.APIError)
+	UpdateProductVariant(ctx context.Context, productUUID string, variant ProductVariant) (*ProductVariant, lib.APIError)
+	FindProductAttributeDefinitions(ctx context.Context, categoryUUIDs []string) ([]CategoryAttribute, lib.APIError)
+	SearchProducts(ctx context.Context, filter ProductSearchFilter) ([]ProductSearchHit, lib.APIError)
+	FindProductSearchFacets(ctx context.Context, filter ProductSearchFilter) ([]FacetCount, lib.APIError)
+}
+
+// ProductFilter selects a page of products newest first, empty fields don't filter,
+// IncludeDescendants matches products of descendants of the category too,
+// AfterUUID is the last product of the previous page.
+type ProductFilter struct {
+	Status             string
+	CategoryUUID       string
+	IncludeDescendants bool
+	Brand              string
+	AfterUUID          string
+	Limit              int
+}
+
+// ProductSearchFilter searches active products with at least one variant matching every filter, empty fields don't filter.
+//   - Query is a websearch query, e.g. gaming phone -refurbished, titles rank higher than brands and descriptions.
+//   - CategoryUUID matches products listed anywhere in the category's subtree.
+//   - Brands are lowercase, MinPrice and MaxPrice are inclusive decimal strings.
+//   - AfterUUID and AfterKey are the product uuid and sort key of the previous page's last product.
+type ProductSearchFilter struct {
+	Query        string
+	CategoryUUID string
+	Brands       []string
+	MinPrice     string
+	MaxPrice     string
+	Attributes   []AttributeFilter
+	Sort         string
+	AfterUUID    string
+	AfterKey     string
+	Limit        int
+}
+
+// AttributeFilter matches a product or variant attribute equal to one of Values, or a number from Min to Max, inclusive.
+type AttributeFilter struct {
+	Code   string
+	Values []string
+	Min    string
+	Max    string
+}
+
+// ProductSearchHit is a search result, SortKey is the cursor key of the product under the search sort.
+type ProductSearchHit struct {
+	Product *Product
+	SortKey string
+}
+
+// FacetCount is the number of matching products per facet value, Facet is 'brand' or 'attribute',
+// Code is the attribute code of attribute facets.
+type FacetCount struct {
+	Facet string
+	Code  string
+	Value string
+	Count int
+}
@@ -0,0 +1,465 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/ashtishad/ecommerce/lib"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// unique constraints of product_variants, violations are reported as conflicts.
+const (
+	uniqueVariantSKU     = "uq_product_variant_sku"
+	uniqueVariantBarcode = "uq_product_variant_barcode"
+	uniqueVariantOptions = "uq_product_variant_options"
+)
+
+type ProductRepoDB struct {
+	db *sql.DB
+	l  *slog.Logger
+}
+
+func NewProductRepoDB(db *sql.DB, l *slog.Logger) *ProductRepoDB {
+	return &ProductRepoDB{db, l}
+}
+
+// CreateProduct inserts a product with its categories and variants in a transaction, returns the created product.
+//   - returns 400 if a category doesn't exist or is deleted.
+//   - returns 409 if a variant's sku or barcode already exists.
+func (d *ProductRepoDB) CreateProduct(ctx context.Context, product Product) (*Product, lib.APIError) {
+	attributes, err := attributeValues(product.Attributes)
+	if err != nil {
+		return nil, lib.NewInternalServerError("failed to encode product attributes", err)
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		d.l.Error(lib.ErrTxBegin, "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	defer rollBackOnError(tx, d.l, &err)
+
+	if err = tx.QueryRowContext(ctx, sqlInsertProduct, product.Title, product.Description, product.Brand, product.Status, attributes).
+		Scan(&product.ProductID, &product.ProductUUID); err != nil {
+		d.l.Error("failed to insert product", "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	if apiErr := d.insertProductCategories(ctx, tx, product.ProductID, product.CategoryUUIDs, product.PrimaryCategoryUUID); apiErr != nil {
+		err = apiErr // rollback
+		return nil, apiErr
+	}
+
+	for i := range product.Variants {
+		if _, apiErr := d.insertProductVariant(ctx, tx, product.ProductID, product.Variants[i]); apiErr != nil {
+			err = apiErr // rollback
+			return nil, apiErr
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return d.FindProductByUUID(ctx, product.ProductUUID)
+}
+
+// FindProductByUUID returns a product with its category uuids and variants, returns 404 if it doesn't exist.
+func (d *ProductRepoDB) FindProductByUUID(ctx context.Context, productUUID string) (*Product, lib.APIError) {
+	product, err := scanProduct(d.db.QueryRowContext(ctx, sqlSelectProductByUUID, productUUID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			d.l.Warn("product not found", "uuid", productUUID)
+			return nil, lib.NewError(http.StatusNotFound, ErrCodeProductNotFound, nil).Wrap(err)
+		}
+
+		d.l.Error("failed to select product", "uuid", productUUID, "err", err)
+
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return product, nil
+}
+
+// FindProducts returns a page of products newest first, with their category uuids and variants.
+//   - returns 404 if the filtered category doesn't exist.
+func (d *ProductRepoDB) FindProducts(ctx context.Context, filter ProductFilter) ([]*Product, lib.APIError) {
+	if filter.CategoryUUID != "" {
+		if apiErr := d.categoryExists(ctx, filter.CategoryUUID); apiErr != nil {
+			return nil, apiErr
+		}
+	}
+
+	rows, err := d.db.QueryContext(ctx, sqlSelectProducts, filter.Status, filter.CategoryUUID, filter.Brand, filter.AfterUUID, filter.Limit,
+		filter.IncludeDescendants)
+	if err != nil {
+		d.l.Error("failed to query products", "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	defer closeRows(rows, d.l)
+
+	products := make([]*Product, 0, filter.Limit)
+
+	for rows.Next() {
+		var p *Product
+		if p, err = scanProduct(rows); err != nil {
+			d.l.Error("failed to scan rows:", "err", err)
+			return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+		}
+
+		products = append(products, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		d.l.Error("unexpected error on scanning product rows", "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return products, nil
+}
+
+// UpdateProduct replaces title, description, brand, status, attributes and categories of a product in a transaction.
+//   - returns 404 if product doesn't exist.
+//   - returns 400 if a category doesn't exist or is deleted, or the product becomes active without variants.
+func (d *ProductRepoDB) UpdateProduct(ctx context.Context, product Product) (*Product, lib.APIError) {
+	attributes, err := attributeValues(product.Attributes)
+	if err != nil {
+		return nil, lib.NewInternalServerError("failed to encode product attributes", err)
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		d.l.Error(lib.ErrTxBegin, "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	defer rollBackOnError(tx, d.l, &err)
+
+	productID, apiErr := d.selectProductIDForUpdate(ctx, tx, product.ProductUUID)
+	if apiErr != nil {
+		err = apiErr // rollback
+		return nil, apiErr
+	}
+
+	if product.Status == ProductStatusActive {
+		var variants int
+		if err = tx.QueryRowContext(ctx, sqlCountProductVariants, productID).Scan(&variants); err != nil {
+			return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+		}
+
+		if variants == 0 {
+			apiErr = lib.NewError(http.StatusBadRequest, ErrCodeProductVariantsRequired, nil)
+			err = apiErr // rollback
+
+			return nil, apiErr
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, sqlUpdateProduct, product.Title, product.Description, product.Brand, product.Status, attributes,
+		productID); err != nil {
+		d.l.Error("failed to update product", "uuid", product.ProductUUID, "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	if _, err = tx.ExecContext(ctx, sqlDeleteProductCategories, productID); err != nil {
+		d.l.Error("failed to delete product categories", "uuid", product.ProductUUID, "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	if apiErr = d.insertProductCategories(ctx, tx, productID, product.CategoryUUIDs, product.PrimaryCategoryUUID); apiErr != nil {
+		err = apiErr // rollback
+		return nil, apiErr
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return d.FindProductByUUID(ctx, product.ProductUUID)
+}
+
+// AddProductVariant adds a variant to a product.
+//   - returns 404 if product doesn't exist.
+//   - returns 409 if the sku or barcode already exists, or the product has a variant with the same options.
+func (d *ProductRepoDB) AddProductVariant(ctx context.Context, productUUID string, variant ProductVariant) (*ProductVariant, lib.APIError) {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		d.l.Error(lib.ErrTxBegin, "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	defer rollBackOnError(tx, d.l, &err)
+
+	productID, apiErr := d.selectProductIDForUpdate(ctx, tx, productUUID)
+	if apiErr != nil {
+		err = apiErr // rollback
+		return nil, apiErr
+	}
+
+	added, apiErr := d.insertProductVariant(ctx, tx, productID, variant)
+	if apiErr != nil {
+		err = apiErr // rollback
+		return nil, apiErr
+	}
+
+	if _, err = tx.ExecContext(ctx, sqlTouchProduct, productID); err != nil {
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return added, nil
+}
+
+// UpdateProductVariant replaces sku, barcode, options and price of a product variant.
+//   - returns 404 if product or the variant doesn't exist.
+//   - returns 409 if the sku or barcode already exists, or the product has another variant with the same options.
+func (d *ProductRepoDB) UpdateProductVariant(ctx context.Context, productUUID string, variant ProductVariant) (*ProductVariant, lib.APIError) {
+	options, err := json.Marshal(variant.Options)
+	if err != nil {
+		return nil, lib.NewInternalServerError("failed to encode variant options", err)
+	}
+
+	attributes, err := attributeValues(variant.Attributes)
+	if err != nil {
+		return nil, lib.NewInternalServerError("failed to encode variant attributes", err)
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		d.l.Error(lib.ErrTxBegin, "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	defer rollBackOnError(tx, d.l, &err)
+
+	productID, apiErr := d.selectProductIDForUpdate(ctx, tx, productUUID)
+	if apiErr != nil {
+		err = apiErr // rollback
+		return nil, apiErr
+	}
+
+	err = tx.QueryRowContext(ctx, sqlUpdateProductVariant, variant.SKU, nullableString(variant.Barcode), options, variant.Price,
+		attributes, productID, variant.VariantUUID).Scan(&variant.CreatedAt, &variant.UpdatedAt)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		d.l.Warn("product variant not found", "product", productUUID, "variant", variant.VariantUUID)
+		return nil, lib.NewError(http.StatusNotFound, ErrCodeProductVariantNotFound, nil).Wrap(err)
+	case err != nil:
+		if apiErr = variantConflict(err, variant); apiErr != nil {
+			return nil, apiErr
+		}
+
+		d.l.Error("failed to update product variant", "variant", variant.VariantUUID, "err", err)
+
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	if _, err = tx.ExecContext(ctx, sqlTouchProduct, productID); err != nil {
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return &variant, nil
+}
+
+// FindProductAttributeDefinitions returns effective attributes of the given categories, primary category first,
+// the closest definition of each code in the primary category's tree wins, then the other categories.
+// Categories that don't exist are skipped, assigning them fails later.
+func (d *ProductRepoDB) FindProductAttributeDefinitions(ctx context.Context, categoryUUIDs []string) ([]CategoryAttribute, lib.APIError) {
+	uuids, err := json.Marshal(categoryUUIDs)
+	if err != nil {
+		return nil, lib.NewInternalServerError("failed to encode category uuids", err)
+	}
+
+	rows, err := d.db.QueryContext(ctx, sqlSelectProductAttributeDefinitions, uuids)
+	if err != nil {
+		d.l.Error("failed to query product attribute definitions", "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	defer closeRows(rows, d.l)
+
+	attributes := make([]CategoryAttribute, 0)
+
+	for rows.Next() {
+		var (
+			a          CategoryAttribute
+			enumValues []byte
+		)
+
+		if err = rows.Scan(&a.AttributeID, &a.CategoryUUID, &a.Code, &a.Name, &a.Type, &a.Unit, &enumValues, &a.Required, &a.Inherited); err != nil {
+			d.l.Error("failed to scan rows:", "err", err)
+			return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+		}
+
+		if err = json.Unmarshal(enumValues, &a.Values); err != nil {
+			d.l.Error("failed to decode attribute enum values", "code", a.Code, "err", err)
+			return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+		}
+
+		attributes = append(attributes, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		d.l.Error("unexpected error on scanning product attribute definition rows", "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return attributes, nil
+}
+
+// categoryExists returns 404 if category doesn't exist.
+func (d *ProductRepoDB) categoryExists(ctx context.Context, categoryUUID string) lib.APIError {
+	var categoryID int
+	if err := d.db.QueryRowContext(ctx, sqlSelectCategoryID, categoryUUID).Scan(&categoryID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			d.l.Warn("category not found", "uuid", categoryUUID)
+			return lib.NewError(http.StatusNotFound, ErrCodeCategoryNotFound, nil).Wrap(err)
+		}
+
+		d.l.Error(lib.ErrScanningRows, "err", err.Error())
+
+		return lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return nil
+}
+
+func (d *ProductRepoDB) selectProductIDForUpdate(ctx context.Context, tx *sql.Tx, productUUID string) (int, lib.APIError) {
+	var productID int
+	if err := tx.QueryRowContext(ctx, sqlSelectProductIDForUpdate, productUUID).Scan(&productID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			d.l.Warn("product not found", "uuid", productUUID)
+			return 0, lib.NewError(http.StatusNotFound, ErrCodeProductNotFound, nil).Wrap(err)
+		}
+
+		d.l.Error(lib.ErrScanningRows, "err", err.Error())
+
+		return 0, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return productID, nil
+}
+
+// insertProductCategories assigns categories to a product in the given order, flags the primary category,
+// returns 400 if a category doesn't exist or is deleted.
+func (d *ProductRepoDB) insertProductCategories(ctx context.Context, tx *sql.Tx, productID int, categoryUUIDs []string,
+	primaryUUID string) lib.APIError {
+	for _, categoryUUID := range categoryUUIDs {
+		var categoryID int
+		if err := tx.QueryRowContext(ctx, sqlSelectAssignableCategoryID, categoryUUID).Scan(&categoryID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				d.l.Warn("product category not found", "uuid", categoryUUID)
+				return lib.NewError(http.StatusBadRequest, ErrCodeProductCategoryNotFound, lib.Args{"uuid": categoryUUID}).Wrap(err)
+			}
+
+			return lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, sqlInsertProductCategory, productID, categoryID,
+			strings.EqualFold(categoryUUID, primaryUUID)); err != nil {
+			d.l.Error("failed to insert product category", "category", categoryUUID, "err", err)
+			return lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+		}
+	}
+
+	return nil
+}
+
+func (d *ProductRepoDB) insertProductVariant(ctx context.Context, tx *sql.Tx, productID int, variant ProductVariant) (*ProductVariant, lib.APIError) {
+	options, err := json.Marshal(variant.Options)
+	if err != nil {
+		return nil, lib.NewInternalServerError("failed to encode variant options", err)
+	}
+
+	attributes, err := attributeValues(variant.Attributes)
+	if err != nil {
+		return nil, lib.NewInternalServerError("failed to encode variant attributes", err)
+	}
+
+	if err = tx.QueryRowContext(ctx, sqlInsertProductVariant, productID, variant.SKU, nullableString(variant.Barcode), options, variant.Price,
+		attributes).
+		Scan(&variant.VariantUUID, &variant.CreatedAt, &variant.UpdatedAt); err != nil {
+		if apiErr := variantConflict(err, variant); apiErr != nil {
+			return nil, apiErr
+		}
+
+		d.l.Error("failed to insert product variant", "sku", variant.SKU, "err", err)
+
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return &variant, nil
+}
+
+// variantConflict maps unique violations of product_variants to 409 errors, nil for other errors.
+func variantConflict(err error, variant ProductVariant) lib.APIError {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != pgUniqueViolation {
+		return nil
+	}
+
+	switch pgErr.ConstraintName {
+	case uniqueVariantSKU:
+		return lib.NewError(http.StatusConflict, ErrCodeProductSKUExists, lib.Args{"sku": variant.SKU}).Wrap(err)
+	case uniqueVariantBarcode:
+		return lib.NewError(http.StatusConflict, ErrCodeProductBarcodeExists, lib.Args{"barcode": variant.Barcode}).Wrap(err)
+	case uniqueVariantOptions:
+		return lib.NewError(http.StatusConflict, ErrCodeProductVariantOptionsExist, nil).Wrap(err)
+	}
+
+	return nil
+}
+
+// scanProduct scans columns of sqlProductColumns, extra destinations scan the columns selected after them.
+func scanProduct(row rowScanner, extra ...any) (*Product, error) {
+	var (
+		p                                            Product
+		categories, breadcrumb, attributes, variants []byte
+	)
+
+	dest := []any{&p.ProductID, &p.ProductUUID, &p.Title, &p.Description, &p.Brand, &p.Status, &p.CreatedAt, &p.UpdatedAt,
+		&categories, &p.PrimaryCategoryUUID, &breadcrumb, &attributes, &variants}
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(attributes, &p.Attributes); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(categories, &p.CategoryUUIDs); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(breadcrumb, &p.Breadcrumb); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(variants, &p.Variants); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// nullableString stores empty optional strings as NULL, so unique constraints ignore them.
+func nullableString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
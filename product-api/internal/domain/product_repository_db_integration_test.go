@@ -0,0 +1,158 @@
+//go:build integration
+
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ashtishad/ecommerce/db/conn"
+	"github.com/stretchr/testify/require"
+)
+
+// TestProductIntegration creates, lists and updates a product with variants against a migrated postgres database,
+// checks the json built by product queries decodes, unique constraints become conflicts and search filters variants.
+func TestProductIntegration(t *testing.T) {
+	if os.Getenv("DB_ADDR") == "" {
+		t.Skip("DB_ADDR is not set, skipping integration test")
+	}
+
+	db := conn.GetDBClient(testLogger)
+	t.Cleanup(func() { _ = db.Close() }) // after the fixture cleanup, cleanups run last in first out
+
+	repo := NewProductRepoDB(db, testLogger)
+	ctx := context.Background()
+	suffix := fmt.Sprint(time.Now().UnixNano())
+	category, gaming, created := createProductFixture(t, db, suffix)
+
+	require.Equal(t, []string{gaming.CategoryUUID, category.CategoryUUID}, created.CategoryUUIDs)
+	require.Equal(t, gaming.CategoryUUID, created.PrimaryCategoryUUID)
+	require.Len(t, created.Breadcrumb, 2)
+	require.Equal(t, category.CategoryUUID, created.Breadcrumb[0].CategoryUUID)
+	require.Equal(t, gaming.CategoryUUID, created.Breadcrumb[1].CategoryUUID)
+	require.Equal(t, []ProductAttribute{{Code: "ram", Name: "RAM", Type: AttributeTypeNumber, Unit: "GB", Value: float64(8)}},
+		created.Attributes)
+	require.Equal(t, float64(12), created.Variants[0].Attributes[0].Value)
+
+	defs, apiErr := repo.FindProductAttributeDefinitions(ctx, []string{gaming.CategoryUUID})
+	require.Nil(t, apiErr)
+	require.Len(t, defs, 1)
+	require.True(t, defs[0].Inherited)
+	require.Len(t, created.Variants, 2)
+	require.Equal(t, "499.90", created.Variants[0].Price)
+	require.False(t, created.Variants[0].CreatedAt.IsZero())
+
+	t.Run("List by category and brand", func(t *testing.T) {
+		products, apiErr := repo.FindProducts(ctx, ProductFilter{CategoryUUID: category.CategoryUUID, Brand: "brand" + suffix, Limit: 10})
+		require.Nil(t, apiErr)
+		require.Len(t, products, 1)
+		require.Equal(t, created.ProductUUID, products[0].ProductUUID)
+	})
+
+	t.Run("List by category subtree", func(t *testing.T) {
+		updated, apiErr := repo.UpdateProduct(ctx, Product{
+			ProductUUID: created.ProductUUID, Title: created.Title, Brand: created.Brand, Status: created.Status,
+			CategoryUUIDs: []string{gaming.CategoryUUID}, PrimaryCategoryUUID: gaming.CategoryUUID,
+		})
+		require.Nil(t, apiErr)
+		require.Equal(t, []string{gaming.CategoryUUID}, updated.CategoryUUIDs)
+
+		products, apiErr := repo.FindProducts(ctx, ProductFilter{CategoryUUID: category.CategoryUUID, Limit: 10})
+		require.Nil(t, apiErr)
+		require.Empty(t, products)
+
+		products, apiErr = repo.FindProducts(ctx, ProductFilter{CategoryUUID: category.CategoryUUID, IncludeDescendants: true, Limit: 10})
+		require.Nil(t, apiErr)
+		require.Len(t, products, 1)
+	})
+
+	t.Run("Same options are a conflict", func(t *testing.T) {
+		_, apiErr := repo.AddProductVariant(ctx, created.ProductUUID, ProductVariant{
+			SKU: "C-" + suffix, Options: map[string]string{"storage": "128GB"}, Price: "1",
+		})
+		require.Equal(t, ErrCodeProductVariantOptionsExist, apiErr.ErrorCode())
+	})
+
+	t.Run("Existing sku is a conflict", func(t *testing.T) {
+		variant := created.Variants[1]
+		variant.SKU = created.Variants[0].SKU
+
+		_, apiErr := repo.UpdateProductVariant(ctx, created.ProductUUID, variant)
+		require.Equal(t, ErrCodeProductSKUExists, apiErr.ErrorCode())
+	})
+
+	t.Run("Search by text, subtree and variant attributes", func(t *testing.T) {
+		filter := ProductSearchFilter{
+			Query: "phone " + suffix, CategoryUUID: category.CategoryUUID, Attributes: []AttributeFilter{{Code: "ram", Min: "10"}},
+			Sort: ProductSortPriceAsc, Limit: 10,
+		}
+
+		hits, apiErr := repo.SearchProducts(ctx, filter)
+		require.Nil(t, apiErr)
+		require.Len(t, hits, 1)
+		require.Equal(t, "-499.90", hits[0].SortKey) // only the variant with 12 GB ram matches
+
+		facets, apiErr := repo.FindProductSearchFacets(ctx, filter)
+		require.Nil(t, apiErr)
+		require.Contains(t, facets, FacetCount{Facet: FacetBrand, Value: "Brand" + suffix, Count: 1})
+		// the ram facet ignores the ram filter, the 8 GB variant is counted
+		require.Contains(t, facets, FacetCount{Facet: FacetAttribute, Code: "ram", Value: "8", Count: 1})
+
+		filter.Attributes = []AttributeFilter{{Code: "ram", Values: []string{"4"}}}
+		hits, apiErr = repo.SearchProducts(ctx, filter)
+		require.Nil(t, apiErr)
+		require.Empty(t, hits)
+	})
+}
+
+// createProductFixture creates an active product with two variants listed in a category and its subcategory,
+// the category defines a ram attribute. All of them are deleted when the test ends.
+func createProductFixture(t *testing.T, db *sql.DB, suffix string) (*Category, *Category, *Product) {
+	t.Helper()
+
+	categoryRepo := NewCategoryRepoDB(db, testLogger)
+	ctx := context.Background()
+
+	category, apiErr := categoryRepo.CreateCategory(ctx, Category{Name: "products" + suffix, Status: CategoryStatusActive})
+	require.Nil(t, apiErr)
+
+	gaming, apiErr := categoryRepo.CreateSubCategory(ctx, Category{Name: "Gaming", Status: CategoryStatusActive}, category.CategoryUUID)
+	require.Nil(t, apiErr)
+
+	_, apiErr = NewCategoryAttributeRepoDB(db, testLogger).SaveCategoryAttribute(ctx, category.CategoryUUID, CategoryAttribute{
+		Code: "ram", Name: "RAM", Type: AttributeTypeNumber, Unit: sql.NullString{String: "GB", Valid: true},
+	})
+	require.Nil(t, apiErr)
+
+	created, apiErr := NewProductRepoDB(db, testLogger).CreateProduct(ctx, Product{
+		Title:               "Phone " + suffix,
+		Brand:               "Brand" + suffix,
+		Status:              ProductStatusActive,
+		CategoryUUIDs:       []string{category.CategoryUUID, gaming.CategoryUUID},
+		PrimaryCategoryUUID: gaming.CategoryUUID,
+		Attributes:          []ProductAttribute{{Code: "ram", Value: 8}},
+		Variants: []ProductVariant{
+			{SKU: "A-" + suffix, Options: map[string]string{"storage": "128GB"}, Price: "499.9",
+				Attributes: []ProductAttribute{{Code: "ram", Value: 12}}},
+			{SKU: "B-" + suffix, Options: map[string]string{"storage": "256GB"}, Price: "599"},
+		},
+	})
+	require.Nil(t, apiErr)
+
+	t.Cleanup(func() {
+		_, _ = db.Exec(`DELETE FROM products WHERE product_id = $1`, created.ProductID)
+
+		for _, id := range []int{gaming.CategoryID, category.CategoryID} {
+			_, _ = db.Exec(`DELETE FROM category_attributes WHERE category_id = $1`, id)
+			_, _ = db.Exec(`DELETE FROM category_relationships WHERE descendant_id = $1`, id)
+			_, _ = db.Exec(`DELETE FROM category_history WHERE category_id = $1`, id)
+			_, _ = db.Exec(`DELETE FROM categories WHERE category_id = $1`, id)
+		}
+	})
+
+	return category, gaming, created
+}
@@ -0,0 +1,297 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testProductUUID  = "5b0e9a4c-2f1d-4c8e-9a7b-3d6f1e2c4b8a"
+	testCategoryUUID = "bd11d903-7549-42b2-bea6-dd8a7cb8821e"
+)
+
+func productRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"product_id", "product_uuid", "title", "description", "brand", "status", "created_at", "updated_at",
+		"categories", "primary_category_uuid", "breadcrumb", "attributes", "variants"})
+}
+
+func mockProductObj() Product {
+	return Product{
+		Title:               "Galaxy S24",
+		Brand:               "Samsung",
+		Status:              ProductStatusActive,
+		CategoryUUIDs:       []string{testCategoryUUID},
+		PrimaryCategoryUUID: testCategoryUUID,
+		Attributes:          []ProductAttribute{{Code: "ram", Value: 8}},
+		Variants: []ProductVariant{
+			{SKU: "S24-BLK-128", Options: map[string]string{"color": "black", "storage": "128GB"}, Price: "799.99"},
+		},
+	}
+}
+
+// TestCreateProduct covers a created product, a missing category and an existing sku, both roll back.
+func TestCreateProduct(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewProductRepoDB(db, testLogger)
+	p := mockProductObj()
+	now := time.Now()
+	options := []byte(`{"color":"black","storage":"128GB"}`)
+	attributes := []byte(`{"ram":8}`)
+
+	expectProductInsert := func() {
+		mock.ExpectBegin()
+		expectQuery(mock, sqlInsertProduct).WithArgs(p.Title, p.Description, p.Brand, p.Status, attributes).
+			WillReturnRows(sqlmock.NewRows([]string{"product_id", "product_uuid"}).AddRow(7, testProductUUID))
+		expectQuery(mock, sqlSelectAssignableCategoryID).WithArgs(testCategoryUUID).
+			WillReturnRows(sqlmock.NewRows([]string{"category_id"}).AddRow(3))
+		expectExec(mock, sqlInsertProductCategory).WithArgs(7, 3, true).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	t.Run("Product created", func(t *testing.T) {
+		expectProductInsert()
+		expectQuery(mock, sqlInsertProductVariant).WithArgs(7, "S24-BLK-128", sql.NullString{}, options, "799.99", []byte(`{}`)).
+			WillReturnRows(sqlmock.NewRows([]string{"variant_uuid", "created_at", "updated_at"}).AddRow("variant-uuid", now, now))
+		mock.ExpectCommit()
+		expectQuery(mock, sqlSelectProductByUUID).WithArgs(testProductUUID).WillReturnRows(productRows().
+			AddRow(7, testProductUUID, p.Title, "", p.Brand, p.Status, now, now, []byte(`["`+testCategoryUUID+`"]`), testCategoryUUID,
+				[]byte(`[{"categoryUuid":"root-uuid","name":"Electronics","slug":"electronics"},`+
+					`{"categoryUuid":"`+testCategoryUUID+`","name":"Smartphones","slug":"smartphones"}]`),
+				[]byte(`[{"code":"ram","name":"RAM","type":"number","unit":"GB","value":8}]`),
+				[]byte(`[{"variantUuid":"variant-uuid","sku":"S24-BLK-128","barcode":null,"options":{"color":"black","storage":"128GB"},`+
+					`"price":"799.99","attributes":[{"code":"anc","name":"anc","type":null,"unit":null,"value":true}],"createdAt":"2024-03-01T10:00:00.123456+00:00","updatedAt":"2024-03-01T10:00:00.123456+00:00"}]`)))
+
+		created, apiErr := repo.CreateProduct(context.Background(), p)
+		require.Nil(t, apiErr)
+		require.Equal(t, testProductUUID, created.ProductUUID)
+		require.Equal(t, []string{testCategoryUUID}, created.CategoryUUIDs)
+		require.Equal(t, testCategoryUUID, created.PrimaryCategoryUUID)
+		require.Equal(t, []ProductAttribute{{Code: "ram", Name: "RAM", Type: AttributeTypeNumber, Unit: "GB", Value: float64(8)}},
+			created.Attributes)
+		require.Equal(t, []ProductAttribute{{Code: "anc", Name: "anc", Value: true}}, created.Variants[0].Attributes)
+		require.Equal(t, []CategoryRef{
+			{CategoryUUID: "root-uuid", Name: "Electronics", Slug: "electronics"},
+			{CategoryUUID: testCategoryUUID, Name: "Smartphones", Slug: "smartphones"},
+		}, created.Breadcrumb)
+		require.Len(t, created.Variants, 1)
+		require.Equal(t, "variant-uuid", created.Variants[0].VariantUUID)
+		require.Equal(t, "128GB", created.Variants[0].Options["storage"])
+		require.Empty(t, created.Variants[0].Barcode)
+	})
+
+	t.Run("Category not found", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectQuery(mock, sqlInsertProduct).WithArgs(p.Title, p.Description, p.Brand, p.Status, attributes).
+			WillReturnRows(sqlmock.NewRows([]string{"product_id", "product_uuid"}).AddRow(8, testProductUUID))
+		expectQuery(mock, sqlSelectAssignableCategoryID).WithArgs(testCategoryUUID).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		created, apiErr := repo.CreateProduct(context.Background(), p)
+		require.Nil(t, created)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Equal(t, ErrCodeProductCategoryNotFound, apiErr.ErrorCode())
+	})
+
+	t.Run("SKU exists", func(t *testing.T) {
+		expectProductInsert()
+		expectQuery(mock, sqlInsertProductVariant).WithArgs(7, "S24-BLK-128", sql.NullString{}, options, "799.99", []byte(`{}`)).
+			WillReturnError(&pgconn.PgError{Code: pgUniqueViolation, ConstraintName: uniqueVariantSKU})
+		mock.ExpectRollback()
+
+		_, apiErr := repo.CreateProduct(context.Background(), p)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+		require.Equal(t, ErrCodeProductSKUExists, apiErr.ErrorCode())
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestUpdateProduct covers replacing categories, activating a product without variants and product not found.
+func TestUpdateProduct(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewProductRepoDB(db, testLogger)
+	p := mockProductObj()
+	p.ProductUUID = testProductUUID
+	now := time.Now()
+
+	idRows := func() *sqlmock.Rows { return sqlmock.NewRows([]string{"product_id"}).AddRow(7) }
+
+	t.Run("Product updated", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectQuery(mock, sqlSelectProductIDForUpdate).WithArgs(testProductUUID).WillReturnRows(idRows())
+		expectQuery(mock, sqlCountProductVariants).WithArgs(7).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		expectExec(mock, sqlUpdateProduct).WithArgs(p.Title, p.Description, p.Brand, p.Status, []byte(`{"ram":8}`), 7).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectExec(mock, sqlDeleteProductCategories).WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 2))
+		expectQuery(mock, sqlSelectAssignableCategoryID).WithArgs(testCategoryUUID).
+			WillReturnRows(sqlmock.NewRows([]string{"category_id"}).AddRow(3))
+		expectExec(mock, sqlInsertProductCategory).WithArgs(7, 3, true).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		expectQuery(mock, sqlSelectProductByUUID).WithArgs(testProductUUID).WillReturnRows(productRows().
+			AddRow(7, testProductUUID, p.Title, "", p.Brand, p.Status, now, now, []byte(`["`+testCategoryUUID+`"]`), testCategoryUUID,
+				[]byte(`[]`), []byte(`[]`), []byte(`[]`)))
+
+		updated, apiErr := repo.UpdateProduct(context.Background(), p)
+		require.Nil(t, apiErr)
+		require.Equal(t, p.Title, updated.Title)
+	})
+
+	t.Run("Active product without variants", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectQuery(mock, sqlSelectProductIDForUpdate).WithArgs(testProductUUID).WillReturnRows(idRows())
+		expectQuery(mock, sqlCountProductVariants).WithArgs(7).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectRollback()
+
+		_, apiErr := repo.UpdateProduct(context.Background(), p)
+		require.Equal(t, ErrCodeProductVariantsRequired, apiErr.ErrorCode())
+	})
+
+	t.Run("Product not found", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectQuery(mock, sqlSelectProductIDForUpdate).WithArgs(testProductUUID).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		_, apiErr := repo.UpdateProduct(context.Background(), p)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+		require.Equal(t, ErrCodeProductNotFound, apiErr.ErrorCode())
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestUpdateProductVariant covers an updated variant, a missing variant and options taken by another variant.
+func TestUpdateProductVariant(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewProductRepoDB(db, testLogger)
+	now := time.Now()
+	variant := ProductVariant{VariantUUID: "variant-uuid", SKU: "S24-BLK-256", Barcode: "4006381333931",
+		Options: map[string]string{"storage": "256GB"}, Price: "899.00"}
+	args := []driver.Value{"S24-BLK-256", sql.NullString{String: "4006381333931", Valid: true}, []byte(`{"storage":"256GB"}`), "899.00", []byte(`{}`),
+		7, "variant-uuid"}
+
+	idRows := func() *sqlmock.Rows { return sqlmock.NewRows([]string{"product_id"}).AddRow(7) }
+
+	t.Run("Variant updated", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectQuery(mock, sqlSelectProductIDForUpdate).WithArgs(testProductUUID).WillReturnRows(idRows())
+		expectQuery(mock, sqlUpdateProductVariant).WithArgs(args...).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(now, now))
+		expectExec(mock, sqlTouchProduct).WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		updated, apiErr := repo.UpdateProductVariant(context.Background(), testProductUUID, variant)
+		require.Nil(t, apiErr)
+		require.Equal(t, now, updated.UpdatedAt)
+	})
+
+	t.Run("Variant not found", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectQuery(mock, sqlSelectProductIDForUpdate).WithArgs(testProductUUID).WillReturnRows(idRows())
+		expectQuery(mock, sqlUpdateProductVariant).WithArgs(args...).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		_, apiErr := repo.UpdateProductVariant(context.Background(), testProductUUID, variant)
+		require.Equal(t, ErrCodeProductVariantNotFound, apiErr.ErrorCode())
+	})
+
+	t.Run("Options exist", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectQuery(mock, sqlSelectProductIDForUpdate).WithArgs(testProductUUID).WillReturnRows(idRows())
+		expectQuery(mock, sqlUpdateProductVariant).WithArgs(args...).
+			WillReturnError(&pgconn.PgError{Code: pgUniqueViolation, ConstraintName: uniqueVariantOptions})
+		mock.ExpectRollback()
+
+		_, apiErr := repo.UpdateProductVariant(context.Background(), testProductUUID, variant)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+		require.Equal(t, ErrCodeProductVariantOptionsExist, apiErr.ErrorCode())
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestFindProducts makes sure filters and the cursor are passed in order, empty ones don't filter,
+// and a filtered category must exist.
+func TestFindProducts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewProductRepoDB(db, testLogger)
+	now := time.Now()
+
+	t.Run("Products filtered", func(t *testing.T) {
+		expectQuery(mock, sqlSelectProducts).WithArgs(ProductStatusActive, "", "samsung", testProductUUID, 2, false).
+			WillReturnRows(productRows().
+				AddRow(6, "a-uuid", "Galaxy A55", "", "Samsung", ProductStatusActive, now, now, []byte(`[]`), "", []byte(`[]`), []byte(`[]`), []byte(`[]`)).
+				AddRow(5, "b-uuid", "Galaxy A35", "", "Samsung", ProductStatusActive, now, now, []byte(`[]`), "", []byte(`[]`), []byte(`[]`), []byte(`[]`)))
+
+		products, apiErr := repo.FindProducts(context.Background(), ProductFilter{
+			Status: ProductStatusActive, Brand: "samsung", AfterUUID: testProductUUID, Limit: 2,
+		})
+		require.Nil(t, apiErr)
+		require.Len(t, products, 2)
+		require.Equal(t, "Galaxy A35", products[1].Title)
+		require.Empty(t, products[1].Variants)
+	})
+
+	t.Run("Products of a category subtree", func(t *testing.T) {
+		expectQuery(mock, sqlSelectCategoryID).WithArgs(testCategoryUUID).WillReturnRows(sqlmock.NewRows([]string{"category_id"}).AddRow(3))
+		expectQuery(mock, sqlSelectProducts).WithArgs("", testCategoryUUID, "", "", 21, true).WillReturnRows(productRows())
+
+		products, apiErr := repo.FindProducts(context.Background(), ProductFilter{
+			CategoryUUID: testCategoryUUID, IncludeDescendants: true, Limit: 21,
+		})
+		require.Nil(t, apiErr)
+		require.Empty(t, products)
+	})
+
+	t.Run("Category not found", func(t *testing.T) {
+		expectQuery(mock, sqlSelectCategoryID).WithArgs(testCategoryUUID).WillReturnError(sql.ErrNoRows)
+
+		_, apiErr := repo.FindProducts(context.Background(), ProductFilter{CategoryUUID: testCategoryUUID, Limit: 21})
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+		require.Equal(t, ErrCodeCategoryNotFound, apiErr.ErrorCode())
+	})
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestFindProductAttributeDefinitions makes sure category uuids are passed as a json array and enum values are decoded.
+func TestFindProductAttributeDefinitions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewProductRepoDB(db, testLogger)
+
+	expectQuery(mock, sqlSelectProductAttributeDefinitions).WithArgs([]byte(`["` + testCategoryUUID + `","gaming-uuid"]`)).
+		WillReturnRows(sqlmock.NewRows([]string{"attribute_id", "category_uuid", "code", "name", "type", "unit", "enum_values", "required",
+			"inherited"}).
+			AddRow(1, "root-uuid", "color", "Color", AttributeTypeEnum, nil, []byte(`["black","blue"]`), false, true).
+			AddRow(2, testCategoryUUID, "ram", "RAM", AttributeTypeNumber, "GB", []byte(`[]`), true, false))
+
+	defs, apiErr := repo.FindProductAttributeDefinitions(context.Background(), []string{testCategoryUUID, "gaming-uuid"})
+	require.Nil(t, apiErr)
+	require.Len(t, defs, 2)
+	require.Equal(t, []string{"black", "blue"}, defs[0].Values)
+	require.False(t, defs[0].Unit.Valid)
+	require.Equal(t, "GB", defs[1].Unit.String)
+	require.True(t, defs[1].Required)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,234 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ashtishad/ecommerce/lib"
+)
+
+// SearchProducts returns a page of active products with a variant matching every filter, in the filter's sort order.
+//   - returns 404 if the filtered category doesn't exist.
+func (d *ProductRepoDB) SearchProducts(ctx context.Context, filter ProductSearchFilter) ([]ProductSearchHit, lib.APIError) {
+	args, apiErr := d.searchArgs(ctx, filter)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	path, vars, err := attributeFilterPath(filter.Attributes)
+	if err != nil {
+		return nil, lib.NewInternalServerError("failed to encode attribute filters", err)
+	}
+
+	rows, err := d.db.QueryContext(ctx, sqlSearchProducts, append(args, path, vars, filter.AfterUUID, filter.AfterKey, filter.Limit, filter.Sort)...)
+	if err != nil {
+		d.l.Error("failed to search products", "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	defer closeRows(rows, d.l)
+
+	hits := make([]ProductSearchHit, 0, filter.Limit)
+
+	for rows.Next() {
+		var hit ProductSearchHit
+		if hit.Product, err = scanProduct(rows, &hit.SortKey); err != nil {
+			d.l.Error("failed to scan rows:", "err", err)
+			return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+		}
+
+		hits = append(hits, hit)
+	}
+
+	if err = rows.Err(); err != nil {
+		d.l.Error("unexpected error on scanning product search rows", "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return hits, nil
+}
+
+// FindProductSearchFacets counts products matching the filter per brand and per scalar attribute value in one query,
+// counts of a filtered facet ignore its own filter, sort and cursor fields are ignored.
+//   - returns 404 if the filtered category doesn't exist.
+func (d *ProductRepoDB) FindProductSearchFacets(ctx context.Context, filter ProductSearchFilter) ([]FacetCount, lib.APIError) {
+	args, apiErr := d.searchArgs(ctx, filter)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	filters, vars, err := attributeFacetFilters(filter.Attributes)
+	if err != nil {
+		return nil, lib.NewInternalServerError("failed to encode attribute filters", err)
+	}
+
+	rows, err := d.db.QueryContext(ctx, sqlSelectProductSearchFacets, append(args, filters, vars)...)
+	if err != nil {
+		d.l.Error("failed to query product search facets", "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	defer closeRows(rows, d.l)
+
+	facets := make([]FacetCount, 0)
+
+	for rows.Next() {
+		var f FacetCount
+		if err = rows.Scan(&f.Facet, &f.Code, &f.Value, &f.Count); err != nil {
+			d.l.Error("failed to scan rows:", "err", err)
+			return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+		}
+
+		facets = append(facets, f)
+	}
+
+	if err = rows.Err(); err != nil {
+		d.l.Error("unexpected error on scanning product search facet rows", "err", err)
+		return nil, lib.NewInternalServerError(lib.UnexpectedDatabaseErr, err)
+	}
+
+	return facets, nil
+}
+
+// searchArgs returns params $1 to $5 of sqlCandidateOffers, checks the filtered category exists.
+func (d *ProductRepoDB) searchArgs(ctx context.Context, filter ProductSearchFilter) ([]any, lib.APIError) {
+	if filter.CategoryUUID != "" {
+		if apiErr := d.categoryExists(ctx, filter.CategoryUUID); apiErr != nil {
+			return nil, apiErr
+		}
+	}
+
+	brands := filter.Brands
+	if brands == nil {
+		brands = []string{}
+	}
+
+	brandsJSON, err := json.Marshal(brands)
+	if err != nil {
+		return nil, lib.NewInternalServerError("failed to encode brands", err)
+	}
+
+	return []any{filter.Query, filter.CategoryUUID, string(brandsJSON), filter.MinPrice, filter.MaxPrice}, nil
+}
+
+// attributeFilterPath builds a jsonpath matching attributes that pass every filter, with values passed as variables,
+// e.g. $ ? (@.color == $v0 || @.color == $v1) ? (@.ram >= $v2). A value matches its string form, and its number
+// or bool form when it parses as one, as attribute values are typed. Codes are validated attribute codes.
+func attributeFilterPath(filters []AttributeFilter) (string, string, error) {
+	conditions, vars := attributeConditions(filters)
+
+	path := "$"
+	for _, c := range conditions {
+		path += " ? (" + c.expr + ")"
+	}
+
+	varsJSON, err := json.Marshal(vars)
+	if err != nil {
+		return "", "", err
+	}
+
+	return path, string(varsJSON), nil
+}
+
+// attributeFacetFilters returns a json array of {"code", "path"} with a jsonpath per filter, e.g.
+// [{"code": "color", "path": "$ ? (@.color == $v0)"}], and the variables of every path.
+func attributeFacetFilters(filters []AttributeFilter) (string, string, error) {
+	conditions, vars := attributeConditions(filters)
+
+	type facetFilter struct {
+		Code string `json:"code"`
+		Path string `json:"path"`
+	}
+
+	facetFilters := make([]facetFilter, 0, len(conditions))
+	for _, c := range conditions {
+		facetFilters = append(facetFilters, facetFilter{Code: c.code, Path: "$ ? (" + c.expr + ")"})
+	}
+
+	filtersJSON, err := json.Marshal(facetFilters)
+	if err != nil {
+		return "", "", err
+	}
+
+	varsJSON, err := json.Marshal(vars)
+	if err != nil {
+		return "", "", err
+	}
+
+	return string(filtersJSON), string(varsJSON), nil
+}
+
+// attributeCondition is the jsonpath filter expression of an attribute filter.
+type attributeCondition struct {
+	code string
+	expr string
+}
+
+// attributeConditions returns the jsonpath filter expression of every attribute filter with a value or a bound,
+// variables are numbered across filters.
+func attributeConditions(filters []AttributeFilter) ([]attributeCondition, map[string]any) {
+	var (
+		conditions = make([]attributeCondition, 0, len(filters))
+		vars       = map[string]any{}
+	)
+
+	variable := func(value any) string {
+		name := fmt.Sprintf("v%d", len(vars))
+		vars[name] = value
+
+		return "$" + name
+	}
+
+	for _, f := range filters {
+		field := "@." + f.Code
+
+		var matches []string
+
+		for _, value := range f.Values {
+			matches = append(matches, field+" == "+variable(value))
+
+			if number, ok := finiteNumber(value); ok {
+				matches = append(matches, field+" == "+variable(number))
+			}
+
+			if value == "true" || value == "false" {
+				matches = append(matches, field+" == "+variable(value == "true"))
+			}
+		}
+
+		var bounds []string
+
+		if number, ok := finiteNumber(f.Min); ok {
+			bounds = append(bounds, field+" >= "+variable(number))
+		}
+
+		if number, ok := finiteNumber(f.Max); ok {
+			bounds = append(bounds, field+" <= "+variable(number))
+		}
+
+		if len(bounds) > 0 {
+			matches = append(matches, strings.Join(bounds, " && "))
+		}
+
+		if len(matches) > 0 {
+			conditions = append(conditions, attributeCondition{code: f.Code, expr: strings.Join(matches, " || ")})
+		}
+	}
+
+	return conditions, vars
+}
+
+// finiteNumber parses value as a number, nan and inf are accepted by strconv but can't be json encoded
+// into jsonpath vars, they only match as strings.
+func finiteNumber(value string) (float64, bool) {
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0, false
+	}
+
+	return number, true
+}
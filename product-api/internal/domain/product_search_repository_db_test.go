@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttributeFilterPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters []AttributeFilter
+		path    string
+		vars    string
+	}{
+		{
+			name: "No filters match every variant",
+			path: "$",
+			vars: `{}`,
+		},
+		{
+			name: "Values match their string, number and bool forms",
+			filters: []AttributeFilter{
+				{Code: "color", Values: []string{"black", "blue"}}, {Code: "ram", Values: []string{"8"}}, {Code: "anc", Values: []string{"true"}},
+			},
+			path: "$ ? (@.color == $v0 || @.color == $v1) ? (@.ram == $v2 || @.ram == $v3) ? (@.anc == $v4 || @.anc == $v5)",
+			vars: `{"v0":"black","v1":"blue","v2":"8","v3":8,"v4":"true","v5":true}`,
+		},
+		{
+			name:    "Number ranges",
+			filters: []AttributeFilter{{Code: "ram", Min: "8", Max: "16"}, {Code: "battery_mah", Min: "4500"}},
+			path:    "$ ? (@.ram >= $v0 && @.ram <= $v1) ? (@.battery_mah >= $v2)",
+			vars:    `{"v0":8,"v1":16,"v2":4500}`,
+		},
+		{
+			name:    "Non finite numbers only match as strings",
+			filters: []AttributeFilter{{Code: "color", Values: []string{"nan", "Infinity"}}, {Code: "ram", Min: "inf"}},
+			path:    "$ ? (@.color == $v0 || @.color == $v1)",
+			vars:    `{"v0":"nan","v1":"Infinity"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, vars, err := attributeFilterPath(tt.filters)
+			require.NoError(t, err)
+			require.Equal(t, tt.path, path)
+			require.JSONEq(t, tt.vars, vars)
+		})
+	}
+}
+
+// TestSearchProducts makes sure params are passed in order and the sort key is scanned after product columns.
+func TestSearchProducts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewProductRepoDB(db, testLogger)
+	now := time.Now()
+
+	expectQuery(mock, sqlSelectCategoryID).WithArgs(testCategoryUUID).WillReturnRows(sqlmock.NewRows([]string{"category_id"}).AddRow(3))
+	expectQuery(mock, sqlSearchProducts).
+		WithArgs("gaming phone", testCategoryUUID, `["asus","samsung"]`, "300", "", "$ ? (@.ram >= $v0)", `{"v0":8}`,
+			testProductUUID, "-899.00", 21, ProductSortPriceAsc).
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "product_uuid", "title", "description", "brand", "status", "created_at",
+			"updated_at", "categories", "primary_category_uuid", "breadcrumb", "attributes", "variants", "sort_key"}).
+			AddRow(6, "a-uuid", "ROG Phone 8", "", "Asus", ProductStatusActive, now, now, []byte(`[]`), "", []byte(`[]`), []byte(`[]`),
+				[]byte(`[]`), "-999.00"))
+
+	hits, apiErr := repo.SearchProducts(context.Background(), ProductSearchFilter{
+		Query: "gaming phone", CategoryUUID: testCategoryUUID, Brands: []string{"asus", "samsung"}, MinPrice: "300",
+		Attributes: []AttributeFilter{{Code: "ram", Min: "8"}}, Sort: ProductSortPriceAsc,
+		AfterUUID: testProductUUID, AfterKey: "-899.00", Limit: 21,
+	})
+	require.Nil(t, apiErr)
+	require.Len(t, hits, 1)
+	require.Equal(t, "ROG Phone 8", hits[0].Product.Title)
+	require.Equal(t, "-999.00", hits[0].SortKey)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAttributeFacetFilters(t *testing.T) {
+	filters, vars, err := attributeFacetFilters([]AttributeFilter{{Code: "color", Values: []string{"black"}}, {Code: "ram", Min: "8", Max: "16"}})
+	require.NoError(t, err)
+	require.JSONEq(t, `[{"code":"color","path":"$ ? (@.color == $v0)"},{"code":"ram","path":"$ ? (@.ram >= $v1 && @.ram <= $v2)"}]`, filters)
+	require.JSONEq(t, `{"v0":"black","v1":8,"v2":16}`, vars)
+
+	filters, vars, err = attributeFacetFilters(nil)
+	require.NoError(t, err)
+	require.Equal(t, `[]`, filters)
+	require.Equal(t, `{}`, vars)
+}
+
+// TestFindProductSearchFacets makes sure facets are counted in one query with a jsonpath per attribute filter.
+func TestFindProductSearchFacets(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewProductRepoDB(db, testLogger)
+
+	expectQuery(mock, sqlSelectProductSearchFacets).
+		WithArgs("", "", `["samsung"]`, "", "", `[{"code":"ram","path":"$ ? (@.ram == $v0 || @.ram == $v1)"}]`, `{"v0":"8","v1":8}`).
+		WillReturnRows(sqlmock.NewRows([]string{"facet", "code", "value", "count"}).
+			AddRow(FacetAttribute, "ram", "8", 2).
+			AddRow(FacetBrand, "", "Samsung", 3))
+
+	facets, apiErr := repo.FindProductSearchFacets(context.Background(), ProductSearchFilter{
+		Brands: []string{"samsung"}, Attributes: []AttributeFilter{{Code: "ram", Values: []string{"8"}}},
+	})
+	require.Nil(t, apiErr)
+	require.Equal(t, []FacetCount{
+		{Facet: FacetAttribute, Code: "ram", Value: "8", Count: 2},
+		{Facet: FacetBrand, Value: "Samsung", Count: 3},
+	}, facets)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/ashtishad/ecommerce/lib"
+	"github.com/ashtishad/ecommerce/product-api/internal/domain"
+	"github.com/ashtishad/ecommerce/product-api/pkg/slug"
+)
+
+// maxImportCategories limits categories of a single import, the whole import runs in one transaction.
+const maxImportCategories = 5000
+
+var categoryCSVHeader = []string{"path", "name", "description"}
+
+// ImportCategories validates a JSON tree or CSV import and upserts its categories by slug path,
+// with dry run the report lists the changes without committing them.
+func (s *DefaultCategoryService) ImportCategories(ctx context.Context, req domain.CategoryImportRequestDTO) (*domain.CategoryImportReportDTO, lib.APIError) {
+	var (
+		items  []domain.CategoryImportItem
+		apiErr lib.APIError
+	)
+
+	if req.CSV != nil {
+		items, apiErr = ParseCategoryImportCSV(req.CSV)
+	} else {
+		items, apiErr = FlattenCategoryImportTree(req.Tree)
+	}
+
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	report, apiErr := s.repo.ImportCategories(ctx, items, req.DryRun)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	return report.ToCategoryImportReportDTO(), nil
+}
+
+// ExportCategories returns the category tree in import format with default locale names,
+// deleted categories and their subtrees are left out.
+func (s *DefaultCategoryService) ExportCategories(ctx context.Context) ([]*domain.CategoryTreeNodeDTO, lib.APIError) {
+	categories, apiErr := s.repo.GetAllCategoriesWithHierarchy(ctx, lib.DefaultLocale)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	return toCategoryTreeNodes(categories), nil
+}
+
+// ExportCategoriesCSV returns the exported tree as CSV of path,name,description rows, parents before their subcategories.
+func (s *DefaultCategoryService) ExportCategoriesCSV(ctx context.Context) ([]byte, lib.APIError) {
+	tree, apiErr := s.ExportCategories(ctx)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	var buf bytes.Buffer
+
+	w := csv.NewWriter(&buf)
+	if err := writeCategoryCSV(w, tree); err != nil {
+		return nil, lib.NewInternalServerError("unable to write categories csv", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func toCategoryTreeNodes(categories []*domain.Category) []*domain.CategoryTreeNodeDTO {
+	nodes := make([]*domain.CategoryTreeNodeDTO, 0, len(categories))
+
+	for _, category := range categories {
+		if category.Status == domain.CategoryStatusDeleted {
+			continue
+		}
+
+		nodes = append(nodes, &domain.CategoryTreeNodeDTO{
+			Slug:          category.Slug,
+			Name:          category.Name,
+			Description:   category.Description,
+			Subcategories: toCategoryTreeNodes(category.Subcategories),
+		})
+	}
+
+	return nodes
+}
+
+func writeCategoryCSV(w *csv.Writer, tree []*domain.CategoryTreeNodeDTO) error {
+	if err := w.Write(categoryCSVHeader); err != nil {
+		return err
+	}
+
+	var writeNodes func(parentPath string, nodes []*domain.CategoryTreeNodeDTO) error
+
+	writeNodes = func(parentPath string, nodes []*domain.CategoryTreeNodeDTO) error {
+		for _, node := range nodes {
+			path := node.Slug
+			if parentPath != "" {
+				path = parentPath + "/" + node.Slug
+			}
+
+			if err := w.Write([]string{path, node.Name, node.Description}); err != nil {
+				return err
+			}
+
+			if err := writeNodes(path, node.Subcategories); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if err := writeNodes("", tree); err != nil {
+		return err
+	}
+
+	w.Flush()
+
+	return w.Error()
+}
+
+// FlattenCategoryImportTree validates a nested JSON import and flattens it into items, parents first.
+// Field errors are reported by position, e.g. categories[0].subcategories[1].name.
+//
+// - Slug is optional, it's generated from the name if missing, otherwise it must be a valid slug.
+// - Name and description follow the rules of ValidateNewCategoryRequest.
+// - A slug path must be listed once and sibling names must be unique, case insensitive.
+func FlattenCategoryImportTree(tree []*domain.CategoryTreeNodeDTO) ([]domain.CategoryImportItem, lib.APIError) {
+	v := newImportValidator()
+
+	var flatten func(ref string, parentPath []string, nodes []*domain.CategoryTreeNodeDTO)
+
+	flatten = func(ref string, parentPath []string, nodes []*domain.CategoryTreeNodeDTO) {
+		for i, node := range nodes {
+			nodeRef := fmt.Sprintf("%s[%d]", ref, i)
+			if node == nil {
+				v.fieldErrs.Add(nodeRef, lib.FieldCodeRequired, "category cannot be null")
+				continue
+			}
+
+			name := strings.TrimSpace(node.Name)
+
+			segment := node.Slug
+			if segment == "" {
+				segment = slug.Make(name)
+			}
+
+			if !slug.Valid(segment) {
+				v.fieldErrs.Add(nodeRef+".slug", lib.FieldCodeInvalidFormat,
+					fmt.Sprintf("slug must be lowercase letters or digits separated by single hyphens, at most %d characters", slug.MaxLength))
+
+				continue
+			}
+
+			path := append(append(make([]string, 0, len(parentPath)+1), parentPath...), segment)
+			v.add(nodeRef, "slug", domain.CategoryImportItem{Path: path, Name: name, Description: node.Description})
+
+			flatten(nodeRef+".subcategories", path, node.Subcategories)
+		}
+	}
+
+	flatten("categories", nil, tree)
+
+	return v.result()
+}
+
+// ParseCategoryImportCSV reads a CSV import with a path,name,description header, path is the slug path of a category
+// like phone/smartphone. Rows are ordered parents first, field errors are reported by line, e.g. rows[3].path.
+// Validation rules are the same as FlattenCategoryImportTree, except slugs are required.
+func ParseCategoryImportCSV(r io.Reader) ([]domain.CategoryImportItem, lib.APIError) {
+	var fieldErrs lib.ValidationErrors
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(categoryCSVHeader)
+
+	header, err := reader.Read()
+	if err != nil && !errors.Is(err, io.EOF) {
+		fieldErrs.Add("header", lib.FieldCodeInvalidFormat, "csv header must be "+strings.Join(categoryCSVHeader, ","))
+		return nil, lib.NewValidationError("invalid category import", fieldErrs)
+	}
+
+	if err == nil && strings.Join(header, ",") != strings.Join(categoryCSVHeader, ",") {
+		fieldErrs.Add("header", lib.FieldCodeInvalidValue, "csv header must be "+strings.Join(categoryCSVHeader, ","))
+		return nil, lib.NewValidationError("invalid category import", fieldErrs)
+	}
+
+	type importRow struct {
+		ref  string
+		item domain.CategoryImportItem
+	}
+
+	v := newImportValidator()
+
+	var rows []importRow
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			v.fieldErrs.Add(fmt.Sprintf("rows[%d]", parseErr.Line), lib.FieldCodeInvalidFormat, "malformed csv row: "+parseErr.Err.Error())
+
+			if errors.Is(parseErr.Err, csv.ErrFieldCount) {
+				continue
+			}
+
+			break // reader can't recover from broken quoting
+		}
+
+		if err != nil {
+			return nil, lib.NewError(http.StatusBadRequest, lib.CodeBadRequest, nil).Wrap(err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		ref := fmt.Sprintf("rows[%d]", line)
+
+		path := strings.Split(strings.TrimSpace(record[0]), "/")
+		if !validSlugPath(path) {
+			v.fieldErrs.Add(ref+".path", lib.FieldCodeInvalidFormat, "path must be slugs separated by '/', e.g. phone/smartphone")
+			continue
+		}
+
+		rows = append(rows, importRow{
+			ref:  ref,
+			item: domain.CategoryImportItem{Path: path, Name: strings.TrimSpace(record[1]), Description: record[2]},
+		})
+	}
+
+	// parents first, a stable sort keeps file order among categories of the same depth
+	sort.SliceStable(rows, func(i, j int) bool { return len(rows[i].item.Path) < len(rows[j].item.Path) })
+
+	for _, row := range rows {
+		v.add(row.ref, "path", row.item)
+	}
+
+	return v.result()
+}
+
+func validSlugPath(path []string) bool {
+	for _, segment := range path {
+		if !slug.Valid(segment) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// importValidator collects items of an import and field errors of invalid or conflicting ones.
+type importValidator struct {
+	items        []domain.CategoryImportItem
+	fieldErrs    lib.ValidationErrors
+	paths        map[string]bool
+	siblingNames map[string]bool // parent path and lowercase name
+}
+
+func newImportValidator() *importValidator {
+	return &importValidator{paths: make(map[string]bool), siblingNames: make(map[string]bool)}
+}
+
+// add validates an item, ref is its position in the import and pathField the field of its slug or path.
+func (v *importValidator) add(ref, pathField string, item domain.CategoryImportItem) {
+	var itemErrs lib.ValidationErrors
+
+	validateCategoryFields(&itemErrs, item.Name, item.Description)
+
+	for _, fe := range itemErrs {
+		v.fieldErrs.Add(ref+"."+fe.Field, fe.Code, fe.Message)
+	}
+
+	path := strings.Join(item.Path, "/")
+	if v.paths[path] {
+		v.fieldErrs.Add(ref+"."+pathField, lib.FieldCodeInvalidValue, "category path is listed more than once: "+path)
+		return
+	}
+
+	v.paths[path] = true
+
+	siblingName := strings.Join(item.Path[:len(item.Path)-1], "/") + "\x00" + strings.ToLower(item.Name)
+	if item.Name != "" && v.siblingNames[siblingName] {
+		v.fieldErrs.Add(ref+".name", lib.FieldCodeInvalidValue, "category name is listed more than once among siblings: "+item.Name)
+	}
+
+	v.siblingNames[siblingName] = true
+
+	if !itemErrs.HasErrors() {
+		v.items = append(v.items, item)
+	}
+}
+
+func (v *importValidator) result() ([]domain.CategoryImportItem, lib.APIError) {
+	switch {
+	case len(v.items) == 0 && !v.fieldErrs.HasErrors():
+		v.fieldErrs.Add("categories", lib.FieldCodeRequired, "import must have at least one category")
+	case len(v.paths) > maxImportCategories:
+		v.fieldErrs.Add("categories", lib.FieldCodeTooLong, fmt.Sprintf("import can have at most %d categories", maxImportCategories))
+	}
+
+	if v.fieldErrs.HasErrors() {
+		return nil, lib.NewValidationError("invalid category import", v.fieldErrs)
+	}
+
+	return v.items, nil
+}
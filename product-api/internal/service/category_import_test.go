@@ -0,0 +1,143 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/ashtishad/ecommerce/product-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlattenCategoryImportTree(t *testing.T) {
+	tests := []struct {
+		name   string
+		tree   []*domain.CategoryTreeNodeDTO
+		paths  []string
+		fields []string
+	}{
+		{
+			name: "Nested tree with generated slug",
+			tree: []*domain.CategoryTreeNodeDTO{
+				{Name: "Sound Equipment", Subcategories: []*domain.CategoryTreeNodeDTO{
+					{Slug: "tws", Name: "TWS"},
+					{Name: "Neckband"},
+				}},
+				{Slug: "phone", Name: "Phone"},
+			},
+			paths: []string{"sound-equipment", "sound-equipment/tws", "sound-equipment/neckband", "phone"},
+		},
+		{
+			name:   "Empty import",
+			tree:   []*domain.CategoryTreeNodeDTO{},
+			fields: []string{"categories"},
+		},
+		{
+			name: "Invalid slug, name and null subcategory",
+			tree: []*domain.CategoryTreeNodeDTO{
+				{Slug: "Phone!", Name: "Phone"},
+				{Slug: "wearable", Name: "Wear@ble", Subcategories: []*domain.CategoryTreeNodeDTO{nil}},
+			},
+			fields: []string{"categories[0].slug", "categories[1].name", "categories[1].subcategories[0]"},
+		},
+		{
+			name: "Duplicate path and sibling name",
+			tree: []*domain.CategoryTreeNodeDTO{
+				{Slug: "phone", Name: "Phone"},
+				{Slug: "phone", Name: "Phones"},
+				{Slug: "mobile", Name: "PHONE"},
+			},
+			fields: []string{"categories[1].slug", "categories[2].name"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, apiErr := FlattenCategoryImportTree(tt.tree)
+			if tt.fields != nil {
+				require.NotNil(t, apiErr)
+				assert.Equal(t, tt.fields, fieldNames(apiErr.FieldErrors()))
+
+				return
+			}
+
+			require.Nil(t, apiErr)
+			assert.Equal(t, tt.paths, itemPaths(items))
+		})
+	}
+}
+
+func TestParseCategoryImportCSV(t *testing.T) {
+	tests := []struct {
+		name   string
+		csv    string
+		paths  []string
+		fields []string
+	}{
+		{
+			name:  "Rows ordered parents first",
+			csv:   "path,name,description\nphone/smartphone,Smartphone,\"Android, iOS\"\nphone,Phone,\nwearable,Wearable,\n",
+			paths: []string{"phone", "wearable", "phone/smartphone"},
+		},
+		{
+			name:   "Wrong header",
+			csv:    "slug,name\nphone,Phone\n",
+			fields: []string{"header"},
+		},
+		{
+			name:   "Empty file",
+			csv:    "",
+			fields: []string{"categories"},
+		},
+		{
+			name:   "Invalid path and wrong field count",
+			csv:    "path,name,description\nphone//gaming,Gaming,\nphone,Phone\n",
+			fields: []string{"rows[2].path", "rows[3]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, apiErr := ParseCategoryImportCSV(strings.NewReader(tt.csv))
+			if tt.fields != nil {
+				require.NotNil(t, apiErr)
+				assert.Equal(t, tt.fields, fieldNames(apiErr.FieldErrors()))
+
+				return
+			}
+
+			require.Nil(t, apiErr)
+			assert.Equal(t, tt.paths, itemPaths(items))
+		})
+	}
+}
+
+// TestExportCSVRoundTrip makes sure an export can be imported as it is, deleted subtrees are left out.
+func TestExportCSVRoundTrip(t *testing.T) {
+	categories := []*domain.Category{
+		{Slug: "phone", Name: "Phone", Description: "Mobile phones", Status: "active", Subcategories: []*domain.Category{
+			{Slug: "flip", Name: "Flip", Status: "inactive"},
+			{Slug: "foldable", Name: "Foldable", Status: "deleted"},
+		}},
+	}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, writeCategoryCSV(csv.NewWriter(&buf), toCategoryTreeNodes(categories)))
+	assert.Equal(t, "path,name,description\nphone,Phone,Mobile phones\nphone/flip,Flip,\n", buf.String())
+
+	items, apiErr := ParseCategoryImportCSV(&buf)
+	require.Nil(t, apiErr)
+	assert.Equal(t, []string{"phone", "phone/flip"}, itemPaths(items))
+}
+
+func itemPaths(items []domain.CategoryImportItem) []string {
+	paths := make([]string, 0, len(items))
+	for _, item := range items {
+		paths = append(paths, strings.Join(item.Path, "/"))
+	}
+
+	return paths
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ashtishad/ecommerce/lib"
+	"github.com/ashtishad/ecommerce/product-api/internal/domain"
+)
+
+// categoryFields are the fields clients can select with fields query param, subcategories of a tree are always kept.
+var categoryFields = map[string]bool{
+	"categoryUuid":       true,
+	"parentCategoryUuid": true,
+	"name":               true,
+	"slug":               true,
+	"description":        true,
+	"status":             true,
+	"level":              true,
+	"createdAt":          true,
+	"updatedAt":          true,
+	"path":               true,
+}
+
+// categoryListOptions are the parsed query params of GET /categories.
+type categoryListOptions struct {
+	statuses map[string]bool // nil for every status
+	maxDepth int             // 0 for every level
+	rootUUID string
+	fields   []string // nil for every field
+	flat     bool
+}
+
+// GetCategories returns the category tree, or the tree as of a past time, filtered and shaped by the request.
+// Response is a tree of categories, or a flat list with parents before their subcategories, with the selected
+// fields only if fields are requested.
+//   - returns 400 if a query param is invalid.
+//   - returns 404 if rootId doesn't exist(or didn't exist at asOf).
+func (s *DefaultCategoryService) GetCategories(ctx context.Context, req domain.CategoryListRequestDTO) (*domain.CategoryListResponseDTO, lib.APIError) {
+	opts, apiErr := ValidateCategoryListRequest(req)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	var categories []*domain.CategoryResponseDTO
+
+	if req.AsOf != "" {
+		categories, apiErr = s.GetAllCategoriesAsOf(ctx, req.AsOf)
+	} else {
+		categories, apiErr = s.GetAllCategoriesByHierarchy(ctx, req.Locale)
+	}
+
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	categories, apiErr = shapeCategories(categories, opts)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if opts.fields == nil {
+		return &domain.CategoryListResponseDTO{Categories: categories}, nil
+	}
+
+	return &domain.CategoryListResponseDTO{Selected: selectCategoryFields(categories, opts.fields)}, nil
+}
+
+// ValidateCategoryListRequest validates the optional query params of GET /categories.
+//
+// - status is a comma separated list of active, inactive and deleted.
+// - maxDepth is a positive number, 1 returns only the top level(roots or rootId).
+// - rootId is a category uuid, fields a comma separated list of category fields.
+// - flat is true or false, path field is only available in flat lists.
+func ValidateCategoryListRequest(req domain.CategoryListRequestDTO) (categoryListOptions, lib.APIError) {
+	var (
+		fieldErrs lib.ValidationErrors
+		opts      categoryListOptions
+		err       error
+	)
+
+	if req.StatusStr != "" {
+		opts.statuses = make(map[string]bool)
+		statusRegex := regexp.MustCompile(categoryStatusRegex)
+
+		for _, status := range strings.Split(req.StatusStr, ",") {
+			status = strings.TrimSpace(status)
+			if !statusRegex.MatchString(status) {
+				fieldErrs.Add("status", lib.FieldCodeInvalidValue,
+					fmt.Sprintf("status must be active, inactive or deleted, you entered: %s", status))
+
+				continue
+			}
+
+			opts.statuses[status] = true
+		}
+	}
+
+	if req.MaxDepthStr != "" {
+		opts.maxDepth, err = strconv.Atoi(req.MaxDepthStr)
+		if err != nil || opts.maxDepth < 1 {
+			fieldErrs.Add("maxDepth", lib.FieldCodeInvalidValue,
+				fmt.Sprintf("maxDepth must be a positive number, you entered: %s", req.MaxDepthStr))
+		}
+	}
+
+	if req.RootUUID != "" {
+		if !regexp.MustCompile(categoryUUIDRegex).MatchString(req.RootUUID) {
+			fieldErrs.Add("rootId", lib.FieldCodeInvalidFormat, "invalid category uuid")
+		}
+
+		// uuids of the tree are lowercase, the lookup compares them as strings
+		opts.rootUUID = strings.ToLower(req.RootUUID)
+	}
+
+	if req.FlatStr != "" {
+		opts.flat, err = strconv.ParseBool(req.FlatStr)
+		if err != nil {
+			fieldErrs.Add("flat", lib.FieldCodeInvalidValue, fmt.Sprintf("flat must be true or false, you entered: %s", req.FlatStr))
+		}
+	}
+
+	if req.FieldsStr != "" {
+		for _, field := range strings.Split(req.FieldsStr, ",") {
+			field = strings.TrimSpace(field)
+
+			switch {
+			case !categoryFields[field]:
+				fieldErrs.Add("fields", lib.FieldCodeInvalidValue, fmt.Sprintf("unknown category field: %s", field))
+			case field == "path" && !opts.flat:
+				fieldErrs.Add("fields", lib.FieldCodeInvalidValue, "path field is only available with flat=true")
+			default:
+				opts.fields = append(opts.fields, field)
+			}
+		}
+	}
+
+	if req.AsOf != "" {
+		if _, apiErr := ParseAsOf(req.AsOf); apiErr != nil {
+			fieldErrs = append(fieldErrs, apiErr.FieldErrors()...)
+		}
+	}
+
+	if fieldErrs.HasErrors() {
+		return categoryListOptions{}, lib.NewValidationError("invalid category list input", fieldErrs)
+	}
+
+	return opts, nil
+}
+
+// shapeCategories selects rootId's subtree, drops categories of other statuses with their subtrees and levels
+// below maxDepth, then flattens the tree if requested. categories is modified in place.
+func shapeCategories(categories []*domain.CategoryResponseDTO, opts categoryListOptions) ([]*domain.CategoryResponseDTO, lib.APIError) {
+	if opts.flat {
+		setCategoryPaths(categories, "")
+	}
+
+	if opts.rootUUID != "" {
+		root := findCategoryDTO(categories, opts.rootUUID)
+		if root == nil {
+			return nil, lib.NewError(http.StatusNotFound, domain.ErrCodeCategoryNotFound, nil)
+		}
+
+		categories = []*domain.CategoryResponseDTO{root}
+	}
+
+	categories = pruneCategories(categories, opts, 1)
+
+	if !opts.flat {
+		return categories, nil
+	}
+
+	flat := make([]*domain.CategoryResponseDTO, 0, len(categories))
+
+	var flatten func(nodes []*domain.CategoryResponseDTO)
+
+	flatten = func(nodes []*domain.CategoryResponseDTO) {
+		for _, node := range nodes {
+			subcategories := node.Subcategories
+			node.Subcategories = nil
+			flat = append(flat, node)
+			flatten(subcategories)
+		}
+	}
+
+	flatten(categories)
+
+	return flat, nil
+}
+
+func setCategoryPaths(categories []*domain.CategoryResponseDTO, parentPath string) {
+	for _, c := range categories {
+		c.Path = c.Slug
+		if parentPath != "" {
+			c.Path = parentPath + "/" + c.Slug
+		}
+
+		setCategoryPaths(c.Subcategories, c.Path)
+	}
+}
+
+func findCategoryDTO(categories []*domain.CategoryResponseDTO, categoryUUID string) *domain.CategoryResponseDTO {
+	for _, c := range categories {
+		if c.CategoryUUID == categoryUUID {
+			return c
+		}
+
+		if found := findCategoryDTO(c.Subcategories, categoryUUID); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
+
+// pruneCategories keeps categories of requested statuses down to maxDepth, depth is the level of categories from 1.
+func pruneCategories(categories []*domain.CategoryResponseDTO, opts categoryListOptions, depth int) []*domain.CategoryResponseDTO {
+	kept := make([]*domain.CategoryResponseDTO, 0, len(categories))
+
+	for _, c := range categories {
+		if opts.statuses != nil && !opts.statuses[c.Status] {
+			continue
+		}
+
+		if opts.maxDepth > 0 && depth >= opts.maxDepth {
+			c.Subcategories = nil
+		} else {
+			c.Subcategories = pruneCategories(c.Subcategories, opts, depth+1)
+		}
+
+		kept = append(kept, c)
+	}
+
+	return kept
+}
+
+// selectCategoryFields converts categories to maps with only the selected fields, subcategories of a tree are kept.
+func selectCategoryFields(categories []*domain.CategoryResponseDTO, fields []string) []map[string]interface{} {
+	selected := make([]map[string]interface{}, 0, len(categories))
+
+	for _, c := range categories {
+		m := make(map[string]interface{}, len(fields)+1)
+
+		for _, field := range fields {
+			switch field {
+			case "categoryUuid":
+				m[field] = c.CategoryUUID
+			case "parentCategoryUuid":
+				if c.ParentCategoryUUID != "" {
+					m[field] = c.ParentCategoryUUID
+				} else {
+					m[field] = nil
+				}
+			case "name":
+				m[field] = c.Name
+			case "slug":
+				m[field] = c.Slug
+			case "description":
+				m[field] = c.Description
+			case "status":
+				m[field] = c.Status
+			case "level":
+				m[field] = c.Level
+			case "createdAt":
+				m[field] = c.CreatedAt
+			case "updatedAt":
+				m[field] = c.UpdatedAt
+			case "path":
+				m[field] = c.Path
+			}
+		}
+
+		if len(c.Subcategories) > 0 {
+			m["subcategories"] = selectCategoryFields(c.Subcategories, fields)
+		}
+
+		selected = append(selected, m)
+	}
+
+	return selected
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ashtishad/ecommerce/lib"
+	"github.com/ashtishad/ecommerce/product-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCategoryListRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    domain.CategoryListRequestDTO
+		fields []string
+	}{
+		{"No params", domain.CategoryListRequestDTO{}, nil},
+		{"Every param", domain.CategoryListRequestDTO{
+			StatusStr: "active, inactive", MaxDepthStr: "2", RootUUID: "e085c298-35b0-4b05-bcc1-a24d4fff4794",
+			FieldsStr: "categoryUuid,name,path", FlatStr: "true", AsOf: "2024-03-01T10:00:00Z",
+		}, nil},
+		{"Invalid status and depth", domain.CategoryListRequestDTO{StatusStr: "active,archived", MaxDepthStr: "0"}, []string{"status", "maxDepth"}},
+		{"Invalid root, flat and asOf", domain.CategoryListRequestDTO{RootUUID: "phone", FlatStr: "yes", AsOf: "yesterday"}, []string{"rootId", "flat", "asOf"}},
+		{"Unknown field and path without flat", domain.CategoryListRequestDTO{FieldsStr: "name,price,path"}, []string{"fields", "fields"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, apiErr := ValidateCategoryListRequest(tt.req)
+			if tt.fields == nil {
+				assert.Nil(t, apiErr)
+				return
+			}
+
+			assert.Equal(t, tt.fields, fieldNames(apiErr.FieldErrors()))
+		})
+	}
+
+	t.Run("Uppercase root is lowercased", func(t *testing.T) {
+		opts, apiErr := ValidateCategoryListRequest(domain.CategoryListRequestDTO{RootUUID: "E085C298-35B0-4B05-BCC1-A24D4FFF4794"})
+		require.Nil(t, apiErr)
+		assert.Equal(t, "e085c298-35b0-4b05-bcc1-a24d4fff4794", opts.rootUUID)
+	})
+}
+
+// categoryDTOTree is phone(smartphone(gaming), flip(deleted)), wearable(inactive).
+func categoryDTOTree() []*domain.CategoryResponseDTO {
+	return []*domain.CategoryResponseDTO{
+		{CategoryUUID: "phone", Slug: "phone", Status: "active", Subcategories: []*domain.CategoryResponseDTO{
+			{CategoryUUID: "smartphone", ParentCategoryUUID: "phone", Slug: "smartphone", Status: "active", Level: 1,
+				Subcategories: []*domain.CategoryResponseDTO{
+					{CategoryUUID: "gaming", ParentCategoryUUID: "smartphone", Slug: "gaming", Status: "active", Level: 2},
+				}},
+			{CategoryUUID: "flip", ParentCategoryUUID: "phone", Slug: "flip", Status: "deleted", Level: 1},
+		}},
+		{CategoryUUID: "wearable", Slug: "wearable", Status: "inactive"},
+	}
+}
+
+func TestShapeCategories(t *testing.T) {
+	uuids := func(categories []*domain.CategoryResponseDTO) []string {
+		var result []string
+
+		var walk func(nodes []*domain.CategoryResponseDTO)
+
+		walk = func(nodes []*domain.CategoryResponseDTO) {
+			for _, c := range nodes {
+				result = append(result, c.CategoryUUID)
+				walk(c.Subcategories)
+			}
+		}
+
+		walk(categories)
+
+		return result
+	}
+
+	tests := []struct {
+		name string
+		opts categoryListOptions
+		want []string
+	}{
+		{"Everything", categoryListOptions{}, []string{"phone", "smartphone", "gaming", "flip", "wearable"}},
+		{"Active only", categoryListOptions{statuses: map[string]bool{"active": true}}, []string{"phone", "smartphone", "gaming"}},
+		{"Roots only", categoryListOptions{maxDepth: 1}, []string{"phone", "wearable"}},
+		{"Root only of a subtree", categoryListOptions{rootUUID: "smartphone", maxDepth: 1}, []string{"smartphone"}},
+		{"Excluded root", categoryListOptions{rootUUID: "flip", statuses: map[string]bool{"active": true}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			categories, apiErr := shapeCategories(categoryDTOTree(), tt.opts)
+			require.Nil(t, apiErr)
+			assert.Equal(t, tt.want, uuids(categories))
+		})
+	}
+
+	t.Run("Flat list with paths", func(t *testing.T) {
+		categories, apiErr := shapeCategories(categoryDTOTree(), categoryListOptions{rootUUID: "smartphone", flat: true})
+		require.Nil(t, apiErr)
+		require.Len(t, categories, 2)
+		assert.Equal(t, "phone/smartphone", categories[0].Path)
+		assert.Equal(t, "phone/smartphone/gaming", categories[1].Path)
+		assert.Equal(t, "smartphone", categories[1].ParentCategoryUUID)
+		assert.Nil(t, categories[0].Subcategories)
+	})
+
+	t.Run("Root not found", func(t *testing.T) {
+		_, apiErr := shapeCategories(categoryDTOTree(), categoryListOptions{rootUUID: "missing"})
+		assert.ErrorIs(t, apiErr, lib.ErrNotFound)
+	})
+}
+
+func TestSelectCategoryFields(t *testing.T) {
+	categories, apiErr := shapeCategories(categoryDTOTree(), categoryListOptions{maxDepth: 2})
+	require.Nil(t, apiErr)
+
+	selected := selectCategoryFields(categories, []string{"categoryUuid", "parentCategoryUuid"})
+	assert.Equal(t, map[string]interface{}{
+		"categoryUuid":       "phone",
+		"parentCategoryUuid": nil,
+		"subcategories": []map[string]interface{}{
+			{"categoryUuid": "smartphone", "parentCategoryUuid": "phone"},
+			{"categoryUuid": "flip", "parentCategoryUuid": "phone"},
+		},
+	}, selected[0])
+	assert.Equal(t, map[string]interface{}{"categoryUuid": "wearable", "parentCategoryUuid": nil}, selected[1])
+}
+
+func TestCategoryListResponseJSON(t *testing.T) {
+	categories := []*domain.CategoryResponseDTO{{CategoryUUID: "phone", Name: "Phone", Slug: "phone", Status: "active"}}
+
+	body, err := json.Marshal(domain.CategoryListResponseDTO{Categories: categories})
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"categoryUuid":"phone","name":"Phone","slug":"phone","description":"","status":"active",
+		"createdAt":"0001-01-01T00:00:00Z","updatedAt":"0001-01-01T00:00:00Z"}]`, string(body))
+
+	body, err = json.Marshal(domain.CategoryListResponseDTO{Selected: selectCategoryFields(categories, []string{"name"})})
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"name":"Phone"}]`, string(body))
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // register gif decoder, jpeg and png are registered by thumbnail
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/ashtishad/ecommerce/lib"
+	"github.com/ashtishad/ecommerce/product-api/internal/domain"
+	"github.com/ashtishad/ecommerce/product-api/pkg/thumbnail"
+)
+
+const (
+	// maxMediaBytes limits the size of an uploaded image.
+	maxMediaBytes = 5 << 20
+	// maxMediaSide limits width and height of an uploaded image, decoding is done in memory.
+	maxMediaSide = 8000
+	// mediaKeyPrefix is the blob key prefix of all category media, GET /media only serves keys under it.
+	mediaKeyPrefix = "categories/"
+)
+
+// mediaExtensions maps allowed sniffed content types to the extension of stored blobs.
+var mediaExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+type thumbnailPreset struct {
+	size    string
+	maxSide int
+}
+
+// thumbnailPresets are the thumbnail sizes generated for each media kind, by longest side in pixels.
+var thumbnailPresets = map[string][]thumbnailPreset{
+	domain.MediaKindBanner: {{"sm", 320}, {"md", 768}, {"lg", 1440}},
+	domain.MediaKindIcon:   {{"sm", 48}, {"md", 96}, {"lg", 192}},
+}
+
+type CategoryMediaService interface {
+	GetCategoryMedia(ctx context.Context, categoryUUID string) ([]*domain.CategoryMediaDTO, lib.APIError)
+	UploadCategoryMedia(ctx context.Context, req domain.UploadCategoryMediaRequestDTO) (*domain.CategoryMediaDTO, lib.APIError)
+	DeleteCategoryMedia(ctx context.Context, categoryUUID string, kind string) lib.APIError
+	GetMediaBlob(ctx context.Context, key string) (*lib.Blob, lib.APIError)
+}
+
+// DefaultCategoryMediaService keeps images in the blob store and their metadata in the repository.
+type DefaultCategoryMediaService struct {
+	categoryRepo domain.CategoryRepository
+	repo         domain.CategoryMediaRepository
+	store        lib.BlobStore
+	l            *slog.Logger
+}
+
+func NewCategoryMediaService(categoryRepo domain.CategoryRepository, repo domain.CategoryMediaRepository,
+	store lib.BlobStore, l *slog.Logger) *DefaultCategoryMediaService {
+	return &DefaultCategoryMediaService{categoryRepo: categoryRepo, repo: repo, store: store, l: l}
+}
+
+// GetCategoryMedia returns banner and icon of a category with urls of the original and thumbnails.
+func (s *DefaultCategoryMediaService) GetCategoryMedia(ctx context.Context, categoryUUID string) ([]*domain.CategoryMediaDTO, lib.APIError) {
+	if apiErr := ValidateCategoryUUID(categoryUUID); apiErr != nil {
+		return nil, apiErr
+	}
+
+	media, apiErr := s.repo.FindCategoryMedia(ctx, categoryUUID)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	mediaDTOs := make([]*domain.CategoryMediaDTO, 0, len(media))
+	for i := range media {
+		mediaDTOs = append(mediaDTOs, media[i].ToCategoryMediaDTO(s.store.URL))
+	}
+
+	return mediaDTOs, nil
+}
+
+// UploadCategoryMedia validates an uploaded image, stores it with its thumbnails under a new key prefix
+// and replaces the category's media of the kind. Blobs of the replaced media are deleted afterwards,
+// blobs of a failed upload are cleaned up, both best effort.
+func (s *DefaultCategoryMediaService) UploadCategoryMedia(ctx context.Context, req domain.UploadCategoryMediaRequestDTO) (*domain.CategoryMediaDTO, lib.APIError) {
+	if apiErr := ValidateUploadCategoryMediaRequest(req); apiErr != nil {
+		return nil, apiErr
+	}
+
+	data, err := io.ReadAll(io.LimitReader(req.File, maxMediaBytes+1))
+	if err != nil {
+		return nil, lib.NewError(http.StatusBadRequest, domain.ErrCodeCategoryMediaBodyInvalid, nil).Wrap(err)
+	}
+
+	img, contentType, apiErr := decodeMediaImage(data)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	// check the category before uploading anything, saving checks it again
+	if _, apiErr = s.categoryRepo.FindCategoryByUUID(ctx, req.CategoryUUID); apiErr != nil {
+		return nil, apiErr
+	}
+
+	media, apiErr := s.storeMedia(ctx, req.CategoryUUID, req.Kind, data, contentType, img)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	saved, previous, apiErr := s.repo.SaveCategoryMedia(ctx, req.CategoryUUID, *media)
+	if apiErr != nil {
+		s.deleteBlobs(media.ObjectKeys())
+		return nil, apiErr
+	}
+
+	if previous != nil {
+		s.deleteBlobs(previous.ObjectKeys())
+	}
+
+	return saved.ToCategoryMediaDTO(s.store.URL), nil
+}
+
+// DeleteCategoryMedia removes a category's media of the kind and then its blobs.
+func (s *DefaultCategoryMediaService) DeleteCategoryMedia(ctx context.Context, categoryUUID string, kind string) lib.APIError {
+	if apiErr := ValidateCategoryMediaKind(categoryUUID, kind); apiErr != nil {
+		return apiErr
+	}
+
+	deleted, apiErr := s.repo.DeleteCategoryMedia(ctx, categoryUUID, kind)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	s.deleteBlobs(deleted.ObjectKeys())
+
+	return nil
+}
+
+// GetMediaBlob returns a stored media blob, caller closes its Body. Only keys of category media are served.
+func (s *DefaultCategoryMediaService) GetMediaBlob(ctx context.Context, key string) (*lib.Blob, lib.APIError) {
+	if !strings.HasPrefix(key, mediaKeyPrefix) || !lib.ValidBlobKey(key) {
+		return nil, lib.NewError(http.StatusNotFound, domain.ErrCodeCategoryMediaNotFound, nil)
+	}
+
+	blob, err := s.store.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, lib.ErrBlobNotFound) {
+			return nil, lib.NewError(http.StatusNotFound, domain.ErrCodeCategoryMediaNotFound, lib.Args{"kind": mediaKeyKind(key)}).Wrap(err)
+		}
+
+		s.l.Error("failed to get media blob", "key", key, "err", err)
+
+		return nil, lib.NewInternalServerError("failed to get media", err)
+	}
+
+	return blob, nil
+}
+
+// mediaKeyKind returns the kind of categories/<uuid>/<kind>/<id>/<file> keys, empty for other keys.
+func mediaKeyKind(key string) string {
+	parts := strings.Split(strings.TrimPrefix(key, mediaKeyPrefix), "/")
+	if len(parts) != 4 {
+		return ""
+	}
+
+	return parts[1]
+}
+
+// storeMedia puts the original and thumbnails of every preset of the kind under
+// categories/<uuid>/<kind>/<id>/, a new random id per upload, so replaced media never shares keys with the new one.
+func (s *DefaultCategoryMediaService) storeMedia(ctx context.Context, categoryUUID, kind string, data []byte,
+	contentType string, img image.Image) (*domain.CategoryMedia, lib.APIError) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, lib.NewInternalServerError("failed to generate media id", err)
+	}
+
+	prefix := fmt.Sprintf("%s%s/%s/%s/", mediaKeyPrefix, categoryUUID, kind, hex.EncodeToString(id))
+	bounds := img.Bounds()
+	media := &domain.CategoryMedia{
+		Kind:        kind,
+		ObjectKey:   prefix + "original" + mediaExtensions[contentType],
+		ContentType: contentType,
+		Width:       bounds.Dx(),
+		Height:      bounds.Dy(),
+		SizeBytes:   int64(len(data)),
+		Thumbnails:  make([]domain.MediaThumbnail, 0, len(thumbnailPresets[kind])),
+	}
+
+	if err := s.store.Put(ctx, media.ObjectKey, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		s.l.Error("failed to store media", "key", media.ObjectKey, "err", err)
+		return nil, lib.NewInternalServerError("failed to store media", err)
+	}
+
+	// gif thumbnails are pngs of the first frame
+	thumbType := "image/png"
+	if contentType == "image/jpeg" {
+		thumbType = contentType
+	}
+
+	for _, preset := range thumbnailPresets[kind] {
+		thumb := thumbnail.Fit(img, preset.maxSide)
+
+		var buf bytes.Buffer
+		if err := thumbnail.Encode(&buf, thumb, thumbType); err != nil {
+			s.deleteBlobs(media.ObjectKeys())
+			return nil, lib.NewInternalServerError("failed to encode thumbnail", err)
+		}
+
+		key := prefix + preset.size + mediaExtensions[thumbType]
+		if err := s.store.Put(ctx, key, &buf, int64(buf.Len()), thumbType); err != nil {
+			s.l.Error("failed to store thumbnail", "key", key, "err", err)
+			s.deleteBlobs(media.ObjectKeys())
+
+			return nil, lib.NewInternalServerError("failed to store media", err)
+		}
+
+		media.Thumbnails = append(media.Thumbnails, domain.MediaThumbnail{
+			Size:      preset.size,
+			ObjectKey: key,
+			Width:     thumb.Bounds().Dx(),
+			Height:    thumb.Bounds().Dy(),
+		})
+	}
+
+	return media, nil
+}
+
+// deleteBlobs deletes blobs with a fresh context, it runs after the request's work is done or failed,
+// failures only leave orphan blobs behind, they're logged.
+func (s *DefaultCategoryMediaService) deleteBlobs(keys []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), lib.TimeoutDeleteBlobs)
+	defer cancel()
+
+	for _, key := range keys {
+		if err := s.store.Delete(ctx, key); err != nil {
+			s.l.Warn("failed to delete media blob", "key", key, "err", err)
+		}
+	}
+}
+
+// decodeMediaImage sniffs the content type of an uploaded file, it must be a jpeg, png or gif image
+// of at most maxMediaSide pixels wide and high. Dimensions are checked before decoding pixels.
+func decodeMediaImage(data []byte) (image.Image, string, lib.APIError) {
+	var fieldErrs lib.ValidationErrors
+
+	contentType := http.DetectContentType(data)
+	_, allowed := mediaExtensions[contentType]
+
+	switch {
+	case len(data) > maxMediaBytes:
+		fieldErrs.Add("file", lib.FieldCodeTooLong, fmt.Sprintf("file must be at most %d MB", maxMediaBytes>>20))
+	case !allowed:
+		fieldErrs.Add("file", lib.FieldCodeInvalidFormat, fmt.Sprintf("file must be a jpeg, png or gif image, detected: %s", contentType))
+	}
+
+	if fieldErrs.HasErrors() {
+		return nil, "", lib.NewValidationError("invalid media upload", fieldErrs)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+
+	switch {
+	case err != nil:
+		fieldErrs.Add("file", lib.FieldCodeInvalidFormat, "file isn't a valid image")
+	case cfg.Width > maxMediaSide || cfg.Height > maxMediaSide:
+		fieldErrs.Add("file", lib.FieldCodeOutOfRange, fmt.Sprintf("image must be at most %dx%d pixels", maxMediaSide, maxMediaSide))
+	}
+
+	if fieldErrs.HasErrors() {
+		return nil, "", lib.NewValidationError("invalid media upload", fieldErrs)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		fieldErrs.Add("file", lib.FieldCodeInvalidFormat, "file isn't a valid image")
+		return nil, "", lib.NewValidationError("invalid media upload", fieldErrs)
+	}
+
+	return img, contentType, nil
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ashtishad/ecommerce/lib"
+	"github.com/ashtishad/ecommerce/product-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mediaCategoryUUID = "0c8a3d3e-4f6e-4c9b-9d5a-2b7e1f0a9c11"
+
+// mediaRepoStub stubs the repository calls of media uploads, other methods panic.
+type mediaRepoStub struct {
+	domain.CategoryRepository
+	domain.CategoryMediaRepository
+	saved    []domain.CategoryMedia
+	previous *domain.CategoryMedia
+	saveErr  lib.APIError
+}
+
+func (r *mediaRepoStub) FindCategoryByUUID(_ context.Context, categoryUUID string) (*domain.Category, lib.APIError) {
+	return &domain.Category{CategoryUUID: categoryUUID}, nil
+}
+
+func (r *mediaRepoStub) SaveCategoryMedia(_ context.Context, _ string, media domain.CategoryMedia) (*domain.CategoryMedia, *domain.CategoryMedia, lib.APIError) {
+	if r.saveErr != nil {
+		return nil, nil, r.saveErr
+	}
+
+	previous := r.previous
+	r.saved = append(r.saved, media)
+	r.previous = &media
+
+	return &media, previous, nil
+}
+
+func encodeTestImage(t *testing.T, width, height int, contentType string) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	if contentType == "image/jpeg" {
+		require.NoError(t, jpeg.Encode(&buf, img, nil))
+	} else {
+		require.NoError(t, png.Encode(&buf, img))
+	}
+
+	return buf.Bytes()
+}
+
+func uploadReq(kind string, data []byte) domain.UploadCategoryMediaRequestDTO {
+	return domain.UploadCategoryMediaRequestDTO{
+		CategoryUUID: mediaCategoryUUID,
+		Kind:         kind,
+		File:         bytes.NewReader(data),
+		Size:         int64(len(data)),
+	}
+}
+
+func TestValidateUploadCategoryMediaRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    domain.UploadCategoryMediaRequestDTO
+		fields []string
+	}{
+		{name: "Valid upload", req: uploadReq(domain.MediaKindBanner, []byte("x"))},
+		{
+			name:   "Invalid uuid and kind",
+			req:    domain.UploadCategoryMediaRequestDTO{CategoryUUID: "1", Kind: "logo", File: strings.NewReader("x"), Size: 1},
+			fields: []string{"categoryUuid", "kind"},
+		},
+		{
+			name:   "Missing file",
+			req:    domain.UploadCategoryMediaRequestDTO{CategoryUUID: mediaCategoryUUID, Kind: domain.MediaKindIcon},
+			fields: []string{"file"},
+		},
+		{
+			name:   "Too large file",
+			req:    domain.UploadCategoryMediaRequestDTO{CategoryUUID: mediaCategoryUUID, Kind: domain.MediaKindIcon, File: strings.NewReader(""), Size: maxMediaBytes + 1},
+			fields: []string{"file"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiErr := ValidateUploadCategoryMediaRequest(tt.req)
+			if tt.fields == nil {
+				assert.Nil(t, apiErr)
+				return
+			}
+
+			require.NotNil(t, apiErr)
+			assert.Equal(t, tt.fields, fieldNames(apiErr.FieldErrors()))
+		})
+	}
+}
+
+func TestDecodeMediaImage(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		contentType string
+		code        string
+	}{
+		{name: "Png", data: encodeTestImage(t, 4, 3, "image/png"), contentType: "image/png"},
+		{name: "Jpeg", data: encodeTestImage(t, 4, 3, "image/jpeg"), contentType: "image/jpeg"},
+		{name: "Not an image", data: []byte("<svg xmlns='http://www.w3.org/2000/svg'></svg>"), code: lib.FieldCodeInvalidFormat},
+		{name: "Truncated png", data: encodeTestImage(t, 4, 3, "image/png")[:20], code: lib.FieldCodeInvalidFormat},
+		{name: "Too wide", data: encodeTestImage(t, maxMediaSide+1, 1, "image/png"), code: lib.FieldCodeOutOfRange},
+		{name: "Too large", data: append(encodeTestImage(t, 1, 1, "image/png"), make([]byte, maxMediaBytes)...), code: lib.FieldCodeTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, contentType, apiErr := decodeMediaImage(tt.data)
+			if tt.code != "" {
+				require.NotNil(t, apiErr)
+				assert.Equal(t, tt.code, apiErr.FieldErrors()[0].Code)
+
+				return
+			}
+
+			require.Nil(t, apiErr)
+			assert.Equal(t, tt.contentType, contentType)
+			assert.Equal(t, image.Rect(0, 0, 4, 3), img.Bounds())
+		})
+	}
+}
+
+// TestUploadCategoryMedia uploads a banner twice into a fs blob store, the second upload replaces the first,
+// whose blobs are deleted. A failed save cleans up the uploaded blobs.
+func TestUploadCategoryMedia(t *testing.T) {
+	root := t.TempDir()
+	store, err := lib.NewFSBlobStore(root, "/media")
+	require.NoError(t, err)
+
+	repo := &mediaRepoStub{}
+	s := NewCategoryMediaService(repo, repo, store, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+
+	blobExists := func(key string) bool {
+		_, err := os.Stat(filepath.Join(root, filepath.FromSlash(key)))
+		return err == nil
+	}
+
+	first, apiErr := s.UploadCategoryMedia(ctx, uploadReq(domain.MediaKindBanner, encodeTestImage(t, 1600, 400, "image/jpeg")))
+	require.Nil(t, apiErr)
+	assert.Equal(t, "image/jpeg", first.ContentType)
+	assert.Equal(t, 1600, first.Width)
+	assert.True(t, strings.HasPrefix(first.URL, "/media/categories/"+mediaCategoryUUID+"/banner/"))
+	assert.True(t, strings.HasSuffix(first.URL, "/original.jpg"))
+
+	require.Len(t, first.Thumbnails, 3)
+	assert.Equal(t, domain.MediaThumbnailDTO{Size: "sm", URL: strings.Replace(first.URL, "original", "sm", 1), Width: 320, Height: 80}, first.Thumbnails[0])
+	assert.Equal(t, 768, first.Thumbnails[1].Width)
+	assert.Equal(t, 1440, first.Thumbnails[2].Width)
+
+	firstKeys := repo.saved[0].ObjectKeys()
+	for _, key := range firstKeys {
+		assert.True(t, blobExists(key), key)
+	}
+
+	t.Run("Replaced media blobs are deleted", func(t *testing.T) {
+		second, apiErr := s.UploadCategoryMedia(ctx, uploadReq(domain.MediaKindBanner, encodeTestImage(t, 200, 100, "image/png")))
+		require.Nil(t, apiErr)
+		assert.NotEqual(t, first.URL, second.URL)
+		// small images aren't upscaled
+		assert.Equal(t, 200, second.Thumbnails[2].Width)
+
+		for _, key := range firstKeys {
+			assert.False(t, blobExists(key), key)
+		}
+
+		for _, key := range repo.saved[1].ObjectKeys() {
+			assert.True(t, blobExists(key), key)
+		}
+	})
+
+	t.Run("Failed save cleans up blobs", func(t *testing.T) {
+		repo.saveErr = lib.NewInternalServerError(lib.UnexpectedDatabaseErr, nil)
+
+		_, apiErr := s.UploadCategoryMedia(ctx, uploadReq(domain.MediaKindIcon, encodeTestImage(t, 64, 64, "image/png")))
+		require.NotNil(t, apiErr)
+
+		var iconFiles []string
+
+		err := filepath.WalkDir(filepath.Join(root, "categories", mediaCategoryUUID, domain.MediaKindIcon), func(path string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				iconFiles = append(iconFiles, path)
+			}
+
+			return err
+		})
+		require.NoError(t, err)
+		assert.Empty(t, iconFiles)
+	})
+}
+
+func TestGetMediaBlob(t *testing.T) {
+	store, err := lib.NewFSBlobStore(t.TempDir(), "/media")
+	require.NoError(t, err)
+
+	s := NewCategoryMediaService(&mediaRepoStub{}, &mediaRepoStub{}, store, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+	key := "categories/" + mediaCategoryUUID + "/icon/ab/sm.png"
+	data := encodeTestImage(t, 2, 2, "image/png")
+	require.NoError(t, store.Put(ctx, key, bytes.NewReader(data), int64(len(data)), "image/png"))
+
+	blob, apiErr := s.GetMediaBlob(ctx, key)
+	require.Nil(t, apiErr)
+	defer blob.Body.Close()
+	assert.Equal(t, "image/png", blob.ContentType)
+
+	for _, missing := range []string{"categories/missing.png", "other/sm.png", "categories/../secret"} {
+		_, apiErr = s.GetMediaBlob(ctx, missing)
+		require.NotNil(t, apiErr, missing)
+		assert.Equal(t, domain.ErrCodeCategoryMediaNotFound, apiErr.ErrorCode(), missing)
+	}
+
+	_, apiErr = s.GetMediaBlob(ctx, "categories/"+mediaCategoryUUID+"/banner/ab/sm.png")
+	require.NotNil(t, apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	assert.Equal(t, "category has no banner media", apiErr.AsMessage())
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"strings"
 
 	"github.com/ashtishad/ecommerce/lib"
 	"github.com/ashtishad/ecommerce/product-api/internal/domain"
@@ -10,15 +12,41 @@ import (
 type CategoryService interface {
 	NewCategory(ctx context.Context, req domain.NewCategoryRequestDTO) (*domain.CategoryResponseDTO, lib.APIError)
 	NewSubCategory(ctx context.Context, req domain.NewCategoryRequestDTO, parentUUID string) (*domain.CategoryResponseDTO, lib.APIError)
-	GetAllCategoriesByHierarchy(ctx context.Context) ([]*domain.CategoryResponseDTO, lib.APIError)
+	GetAllCategoriesByHierarchy(ctx context.Context, locale string) ([]*domain.CategoryResponseDTO, lib.APIError)
+	GetCategory(ctx context.Context, categoryUUID string) (*domain.CategoryResponseDTO, lib.APIError)
+	UpdateCategory(ctx context.Context, req domain.UpdateCategoryRequestDTO) (*domain.CategoryResponseDTO, lib.APIError)
+	UpdateCategoryStatus(ctx context.Context, req domain.UpdateCategoryStatusRequestDTO) (*domain.CategoryResponseDTO, lib.APIError)
+	MoveCategory(ctx context.Context, req domain.MoveCategoryRequestDTO) (*domain.CategoryResponseDTO, lib.APIError)
+	GetCategoryAncestors(ctx context.Context, categoryUUID string, includeSelf bool) ([]*domain.CategoryResponseDTO, lib.APIError)
+	GetCategoryDescendants(ctx context.Context, req domain.CategorySubtreeRequestDTO) ([]*domain.CategoryResponseDTO, lib.APIError)
+	GetCategoryTree(ctx context.Context, req domain.CategorySubtreeRequestDTO) (*domain.CategoryResponseDTO, lib.APIError)
+	ReorderChildren(ctx context.Context, req domain.ReorderChildrenRequestDTO) (*domain.CategoryResponseDTO, lib.APIError)
+	UpdateCategorySlug(ctx context.Context, req domain.UpdateCategorySlugRequestDTO) (*domain.CategoryResponseDTO, lib.APIError)
+	GetCategoryByPath(ctx context.Context, path string) (*domain.CategoryResponseDTO, string, lib.APIError)
+	GetCategoryAttributes(ctx context.Context, categoryUUID string) ([]*domain.CategoryAttributeResponseDTO, lib.APIError)
+	SaveCategoryAttribute(ctx context.Context, req domain.SaveCategoryAttributeRequestDTO) (*domain.CategoryAttributeResponseDTO, lib.APIError)
+	DeleteCategoryAttribute(ctx context.Context, categoryUUID string, code string) lib.APIError
+	GetCategoryTranslations(ctx context.Context, categoryUUID string) ([]*domain.CategoryTranslationDTO, lib.APIError)
+	SaveCategoryTranslation(ctx context.Context, req domain.SaveCategoryTranslationRequestDTO) (*domain.CategoryTranslationDTO, lib.APIError)
+	DeleteCategoryTranslation(ctx context.Context, categoryUUID string, locale string) lib.APIError
+	ImportCategories(ctx context.Context, req domain.CategoryImportRequestDTO) (*domain.CategoryImportReportDTO, lib.APIError)
+	ExportCategories(ctx context.Context) ([]*domain.CategoryTreeNodeDTO, lib.APIError)
+	ExportCategoriesCSV(ctx context.Context) ([]byte, lib.APIError)
+	GetCategoryHistory(ctx context.Context, req domain.CategoryHistoryRequestDTO) ([]*domain.CategoryHistoryEntryDTO, lib.APIError)
+	GetAllCategoriesAsOf(ctx context.Context, asOf string) ([]*domain.CategoryResponseDTO, lib.APIError)
+	GetCategories(ctx context.Context, req domain.CategoryListRequestDTO) (*domain.CategoryListResponseDTO, lib.APIError)
 }
 
 type DefaultCategoryService struct {
-	repo domain.CategoryRepository
+	repo            domain.CategoryRepository
+	attributeRepo   domain.CategoryAttributeRepository
+	translationRepo domain.CategoryTranslationRepository
+	historyRepo     domain.CategoryHistoryRepository
 }
 
-func NewCategoryService(repo domain.CategoryRepository) *DefaultCategoryService {
-	return &DefaultCategoryService{repo: repo}
+func NewCategoryService(repo domain.CategoryRepository, attributeRepo domain.CategoryAttributeRepository,
+	translationRepo domain.CategoryTranslationRepository, historyRepo domain.CategoryHistoryRepository) *DefaultCategoryService {
+	return &DefaultCategoryService{repo: repo, attributeRepo: attributeRepo, translationRepo: translationRepo, historyRepo: historyRepo}
 }
 
 func (s *DefaultCategoryService) NewCategory(ctx context.Context, req domain.NewCategoryRequestDTO) (*domain.CategoryResponseDTO, lib.APIError) {
@@ -61,8 +89,298 @@ func (s *DefaultCategoryService) NewSubCategory(ctx context.Context, req domain.
 	return response, nil
 }
 
-func (s *DefaultCategoryService) GetAllCategoriesByHierarchy(ctx context.Context) ([]*domain.CategoryResponseDTO, lib.APIError) {
-	categories, apiErr := s.repo.GetAllCategoriesWithHierarchy(ctx)
+// GetAllCategoriesByHierarchy returns the category tree with names and descriptions in locale,
+// untranslated categories fall back to default locale values.
+func (s *DefaultCategoryService) GetAllCategoriesByHierarchy(ctx context.Context, locale string) ([]*domain.CategoryResponseDTO, lib.APIError) {
+	categories, apiErr := s.repo.GetAllCategoriesWithHierarchy(ctx, locale)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	categoryResponseDTOs := make([]*domain.CategoryResponseDTO, 0, len(categories))
+	for _, category := range categories {
+		categoryResponseDTOs = append(categoryResponseDTOs, category.ToCategoryResponseDTO())
+	}
+
+	return categoryResponseDTOs, nil
+}
+
+func (s *DefaultCategoryService) GetCategory(ctx context.Context, categoryUUID string) (*domain.CategoryResponseDTO, lib.APIError) {
+	if apiErr := ValidateCategoryUUID(categoryUUID); apiErr != nil {
+		return nil, apiErr
+	}
+
+	category, apiErr := s.repo.FindCategoryByUUID(ctx, categoryUUID)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	return category.ToCategoryResponseDTO(), nil
+}
+
+// UpdateCategory validates the request and renames a category or updates its description.
+func (s *DefaultCategoryService) UpdateCategory(ctx context.Context, req domain.UpdateCategoryRequestDTO) (*domain.CategoryResponseDTO, lib.APIError) {
+	if apiErr := ValidateUpdateCategoryRequest(req); apiErr != nil {
+		return nil, apiErr
+	}
+
+	category := domain.Category{
+		CategoryUUID: req.CategoryUUID,
+		Name:         req.Name,
+		Description:  req.Description,
+	}
+
+	updatedCategory, apiErr := s.repo.UpdateCategory(ctx, category)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	return updatedCategory.ToCategoryResponseDTO(), nil
+}
+
+// UpdateCategoryStatus validates the request and changes status of a category, optionally for the whole subtree.
+func (s *DefaultCategoryService) UpdateCategoryStatus(ctx context.Context, req domain.UpdateCategoryStatusRequestDTO) (*domain.CategoryResponseDTO, lib.APIError) {
+	if apiErr := ValidateUpdateCategoryStatusRequest(req); apiErr != nil {
+		return nil, apiErr
+	}
+
+	updatedCategory, apiErr := s.repo.UpdateCategoryStatus(ctx, req.CategoryUUID, req.Status, req.Cascade)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	return updatedCategory.ToCategoryResponseDTO(), nil
+}
+
+// MoveCategory validates the request and re-parents a category with its whole subtree.
+func (s *DefaultCategoryService) MoveCategory(ctx context.Context, req domain.MoveCategoryRequestDTO) (*domain.CategoryResponseDTO, lib.APIError) {
+	if apiErr := ValidateMoveCategoryRequest(req); apiErr != nil {
+		return nil, apiErr
+	}
+
+	movedCategory, apiErr := s.repo.MoveCategory(ctx, req.CategoryUUID, req.ParentCategoryUUID)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	return movedCategory.ToCategoryResponseDTO(), nil
+}
+
+// GetCategoryAncestors returns ancestors of a category ordered from root, includeSelf appends the category itself.
+func (s *DefaultCategoryService) GetCategoryAncestors(ctx context.Context, categoryUUID string, includeSelf bool) ([]*domain.CategoryResponseDTO, lib.APIError) {
+	if apiErr := ValidateCategoryUUID(categoryUUID); apiErr != nil {
+		return nil, apiErr
+	}
+
+	ancestors, apiErr := s.repo.FindAncestors(ctx, categoryUUID, includeSelf)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	ancestorDTOs := make([]*domain.CategoryResponseDTO, 0, len(ancestors))
+	for _, ancestor := range ancestors {
+		ancestorDTOs = append(ancestorDTOs, ancestor.ToCategoryResponseDTO())
+	}
+
+	return ancestorDTOs, nil
+}
+
+// GetCategoryDescendants returns children of a category with their own subcategories nested, up to requested depth.
+func (s *DefaultCategoryService) GetCategoryDescendants(ctx context.Context, req domain.CategorySubtreeRequestDTO) ([]*domain.CategoryResponseDTO, lib.APIError) {
+	tree, apiErr := s.GetCategoryTree(ctx, req)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	return tree.Subcategories, nil
+}
+
+// GetCategoryTree returns a single branch, the category itself with its descendants nested up to requested depth.
+func (s *DefaultCategoryService) GetCategoryTree(ctx context.Context, req domain.CategorySubtreeRequestDTO) (*domain.CategoryResponseDTO, lib.APIError) {
+	maxDepth, apiErr := ValidateCategorySubtreeRequest(req)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	category, apiErr := s.repo.FindSubtree(ctx, req.CategoryUUID, maxDepth)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	return category.ToCategoryResponseDTO(), nil
+}
+
+// ReorderChildren validates the request and sets the order of a category's direct subcategories.
+func (s *DefaultCategoryService) ReorderChildren(ctx context.Context, req domain.ReorderChildrenRequestDTO) (*domain.CategoryResponseDTO, lib.APIError) {
+	if apiErr := ValidateReorderChildrenRequest(req); apiErr != nil {
+		return nil, apiErr
+	}
+
+	parent, apiErr := s.repo.ReorderChildren(ctx, req.CategoryUUID, req.Order)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	return parent.ToCategoryResponseDTO(), nil
+}
+
+// UpdateCategorySlug validates the request and sets a new slug for a category.
+func (s *DefaultCategoryService) UpdateCategorySlug(ctx context.Context, req domain.UpdateCategorySlugRequestDTO) (*domain.CategoryResponseDTO, lib.APIError) {
+	if apiErr := ValidateUpdateCategorySlugRequest(req); apiErr != nil {
+		return nil, apiErr
+	}
+
+	updated, apiErr := s.repo.UpdateCategorySlug(ctx, req.CategoryUUID, req.Slug)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	return updated.ToCategoryResponseDTO(), nil
+}
+
+// GetCategoryByPath resolves a slug path like phone/smartphone/gaming, returns the category and its canonical path,
+// canonical path differs from the input when an old slug or different letter case was used.
+func (s *DefaultCategoryService) GetCategoryByPath(ctx context.Context, path string) (*domain.CategoryResponseDTO, string, lib.APIError) {
+	slugs, apiErr := ParseCategoryPath(path)
+	if apiErr != nil {
+		return nil, "", apiErr
+	}
+
+	category, canonical, apiErr := s.repo.FindCategoryByPath(ctx, slugs)
+	if apiErr != nil {
+		return nil, "", apiErr
+	}
+
+	return category.ToCategoryResponseDTO(), strings.Join(canonical, "/"), nil
+}
+
+// GetCategoryAttributes returns attributes a category defines or inherits from its ancestors.
+func (s *DefaultCategoryService) GetCategoryAttributes(ctx context.Context, categoryUUID string) ([]*domain.CategoryAttributeResponseDTO, lib.APIError) {
+	if apiErr := ValidateCategoryUUID(categoryUUID); apiErr != nil {
+		return nil, apiErr
+	}
+
+	attributes, apiErr := s.attributeRepo.FindCategoryAttributes(ctx, categoryUUID)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	attributeDTOs := make([]*domain.CategoryAttributeResponseDTO, 0, len(attributes))
+	for i := range attributes {
+		attributeDTOs = append(attributeDTOs, attributes[i].ToCategoryAttributeResponseDTO())
+	}
+
+	return attributeDTOs, nil
+}
+
+// SaveCategoryAttribute validates the request and defines an attribute on a category, or overrides an inherited one.
+func (s *DefaultCategoryService) SaveCategoryAttribute(ctx context.Context, req domain.SaveCategoryAttributeRequestDTO) (*domain.CategoryAttributeResponseDTO, lib.APIError) {
+	if apiErr := ValidateSaveCategoryAttributeRequest(req); apiErr != nil {
+		return nil, apiErr
+	}
+
+	attribute := domain.CategoryAttribute{
+		Code:     req.Code,
+		Name:     strings.TrimSpace(req.Name),
+		Type:     req.Type,
+		Unit:     sql.NullString{String: req.Unit, Valid: req.Unit != ""},
+		Values:   req.Values,
+		Required: req.Required,
+	}
+
+	saved, apiErr := s.attributeRepo.SaveCategoryAttribute(ctx, req.CategoryUUID, attribute)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	return saved.ToCategoryAttributeResponseDTO(), nil
+}
+
+// DeleteCategoryAttribute removes an attribute a category defines itself.
+func (s *DefaultCategoryService) DeleteCategoryAttribute(ctx context.Context, categoryUUID string, code string) lib.APIError {
+	if apiErr := ValidateCategoryAttributeCode(categoryUUID, code); apiErr != nil {
+		return apiErr
+	}
+
+	return s.attributeRepo.DeleteCategoryAttribute(ctx, categoryUUID, code)
+}
+
+// GetCategoryTranslations returns translations of a category in non default locales.
+func (s *DefaultCategoryService) GetCategoryTranslations(ctx context.Context, categoryUUID string) ([]*domain.CategoryTranslationDTO, lib.APIError) {
+	if apiErr := ValidateCategoryUUID(categoryUUID); apiErr != nil {
+		return nil, apiErr
+	}
+
+	translations, apiErr := s.translationRepo.FindCategoryTranslations(ctx, categoryUUID)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	translationDTOs := make([]*domain.CategoryTranslationDTO, 0, len(translations))
+	for i := range translations {
+		translationDTOs = append(translationDTOs, translations[i].ToCategoryTranslationDTO())
+	}
+
+	return translationDTOs, nil
+}
+
+// SaveCategoryTranslation validates the request and sets name and description of a category in a locale.
+func (s *DefaultCategoryService) SaveCategoryTranslation(ctx context.Context, req domain.SaveCategoryTranslationRequestDTO) (*domain.CategoryTranslationDTO, lib.APIError) {
+	if apiErr := ValidateSaveCategoryTranslationRequest(req); apiErr != nil {
+		return nil, apiErr
+	}
+
+	translation := domain.CategoryTranslation{
+		Locale:      req.Locale,
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+	}
+
+	saved, apiErr := s.translationRepo.SaveCategoryTranslation(ctx, req.CategoryUUID, translation)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	return saved.ToCategoryTranslationDTO(), nil
+}
+
+// DeleteCategoryTranslation removes a category translation, the locale falls back to default locale values.
+func (s *DefaultCategoryService) DeleteCategoryTranslation(ctx context.Context, categoryUUID string, locale string) lib.APIError {
+	if apiErr := ValidateCategoryTranslationLocale(categoryUUID, locale); apiErr != nil {
+		return apiErr
+	}
+
+	return s.translationRepo.DeleteCategoryTranslation(ctx, categoryUUID, locale)
+}
+
+// GetCategoryHistory returns recorded changes of a category newest first, with actor and before and after snapshots.
+func (s *DefaultCategoryService) GetCategoryHistory(ctx context.Context, req domain.CategoryHistoryRequestDTO) ([]*domain.CategoryHistoryEntryDTO, lib.APIError) {
+	beforeID, limit, apiErr := ValidateCategoryHistoryRequest(req)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	entries, apiErr := s.historyRepo.FindCategoryHistory(ctx, req.CategoryUUID, beforeID, limit)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	entryDTOs := make([]*domain.CategoryHistoryEntryDTO, 0, len(entries))
+	for i := range entries {
+		entryDTOs = append(entryDTOs, entries[i].ToCategoryHistoryEntryDTO())
+	}
+
+	return entryDTOs, nil
+}
+
+// GetAllCategoriesAsOf returns the category tree as it was at asOf, names and descriptions in default locale.
+func (s *DefaultCategoryService) GetAllCategoriesAsOf(ctx context.Context, asOf string) ([]*domain.CategoryResponseDTO, lib.APIError) {
+	asOfTime, apiErr := ParseAsOf(asOf)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	categories, apiErr := s.historyRepo.GetCategoryTreeAsOf(ctx, asOfTime)
 	if apiErr != nil {
 		return nil, apiErr
 	}
@@ -0,0 +1,380 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ashtishad/ecommerce/lib"
+	"github.com/ashtishad/ecommerce/product-api/internal/domain"
+	"github.com/ashtishad/ecommerce/product-api/pkg/money"
+)
+
+const (
+	// priceListCodeRegex matches price list codes and customer groups, e.g. retail, b2b, wholesale-eu.
+	priceListCodeRegex     = `^[a-z0-9][a-z0-9_-]{0,49}$`
+	priceListNameMaxLength = 255
+	maxPriceListPriority   = 1000
+	// maxPriceAmount keeps amounts exact as json numbers in every client, 2^53 - 1 minor units.
+	maxPriceAmount = 1<<53 - 1
+)
+
+type PriceService interface {
+	NewPriceList(ctx context.Context, req domain.NewPriceListRequestDTO) (*domain.PriceListDTO, lib.APIError)
+	GetPriceLists(ctx context.Context) ([]*domain.PriceListDTO, lib.APIError)
+	SavePrice(ctx context.Context, req domain.SavePriceRequestDTO) (*domain.PriceDTO, lib.APIError)
+	DeletePrice(ctx context.Context, priceListCode string, priceUUID string) lib.APIError
+	GetSKUPrices(ctx context.Context, sku string) ([]*domain.SKUPriceListDTO, lib.APIError)
+	ResolvePrice(ctx context.Context, req domain.ResolvePriceRequestDTO) (*domain.EffectivePriceDTO, lib.APIError)
+}
+
+// DefaultPriceService resolves prices at the current time unless a request asks for another moment.
+type DefaultPriceService struct {
+	repo domain.PriceRepository
+	now  func() time.Time
+}
+
+func NewPriceService(repo domain.PriceRepository) *DefaultPriceService {
+	return &DefaultPriceService{repo: repo, now: time.Now}
+}
+
+// NewPriceList validates the request and creates a price list, currency is uppercased.
+func (s *DefaultPriceService) NewPriceList(ctx context.Context, req domain.NewPriceListRequestDTO) (*domain.PriceListDTO, lib.APIError) {
+	if apiErr := ValidateNewPriceListRequest(req); apiErr != nil {
+		return nil, apiErr
+	}
+
+	created, apiErr := s.repo.CreatePriceList(ctx, domain.PriceList{
+		Code:          req.Code,
+		Name:          strings.TrimSpace(req.Name),
+		Currency:      strings.ToUpper(req.Currency),
+		CustomerGroup: req.CustomerGroup,
+		Priority:      req.Priority,
+	})
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	return created.ToPriceListDTO(), nil
+}
+
+func (s *DefaultPriceService) GetPriceLists(ctx context.Context) ([]*domain.PriceListDTO, lib.APIError) {
+	priceLists, apiErr := s.repo.FindPriceLists(ctx)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	dtos := make([]*domain.PriceListDTO, len(priceLists))
+	for i := range priceLists {
+		dtos[i] = priceLists[i].ToPriceListDTO()
+	}
+
+	return dtos, nil
+}
+
+// SavePrice sets the base price of a SKU in a price list, or adds a scheduled price if the request has a schedule.
+// Base prices of the retail list follow variant prices, setting them returns 409.
+func (s *DefaultPriceService) SavePrice(ctx context.Context, req domain.SavePriceRequestDTO) (*domain.PriceDTO, lib.APIError) {
+	if apiErr := ValidateSavePriceRequest(req); apiErr != nil {
+		return nil, apiErr
+	}
+
+	price := domain.Price{
+		PriceListCode: req.PriceListCode,
+		SKU:           req.SKU,
+		Amount:        *req.Amount,
+		StartsAt:      req.StartsAt,
+		EndsAt:        req.EndsAt,
+	}
+
+	if price.IsBase() && price.PriceListCode == domain.RetailPriceList {
+		return nil, lib.NewError(http.StatusConflict, domain.ErrCodePriceBaseManaged, nil)
+	}
+
+	saved, apiErr := s.repo.SavePrice(ctx, price)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	return saved.ToPriceDTO(), nil
+}
+
+func (s *DefaultPriceService) DeletePrice(ctx context.Context, priceListCode string, priceUUID string) lib.APIError {
+	var fieldErrs lib.ValidationErrors
+
+	validatePriceListCode(&fieldErrs, "priceList", priceListCode)
+
+	if !regexp.MustCompile(categoryUUIDRegex).MatchString(priceUUID) {
+		fieldErrs.Add("priceUuid", lib.FieldCodeInvalidFormat, "invalid price uuid")
+	}
+
+	if fieldErrs.HasErrors() {
+		return lib.NewValidationError("invalid price input", fieldErrs)
+	}
+
+	return s.repo.DeletePrice(ctx, priceListCode, priceUUID)
+}
+
+// GetSKUPrices returns every price of a SKU grouped by price list.
+func (s *DefaultPriceService) GetSKUPrices(ctx context.Context, sku string) ([]*domain.SKUPriceListDTO, lib.APIError) {
+	var fieldErrs lib.ValidationErrors
+
+	validatePriceSKU(&fieldErrs, sku)
+
+	if fieldErrs.HasErrors() {
+		return nil, lib.NewValidationError("invalid price input", fieldErrs)
+	}
+
+	priceLists, apiErr := s.repo.FindSKUPrices(ctx, sku)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	dtos := make([]*domain.SKUPriceListDTO, len(priceLists))
+	for i := range priceLists {
+		dtos[i] = priceLists[i].ToSKUPriceListDTO()
+	}
+
+	return dtos, nil
+}
+
+// ResolvePrice returns the price a customer group pays for a SKU at the requested moment, now by default,
+// returns 404 if no applicable price list prices the SKU at that moment.
+func (s *DefaultPriceService) ResolvePrice(ctx context.Context, req domain.ResolvePriceRequestDTO) (*domain.EffectivePriceDTO, lib.APIError) {
+	at, apiErr := ValidateResolvePriceRequest(req)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if at.IsZero() {
+		at = s.now()
+	}
+
+	priceLists, apiErr := s.repo.FindSKUPrices(ctx, req.SKU)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	effective := resolvePrice(priceLists, req.CustomerGroup, strings.ToUpper(req.Currency), at)
+	if effective == nil {
+		return nil, lib.NewError(http.StatusNotFound, domain.ErrCodePriceUnavailable, lib.Args{"sku": req.SKU})
+	}
+
+	effective.SKU = req.SKU
+
+	return effective.ToEffectivePriceDTO(), nil
+}
+
+// resolvePrice picks the effective price of a SKU at a moment from the price lists that price it.
+//   - lists apply to every customer or to one customer group, lists of other groups and other currencies are skipped,
+//     an empty currency is the retail list's, lists in other currencies never compete on priority.
+//   - lists of the customer's own group are tried first, then lists of every customer, each by priority, higher first,
+//     then by code.
+//   - in a list, the scheduled price active at the moment with the latest start wins, the last added on a tie,
+//     a schedule starts inclusive and ends exclusive. The base price applies when no scheduled price is active.
+//   - the first list with an effective price wins, a list without one falls through, e.g. a b2b list without
+//     a price for the SKU falls back to retail.
+//
+// returns nil if no list prices the SKU at that moment.
+func resolvePrice(priceLists []domain.SKUPriceList, customerGroup string, currency string, at time.Time) *domain.EffectivePrice {
+	if currency == "" {
+		currency = retailCurrency(priceLists)
+	}
+
+	candidates := make([]domain.SKUPriceList, 0, len(priceLists))
+
+	for _, pl := range priceLists {
+		if (pl.PriceList.CustomerGroup == "" || pl.PriceList.CustomerGroup == customerGroup) && pl.PriceList.Currency == currency {
+			candidates = append(candidates, pl)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i].PriceList, candidates[j].PriceList
+		if (a.CustomerGroup != "") != (b.CustomerGroup != "") {
+			return a.CustomerGroup != ""
+		}
+
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+
+		return a.Code < b.Code
+	})
+
+	for _, pl := range candidates {
+		var scheduled, base *domain.Price
+
+		for i := range pl.Prices {
+			p := &pl.Prices[i]
+
+			switch {
+			case p.IsBase():
+				base = p
+			case p.ActiveAt(at) && (scheduled == nil || !startsBefore(p, scheduled)):
+				scheduled = p
+			}
+		}
+
+		switch {
+		case scheduled != nil:
+			return &domain.EffectivePrice{PriceList: pl.PriceList, Price: *scheduled, Regular: base, At: at}
+		case base != nil:
+			return &domain.EffectivePrice{PriceList: pl.PriceList, Price: *base, At: at}
+		}
+	}
+
+	return nil
+}
+
+// retailCurrency returns the currency of the retail list, empty if it doesn't price the SKU.
+func retailCurrency(priceLists []domain.SKUPriceList) string {
+	for _, pl := range priceLists {
+		if pl.PriceList.Code == domain.RetailPriceList {
+			return pl.PriceList.Currency
+		}
+	}
+
+	return ""
+}
+
+// startsBefore reports whether scheduled price a starts before b, an open start is the earliest.
+func startsBefore(a, b *domain.Price) bool {
+	switch {
+	case a.StartsAt == nil:
+		return b.StartsAt != nil
+	case b.StartsAt == nil:
+		return false
+	default:
+		return a.StartsAt.Before(*b.StartsAt)
+	}
+}
+
+// ValidateNewPriceListRequest validates a new price list.
+//   - code and the optional customer group are 1 to 50 lowercase letters, digits, '-' or '_'.
+//   - currency is a supported ISO 4217 code, any case.
+//   - priority is from 0 to 1000.
+func ValidateNewPriceListRequest(req domain.NewPriceListRequestDTO) lib.APIError {
+	var fieldErrs lib.ValidationErrors
+
+	validatePriceListCode(&fieldErrs, "code", req.Code)
+
+	name := strings.TrimSpace(req.Name)
+
+	switch {
+	case name == "":
+		fieldErrs.Add("name", lib.FieldCodeRequired, "name is required")
+	case utf8.RuneCountInString(name) > priceListNameMaxLength:
+		fieldErrs.Add("name", lib.FieldCodeTooLong, fmt.Sprintf("name must be at most %d characters", priceListNameMaxLength))
+	}
+
+	validateCurrency(&fieldErrs, "currency", req.Currency, true)
+
+	if req.CustomerGroup != "" {
+		validatePriceListCode(&fieldErrs, "customerGroup", req.CustomerGroup)
+	}
+
+	if req.Priority < 0 || req.Priority > maxPriceListPriority {
+		fieldErrs.Add("priority", lib.FieldCodeOutOfRange, fmt.Sprintf("priority must be from 0 to %d", maxPriceListPriority))
+	}
+
+	if fieldErrs.HasErrors() {
+		return lib.NewValidationError("invalid price list input", fieldErrs)
+	}
+
+	return nil
+}
+
+// ValidateSavePriceRequest validates a base or scheduled price, amount is in minor units,
+// a schedule with both bounds must start before it ends.
+func ValidateSavePriceRequest(req domain.SavePriceRequestDTO) lib.APIError {
+	var fieldErrs lib.ValidationErrors
+
+	validatePriceListCode(&fieldErrs, "priceList", req.PriceListCode)
+	validatePriceSKU(&fieldErrs, req.SKU)
+
+	switch {
+	case req.Amount == nil:
+		fieldErrs.Add("amount", lib.FieldCodeRequired, "amount is required")
+	case *req.Amount < 0 || *req.Amount > maxPriceAmount:
+		fieldErrs.Add("amount", lib.FieldCodeOutOfRange,
+			fmt.Sprintf("amount must be from 0 to %d minor units, you entered: %d", int64(maxPriceAmount), *req.Amount))
+	}
+
+	if req.StartsAt != nil && req.EndsAt != nil && !req.StartsAt.Before(*req.EndsAt) {
+		fieldErrs.Add("endsAt", lib.FieldCodeInvalidValue, "endsAt must be after startsAt")
+	}
+
+	if fieldErrs.HasErrors() {
+		return lib.NewValidationError("invalid price input", fieldErrs)
+	}
+
+	return nil
+}
+
+// ValidateResolvePriceRequest validates a price resolution request, returns the parsed moment, zero if it's empty.
+func ValidateResolvePriceRequest(req domain.ResolvePriceRequestDTO) (time.Time, lib.APIError) {
+	var (
+		fieldErrs lib.ValidationErrors
+		at        time.Time
+		err       error
+	)
+
+	validatePriceSKU(&fieldErrs, req.SKU)
+
+	if req.CustomerGroup != "" {
+		validatePriceListCode(&fieldErrs, "customerGroup", req.CustomerGroup)
+	}
+
+	validateCurrency(&fieldErrs, "currency", req.Currency, false)
+
+	if req.At != "" {
+		if at, err = time.Parse(time.RFC3339, req.At); err != nil {
+			fieldErrs.Add("at", lib.FieldCodeInvalidFormat,
+				fmt.Sprintf("at must be an RFC 3339 timestamp like 2024-03-01T10:00:00Z, you entered: %s", req.At))
+		}
+	}
+
+	if fieldErrs.HasErrors() {
+		return time.Time{}, lib.NewValidationError("invalid price input", fieldErrs)
+	}
+
+	return at, nil
+}
+
+func validatePriceListCode(fieldErrs *lib.ValidationErrors, field string, code string) {
+	if !regexp.MustCompile(priceListCodeRegex).MatchString(code) {
+		fieldErrs.Add(field, lib.FieldCodeInvalidFormat,
+			fmt.Sprintf("%s must be 1 to 50 lowercase letters, digits, '-' or '_', you entered: %s", field, code))
+	}
+}
+
+func validatePriceSKU(fieldErrs *lib.ValidationErrors, sku string) {
+	switch {
+	case sku == "":
+		fieldErrs.Add("sku", lib.FieldCodeRequired, "sku is required")
+	case !regexp.MustCompile(skuRegex).MatchString(sku):
+		fieldErrs.Add("sku", lib.FieldCodeInvalidFormat,
+			"sku must be 1 to 64 letters, digits, '-', '_' or '.', starting with a letter or digit")
+	}
+}
+
+func validateCurrency(fieldErrs *lib.ValidationErrors, field string, currency string, required bool) {
+	if currency == "" {
+		if required {
+			fieldErrs.Add(field, lib.FieldCodeRequired, "currency is required")
+		}
+
+		return
+	}
+
+	if _, ok := money.MinorUnits(strings.ToUpper(currency)); !ok {
+		fieldErrs.Add(field, lib.FieldCodeInvalidValue,
+			fmt.Sprintf("currency must be a supported ISO 4217 code like USD, you entered: %s", currency))
+	}
+}
@@ -0,0 +1,358 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ashtishad/ecommerce/lib"
+	"github.com/ashtishad/ecommerce/product-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSKU = "SM-S921B-128-BLK"
+
+// blackFriday is the start of the sales in testPriceLists.
+var blackFriday = time.Date(2024, 11, 29, 0, 0, 0, 0, time.UTC)
+
+func scheduled(uuid string, amount int64, currency string, starts, ends time.Time) domain.Price {
+	p := domain.Price{PriceUUID: uuid, Amount: amount, Currency: currency}
+	if !starts.IsZero() {
+		p.StartsAt = &starts
+	}
+
+	if !ends.IsZero() {
+		p.EndsAt = &ends
+	}
+
+	return p
+}
+
+func base(uuid string, amount int64, currency string) domain.Price {
+	return domain.Price{PriceUUID: uuid, Amount: amount, Currency: currency}
+}
+
+// testPriceLists price one SKU in:
+//   - retail, every customer, USD: 799.99, black friday 699.99 for 3 days, a 2 hours flash sale of 599.99 on day 2,
+//     a clearance of 499.99 from 2025-01-10 without an end.
+//   - outlet, every customer, priority 5, USD: only a year end sale of 649.99.
+//   - b2b, priority 10, USD: 600.00, 550.00 during black friday.
+//   - b2b-eu, priority 20, EUR: 570.00.
+//   - vip, USD: only 500.00 on the first day of black friday.
+func testPriceLists() []domain.SKUPriceList {
+	return []domain.SKUPriceList{
+		{
+			PriceList: domain.PriceList{Code: "b2b", Currency: "USD", CustomerGroup: "b2b", Priority: 10},
+			Prices: []domain.Price{
+				base("b2b-base", 60000, "USD"),
+				scheduled("b2b-bf", 55000, "USD", blackFriday, blackFriday.Add(72*time.Hour)),
+			},
+		},
+		{
+			PriceList: domain.PriceList{Code: "b2b-eu", Currency: "EUR", CustomerGroup: "b2b", Priority: 20},
+			Prices:    []domain.Price{base("b2b-eu-base", 57000, "EUR")},
+		},
+		{
+			PriceList: domain.PriceList{Code: "outlet", Currency: "USD", Priority: 5},
+			Prices: []domain.Price{
+				scheduled("outlet-ye", 64999, "USD", time.Date(2024, 12, 26, 0, 0, 0, 0, time.UTC),
+					time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)),
+			},
+		},
+		{
+			PriceList: domain.PriceList{Code: domain.RetailPriceList, Currency: "USD"},
+			Prices: []domain.Price{
+				base("retail-base", 79999, "USD"),
+				scheduled("retail-bf", 69999, "USD", blackFriday, blackFriday.Add(72*time.Hour)),
+				scheduled("retail-flash", 59999, "USD", blackFriday.Add(24*time.Hour), blackFriday.Add(26*time.Hour)),
+				scheduled("retail-clearance", 49999, "USD", time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC), time.Time{}),
+			},
+		},
+		{
+			PriceList: domain.PriceList{Code: "vip", Currency: "USD", CustomerGroup: "vip"},
+			Prices:    []domain.Price{scheduled("vip-bf", 50000, "USD", blackFriday, blackFriday.Add(24*time.Hour))},
+		},
+	}
+}
+
+func TestResolvePriceBoundaries(t *testing.T) {
+	dhaka := time.FixedZone("Asia/Dhaka", 6*60*60)
+
+	tests := []struct {
+		name     string
+		group    string
+		currency string
+		at       time.Time
+		price    string // uuid of the effective price, empty if none applies
+		regular  string // uuid of the regular price, empty if it isn't a sale
+	}{
+		{name: "Base price before the sale", at: blackFriday.Add(-time.Nanosecond), price: "retail-base"},
+		{name: "Sale applies at its start", at: blackFriday, price: "retail-bf", regular: "retail-base"},
+		{name: "Same instant in another zone", at: blackFriday.In(dhaka), price: "retail-bf", regular: "retail-base"},
+		{name: "Sale applies just before its end", at: blackFriday.Add(72*time.Hour - time.Nanosecond), price: "retail-bf", regular: "retail-base"},
+		{name: "Sale doesn't apply at its end", at: blackFriday.Add(72 * time.Hour), price: "retail-base"},
+		{name: "Later overlapping sale wins at its start", at: blackFriday.Add(24 * time.Hour), price: "retail-flash", regular: "retail-base"},
+		{name: "Outer sale applies again at the inner end", at: blackFriday.Add(26 * time.Hour), price: "retail-bf", regular: "retail-base"},
+		{name: "Open ended sale applies forever", at: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), price: "retail-clearance", regular: "retail-base"},
+		{name: "Open ended sale doesn't apply before its start", at: time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC).Add(-time.Second), price: "retail-base"},
+		{
+			name: "Higher priority list for every customer wins while its sale applies",
+			at:   time.Date(2024, 12, 26, 0, 0, 0, 0, time.UTC), price: "outlet-ye",
+		},
+		{name: "Scheduled only list falls through at its end", at: time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), price: "retail-base"},
+		{name: "Group list wins over lists of every customer", group: "b2b", currency: "USD", at: blackFriday.Add(-time.Hour), price: "b2b-base"},
+		{name: "Group sale applies at its start", group: "b2b", currency: "USD", at: blackFriday, price: "b2b-bf", regular: "b2b-base"},
+		{name: "Retail currency by default", group: "b2b", at: blackFriday, price: "b2b-bf", regular: "b2b-base"},
+		{name: "Currency selects the list", group: "b2b", currency: "EUR", at: blackFriday, price: "b2b-eu-base"},
+		{name: "Group sale without a base price", group: "vip", at: blackFriday, price: "vip-bf"},
+		{
+			name: "Group without an active price falls back to retail", group: "vip", at: blackFriday.Add(24 * time.Hour),
+			price: "retail-flash", regular: "retail-base",
+		},
+		{name: "Unknown group gets prices of every customer", group: "wholesale", at: blackFriday, price: "retail-bf", regular: "retail-base"},
+		{name: "Group lists don't apply to other customers", currency: "EUR", at: blackFriday},
+		{name: "No list in the currency", group: "b2b", currency: "JPY", at: blackFriday},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			effective := resolvePrice(testPriceLists(), tt.group, tt.currency, tt.at)
+			if tt.price == "" {
+				require.Nil(t, effective)
+				return
+			}
+
+			require.NotNil(t, effective)
+			assert.Equal(t, tt.price, effective.Price.PriceUUID)
+			assert.True(t, effective.At.Equal(tt.at))
+
+			if tt.regular == "" {
+				assert.Nil(t, effective.Regular)
+			} else {
+				require.NotNil(t, effective.Regular)
+				assert.Equal(t, tt.regular, effective.Regular.PriceUUID)
+			}
+		})
+	}
+}
+
+func TestResolvePriceTies(t *testing.T) {
+	ends := blackFriday.Add(24 * time.Hour)
+
+	t.Run("Last added wins on the same start", func(t *testing.T) {
+		lists := []domain.SKUPriceList{{
+			PriceList: domain.PriceList{Code: domain.RetailPriceList, Currency: "USD"},
+			Prices: []domain.Price{
+				scheduled("first", 70000, "USD", blackFriday, ends),
+				scheduled("second", 65000, "USD", blackFriday, ends.Add(time.Hour)),
+			},
+		}}
+
+		effective := resolvePrice(lists, "", "", blackFriday)
+		require.NotNil(t, effective)
+		assert.Equal(t, "second", effective.Price.PriceUUID)
+	})
+
+	t.Run("Open start is the earliest start", func(t *testing.T) {
+		lists := []domain.SKUPriceList{{
+			PriceList: domain.PriceList{Code: domain.RetailPriceList, Currency: "USD"},
+			Prices: []domain.Price{
+				scheduled("started", 70000, "USD", blackFriday, ends),
+				scheduled("open", 65000, "USD", time.Time{}, ends),
+			},
+		}}
+
+		effective := resolvePrice(lists, "", "", blackFriday)
+		require.NotNil(t, effective)
+		assert.Equal(t, "started", effective.Price.PriceUUID)
+		assert.Nil(t, effective.Regular)
+
+		effective = resolvePrice(lists, "", "", blackFriday.Add(-time.Nanosecond))
+		require.NotNil(t, effective)
+		assert.Equal(t, "open", effective.Price.PriceUUID)
+	})
+
+	t.Run("Same priority lists are ordered by code", func(t *testing.T) {
+		lists := []domain.SKUPriceList{
+			{PriceList: domain.PriceList{Code: "zeta", Currency: "USD"}, Prices: []domain.Price{base("zeta", 100, "USD")}},
+			{PriceList: domain.PriceList{Code: "alpha", Currency: "USD"}, Prices: []domain.Price{base("alpha", 200, "USD")}},
+		}
+
+		effective := resolvePrice(lists, "", "USD", blackFriday)
+		require.NotNil(t, effective)
+		assert.Equal(t, "alpha", effective.PriceList.Code)
+
+		// no retail list, no default currency
+		assert.Nil(t, resolvePrice(lists, "", "", blackFriday))
+	})
+}
+
+// priceRepo serves testPriceLists, other methods aren't expected to be called.
+type priceRepo struct {
+	domain.PriceRepository
+	err lib.APIError
+}
+
+func (r *priceRepo) FindSKUPrices(_ context.Context, _ string) ([]domain.SKUPriceList, lib.APIError) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return testPriceLists(), nil
+}
+
+func TestResolvePrice(t *testing.T) {
+	s := &DefaultPriceService{repo: &priceRepo{}, now: func() time.Time { return blackFriday.Add(24 * time.Hour) }}
+
+	t.Run("Now by default", func(t *testing.T) {
+		price, apiErr := s.ResolvePrice(context.Background(), domain.ResolvePriceRequestDTO{SKU: testSKU})
+		require.Nil(t, apiErr)
+		assert.Equal(t, testSKU, price.SKU)
+		assert.Equal(t, domain.RetailPriceList, price.PriceList)
+		assert.Equal(t, domain.MoneyDTO{Amount: 59999, Currency: "USD", Formatted: "599.99"}, price.Price)
+		assert.Equal(t, &domain.MoneyDTO{Amount: 79999, Currency: "USD", Formatted: "799.99"}, price.RegularPrice)
+		assert.True(t, price.EndsAt.Equal(blackFriday.Add(26*time.Hour)))
+	})
+
+	t.Run("Requested moment and lowercase currency", func(t *testing.T) {
+		price, apiErr := s.ResolvePrice(context.Background(), domain.ResolvePriceRequestDTO{
+			SKU: testSKU, CustomerGroup: "b2b", Currency: "usd", At: "2024-11-28T23:59:59Z",
+		})
+		require.Nil(t, apiErr)
+		assert.Equal(t, "b2b", price.PriceList)
+		assert.Equal(t, int64(60000), price.Price.Amount)
+		assert.Nil(t, price.RegularPrice)
+	})
+
+	t.Run("No applicable price", func(t *testing.T) {
+		price, apiErr := s.ResolvePrice(context.Background(), domain.ResolvePriceRequestDTO{SKU: testSKU, Currency: "JPY"})
+		require.Nil(t, price)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+		assert.Equal(t, domain.ErrCodePriceUnavailable, apiErr.ErrorCode())
+	})
+
+	t.Run("Repository error", func(t *testing.T) {
+		notFound := lib.NewError(http.StatusNotFound, domain.ErrCodePriceSKUNotFound, lib.Args{"sku": testSKU})
+		s := &DefaultPriceService{repo: &priceRepo{err: notFound}, now: time.Now}
+
+		_, apiErr := s.ResolvePrice(context.Background(), domain.ResolvePriceRequestDTO{SKU: testSKU})
+		assert.Equal(t, notFound, apiErr)
+	})
+
+	t.Run("Invalid params", func(t *testing.T) {
+		_, apiErr := s.ResolvePrice(context.Background(), domain.ResolvePriceRequestDTO{
+			SKU: "-bad", CustomerGroup: "B2B", Currency: "XYZ", At: "2024-11-29",
+		})
+		require.ErrorIs(t, apiErr, lib.ErrValidation)
+		assert.Equal(t, []string{"sku", "customerGroup", "currency", "at"}, fieldNames(apiErr.FieldErrors()))
+	})
+}
+
+func TestSaveRetailBasePrice(t *testing.T) {
+	s := NewPriceService(&priceRepo{})
+	amount := int64(79999)
+
+	price, apiErr := s.SavePrice(context.Background(), domain.SavePriceRequestDTO{
+		PriceListCode: domain.RetailPriceList, SKU: testSKU, Amount: &amount,
+	})
+	require.Nil(t, price)
+	require.ErrorIs(t, apiErr, lib.ErrConflict)
+	assert.Equal(t, domain.ErrCodePriceBaseManaged, apiErr.ErrorCode())
+}
+
+func TestValidateSavePriceRequest(t *testing.T) {
+	amount, negative := int64(69999), int64(-1)
+	starts, ends := blackFriday, blackFriday.Add(72*time.Hour)
+
+	tests := []struct {
+		name   string
+		req    domain.SavePriceRequestDTO
+		fields []string
+	}{
+		{
+			name: "Base price",
+			req:  domain.SavePriceRequestDTO{PriceListCode: "b2b", SKU: testSKU, Amount: &amount},
+		},
+		{
+			name: "Scheduled price with an open end",
+			req:  domain.SavePriceRequestDTO{PriceListCode: "retail", SKU: testSKU, Amount: &amount, StartsAt: &starts},
+		},
+		{
+			name:   "Missing fields",
+			req:    domain.SavePriceRequestDTO{PriceListCode: "B2B"},
+			fields: []string{"priceList", "sku", "amount"},
+		},
+		{
+			name:   "Negative amount",
+			req:    domain.SavePriceRequestDTO{PriceListCode: "b2b", SKU: testSKU, Amount: &negative},
+			fields: []string{"amount"},
+		},
+		{
+			name:   "Schedule ends at its start",
+			req:    domain.SavePriceRequestDTO{PriceListCode: "b2b", SKU: testSKU, Amount: &amount, StartsAt: &starts, EndsAt: &starts},
+			fields: []string{"endsAt"},
+		},
+		{
+			name:   "Schedule ends before its start",
+			req:    domain.SavePriceRequestDTO{PriceListCode: "b2b", SKU: testSKU, Amount: &amount, StartsAt: &ends, EndsAt: &starts},
+			fields: []string{"endsAt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiErr := ValidateSavePriceRequest(tt.req)
+			if tt.fields == nil {
+				require.Nil(t, apiErr)
+				return
+			}
+
+			require.ErrorIs(t, apiErr, lib.ErrValidation)
+			assert.Equal(t, tt.fields, fieldNames(apiErr.FieldErrors()))
+		})
+	}
+}
+
+func TestValidateNewPriceListRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    domain.NewPriceListRequestDTO
+		fields []string
+	}{
+		{
+			name: "Valid list of a customer group",
+			req:  domain.NewPriceListRequestDTO{Code: "b2b", Name: "Business", Currency: "usd", CustomerGroup: "b2b", Priority: 10},
+		},
+		{
+			name: "Valid list of every customer",
+			req:  domain.NewPriceListRequestDTO{Code: "jp-retail", Name: "Japan", Currency: "JPY"},
+		},
+		{
+			name:   "Missing fields",
+			req:    domain.NewPriceListRequestDTO{Name: " "},
+			fields: []string{"code", "name", "currency"},
+		},
+		{
+			name: "Invalid fields",
+			req: domain.NewPriceListRequestDTO{
+				Code: "B2B", Name: "Business", Currency: "US", CustomerGroup: "big spenders", Priority: maxPriceListPriority + 1,
+			},
+			fields: []string{"code", "currency", "customerGroup", "priority"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiErr := ValidateNewPriceListRequest(tt.req)
+			if tt.fields == nil {
+				require.Nil(t, apiErr)
+				return
+			}
+
+			require.ErrorIs(t, apiErr, lib.ErrValidation)
+			assert.Equal(t, tt.fields, fieldNames(apiErr.FieldErrors()))
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ashtishad/ecommerce/lib"
+	"github.com/ashtishad/ecommerce/product-api/internal/domain"
+)
+
+const (
+	searchQueryMaxLength     = 200
+	maxSearchBrands          = 20
+	maxAttributeFilters      = 10
+	maxAttributeFilterValues = 20
+)
+
+// searchCursor is the decoded after param of product search, the sort key and uuid of the previous page's last product.
+type searchCursor struct {
+	Sort        string `json:"sort"`
+	Key         string `json:"key"`
+	ProductUUID string `json:"id"`
+}
+
+// SearchProducts returns a page of active products with a variant matching every filter, with facet counts.
+// One more product than the limit is fetched to tell whether there is a next page.
+//   - returns 400 if a query param is invalid.
+//   - returns 404 if the filtered category doesn't exist.
+func (s *DefaultProductService) SearchProducts(ctx context.Context, req domain.ProductSearchRequestDTO) (*domain.ProductSearchResponseDTO, lib.APIError) {
+	filter, apiErr := ValidateProductSearchRequest(req)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	limit := filter.Limit
+	filter.Limit++
+
+	hits, apiErr := s.repo.SearchProducts(ctx, filter)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	facets, apiErr := s.searchFacets(ctx, filter)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	page := &domain.ProductSearchResponseDTO{Products: make([]*domain.ProductResponseDTO, 0, limit), Facets: facets}
+
+	if len(hits) > limit {
+		hits = hits[:limit]
+		last := hits[limit-1]
+		page.NextCursor = encodeSearchCursor(searchCursor{Sort: filter.Sort, Key: last.SortKey, ProductUUID: last.Product.ProductUUID})
+	}
+
+	for _, hit := range hits {
+		page.Products = append(page.Products, hit.Product.ToProductResponseDTO())
+	}
+
+	return page, nil
+}
+
+// searchFacets counts matching products per brand and attribute value. Counts of a filtered facet ignore its own
+// filter, e.g. other brands are counted while brand=samsung is selected, the repository counts every facet in one query.
+func (s *DefaultProductService) searchFacets(ctx context.Context, filter domain.ProductSearchFilter) (*domain.ProductSearchFacetsDTO, lib.APIError) {
+	counts, apiErr := s.repo.FindProductSearchFacets(ctx, filter)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	facets := &domain.ProductSearchFacetsDTO{Brands: []*domain.FacetValueDTO{}, Attributes: map[string][]*domain.FacetValueDTO{}}
+
+	// counts are in their order, most products first
+	for _, c := range counts {
+		value := &domain.FacetValueDTO{Value: c.Value, Count: c.Count}
+
+		if c.Facet == domain.FacetBrand {
+			facets.Brands = append(facets.Brands, value)
+		} else {
+			facets.Attributes[c.Code] = append(facets.Attributes[c.Code], value)
+		}
+	}
+
+	return facets, nil
+}
+
+// ValidateProductSearchRequest validates GET /products/search query params, returns the search filter.
+//
+//   - q is at most 200 characters, categoryId is a category uuid.
+//   - brand is repeatable, at most 20 brands, compared case-insensitive.
+//   - minPrice and maxPrice are decimals with up to 2 fraction digits, minPrice can't be more than maxPrice.
+//   - attr[code] is a comma separated list of values, e.g. attr[color]=black,blue, or a number range with
+//     an optional bound on either side, e.g. attr[ram]=8..16, attr[ram]=8.., at most 10 attributes.
+//   - sort is relevance(default with q, needs q), newest(default), price_asc or price_desc.
+//   - limit is 1 to 100, 20 by default, after is the nextCursor of the previous page with the same sort.
+func ValidateProductSearchRequest(req domain.ProductSearchRequestDTO) (domain.ProductSearchFilter, lib.APIError) {
+	var (
+		fieldErrs lib.ValidationErrors
+		filter    = domain.ProductSearchFilter{
+			Query:        strings.TrimSpace(req.Query),
+			CategoryUUID: req.CategoryUUID,
+			MinPrice:     req.MinPrice,
+			MaxPrice:     req.MaxPrice,
+			Limit:        defaultProductLimit,
+		}
+		err error
+	)
+
+	if utf8.RuneCountInString(filter.Query) > searchQueryMaxLength {
+		fieldErrs.Add("q", lib.FieldCodeTooLong, fmt.Sprintf("search query must be at most %d characters", searchQueryMaxLength))
+	}
+
+	if req.CategoryUUID != "" {
+		validateUUIDField(&fieldErrs, "categoryId", req.CategoryUUID)
+	}
+
+	filter.Brands = parseSearchBrands(&fieldErrs, req.Brands)
+	validateSearchPrices(&fieldErrs, req.MinPrice, req.MaxPrice)
+
+	if len(req.Attributes) > maxAttributeFilters {
+		fieldErrs.Add("attr", lib.FieldCodeTooLong, fmt.Sprintf("at most %d attribute filters are allowed", maxAttributeFilters))
+	}
+
+	for _, code := range sortedKeys(req.Attributes) {
+		if f, ok := parseAttributeFilter(&fieldErrs, code, req.Attributes[code]); ok {
+			filter.Attributes = append(filter.Attributes, f)
+		}
+	}
+
+	filter.Sort = searchSort(&fieldErrs, req.Sort, filter.Query)
+
+	if req.LimitStr != "" {
+		filter.Limit, err = strconv.Atoi(req.LimitStr)
+		if err != nil || filter.Limit < 1 || filter.Limit > maxProductLimit {
+			fieldErrs.Add("limit", lib.FieldCodeOutOfRange,
+				fmt.Sprintf("limit must be a number from 1 to %d, you entered: %s", maxProductLimit, req.LimitStr))
+		}
+	}
+
+	if req.After != "" {
+		cursor, ok := decodeSearchCursor(req.After)
+		if !ok || cursor.Sort != filter.Sort {
+			fieldErrs.Add("after", lib.FieldCodeInvalidValue, "after must be the nextCursor of a search with the same sort")
+		}
+
+		filter.AfterUUID, filter.AfterKey = cursor.ProductUUID, cursor.Key
+	}
+
+	if fieldErrs.HasErrors() {
+		return domain.ProductSearchFilter{}, lib.NewValidationError("invalid product search input", fieldErrs)
+	}
+
+	return filter, nil
+}
+
+// parseSearchBrands returns trimmed lowercase brands.
+func parseSearchBrands(fieldErrs *lib.ValidationErrors, brands []string) []string {
+	if len(brands) > maxSearchBrands {
+		fieldErrs.Add("brand", lib.FieldCodeTooLong, fmt.Sprintf("at most %d brands are allowed", maxSearchBrands))
+	}
+
+	parsed := make([]string, 0, len(brands))
+
+	for _, brand := range brands {
+		brand = strings.ToLower(strings.TrimSpace(brand))
+
+		switch {
+		case brand == "":
+			fieldErrs.Add("brand", lib.FieldCodeRequired, "brand cannot be empty")
+		case utf8.RuneCountInString(brand) > productBrandMaxLength:
+			fieldErrs.Add("brand", lib.FieldCodeTooLong, fmt.Sprintf("brand must be at most %d characters", productBrandMaxLength))
+		default:
+			parsed = append(parsed, brand)
+		}
+	}
+
+	return parsed
+}
+
+// searchSort returns the sort, relevance by default with a query, newest without one.
+func searchSort(fieldErrs *lib.ValidationErrors, sort, query string) string {
+	switch sort {
+	case "":
+		if query != "" {
+			return domain.ProductSortRelevance
+		}
+
+		return domain.ProductSortNewest
+	case domain.ProductSortRelevance:
+		if query == "" {
+			fieldErrs.Add("sort", lib.FieldCodeInvalidValue, "relevance sort needs a search query")
+		}
+	case domain.ProductSortNewest, domain.ProductSortPriceAsc, domain.ProductSortPriceDesc:
+	default:
+		fieldErrs.Add("sort", lib.FieldCodeInvalidValue, "sort must be 'relevance', 'newest', 'price_asc' or 'price_desc'")
+	}
+
+	return sort
+}
+
+// validateSearchPrices validates the price range, minPrice is compared with maxPrice when both are valid.
+func validateSearchPrices(fieldErrs *lib.ValidationErrors, minPrice, maxPrice string) {
+	priceFormat := regexp.MustCompile(priceRegex)
+	valid := true
+
+	if minPrice != "" && !priceFormat.MatchString(minPrice) {
+		fieldErrs.Add("minPrice", lib.FieldCodeInvalidFormat, "minPrice must be a non-negative decimal with up to 2 fraction digits")
+		valid = false
+	}
+
+	if maxPrice != "" && !priceFormat.MatchString(maxPrice) {
+		fieldErrs.Add("maxPrice", lib.FieldCodeInvalidFormat, "maxPrice must be a non-negative decimal with up to 2 fraction digits")
+		valid = false
+	}
+
+	if !valid || minPrice == "" || maxPrice == "" {
+		return
+	}
+
+	minValue, _ := strconv.ParseFloat(minPrice, 64)
+	maxValue, _ := strconv.ParseFloat(maxPrice, 64)
+
+	if minValue > maxValue {
+		fieldErrs.Add("minPrice", lib.FieldCodeOutOfRange, "minPrice can't be more than maxPrice")
+	}
+}
+
+// parseAttributeFilter parses attr[code], a number range lo..hi or a comma separated list of values.
+func parseAttributeFilter(fieldErrs *lib.ValidationErrors, code, value string) (domain.AttributeFilter, bool) {
+	field := "attr[" + code + "]"
+	f := domain.AttributeFilter{Code: code}
+
+	if !regexp.MustCompile(attributeCodeRegex).MatchString(code) {
+		fieldErrs.Add(field, lib.FieldCodeInvalidFormat, "attribute code must be lowercase letters, digits or underscores starting with a letter")
+		return f, false
+	}
+
+	if lo, hi, isRange := strings.Cut(value, ".."); isRange {
+		f.Min, f.Max = strings.TrimSpace(lo), strings.TrimSpace(hi)
+
+		minValue, minErr := parseFiniteFloat(f.Min)
+		maxValue, maxErr := parseFiniteFloat(f.Max)
+
+		switch {
+		case f.Min == "" && f.Max == "",
+			f.Min != "" && minErr != nil,
+			f.Max != "" && maxErr != nil:
+			fieldErrs.Add(field, lib.FieldCodeInvalidFormat, "range must be numbers like 8..16, 8.. or ..16, you entered: "+value)
+			return f, false
+		case f.Min != "" && f.Max != "" && minValue > maxValue:
+			fieldErrs.Add(field, lib.FieldCodeOutOfRange, "range start can't be more than its end, you entered: "+value)
+			return f, false
+		}
+
+		return f, true
+	}
+
+	for _, v := range strings.Split(value, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" || utf8.RuneCountInString(v) > attributeValueMaxLength {
+			fieldErrs.Add(field, lib.FieldCodeInvalidValue,
+				fmt.Sprintf("values must be 1 to %d characters, separated by commas", attributeValueMaxLength))
+
+			return f, false
+		}
+
+		f.Values = append(f.Values, v)
+	}
+
+	if len(f.Values) > maxAttributeFilterValues {
+		fieldErrs.Add(field, lib.FieldCodeTooLong, fmt.Sprintf("at most %d values are allowed", maxAttributeFilterValues))
+		return f, false
+	}
+
+	return f, true
+}
+
+// parseFiniteFloat parses a number, rejecting nan and inf which strconv.ParseFloat accepts.
+func parseFiniteFloat(value string) (float64, error) {
+	number, err := strconv.ParseFloat(value, 64)
+	if err == nil && (math.IsNaN(number) || math.IsInf(number, 0)) {
+		return 0, strconv.ErrSyntax
+	}
+
+	return number, err
+}
+
+func encodeSearchCursor(cursor searchCursor) string {
+	b, _ := json.Marshal(cursor) // a struct of strings always encodes
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeSearchCursor(after string) (searchCursor, bool) {
+	var cursor searchCursor
+
+	b, err := base64.RawURLEncoding.DecodeString(after)
+	if err != nil || json.Unmarshal(b, &cursor) != nil || cursor.ProductUUID == "" {
+		return searchCursor{}, false
+	}
+
+	if _, err = strconv.ParseFloat(cursor.Key, 64); err != nil || !regexp.MustCompile(categoryUUIDRegex).MatchString(cursor.ProductUUID) {
+		return searchCursor{}, false
+	}
+
+	return cursor, true
+}
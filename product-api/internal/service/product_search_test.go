@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ashtishad/ecommerce/lib"
+	"github.com/ashtishad/ecommerce/product-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateProductSearchRequest(t *testing.T) {
+	cursor := encodeSearchCursor(searchCursor{Sort: domain.ProductSortPriceAsc, Key: "-799.99", ProductUUID: testProductCategoryUUID})
+
+	tests := []struct {
+		name   string
+		req    domain.ProductSearchRequestDTO
+		filter domain.ProductSearchFilter
+		fields []string
+	}{
+		{
+			name:   "Defaults to newest without a query",
+			filter: domain.ProductSearchFilter{Brands: []string{}, Sort: domain.ProductSortNewest, Limit: defaultProductLimit},
+		},
+		{
+			name: "Defaults to relevance with a query",
+			req:  domain.ProductSearchRequestDTO{Query: " gaming phone ", Brands: []string{" ASUS "}},
+			filter: domain.ProductSearchFilter{
+				Query: "gaming phone", Brands: []string{"asus"}, Sort: domain.ProductSortRelevance, Limit: defaultProductLimit,
+			},
+		},
+		{
+			name: "Attribute values, ranges and a cursor",
+			req: domain.ProductSearchRequestDTO{
+				CategoryUUID: testProductCategoryUUID, MinPrice: "300", MaxPrice: "999.99",
+				Attributes: map[string]string{"ram": "8..16", "color": "black, blue", "battery_mah": "4500.."},
+				Sort:       domain.ProductSortPriceAsc, LimitStr: "10", After: cursor,
+			},
+			filter: domain.ProductSearchFilter{
+				CategoryUUID: testProductCategoryUUID, Brands: []string{}, MinPrice: "300", MaxPrice: "999.99",
+				Attributes: []domain.AttributeFilter{
+					{Code: "battery_mah", Min: "4500"},
+					{Code: "color", Values: []string{"black", "blue"}},
+					{Code: "ram", Min: "8", Max: "16"},
+				},
+				Sort: domain.ProductSortPriceAsc, AfterUUID: testProductCategoryUUID, AfterKey: "-799.99", Limit: 10,
+			},
+		},
+		{
+			name: "Invalid params",
+			req: domain.ProductSearchRequestDTO{
+				CategoryUUID: "x", Brands: []string{" "}, MinPrice: "10.999", MaxPrice: "5",
+				Attributes: map[string]string{"Color": "black", "ram": "16..8", "weight": ".."}, Sort: "popular", LimitStr: "0",
+			},
+			fields: []string{"categoryId", "brand", "minPrice", "attr[Color]", "attr[ram]", "attr[weight]", "sort", "limit"},
+		},
+		{
+			name: "Non finite range bounds are invalid",
+			req: domain.ProductSearchRequestDTO{
+				Attributes: map[string]string{"color": "nan", "ram": "inf..", "weight": "..NaN"},
+			},
+			fields: []string{"attr[ram]", "attr[weight]"},
+		},
+		{
+			name: "Non finite value is a string",
+			req:  domain.ProductSearchRequestDTO{Attributes: map[string]string{"color": "nan"}},
+			filter: domain.ProductSearchFilter{
+				Brands: []string{}, Attributes: []domain.AttributeFilter{{Code: "color", Values: []string{"nan"}}},
+				Sort: domain.ProductSortNewest, Limit: defaultProductLimit,
+			},
+		},
+		{
+			name:   "Relevance needs a query and cursor of another sort",
+			req:    domain.ProductSearchRequestDTO{Sort: domain.ProductSortRelevance, MinPrice: "50", MaxPrice: "10", After: cursor},
+			fields: []string{"minPrice", "sort", "after"},
+		},
+		{
+			name:   "Malformed cursor",
+			req:    domain.ProductSearchRequestDTO{After: "not-a-cursor"},
+			fields: []string{"after"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, apiErr := ValidateProductSearchRequest(tt.req)
+			if tt.fields == nil {
+				require.Nil(t, apiErr)
+				assert.Equal(t, tt.filter, filter)
+
+				return
+			}
+
+			require.NotNil(t, apiErr)
+			assert.Equal(t, tt.fields, fieldNames(apiErr.FieldErrors()))
+		})
+	}
+}
+
+// facetRepo returns facet counts in repository order, brands first, then attributes by code.
+type facetRepo struct {
+	domain.ProductRepository
+	calls int
+}
+
+func (r *facetRepo) FindProductSearchFacets(_ context.Context, _ domain.ProductSearchFilter) ([]domain.FacetCount, lib.APIError) {
+	r.calls++
+
+	return []domain.FacetCount{
+		{Facet: domain.FacetBrand, Value: "Asus", Count: 2},
+		{Facet: domain.FacetBrand, Value: "Samsung", Count: 1},
+		{Facet: domain.FacetAttribute, Code: "color", Value: "black", Count: 1},
+		{Facet: domain.FacetAttribute, Code: "ram", Value: "12", Count: 2},
+		{Facet: domain.FacetAttribute, Code: "ram", Value: "8", Count: 1},
+	}, nil
+}
+
+// TestSearchFacets makes sure facets of every filter come from one repository call, grouped in their order.
+func TestSearchFacets(t *testing.T) {
+	repo := &facetRepo{}
+	s := NewProductService(repo)
+
+	facets, apiErr := s.searchFacets(context.Background(), domain.ProductSearchFilter{
+		Brands:     []string{"asus"},
+		Attributes: []domain.AttributeFilter{{Code: "ram", Values: []string{"12"}}, {Code: "color", Values: []string{"black"}}},
+	})
+	require.Nil(t, apiErr)
+	assert.Equal(t, 1, repo.calls)
+	assert.Equal(t, []*domain.FacetValueDTO{{Value: "Asus", Count: 2}, {Value: "Samsung", Count: 1}}, facets.Brands)
+	assert.Equal(t, []*domain.FacetValueDTO{{Value: "12", Count: 2}, {Value: "8", Count: 1}}, facets.Attributes["ram"])
+	assert.Equal(t, []*domain.FacetValueDTO{{Value: "black", Count: 1}}, facets.Attributes["color"])
+}
+
+func TestSearchCursor(t *testing.T) {
+	cursor := searchCursor{Sort: domain.ProductSortRelevance, Key: "0.0607927", ProductUUID: testProductCategoryUUID}
+
+	decoded, ok := decodeSearchCursor(encodeSearchCursor(cursor))
+	require.True(t, ok)
+	assert.Equal(t, cursor, decoded)
+
+	_, ok = decodeSearchCursor(encodeSearchCursor(searchCursor{Sort: domain.ProductSortNewest, Key: "0; DROP", ProductUUID: "x"}))
+	assert.False(t, ok)
+}
@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ashtishad/ecommerce/lib"
+	"github.com/ashtishad/ecommerce/product-api/internal/domain"
+)
+
+type ProductService interface {
+	NewProduct(ctx context.Context, req domain.NewProductRequestDTO) (*domain.ProductResponseDTO, lib.APIError)
+	GetProduct(ctx context.Context, productUUID string) (*domain.ProductResponseDTO, lib.APIError)
+	GetProducts(ctx context.Context, req domain.ProductListRequestDTO) (*domain.ProductListResponseDTO, lib.APIError)
+	UpdateProduct(ctx context.Context, req domain.UpdateProductRequestDTO) (*domain.ProductResponseDTO, lib.APIError)
+	AddProductVariant(ctx context.Context, req domain.ProductVariantRequestDTO) (*domain.ProductVariantResponseDTO, lib.APIError)
+	UpdateProductVariant(ctx context.Context, req domain.ProductVariantRequestDTO) (*domain.ProductVariantResponseDTO, lib.APIError)
+	SearchProducts(ctx context.Context, req domain.ProductSearchRequestDTO) (*domain.ProductSearchResponseDTO, lib.APIError)
+}
+
+type DefaultProductService struct {
+	repo domain.ProductRepository
+}
+
+func NewProductService(repo domain.ProductRepository) *DefaultProductService {
+	return &DefaultProductService{repo: repo}
+}
+
+// NewProduct validates the request and creates a product with its variants, status defaults to draft.
+// Product and variant attributes are validated against attribute definitions of the product's categories.
+func (s *DefaultProductService) NewProduct(ctx context.Context, req domain.NewProductRequestDTO) (*domain.ProductResponseDTO, lib.APIError) {
+	if apiErr := ValidateNewProductRequest(req); apiErr != nil {
+		return nil, apiErr
+	}
+
+	status := req.Status
+	if status == "" {
+		status = domain.ProductStatusDraft
+	}
+
+	primaryUUID := primaryCategoryUUID(req.PrimaryCategoryUUID, req.CategoryUUIDs)
+
+	defs, apiErr := s.repo.FindProductAttributeDefinitions(ctx, primaryFirst(primaryUUID, req.CategoryUUIDs))
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	variantSets := make([]attributeSet, len(req.Variants))
+	for i, v := range req.Variants {
+		variantSets[i] = attributeSet{field: fmt.Sprintf("variants[%d]", i), values: v.Attributes}
+	}
+
+	if apiErr = validateProductAttributes(defs, attributeSet{values: req.Attributes}, variantSets,
+		status == domain.ProductStatusActive); apiErr != nil {
+		return nil, apiErr
+	}
+
+	product := domain.Product{
+		Title:               strings.TrimSpace(req.Title),
+		Description:         req.Description,
+		Brand:               strings.TrimSpace(req.Brand),
+		Status:              status,
+		CategoryUUIDs:       req.CategoryUUIDs,
+		PrimaryCategoryUUID: primaryUUID,
+		Attributes:          toProductAttributes(req.Attributes, defs),
+		Variants:            make([]domain.ProductVariant, len(req.Variants)),
+	}
+
+	for i := range req.Variants {
+		product.Variants[i] = toProductVariant(req.Variants[i], defs)
+	}
+
+	created, apiErr := s.repo.CreateProduct(ctx, product)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	return created.ToProductResponseDTO(), nil
+}
+
+// GetProduct returns a product with its category uuids and variants.
+func (s *DefaultProductService) GetProduct(ctx context.Context, productUUID string) (*domain.ProductResponseDTO, lib.APIError) {
+	if apiErr := ValidateProductUUID(productUUID); apiErr != nil {
+		return nil, apiErr
+	}
+
+	product, apiErr := s.repo.FindProductByUUID(ctx, productUUID)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	return product.ToProductResponseDTO(), nil
+}
+
+// GetProducts returns a page of products newest first, one more product than the limit is fetched
+// to tell whether there is a next page. With includeDescendants products of the category's subtree are listed.
+func (s *DefaultProductService) GetProducts(ctx context.Context, req domain.ProductListRequestDTO) (*domain.ProductListResponseDTO, lib.APIError) {
+	filter, apiErr := ValidateProductListRequest(req)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	limit := filter.Limit
+	filter.Limit++
+
+	products, apiErr := s.repo.FindProducts(ctx, filter)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	page := &domain.ProductListResponseDTO{Products: make([]*domain.ProductResponseDTO, 0, limit)}
+
+	if len(products) > limit {
+		products = products[:limit]
+		page.NextCursor = products[limit-1].ProductUUID
+	}
+
+	for _, p := range products {
+		page.Products = append(page.Products, p.ToProductResponseDTO())
+	}
+
+	return page, nil
+}
+
+// UpdateProduct validates the request and replaces the editable fields, attributes and categories of a product.
+// Attributes and attributes of existing variants are validated against attribute definitions of the new categories,
+// variants must drop values the new categories don't define before categories change. An active product's
+// existing variants may set required attributes the product doesn't.
+func (s *DefaultProductService) UpdateProduct(ctx context.Context, req domain.UpdateProductRequestDTO) (*domain.ProductResponseDTO, lib.APIError) {
+	if apiErr := ValidateUpdateProductRequest(req); apiErr != nil {
+		return nil, apiErr
+	}
+
+	existing, apiErr := s.repo.FindProductByUUID(ctx, req.ProductUUID)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	primaryUUID := primaryCategoryUUID(req.PrimaryCategoryUUID, req.CategoryUUIDs)
+
+	defs, apiErr := s.repo.FindProductAttributeDefinitions(ctx, primaryFirst(primaryUUID, req.CategoryUUIDs))
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if apiErr = validateProductAttributes(defs, attributeSet{values: req.Attributes}, variantAttributeSets(existing.Variants),
+		req.Status == domain.ProductStatusActive); apiErr != nil {
+		return nil, apiErr
+	}
+
+	updated, apiErr := s.repo.UpdateProduct(ctx, domain.Product{
+		ProductUUID:         req.ProductUUID,
+		Title:               strings.TrimSpace(req.Title),
+		Description:         req.Description,
+		Brand:               strings.TrimSpace(req.Brand),
+		Status:              req.Status,
+		CategoryUUIDs:       req.CategoryUUIDs,
+		PrimaryCategoryUUID: primaryUUID,
+		Attributes:          toProductAttributes(req.Attributes, defs),
+	})
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	return updated.ToProductResponseDTO(), nil
+}
+
+// AddProductVariant validates the request and adds a variant to a product.
+func (s *DefaultProductService) AddProductVariant(ctx context.Context, req domain.ProductVariantRequestDTO) (*domain.ProductVariantResponseDTO, lib.APIError) {
+	if apiErr := ValidateProductVariantRequest(req, false); apiErr != nil {
+		return nil, apiErr
+	}
+
+	defs, apiErr := s.validateVariantAttributes(ctx, req)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	added, apiErr := s.repo.AddProductVariant(ctx, req.ProductUUID, toProductVariant(req, defs))
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	return added.ToProductVariantResponseDTO(), nil
+}
+
+// UpdateProductVariant validates the request and replaces sku, barcode, options, price and attributes of a variant.
+func (s *DefaultProductService) UpdateProductVariant(ctx context.Context, req domain.ProductVariantRequestDTO) (*domain.ProductVariantResponseDTO, lib.APIError) {
+	if apiErr := ValidateProductVariantRequest(req, true); apiErr != nil {
+		return nil, apiErr
+	}
+
+	defs, apiErr := s.validateVariantAttributes(ctx, req)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	updated, apiErr := s.repo.UpdateProductVariant(ctx, req.ProductUUID, toProductVariant(req, defs))
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	return updated.ToProductVariantResponseDTO(), nil
+}
+
+// validateVariantAttributes validates variant attributes against attribute definitions of the product's categories,
+// returns the definitions. Required attributes the product doesn't set must be set on the variant of an active product.
+func (s *DefaultProductService) validateVariantAttributes(ctx context.Context, req domain.ProductVariantRequestDTO) ([]domain.CategoryAttribute, lib.APIError) {
+	product, apiErr := s.repo.FindProductByUUID(ctx, req.ProductUUID)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	// category uuids of a product are primary first
+	defs, apiErr := s.repo.FindProductAttributeDefinitions(ctx, product.CategoryUUIDs)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if apiErr = validateProductAttributes(defs, attributeSet{values: attributeValues(product.Attributes), stored: true},
+		[]attributeSet{{values: req.Attributes}}, product.Status == domain.ProductStatusActive); apiErr != nil {
+		return nil, apiErr
+	}
+
+	return defs, nil
+}
+
+// primaryCategoryUUID returns the requested primary category, the first category by default.
+func primaryCategoryUUID(primaryUUID string, categoryUUIDs []string) string {
+	if primaryUUID == "" && len(categoryUUIDs) > 0 {
+		return categoryUUIDs[0]
+	}
+
+	return primaryUUID
+}
+
+// primaryFirst returns category uuids with the primary category first.
+func primaryFirst(primaryUUID string, categoryUUIDs []string) []string {
+	uuids := []string{primaryUUID}
+
+	for _, categoryUUID := range categoryUUIDs {
+		if !strings.EqualFold(categoryUUID, primaryUUID) {
+			uuids = append(uuids, categoryUUID)
+		}
+	}
+
+	return uuids
+}
+
+// toProductAttributes converts validated attribute values to attributes sorted by code with their definitions,
+// string values are trimmed.
+func toProductAttributes(values map[string]any, defs []domain.CategoryAttribute) []domain.ProductAttribute {
+	attributes := make([]domain.ProductAttribute, 0, len(values))
+
+	for _, code := range sortedKeys(values) {
+		value := values[code]
+		if str, ok := value.(string); ok {
+			value = strings.TrimSpace(str)
+		}
+
+		attribute := domain.ProductAttribute{Code: code, Value: value}
+
+		for _, def := range defs {
+			if def.Code == code {
+				attribute.Name, attribute.Type, attribute.Unit = def.Name, def.Type, def.Unit.String
+				break
+			}
+		}
+
+		attributes = append(attributes, attribute)
+	}
+
+	return attributes
+}
+
+// attributeValues returns stored attributes as {code: value}.
+func attributeValues(attributes []domain.ProductAttribute) map[string]any {
+	values := make(map[string]any, len(attributes))
+	for _, a := range attributes {
+		values[a.Code] = a.Value
+	}
+
+	return values
+}
+
+// variantAttributeSets returns attribute values of existing variants to validate against definitions of new categories,
+// error fields are variants[i] in the order of the product response.
+func variantAttributeSets(variants []domain.ProductVariant) []attributeSet {
+	sets := make([]attributeSet, len(variants))
+	for i, v := range variants {
+		sets[i] = attributeSet{field: fmt.Sprintf("variants[%d]", i), values: attributeValues(v.Attributes)}
+	}
+
+	return sets
+}
+
+// toProductVariant converts a validated variant request, option and attribute values are trimmed.
+func toProductVariant(req domain.ProductVariantRequestDTO, defs []domain.CategoryAttribute) domain.ProductVariant {
+	options := make(map[string]string, len(req.Options))
+	for name, value := range req.Options {
+		options[name] = strings.TrimSpace(value)
+	}
+
+	return domain.ProductVariant{
+		VariantUUID: req.VariantUUID,
+		SKU:         req.SKU,
+		Barcode:     req.Barcode,
+		Options:     options,
+		Price:       req.Price,
+		Attributes:  toProductAttributes(req.Attributes, defs),
+	}
+}
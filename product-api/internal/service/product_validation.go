@@ -0,0 +1,466 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ashtishad/ecommerce/lib"
+	"github.com/ashtishad/ecommerce/product-api/internal/domain"
+)
+
+const (
+	productStatusRegex = `^(draft|active|archived)$`
+	skuRegex           = `^[A-Za-z0-9][A-Za-z0-9\-_.]{0,63}$`
+	barcodeRegex       = `^(\d{8}|\d{12,14})$`
+	// priceRegex accepts a non-negative decimal with up to 2 fraction digits, fits NUMERIC(12, 2).
+	priceRegex = `^\d{1,10}(\.\d{1,2})?$`
+
+	productTitleMaxLength       = 255
+	productDescriptionMaxLength = 5000
+	productBrandMaxLength       = 100
+	optionValueMaxLength        = 100
+
+	attributeValueMaxLength = 255
+
+	maxProductCategories = 10
+	maxProductVariants   = 100
+	maxVariantOptions    = 10
+	maxProductAttributes = 50
+
+	defaultProductLimit = 20
+	maxProductLimit     = 100
+)
+
+// ValidateNewProductRequest validates a new product with its variants, status defaults to draft.
+//
+//   - title is required, description, brand, categories and the primary category follow validateProductFields rules.
+//   - an active product must have at least one variant, at most 100.
+//   - variants of a product must have distinct skus, barcodes and options, and the same option names.
+func ValidateNewProductRequest(req domain.NewProductRequestDTO) lib.APIError {
+	var fieldErrs lib.ValidationErrors
+
+	validateProductFields(&fieldErrs, req.Title, req.Description, req.Brand, req.Status, req.CategoryUUIDs, req.PrimaryCategoryUUID)
+
+	switch {
+	case req.Status == domain.ProductStatusActive && len(req.Variants) == 0:
+		fieldErrs.Add("variants", lib.FieldCodeRequired, "an active product must have at least one variant")
+	case len(req.Variants) > maxProductVariants:
+		fieldErrs.Add("variants", lib.FieldCodeTooLong, fmt.Sprintf("a product can have at most %d variants", maxProductVariants))
+	}
+
+	var (
+		skus, barcodes, optionSets = map[string]bool{}, map[string]bool{}, map[string]bool{}
+		optionNames                string
+	)
+
+	for i, v := range req.Variants {
+		field := fmt.Sprintf("variants[%d]", i)
+		validateVariantFields(&fieldErrs, field, v)
+
+		// skus are unique case-insensitively, uq_product_variant_sku is on upper(sku)
+		if skus[strings.ToUpper(v.SKU)] {
+			fieldErrs.Add(field+".sku", lib.FieldCodeInvalidValue, "sku is repeated in variants, input: "+v.SKU)
+		}
+
+		skus[strings.ToUpper(v.SKU)] = true
+
+		if v.Barcode != "" {
+			if barcodes[v.Barcode] {
+				fieldErrs.Add(field+".barcode", lib.FieldCodeInvalidValue, "barcode is repeated in variants, input: "+v.Barcode)
+			}
+
+			barcodes[v.Barcode] = true
+		}
+
+		names, set := optionsKey(v.Options)
+
+		switch {
+		case i == 0:
+			optionNames = names
+		case names != optionNames:
+			fieldErrs.Add(field+".options", lib.FieldCodeInvalidValue, "variants of a product must have the same option names")
+		}
+
+		if optionSets[set] {
+			fieldErrs.Add(field+".options", lib.FieldCodeInvalidValue, "another variant has the same options")
+		}
+
+		optionSets[set] = true
+	}
+
+	if fieldErrs.HasErrors() {
+		return lib.NewValidationError("invalid product input", fieldErrs)
+	}
+
+	return nil
+}
+
+// ValidateUpdateProductRequest validates product uuid path param and the editable fields, status is required.
+func ValidateUpdateProductRequest(req domain.UpdateProductRequestDTO) lib.APIError {
+	var fieldErrs lib.ValidationErrors
+
+	validateUUIDField(&fieldErrs, "productUuid", req.ProductUUID)
+
+	if req.Status == "" {
+		fieldErrs.Add("status", lib.FieldCodeRequired, "product status is required")
+	}
+
+	validateProductFields(&fieldErrs, req.Title, req.Description, req.Brand, req.Status, req.CategoryUUIDs, req.PrimaryCategoryUUID)
+
+	if fieldErrs.HasErrors() {
+		return lib.NewValidationError("invalid product input", fieldErrs)
+	}
+
+	return nil
+}
+
+// ValidateProductVariantRequest validates product uuid path param, variant uuid path param when updating and
+// the variant fields.
+func ValidateProductVariantRequest(req domain.ProductVariantRequestDTO, updating bool) lib.APIError {
+	var fieldErrs lib.ValidationErrors
+
+	validateUUIDField(&fieldErrs, "productUuid", req.ProductUUID)
+
+	if updating {
+		validateUUIDField(&fieldErrs, "variantUuid", req.VariantUUID)
+	}
+
+	validateVariantFields(&fieldErrs, "", req)
+
+	if fieldErrs.HasErrors() {
+		return lib.NewValidationError("invalid product variant input", fieldErrs)
+	}
+
+	return nil
+}
+
+// ValidateProductUUID validates a product uuid path param.
+func ValidateProductUUID(productUUID string) lib.APIError {
+	var fieldErrs lib.ValidationErrors
+
+	validateUUIDField(&fieldErrs, "productUuid", productUUID)
+
+	if fieldErrs.HasErrors() {
+		return lib.NewValidationError("invalid product id", fieldErrs)
+	}
+
+	return nil
+}
+
+// ValidateProductListRequest validates GET /products and GET /categories/:category_id/products params,
+// returns the product filter, page size is 20 by default.
+func ValidateProductListRequest(req domain.ProductListRequestDTO) (domain.ProductFilter, lib.APIError) {
+	var (
+		fieldErrs lib.ValidationErrors
+		filter    = domain.ProductFilter{
+			Status:       req.Status,
+			CategoryUUID: req.CategoryUUID,
+			Brand:        strings.TrimSpace(req.Brand),
+			AfterUUID:    req.After,
+			Limit:        defaultProductLimit,
+		}
+		err error
+	)
+
+	if req.Status != "" && !regexp.MustCompile(productStatusRegex).MatchString(req.Status) {
+		fieldErrs.Add("status", lib.FieldCodeInvalidValue, "product status must be 'draft', 'active' or 'archived'")
+	}
+
+	if req.CategoryUUID != "" {
+		validateUUIDField(&fieldErrs, "categoryId", req.CategoryUUID)
+	}
+
+	if req.IncludeDescendantsStr != "" {
+		filter.IncludeDescendants, err = strconv.ParseBool(req.IncludeDescendantsStr)
+		if err != nil {
+			fieldErrs.Add("includeDescendants", lib.FieldCodeInvalidValue,
+				fmt.Sprintf("includeDescendants must be true or false, you entered: %s", req.IncludeDescendantsStr))
+		}
+	}
+
+	if utf8.RuneCountInString(req.Brand) > productBrandMaxLength {
+		fieldErrs.Add("brand", lib.FieldCodeTooLong, fmt.Sprintf("brand must be at most %d characters", productBrandMaxLength))
+	}
+
+	if req.LimitStr != "" {
+		filter.Limit, err = strconv.Atoi(req.LimitStr)
+		if err != nil || filter.Limit < 1 || filter.Limit > maxProductLimit {
+			fieldErrs.Add("limit", lib.FieldCodeOutOfRange,
+				fmt.Sprintf("limit must be a number from 1 to %d, you entered: %s", maxProductLimit, req.LimitStr))
+		}
+	}
+
+	if req.After != "" {
+		validateUUIDField(&fieldErrs, "after", req.After)
+	}
+
+	if fieldErrs.HasErrors() {
+		return domain.ProductFilter{}, lib.NewValidationError("invalid product list input", fieldErrs)
+	}
+
+	return filter, nil
+}
+
+// validateProductFields validates fields shared by creating and updating a product,
+// an empty status is allowed here, it defaults to draft on create.
+// an empty primary category defaults to the first category, otherwise it must be one of the categories.
+func validateProductFields(fieldErrs *lib.ValidationErrors, title, description, brand, status string, categoryUUIDs []string,
+	primaryCategoryUUID string) {
+	title = strings.TrimSpace(title)
+
+	switch {
+	case title == "":
+		fieldErrs.Add("title", lib.FieldCodeRequired, "product title cannot be empty")
+	case utf8.RuneCountInString(title) > productTitleMaxLength:
+		fieldErrs.Add("title", lib.FieldCodeTooLong, fmt.Sprintf("product title must be at most %d characters", productTitleMaxLength))
+	}
+
+	if utf8.RuneCountInString(description) > productDescriptionMaxLength {
+		fieldErrs.Add("description", lib.FieldCodeTooLong,
+			fmt.Sprintf("product description must be at most %d characters", productDescriptionMaxLength))
+	}
+
+	if utf8.RuneCountInString(strings.TrimSpace(brand)) > productBrandMaxLength {
+		fieldErrs.Add("brand", lib.FieldCodeTooLong, fmt.Sprintf("brand must be at most %d characters", productBrandMaxLength))
+	}
+
+	if status != "" && !regexp.MustCompile(productStatusRegex).MatchString(status) {
+		fieldErrs.Add("status", lib.FieldCodeInvalidValue, "product status must be 'draft', 'active' or 'archived'")
+	}
+
+	switch {
+	case len(categoryUUIDs) == 0:
+		fieldErrs.Add("categoryUuids", lib.FieldCodeRequired, "a product must be listed under at least one category")
+	case len(categoryUUIDs) > maxProductCategories:
+		fieldErrs.Add("categoryUuids", lib.FieldCodeTooLong,
+			fmt.Sprintf("a product can be listed under at most %d categories", maxProductCategories))
+	}
+
+	seen := make(map[string]bool, len(categoryUUIDs))
+
+	for i, categoryUUID := range categoryUUIDs {
+		field := fmt.Sprintf("categoryUuids[%d]", i)
+
+		validateUUIDField(fieldErrs, field, categoryUUID)
+
+		if seen[strings.ToLower(categoryUUID)] {
+			fieldErrs.Add(field, lib.FieldCodeInvalidValue, "category is repeated, input: "+categoryUUID)
+		}
+
+		seen[strings.ToLower(categoryUUID)] = true
+	}
+
+	if primaryCategoryUUID != "" && !seen[strings.ToLower(primaryCategoryUUID)] {
+		fieldErrs.Add("primaryCategoryUuid", lib.FieldCodeInvalidValue,
+			"primary category must be one of the product categories, input: "+primaryCategoryUUID)
+	}
+}
+
+// validateVariantFields validates sku, optional barcode(GTIN-8, 12, 13 or 14 with a valid check digit),
+// options and price of a variant, field prefixes error fields, e.g. variants[0].
+func validateVariantFields(fieldErrs *lib.ValidationErrors, field string, v domain.ProductVariantRequestDTO) {
+	if field != "" {
+		field += "."
+	}
+
+	if !regexp.MustCompile(skuRegex).MatchString(v.SKU) {
+		fieldErrs.Add(field+"sku", lib.FieldCodeInvalidFormat,
+			"sku must be 1 to 64 letters, digits, '-', '_' or '.', starting with a letter or digit")
+	}
+
+	if v.Barcode != "" && (!regexp.MustCompile(barcodeRegex).MatchString(v.Barcode) || !validGTINCheckDigit(v.Barcode)) {
+		fieldErrs.Add(field+"barcode", lib.FieldCodeInvalidFormat, "barcode must be a GTIN of 8, 12, 13 or 14 digits with a valid check digit")
+	}
+
+	if len(v.Options) > maxVariantOptions {
+		fieldErrs.Add(field+"options", lib.FieldCodeTooLong, fmt.Sprintf("a variant can have at most %d options", maxVariantOptions))
+	}
+
+	for _, name := range sortedKeys(v.Options) {
+		value := strings.TrimSpace(v.Options[name])
+
+		switch {
+		case !regexp.MustCompile(attributeCodeRegex).MatchString(name):
+			fieldErrs.Add(field+"options."+name, lib.FieldCodeInvalidFormat,
+				"option name must start with a lowercase letter, followed by lowercase letters, digits or '_'")
+		case value == "":
+			fieldErrs.Add(field+"options."+name, lib.FieldCodeRequired, "option value cannot be empty")
+		case utf8.RuneCountInString(value) > optionValueMaxLength:
+			fieldErrs.Add(field+"options."+name, lib.FieldCodeTooLong,
+				fmt.Sprintf("option value must be at most %d characters", optionValueMaxLength))
+		}
+	}
+
+	if !regexp.MustCompile(priceRegex).MatchString(v.Price) {
+		fieldErrs.Add(field+"price", lib.FieldCodeInvalidFormat,
+			fmt.Sprintf("price must be a non-negative decimal with up to 2 fraction digits, e.g. 499.99, you entered: %s", v.Price))
+	}
+}
+
+func validateUUIDField(fieldErrs *lib.ValidationErrors, field string, uuid string) {
+	if !regexp.MustCompile(categoryUUIDRegex).MatchString(uuid) {
+		fieldErrs.Add(field, lib.FieldCodeInvalidFormat, fmt.Sprintf("invalid %s, you entered: %s", field, uuid))
+	}
+}
+
+// attributeSet is the attribute values of a product or a variant, field prefixes error fields, e.g. variants[0],
+// values of a stored set are only used to tell whether required attributes are set.
+type attributeSet struct {
+	field  string
+	values map[string]any
+	stored bool
+}
+
+// validateProductAttributes validates attribute values of a product and its variants against the effective
+// attribute definitions of the product's categories.
+//
+//   - every code must be defined, values must match the attribute type, string and enum values are non-empty strings,
+//     enum values must be one of the allowed values, number values are numbers and bool values are true or false.
+//   - when active, a required attribute must be set on the product, or on every variant.
+func validateProductAttributes(defs []domain.CategoryAttribute, product attributeSet, variants []attributeSet, active bool) lib.APIError {
+	var fieldErrs lib.ValidationErrors
+
+	defByCode := make(map[string]domain.CategoryAttribute, len(defs))
+	for _, def := range defs {
+		defByCode[def.Code] = def
+	}
+
+	for _, set := range append([]attributeSet{product}, variants...) {
+		if !set.stored {
+			validateAttributeSet(&fieldErrs, defByCode, set)
+		}
+	}
+
+	if active {
+		for _, def := range defs {
+			if !def.Required || product.values[def.Code] != nil {
+				continue
+			}
+
+			sets := variants
+			if len(sets) == 0 {
+				sets = []attributeSet{product}
+			}
+
+			for _, set := range sets {
+				if set.values[def.Code] == nil {
+					fieldErrs.Add(attributeField(set.field, def.Code), lib.FieldCodeRequired,
+						fmt.Sprintf("attribute %s is required, set it on the product or on every variant", def.Code))
+				}
+			}
+		}
+	}
+
+	if fieldErrs.HasErrors() {
+		return lib.NewValidationError("invalid product attributes", fieldErrs)
+	}
+
+	return nil
+}
+
+func validateAttributeSet(fieldErrs *lib.ValidationErrors, defByCode map[string]domain.CategoryAttribute, set attributeSet) {
+	if len(set.values) > maxProductAttributes {
+		fieldErrs.Add(attributeField(set.field, ""), lib.FieldCodeTooLong,
+			fmt.Sprintf("at most %d attributes are allowed", maxProductAttributes))
+	}
+
+	for _, code := range sortedKeys(set.values) {
+		field := attributeField(set.field, code)
+
+		def, ok := defByCode[code]
+		if !ok {
+			fieldErrs.Add(field, lib.FieldCodeInvalidValue, "attribute is not defined for the product categories")
+			continue
+		}
+
+		validateAttributeValue(fieldErrs, field, def, set.values[code])
+	}
+}
+
+// validateAttributeValue validates a value against the attribute type, string values are trimmed.
+func validateAttributeValue(fieldErrs *lib.ValidationErrors, field string, def domain.CategoryAttribute, value any) {
+	switch def.Type {
+	case domain.AttributeTypeString, domain.AttributeTypeEnum:
+		str, isString := value.(string)
+		str = strings.TrimSpace(str)
+
+		switch {
+		case !isString:
+			fieldErrs.Add(field, lib.FieldCodeInvalidValue, def.Code+" must be a string")
+		case str == "":
+			fieldErrs.Add(field, lib.FieldCodeRequired, def.Code+" cannot be empty")
+		case utf8.RuneCountInString(str) > attributeValueMaxLength:
+			fieldErrs.Add(field, lib.FieldCodeTooLong, fmt.Sprintf("%s must be at most %d characters", def.Code, attributeValueMaxLength))
+		case def.Type == domain.AttributeTypeEnum && !slices.Contains(def.Values, str):
+			fieldErrs.Add(field, lib.FieldCodeInvalidValue,
+				fmt.Sprintf("%s must be one of %s, you entered: %s", def.Code, strings.Join(def.Values, ", "), str))
+		}
+	case domain.AttributeTypeNumber:
+		if _, isNumber := value.(float64); !isNumber {
+			fieldErrs.Add(field, lib.FieldCodeInvalidValue, def.Code+" must be a number")
+		}
+	case domain.AttributeTypeBool:
+		if _, isBool := value.(bool); !isBool {
+			fieldErrs.Add(field, lib.FieldCodeInvalidValue, def.Code+" must be true or false")
+		}
+	}
+}
+
+// attributeField returns the error field of an attribute, e.g. variants[0].attributes.ram.
+func attributeField(prefix, code string) string {
+	field := "attributes"
+	if code != "" {
+		field += "." + code
+	}
+
+	if prefix != "" {
+		field = prefix + "." + field
+	}
+
+	return field
+}
+
+// validGTINCheckDigit checks the last digit of a GTIN, digits from the right are weighted 3, 1, 3, ...
+func validGTINCheckDigit(gtin string) bool {
+	sum := 0
+
+	for i := len(gtin) - 2; i >= 0; i-- {
+		digit := int(gtin[i] - '0')
+		if (len(gtin)-2-i)%2 == 0 {
+			digit *= 3
+		}
+
+		sum += digit
+	}
+
+	return (10-sum%10)%10 == int(gtin[len(gtin)-1]-'0')
+}
+
+// optionsKey returns the sorted option names and the sorted name=value pairs of variant options,
+// used to compare variants of a request.
+func optionsKey(options map[string]string) (string, string) {
+	names := sortedKeys(options)
+	pairs := make([]string, len(names))
+
+	for i, name := range names {
+		pairs[i] = name + "=" + strings.TrimSpace(options[name])
+	}
+
+	return strings.Join(names, ","), strings.Join(pairs, ",")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
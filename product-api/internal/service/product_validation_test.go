@@ -0,0 +1,233 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/ashtishad/ecommerce/product-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testProductCategoryUUID = "bd11d903-7549-42b2-bea6-dd8a7cb8821e"
+	testGamingCategoryUUID  = "5f0c7a34-64a1-4d2b-9d0e-3e9b8a2f6c11"
+)
+
+func validVariant(sku string, storage string) domain.ProductVariantRequestDTO {
+	return domain.ProductVariantRequestDTO{SKU: sku, Options: map[string]string{"color": "black", "storage": storage}, Price: "799.99"}
+}
+
+func TestValidateNewProductRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    domain.NewProductRequestDTO
+		fields []string
+	}{
+		{
+			name: "Valid active product",
+			req: domain.NewProductRequestDTO{
+				Title: "Galaxy S24", Brand: "Samsung", Status: domain.ProductStatusActive, CategoryUUIDs: []string{testProductCategoryUUID},
+				Variants: []domain.ProductVariantRequestDTO{validVariant("S24-128", "128GB"), validVariant("S24-256", "256GB")},
+			},
+		},
+		{
+			name: "Draft product without variants",
+			req:  domain.NewProductRequestDTO{Title: "Galaxy S25", CategoryUUIDs: []string{testProductCategoryUUID}},
+		},
+		{
+			name:   "Missing title, categories and variants of an active product",
+			req:    domain.NewProductRequestDTO{Title: " ", Status: domain.ProductStatusActive},
+			fields: []string{"title", "categoryUuids", "variants"},
+		},
+		{
+			name: "Invalid status and repeated category",
+			req: domain.NewProductRequestDTO{
+				Title: "Galaxy S24", Status: "published", CategoryUUIDs: []string{testProductCategoryUUID, testProductCategoryUUID, "1"},
+			},
+			fields: []string{"status", "categoryUuids[1]", "categoryUuids[2]"},
+		},
+		{
+			name: "Primary category is one of the categories",
+			req: domain.NewProductRequestDTO{
+				Title: "ROG Phone 8", CategoryUUIDs: []string{testProductCategoryUUID, testGamingCategoryUUID},
+				PrimaryCategoryUUID: testGamingCategoryUUID,
+			},
+		},
+		{
+			name: "Primary category isn't one of the categories",
+			req: domain.NewProductRequestDTO{
+				Title: "ROG Phone 8", CategoryUUIDs: []string{testProductCategoryUUID}, PrimaryCategoryUUID: testGamingCategoryUUID,
+			},
+			fields: []string{"primaryCategoryUuid"},
+		},
+		{
+			name: "Invalid variant fields",
+			req: domain.NewProductRequestDTO{
+				Title: "Galaxy S24", CategoryUUIDs: []string{testProductCategoryUUID},
+				Variants: []domain.ProductVariantRequestDTO{
+					{SKU: "-S24", Barcode: "4006381333932", Options: map[string]string{"Color": "black", "storage": " "}, Price: "7.999"},
+				},
+			},
+			fields: []string{"variants[0].sku", "variants[0].barcode", "variants[0].options.Color", "variants[0].options.storage", "variants[0].price"},
+		},
+		{
+			name: "Repeated sku, options and different option names",
+			req: domain.NewProductRequestDTO{
+				Title: "Galaxy S24", CategoryUUIDs: []string{testProductCategoryUUID},
+				Variants: []domain.ProductVariantRequestDTO{
+					validVariant("S24-128", "128GB"),
+					validVariant("s24-128", "128GB"),
+					{SKU: "S24-BLUE", Options: map[string]string{"color": "blue"}, Price: "799"},
+				},
+			},
+			fields: []string{"variants[1].sku", "variants[1].options", "variants[2].options"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiErr := ValidateNewProductRequest(tt.req)
+			if tt.fields == nil {
+				assert.Nil(t, apiErr)
+				return
+			}
+
+			require.NotNil(t, apiErr)
+			assert.Equal(t, tt.fields, fieldNames(apiErr.FieldErrors()))
+		})
+	}
+}
+
+func TestValidateProductListRequest(t *testing.T) {
+	filter, apiErr := ValidateProductListRequest(domain.ProductListRequestDTO{})
+	require.Nil(t, apiErr)
+	assert.Equal(t, domain.ProductFilter{Limit: defaultProductLimit}, filter)
+
+	filter, apiErr = ValidateProductListRequest(domain.ProductListRequestDTO{
+		Status: domain.ProductStatusActive, CategoryUUID: testProductCategoryUUID, IncludeDescendantsStr: "true", LimitStr: "50",
+	})
+	require.Nil(t, apiErr)
+	assert.Equal(t, domain.ProductFilter{
+		Status: domain.ProductStatusActive, CategoryUUID: testProductCategoryUUID, IncludeDescendants: true, Limit: 50,
+	}, filter)
+
+	_, apiErr = ValidateProductListRequest(domain.ProductListRequestDTO{
+		Status: "sold", CategoryUUID: "x", IncludeDescendantsStr: "all", LimitStr: "101", After: "y",
+	})
+	require.NotNil(t, apiErr)
+	assert.Equal(t, []string{"status", "categoryId", "includeDescendants", "limit", "after"}, fieldNames(apiErr.FieldErrors()))
+}
+
+func TestValidateProductAttributes(t *testing.T) {
+	defs := []domain.CategoryAttribute{
+		{Code: "color", Type: domain.AttributeTypeEnum, Values: []string{"black", "blue"}},
+		{Code: "anc", Type: domain.AttributeTypeBool},
+		{Code: "chipset", Type: domain.AttributeTypeString},
+		{Code: "ram", Type: domain.AttributeTypeNumber, Required: true},
+	}
+
+	variants := func(values ...map[string]any) []attributeSet {
+		sets := make([]attributeSet, len(values))
+		for i, v := range values {
+			sets[i] = attributeSet{field: fmt.Sprintf("variants[%d]", i), values: v}
+		}
+
+		return sets
+	}
+
+	tests := []struct {
+		name     string
+		product  attributeSet
+		variants []attributeSet
+		active   bool
+		fields   []string
+	}{
+		{
+			name:    "Valid typed values",
+			product: attributeSet{values: map[string]any{"color": "black", "anc": true, "chipset": " Snapdragon 8 Gen 3 ", "ram": 8.0}},
+			active:  true,
+		},
+		{
+			name:     "Required attribute set on every variant",
+			variants: variants(map[string]any{"ram": 8.0}, map[string]any{"ram": 12.0}),
+			active:   true,
+		},
+		{
+			name:     "Required attribute missing on a variant",
+			variants: variants(map[string]any{"ram": 8.0}, map[string]any{"color": "blue"}),
+			active:   true,
+			fields:   []string{"variants[1].attributes.ram"},
+		},
+		{
+			name:    "Required attribute missing on an active product without variants",
+			product: attributeSet{values: map[string]any{}},
+			active:  true,
+			fields:  []string{"attributes.ram"},
+		},
+		{
+			name:    "Draft product may miss required attributes",
+			product: attributeSet{values: map[string]any{"anc": false}},
+		},
+		{
+			name:    "Wrong types, unknown code and value outside enum",
+			product: attributeSet{values: map[string]any{"anc": "yes", "chipset": " ", "color": "red", "ram": "8GB", "weight": 180.0}},
+			fields: []string{
+				"attributes.anc", "attributes.chipset", "attributes.color", "attributes.ram", "attributes.weight",
+			},
+		},
+		{
+			name:     "Stored values are not validated",
+			product:  attributeSet{values: map[string]any{"weight": 180.0, "ram": 8.0}, stored: true},
+			variants: []attributeSet{{values: map[string]any{"color": "pink"}}},
+			active:   true,
+			fields:   []string{"attributes.color"},
+		},
+		{
+			name:    "Existing variant values the new categories don't define",
+			product: attributeSet{values: map[string]any{"ram": 8.0}},
+			variants: variantAttributeSets([]domain.ProductVariant{
+				{Attributes: []domain.ProductAttribute{{Code: "color", Value: "blue"}}},
+				{Attributes: []domain.ProductAttribute{{Code: "color", Value: "black"}, {Code: "band", Value: "n78"}}},
+			}),
+			active: true,
+			fields: []string{"variants[1].attributes.band"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiErr := validateProductAttributes(defs, tt.product, tt.variants, tt.active)
+			if tt.fields == nil {
+				assert.Nil(t, apiErr)
+				return
+			}
+
+			require.NotNil(t, apiErr)
+			assert.Equal(t, tt.fields, fieldNames(apiErr.FieldErrors()))
+		})
+	}
+}
+
+func TestToProductAttributes(t *testing.T) {
+	defs := []domain.CategoryAttribute{
+		{Code: "ram", Name: "RAM", Type: domain.AttributeTypeNumber, Unit: sql.NullString{String: "GB", Valid: true}},
+		{Code: "chipset", Name: "Chipset", Type: domain.AttributeTypeString},
+	}
+
+	assert.Equal(t, []domain.ProductAttribute{
+		{Code: "chipset", Name: "Chipset", Type: domain.AttributeTypeString, Value: "Tensor G3"},
+		{Code: "ram", Name: "RAM", Type: domain.AttributeTypeNumber, Unit: "GB", Value: 8.0},
+	}, toProductAttributes(map[string]any{"ram": 8.0, "chipset": " Tensor G3 "}, defs))
+}
+
+func TestValidGTINCheckDigit(t *testing.T) {
+	for _, valid := range []string{"4006381333931", "96385074", "036000291452", "10012345678902"} {
+		assert.True(t, validGTINCheckDigit(valid), valid)
+	}
+
+	for _, invalid := range []string{"4006381333932", "96385075", "036000291453"} {
+		assert.False(t, validGTINCheckDigit(invalid), invalid)
+	}
+}
@@ -1,40 +1,484 @@
 package service
 
 import (
+	"fmt"
+	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/ashtishad/ecommerce/lib"
 	"github.com/ashtishad/ecommerce/product-api/internal/domain"
+	"github.com/ashtishad/ecommerce/product-api/pkg/slug"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	categoryNameRegex   = `^[A-Za-z0-9\s\-_&]*$`
+	categoryUUIDRegex   = `^[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-[1-5][a-fA-F0-9]{3}-[89abAB][a-fA-F0-9]{3}-[a-fA-F0-9]{12}$`
+	categoryStatusRegex = `^(active|inactive|deleted)$`
+	attributeCodeRegex  = `^[a-z][a-z0-9_]{0,49}$`
+
+	// translated names allow letters and combining marks of any script, e.g. Bengali vowel signs.
+	translatedNameRegex   = `^[\p{L}\p{M}\p{N}\s\-_&]+$`
+	categoryNameMaxLength = 255
+
+	attributeNameMaxLength = 100
+	attributeUnitMaxLength = 20
+
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 200
 )
 
 // ValidateNewCategoryRequest validates the new category request data.
-// It returns a combined error message if any of the validations fail.
+// It collects every failed field, so clients can map them to form fields.
 //
 // - Name must not be empty and should only contain alphanumeric characters, spaces, or certain symbols like '-' and '_'.
 // - Description must be less than 256 characters in length.
 //
-// If any validation rule is not met, it returns an APIError with all error messages combined.
+// If any validation rule is not met, it returns a validation APIError with all field errors as causes.
 func ValidateNewCategoryRequest(req domain.NewCategoryRequestDTO) lib.APIError {
-	var errMessages []string
+	var fieldErrs lib.ValidationErrors
+
+	validateCategoryFields(&fieldErrs, req.Name, req.Description)
+
+	if fieldErrs.HasErrors() {
+		return lib.NewValidationError("invalid category input", fieldErrs)
+	}
+
+	return nil
+}
+
+// ValidateUpdateCategoryRequest validates category uuid path param, name and description,
+// with the same rules of ValidateNewCategoryRequest.
+func ValidateUpdateCategoryRequest(req domain.UpdateCategoryRequestDTO) lib.APIError {
+	var fieldErrs lib.ValidationErrors
+
+	validateCategoryUUID(&fieldErrs, req.CategoryUUID)
+	validateCategoryFields(&fieldErrs, req.Name, req.Description)
+
+	if fieldErrs.HasErrors() {
+		return lib.NewValidationError("invalid category input", fieldErrs)
+	}
+
+	return nil
+}
+
+// ValidateUpdateCategoryStatusRequest validates category uuid path param,
+// status must be 'active', 'inactive' or 'deleted'.
+func ValidateUpdateCategoryStatusRequest(req domain.UpdateCategoryStatusRequestDTO) lib.APIError {
+	var fieldErrs lib.ValidationErrors
+
+	validateCategoryUUID(&fieldErrs, req.CategoryUUID)
+
+	if !regexp.MustCompile(categoryStatusRegex).MatchString(req.Status) {
+		fieldErrs.Add("status", lib.FieldCodeInvalidValue, "category status must be 'active', 'inactive' or 'deleted'")
+	}
+
+	if fieldErrs.HasErrors() {
+		return lib.NewValidationError("invalid category status input", fieldErrs)
+	}
+
+	return nil
+}
+
+// ValidateMoveCategoryRequest validates category uuid path param and the optional new parent uuid,
+// a category can't be moved under itself.
+func ValidateMoveCategoryRequest(req domain.MoveCategoryRequestDTO) lib.APIError {
+	var fieldErrs lib.ValidationErrors
+
+	validateCategoryUUID(&fieldErrs, req.CategoryUUID)
+
+	if req.ParentCategoryUUID != "" && !regexp.MustCompile(categoryUUIDRegex).MatchString(req.ParentCategoryUUID) {
+		fieldErrs.Add("parentCategoryUuid", lib.FieldCodeInvalidFormat, "invalid parent category uuid")
+	}
+
+	if fieldErrs.HasErrors() {
+		return lib.NewValidationError("invalid move category input", fieldErrs)
+	}
+
+	if strings.EqualFold(req.CategoryUUID, req.ParentCategoryUUID) {
+		return lib.NewError(http.StatusBadRequest, domain.ErrCodeCategoryMoveCycle, nil)
+	}
+
+	return nil
+}
+
+// ValidateCategorySubtreeRequest validates category uuid path param and optional depth query param,
+// returns max depth, 0 means the whole subtree.
+func ValidateCategorySubtreeRequest(req domain.CategorySubtreeRequestDTO) (int, lib.APIError) {
+	var (
+		fieldErrs lib.ValidationErrors
+		maxDepth  int
+	)
+
+	validateCategoryUUID(&fieldErrs, req.CategoryUUID)
+
+	if req.DepthStr != "" {
+		depth, err := strconv.Atoi(req.DepthStr)
+		if err != nil || depth < 1 {
+			fieldErrs.Add("depth", lib.FieldCodeInvalidValue,
+				fmt.Sprintf("depth must be a positive number, you entered: %s", req.DepthStr))
+		}
+
+		maxDepth = depth
+	}
+
+	if fieldErrs.HasErrors() {
+		return 0, lib.NewValidationError("invalid category subtree input", fieldErrs)
+	}
 
-	if req.Name == "" {
-		errMessages = append(errMessages, "category name cannot be empty")
+	return maxDepth, nil
+}
+
+// ValidateReorderChildrenRequest validates category uuid path param and the children order,
+// every entry must be a valid uuid and listed once, matching actual subcategories is checked by repository.
+func ValidateReorderChildrenRequest(req domain.ReorderChildrenRequestDTO) lib.APIError {
+	var fieldErrs lib.ValidationErrors
+
+	validateCategoryUUID(&fieldErrs, req.CategoryUUID)
+
+	uuidRegex := regexp.MustCompile(categoryUUIDRegex)
+	seen := make(map[string]bool, len(req.Order))
+
+	for i, childUUID := range req.Order {
+		field := fmt.Sprintf("order[%d]", i)
+
+		if !uuidRegex.MatchString(childUUID) {
+			fieldErrs.Add(field, lib.FieldCodeInvalidFormat, "invalid category uuid")
+			continue
+		}
+
+		key := strings.ToLower(childUUID)
+		if seen[key] {
+			fieldErrs.Add(field, lib.FieldCodeInvalidValue, fmt.Sprintf("category uuid is listed more than once: %s", childUUID))
+		}
+
+		seen[key] = true
 	}
 
-	nameRegex := `^[A-Za-z0-9\s\-_&]*$`
-	if !regexp.MustCompile(nameRegex).MatchString(req.Name) {
-		errMessages = append(errMessages, "invalid characters in Category name field")
+	if fieldErrs.HasErrors() {
+		return lib.NewValidationError("invalid reorder subcategories input", fieldErrs)
+	}
+
+	return nil
+}
+
+// ValidateUpdateCategorySlugRequest validates category uuid path param and the new slug.
+func ValidateUpdateCategorySlugRequest(req domain.UpdateCategorySlugRequestDTO) lib.APIError {
+	var fieldErrs lib.ValidationErrors
+
+	validateCategoryUUID(&fieldErrs, req.CategoryUUID)
+
+	if !slug.Valid(req.Slug) {
+		fieldErrs.Add("slug", lib.FieldCodeInvalidFormat,
+			fmt.Sprintf("slug must be lowercase letters and digits separated by single hyphens, at most %d characters, you entered: %s",
+				slug.MaxLength, req.Slug))
+	}
+
+	if fieldErrs.HasErrors() {
+		return lib.NewValidationError("invalid category slug input", fieldErrs)
+	}
+
+	return nil
+}
+
+// ParseCategoryPath splits a slug path into segments, segments are lowercased in NFC form,
+// returns 404 if a segment can't be a slug, as no category can match it.
+func ParseCategoryPath(path string) ([]string, lib.APIError) {
+	var slugs []string
+
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" {
+			continue
+		}
+
+		segment = strings.ToLower(norm.NFC.String(segment))
+		if !slug.Valid(segment) {
+			return nil, lib.NewError(http.StatusNotFound, domain.ErrCodeCategoryNotFound, nil)
+		}
+
+		slugs = append(slugs, segment)
+	}
+
+	if len(slugs) == 0 {
+		var fieldErrs lib.ValidationErrors
+		fieldErrs.Add("path", lib.FieldCodeRequired, "category path cannot be empty")
+
+		return nil, lib.NewValidationError("invalid category path", fieldErrs)
+	}
+
+	return slugs, nil
+}
+
+// ValidateSaveCategoryAttributeRequest validates category uuid and attribute code path params and the definition.
+//
+// - Code must be lowercase letters, digits or underscores starting with a letter, at most 50 characters, e.g. battery_mah.
+// - Type must be 'string', 'enum', 'number' or 'bool', unit is only allowed for number.
+// - Values are required for enum and must be unique, other types can't have values.
+func ValidateSaveCategoryAttributeRequest(req domain.SaveCategoryAttributeRequestDTO) lib.APIError {
+	var fieldErrs lib.ValidationErrors
+
+	validateCategoryUUID(&fieldErrs, req.CategoryUUID)
+	validateAttributeCode(&fieldErrs, req.Code)
+
+	if strings.TrimSpace(req.Name) == "" {
+		fieldErrs.Add("name", lib.FieldCodeRequired, "attribute name cannot be empty")
+	} else if utf8.RuneCountInString(req.Name) > attributeNameMaxLength {
+		fieldErrs.Add("name", lib.FieldCodeTooLong, fmt.Sprintf("attribute name must be at most %d characters", attributeNameMaxLength))
+	}
+
+	switch req.Type {
+	case domain.AttributeTypeString, domain.AttributeTypeEnum, domain.AttributeTypeNumber, domain.AttributeTypeBool:
+	default:
+		fieldErrs.Add("type", lib.FieldCodeInvalidValue, "attribute type must be 'string', 'enum', 'number' or 'bool'")
+	}
+
+	if req.Unit != "" && req.Type != domain.AttributeTypeNumber {
+		fieldErrs.Add("unit", lib.FieldCodeInvalidValue, "only number attributes can have a unit")
+	} else if utf8.RuneCountInString(req.Unit) > attributeUnitMaxLength {
+		fieldErrs.Add("unit", lib.FieldCodeTooLong, fmt.Sprintf("attribute unit must be at most %d characters", attributeUnitMaxLength))
+	}
+
+	validateAttributeValues(&fieldErrs, req.Type, req.Values)
+
+	if fieldErrs.HasErrors() {
+		return lib.NewValidationError("invalid category attribute input", fieldErrs)
+	}
+
+	return nil
+}
+
+// ValidateCategoryAttributeCode validates category uuid and attribute code path params.
+func ValidateCategoryAttributeCode(categoryUUID string, code string) lib.APIError {
+	var fieldErrs lib.ValidationErrors
+
+	validateCategoryUUID(&fieldErrs, categoryUUID)
+	validateAttributeCode(&fieldErrs, code)
+
+	if fieldErrs.HasErrors() {
+		return lib.NewValidationError("invalid category attribute input", fieldErrs)
+	}
+
+	return nil
+}
+
+func validateAttributeCode(fieldErrs *lib.ValidationErrors, code string) {
+	if !regexp.MustCompile(attributeCodeRegex).MatchString(code) {
+		fieldErrs.Add("code", lib.FieldCodeInvalidFormat,
+			fmt.Sprintf("attribute code must be lowercase letters, digits or underscores starting with a letter, you entered: %s", code))
+	}
+}
+
+func validateAttributeValues(fieldErrs *lib.ValidationErrors, attributeType string, values []string) {
+	if attributeType != domain.AttributeTypeEnum {
+		if len(values) > 0 {
+			fieldErrs.Add("values", lib.FieldCodeInvalidValue, "only enum attributes can have values")
+		}
+
+		return
+	}
+
+	if len(values) == 0 {
+		fieldErrs.Add("values", lib.FieldCodeRequired, "enum attribute must have at least one value")
+		return
+	}
+
+	seen := make(map[string]bool, len(values))
+
+	for i, value := range values {
+		field := fmt.Sprintf("values[%d]", i)
+
+		switch {
+		case strings.TrimSpace(value) == "":
+			fieldErrs.Add(field, lib.FieldCodeRequired, "enum value cannot be empty")
+		case seen[value]:
+			fieldErrs.Add(field, lib.FieldCodeInvalidValue, fmt.Sprintf("enum value is listed more than once: %s", value))
+		}
+
+		seen[value] = true
+	}
+}
+
+// ValidateSaveCategoryTranslationRequest validates category uuid and locale path params, name and description.
+//
+// - Locale must be a supported locale other than the default one, default locale values are the category's own.
+// - Name must not be empty, letters of any script, digits, spaces, '-', '_' and '&' are allowed.
+// - Description must be less than 256 characters in length.
+func ValidateSaveCategoryTranslationRequest(req domain.SaveCategoryTranslationRequestDTO) lib.APIError {
+	var fieldErrs lib.ValidationErrors
+
+	validateCategoryUUID(&fieldErrs, req.CategoryUUID)
+	validateTranslationLocale(&fieldErrs, req.Locale)
+
+	name := strings.TrimSpace(req.Name)
+
+	switch {
+	case name == "":
+		fieldErrs.Add("name", lib.FieldCodeRequired, "category name cannot be empty")
+	case utf8.RuneCountInString(name) > categoryNameMaxLength:
+		fieldErrs.Add("name", lib.FieldCodeTooLong, fmt.Sprintf("category name must be at most %d characters", categoryNameMaxLength))
+	case !regexp.MustCompile(translatedNameRegex).MatchString(name):
+		fieldErrs.Add("name", lib.FieldCodeInvalidFormat, "invalid characters in Category name field")
 	}
 
 	if utf8.RuneCountInString(req.Description) > 255 {
-		errMessages = append(errMessages, "category description must be less than 256 characters")
+		fieldErrs.Add("description", lib.FieldCodeTooLong, "category description must be less than 256 characters")
+	}
+
+	if fieldErrs.HasErrors() {
+		return lib.NewValidationError("invalid category translation input", fieldErrs)
+	}
+
+	return nil
+}
+
+// ValidateCategoryTranslationLocale validates category uuid and locale path params.
+func ValidateCategoryTranslationLocale(categoryUUID string, locale string) lib.APIError {
+	var fieldErrs lib.ValidationErrors
+
+	validateCategoryUUID(&fieldErrs, categoryUUID)
+	validateTranslationLocale(&fieldErrs, locale)
+
+	if fieldErrs.HasErrors() {
+		return lib.NewValidationError("invalid category translation input", fieldErrs)
 	}
 
-	if len(errMessages) > 0 {
-		return lib.NewBadRequestError(strings.Join(errMessages, "; "))
+	return nil
+}
+
+func validateTranslationLocale(fieldErrs *lib.ValidationErrors, locale string) {
+	switch {
+	case locale == lib.DefaultLocale:
+		fieldErrs.Add("locale", lib.FieldCodeInvalidValue,
+			fmt.Sprintf("%s is the default locale, update the category itself to change its name or description", locale))
+	case !lib.IsSupportedLocale(locale):
+		fieldErrs.Add("locale", lib.FieldCodeInvalidValue, fmt.Sprintf("unsupported locale, you entered: %s", locale))
+	}
+}
+
+// ValidateCategoryUUID validates a category uuid path param.
+func ValidateCategoryUUID(categoryUUID string) lib.APIError {
+	var fieldErrs lib.ValidationErrors
+
+	validateCategoryUUID(&fieldErrs, categoryUUID)
+
+	if fieldErrs.HasErrors() {
+		return lib.NewValidationError("invalid category id", fieldErrs)
 	}
 
 	return nil
 }
+
+func validateCategoryUUID(fieldErrs *lib.ValidationErrors, categoryUUID string) {
+	if !regexp.MustCompile(categoryUUIDRegex).MatchString(categoryUUID) {
+		fieldErrs.Add("categoryUuid", lib.FieldCodeInvalidFormat, "invalid category uuid")
+	}
+}
+
+func validateCategoryFields(fieldErrs *lib.ValidationErrors, name, description string) {
+	if name == "" {
+		fieldErrs.Add("name", lib.FieldCodeRequired, "category name cannot be empty")
+	}
+
+	if !regexp.MustCompile(categoryNameRegex).MatchString(name) {
+		fieldErrs.Add("name", lib.FieldCodeInvalidFormat, "invalid characters in Category name field")
+	}
+
+	if utf8.RuneCountInString(description) > 255 {
+		fieldErrs.Add("description", lib.FieldCodeTooLong, "category description must be less than 256 characters")
+	}
+}
+
+// ValidateCategoryHistoryRequest validates category uuid path param and optional limit and before query params,
+// returns the before cursor(0 for newest entries) and limit(50 if missing, at most 200).
+func ValidateCategoryHistoryRequest(req domain.CategoryHistoryRequestDTO) (int64, int, lib.APIError) {
+	var (
+		fieldErrs lib.ValidationErrors
+		beforeID  int64
+		limit     = defaultHistoryLimit
+		err       error
+	)
+
+	validateCategoryUUID(&fieldErrs, req.CategoryUUID)
+
+	if req.LimitStr != "" {
+		limit, err = strconv.Atoi(req.LimitStr)
+		if err != nil || limit < 1 || limit > maxHistoryLimit {
+			fieldErrs.Add("limit", lib.FieldCodeOutOfRange,
+				fmt.Sprintf("limit must be a number from 1 to %d, you entered: %s", maxHistoryLimit, req.LimitStr))
+		}
+	}
+
+	if req.BeforeStr != "" {
+		beforeID, err = strconv.ParseInt(req.BeforeStr, 10, 64)
+		if err != nil || beforeID < 1 {
+			fieldErrs.Add("before", lib.FieldCodeInvalidValue,
+				fmt.Sprintf("before must be a positive history id, you entered: %s", req.BeforeStr))
+		}
+	}
+
+	if fieldErrs.HasErrors() {
+		return 0, 0, lib.NewValidationError("invalid category history input", fieldErrs)
+	}
+
+	return beforeID, limit, nil
+}
+
+// ParseAsOf parses the asOf query param, an RFC 3339 timestamp like 2024-03-01T10:00:00Z.
+func ParseAsOf(asOf string) (time.Time, lib.APIError) {
+	t, err := time.Parse(time.RFC3339, asOf)
+	if err != nil {
+		var fieldErrs lib.ValidationErrors
+		fieldErrs.Add("asOf", lib.FieldCodeInvalidFormat,
+			fmt.Sprintf("asOf must be an RFC 3339 timestamp like 2024-03-01T10:00:00Z, you entered: %s", asOf))
+
+		return time.Time{}, lib.NewValidationError("invalid category tree input", fieldErrs)
+	}
+
+	return t, nil
+}
+
+// ValidateUploadCategoryMediaRequest validates path params and the declared size of an uploaded file,
+// content type and dimensions are checked from the file content.
+func ValidateUploadCategoryMediaRequest(req domain.UploadCategoryMediaRequestDTO) lib.APIError {
+	var fieldErrs lib.ValidationErrors
+
+	validateCategoryUUID(&fieldErrs, req.CategoryUUID)
+	validateMediaKind(&fieldErrs, req.Kind)
+
+	switch {
+	case req.File == nil || req.Size == 0:
+		fieldErrs.Add("file", lib.FieldCodeRequired, "file is required")
+	case req.Size > maxMediaBytes:
+		fieldErrs.Add("file", lib.FieldCodeTooLong, fmt.Sprintf("file must be at most %d MB", maxMediaBytes>>20))
+	}
+
+	if fieldErrs.HasErrors() {
+		return lib.NewValidationError("invalid media upload", fieldErrs)
+	}
+
+	return nil
+}
+
+// ValidateCategoryMediaKind validates category uuid and media kind path params.
+func ValidateCategoryMediaKind(categoryUUID string, kind string) lib.APIError {
+	var fieldErrs lib.ValidationErrors
+
+	validateCategoryUUID(&fieldErrs, categoryUUID)
+	validateMediaKind(&fieldErrs, kind)
+
+	if fieldErrs.HasErrors() {
+		return lib.NewValidationError("invalid category media input", fieldErrs)
+	}
+
+	return nil
+}
+
+func validateMediaKind(fieldErrs *lib.ValidationErrors, kind string) {
+	if _, ok := thumbnailPresets[kind]; !ok {
+		fieldErrs.Add("kind", lib.FieldCodeInvalidValue, fmt.Sprintf("media kind must be banner or icon, you entered: %s", kind))
+	}
+}
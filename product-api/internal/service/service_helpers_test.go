@@ -5,7 +5,9 @@ import (
 	"github.com/ashtishad/ecommerce/lib"
 	"github.com/ashtishad/ecommerce/product-api/internal/domain"
 	"github.com/stretchr/testify/assert"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestValidateNewCategoryRequest(t *testing.T) {
@@ -14,6 +16,7 @@ func TestValidateNewCategoryRequest(t *testing.T) {
 		req     domain.NewCategoryRequestDTO
 		wantErr bool
 		errMsg  string
+		fields  []string
 	}{
 		{
 			name:    "Valid Category - No Description",
@@ -30,24 +33,28 @@ func TestValidateNewCategoryRequest(t *testing.T) {
 			req:     domain.NewCategoryRequestDTO{Name: "", Description: "Great Quality"},
 			wantErr: true,
 			errMsg:  "category name cannot be empty",
+			fields:  []string{"name"},
 		},
 		{
 			name:    "Invalid Category - Invalid Characters in Name",
 			req:     domain.NewCategoryRequestDTO{Name: "Sound@Equipment", Description: "Great Quality"},
 			wantErr: true,
 			errMsg:  "invalid characters in Category name field",
+			fields:  []string{"name"},
 		},
 		{
 			name:    "Invalid Description - Description Too Long",
 			req:     domain.NewCategoryRequestDTO{Name: "Phone", Description: string(make([]rune, 256))},
 			wantErr: true,
 			errMsg:  "category description must be less than 256 characters",
+			fields:  []string{"description"},
 		},
 		{
 			name:    "Invalid Category and Description Too Long",
 			req:     domain.NewCategoryRequestDTO{Name: "Sound@Equipment", Description: string(make([]rune, 256))},
 			wantErr: true,
 			errMsg:  "invalid characters in Category name field; category description must be less than 256 characters",
+			fields:  []string{"name", "description"},
 		},
 	}
 
@@ -59,10 +66,320 @@ func TestValidateNewCategoryRequest(t *testing.T) {
 				var apiErr lib.APIError
 				ok := errors.As(err, &apiErr)
 				assert.True(t, ok, "Expected APIError type")
-				assert.Equal(t, tt.errMsg, apiErr.AsMessage())
+				assert.Equal(t, "invalid category input", apiErr.AsMessage())
+				assert.Equal(t, tt.errMsg, apiErr.FieldErrors().Error())
+				assert.Equal(t, tt.fields, fieldNames(apiErr.FieldErrors()))
 			} else {
 				assert.Nil(t, err)
 			}
 		})
 	}
 }
+
+func fieldNames(fieldErrs lib.ValidationErrors) []string {
+	names := make([]string, 0, len(fieldErrs))
+	for _, fe := range fieldErrs {
+		names = append(names, fe.Field)
+	}
+
+	return names
+}
+
+func TestValidateUpdateCategoryRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    domain.UpdateCategoryRequestDTO
+		fields []string
+	}{
+		{
+			name:   "Valid rename",
+			req:    domain.UpdateCategoryRequestDTO{CategoryUUID: "e085c298-35b0-4b05-bcc1-a24d4fff4794", Name: "Gaming Phones"},
+			fields: nil,
+		},
+		{
+			name:   "Invalid uuid and empty name",
+			req:    domain.UpdateCategoryRequestDTO{CategoryUUID: "not-a-uuid", Name: ""},
+			fields: []string{"categoryUuid", "name"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiErr := ValidateUpdateCategoryRequest(tt.req)
+			if tt.fields == nil {
+				assert.Nil(t, apiErr)
+				return
+			}
+
+			assert.Equal(t, tt.fields, fieldNames(apiErr.FieldErrors()))
+		})
+	}
+}
+
+func TestValidateUpdateCategoryStatusRequest(t *testing.T) {
+	validUUID := "e085c298-35b0-4b05-bcc1-a24d4fff4794"
+
+	for _, status := range []string{"active", "inactive", "deleted"} {
+		assert.Nil(t, ValidateUpdateCategoryStatusRequest(domain.UpdateCategoryStatusRequestDTO{CategoryUUID: validUUID, Status: status}))
+	}
+
+	apiErr := ValidateUpdateCategoryStatusRequest(domain.UpdateCategoryStatusRequestDTO{CategoryUUID: validUUID, Status: "archived"})
+	assert.Equal(t, []string{"status"}, fieldNames(apiErr.FieldErrors()))
+	assert.Equal(t, lib.FieldCodeInvalidValue, apiErr.FieldErrors()[0].Code)
+}
+
+func TestValidateMoveCategoryRequest(t *testing.T) {
+	validUUID := "e085c298-35b0-4b05-bcc1-a24d4fff4794"
+	parentUUID := "bd11d903-7549-42b2-bea6-dd8a7cb8821e"
+
+	assert.Nil(t, ValidateMoveCategoryRequest(domain.MoveCategoryRequestDTO{CategoryUUID: validUUID, ParentCategoryUUID: parentUUID}))
+	assert.Nil(t, ValidateMoveCategoryRequest(domain.MoveCategoryRequestDTO{CategoryUUID: validUUID}))
+
+	apiErr := ValidateMoveCategoryRequest(domain.MoveCategoryRequestDTO{CategoryUUID: validUUID, ParentCategoryUUID: "not-a-uuid"})
+	assert.Equal(t, []string{"parentCategoryUuid"}, fieldNames(apiErr.FieldErrors()))
+
+	apiErr = ValidateMoveCategoryRequest(domain.MoveCategoryRequestDTO{CategoryUUID: validUUID, ParentCategoryUUID: validUUID})
+	assert.Equal(t, domain.ErrCodeCategoryMoveCycle, apiErr.ErrorCode())
+}
+
+func TestValidateCategorySubtreeRequest(t *testing.T) {
+	validUUID := "e085c298-35b0-4b05-bcc1-a24d4fff4794"
+
+	tests := []struct {
+		name      string
+		depth     string
+		wantDepth int
+		wantErr   bool
+	}{
+		{"Whole subtree", "", 0, false},
+		{"Depth one", "1", 1, false},
+		{"Depth three", "3", 3, false},
+		{"Zero depth", "0", 0, true},
+		{"Negative depth", "-2", 0, true},
+		{"Not a number", "all", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			depth, apiErr := ValidateCategorySubtreeRequest(domain.CategorySubtreeRequestDTO{CategoryUUID: validUUID, DepthStr: tt.depth})
+			if tt.wantErr {
+				assert.Equal(t, []string{"depth"}, fieldNames(apiErr.FieldErrors()))
+				return
+			}
+
+			assert.Nil(t, apiErr)
+			assert.Equal(t, tt.wantDepth, depth)
+		})
+	}
+}
+
+func TestValidateReorderChildrenRequest(t *testing.T) {
+	validUUID := "e085c298-35b0-4b05-bcc1-a24d4fff4794"
+	childA := "bd11d903-7549-42b2-bea6-dd8a7cb8821e"
+	childB := "0f0c6b7e-3f1a-4c55-9a52-3c1f2a9d7b10"
+
+	tests := []struct {
+		name       string
+		order      []string
+		wantFields []string
+	}{
+		{"Valid order", []string{childA, childB}, nil},
+		{"No children", nil, nil},
+		{"Invalid uuid", []string{childA, "nope"}, []string{"order[1]"}},
+		{"Duplicate uuid", []string{childA, childB, strings.ToUpper(childA)}, []string{"order[2]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiErr := ValidateReorderChildrenRequest(domain.ReorderChildrenRequestDTO{CategoryUUID: validUUID, Order: tt.order})
+			if tt.wantFields == nil {
+				assert.Nil(t, apiErr)
+				return
+			}
+
+			assert.Equal(t, tt.wantFields, fieldNames(apiErr.FieldErrors()))
+		})
+	}
+}
+
+func TestValidateUpdateCategorySlugRequest(t *testing.T) {
+	validUUID := "e085c298-35b0-4b05-bcc1-a24d4fff4794"
+
+	assert.Nil(t, ValidateUpdateCategorySlugRequest(domain.UpdateCategorySlugRequestDTO{CategoryUUID: validUUID, Slug: "gaming-phones"}))
+
+	for _, invalid := range []string{"", "Gaming", "gaming phones", "gaming--phones", "-gaming"} {
+		apiErr := ValidateUpdateCategorySlugRequest(domain.UpdateCategorySlugRequestDTO{CategoryUUID: validUUID, Slug: invalid})
+		assert.Equal(t, []string{"slug"}, fieldNames(apiErr.FieldErrors()), "slug %q", invalid)
+	}
+}
+
+func TestParseCategoryPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		want     []string
+		wantCode string
+	}{
+		{"Single segment", "phone", []string{"phone"}, ""},
+		{"Nested with extra slashes", "/phone//smartphone/gaming/", []string{"phone", "smartphone", "gaming"}, ""},
+		{"Uppercase is lowered", "Phone/Gaming", []string{"phone", "gaming"}, ""},
+		{"Empty path", "/", nil, lib.CodeValidationFailed},
+		{"Segment can't be a slug", "phone/smart phone", nil, domain.ErrCodeCategoryNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slugs, apiErr := ParseCategoryPath(tt.path)
+			if tt.wantCode != "" {
+				assert.Equal(t, tt.wantCode, apiErr.ErrorCode())
+				return
+			}
+
+			assert.Nil(t, apiErr)
+			assert.Equal(t, tt.want, slugs)
+		})
+	}
+}
+
+func TestValidateSaveCategoryAttributeRequest(t *testing.T) {
+	validUUID := "e085c298-35b0-4b05-bcc1-a24d4fff4794"
+
+	tests := []struct {
+		name   string
+		req    domain.SaveCategoryAttributeRequestDTO
+		fields []string
+	}{
+		{
+			name: "Valid number with unit",
+			req:  domain.SaveCategoryAttributeRequestDTO{CategoryUUID: validUUID, Code: "battery_mah", Name: "Battery", Type: "number", Unit: "mAh", Required: true},
+		},
+		{
+			name: "Valid enum",
+			req:  domain.SaveCategoryAttributeRequestDTO{CategoryUUID: validUUID, Code: "anc_mode", Name: "ANC mode", Type: "enum", Values: []string{"off", "hybrid"}},
+		},
+		{
+			name:   "Invalid code and type",
+			req:    domain.SaveCategoryAttributeRequestDTO{CategoryUUID: validUUID, Code: "Screen-Size", Name: "Screen", Type: "float"},
+			fields: []string{"code", "type"},
+		},
+		{
+			name:   "Unit on non number, values on bool",
+			req:    domain.SaveCategoryAttributeRequestDTO{CategoryUUID: validUUID, Code: "anc", Name: "ANC", Type: "bool", Unit: "db", Values: []string{"yes"}},
+			fields: []string{"unit", "values"},
+		},
+		{
+			name:   "Enum without values",
+			req:    domain.SaveCategoryAttributeRequestDTO{CategoryUUID: validUUID, Code: "color", Name: "Color", Type: "enum"},
+			fields: []string{"values"},
+		},
+		{
+			name:   "Enum with empty and duplicate values",
+			req:    domain.SaveCategoryAttributeRequestDTO{CategoryUUID: validUUID, Code: "color", Name: "Color", Type: "enum", Values: []string{"red", " ", "red"}},
+			fields: []string{"values[1]", "values[2]"},
+		},
+		{
+			name:   "Missing name and invalid uuid",
+			req:    domain.SaveCategoryAttributeRequestDTO{CategoryUUID: "bad", Code: "size", Name: " ", Type: "string"},
+			fields: []string{"categoryUuid", "name"},
+		},
+		{
+			name:   "Too long name",
+			req:    domain.SaveCategoryAttributeRequestDTO{CategoryUUID: validUUID, Code: "size", Name: strings.Repeat("a", 101), Type: "string"},
+			fields: []string{"name"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiErr := ValidateSaveCategoryAttributeRequest(tt.req)
+			if tt.fields == nil {
+				assert.Nil(t, apiErr)
+				return
+			}
+
+			assert.Equal(t, tt.fields, fieldNames(apiErr.FieldErrors()))
+		})
+	}
+}
+
+func TestValidateSaveCategoryTranslationRequest(t *testing.T) {
+	validUUID := "e085c298-35b0-4b05-bcc1-a24d4fff4794"
+
+	tests := []struct {
+		name   string
+		req    domain.SaveCategoryTranslationRequestDTO
+		fields []string
+	}{
+		{
+			name: "Valid Bengali name",
+			req:  domain.SaveCategoryTranslationRequestDTO{CategoryUUID: validUUID, Locale: "bn", Name: "স্মার্টফোন ও ট্যাবলেট", Description: "সব ধরনের ফোন"},
+		},
+		{
+			name:   "Default locale",
+			req:    domain.SaveCategoryTranslationRequestDTO{CategoryUUID: validUUID, Locale: "en", Name: "Phone"},
+			fields: []string{"locale"},
+		},
+		{
+			name:   "Unsupported locale and empty name",
+			req:    domain.SaveCategoryTranslationRequestDTO{CategoryUUID: validUUID, Locale: "fr", Name: " "},
+			fields: []string{"locale", "name"},
+		},
+		{
+			name:   "Invalid characters and long description",
+			req:    domain.SaveCategoryTranslationRequestDTO{CategoryUUID: validUUID, Locale: "bn", Name: "ফোন!", Description: strings.Repeat("a", 256)},
+			fields: []string{"name", "description"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiErr := ValidateSaveCategoryTranslationRequest(tt.req)
+			if tt.fields == nil {
+				assert.Nil(t, apiErr)
+				return
+			}
+
+			assert.Equal(t, tt.fields, fieldNames(apiErr.FieldErrors()))
+		})
+	}
+}
+
+func TestValidateCategoryHistoryRequest(t *testing.T) {
+	validUUID := "e085c298-35b0-4b05-bcc1-a24d4fff4794"
+
+	tests := []struct {
+		name       string
+		req        domain.CategoryHistoryRequestDTO
+		wantBefore int64
+		wantLimit  int
+		fields     []string
+	}{
+		{"Defaults", domain.CategoryHistoryRequestDTO{CategoryUUID: validUUID}, 0, 50, nil},
+		{"Next page", domain.CategoryHistoryRequestDTO{CategoryUUID: validUUID, LimitStr: "200", BeforeStr: "42"}, 42, 200, nil},
+		{"Limit over max", domain.CategoryHistoryRequestDTO{CategoryUUID: validUUID, LimitStr: "201"}, 0, 0, []string{"limit"}},
+		{"Invalid uuid and cursor", domain.CategoryHistoryRequestDTO{CategoryUUID: "abc", BeforeStr: "0"}, 0, 0, []string{"categoryUuid", "before"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, limit, apiErr := ValidateCategoryHistoryRequest(tt.req)
+			if tt.fields != nil {
+				assert.Equal(t, tt.fields, fieldNames(apiErr.FieldErrors()))
+				return
+			}
+
+			assert.Nil(t, apiErr)
+			assert.Equal(t, tt.wantBefore, before)
+			assert.Equal(t, tt.wantLimit, limit)
+		})
+	}
+}
+
+func TestParseAsOf(t *testing.T) {
+	asOf, apiErr := ParseAsOf("2024-03-01T10:00:00+06:00")
+	assert.Nil(t, apiErr)
+	assert.True(t, asOf.Equal(time.Date(2024, 3, 1, 4, 0, 0, 0, time.UTC)))
+
+	_, apiErr = ParseAsOf("2024-03-01")
+	assert.Equal(t, []string{"asOf"}, fieldNames(apiErr.FieldErrors()))
+}
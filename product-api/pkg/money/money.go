@@ -0,0 +1,70 @@
+package money
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount in minor units of an ISO 4217 currency, e.g. {79999, "USD"} is 799.99 USD and {1500, "JPY"} is 1500 JPY.
+// Amounts are integers, so adding and comparing prices never rounds.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// minorUnits are the fraction digits of supported ISO 4217 currencies.
+var minorUnits = map[string]int{
+	// no minor unit
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0,
+	"VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	// thousandths
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	// hundredths
+	"AED": 2, "AUD": 2, "BDT": 2, "BRL": 2, "CAD": 2, "CHF": 2, "CNY": 2, "CZK": 2, "DKK": 2, "EGP": 2, "EUR": 2,
+	"GBP": 2, "HKD": 2, "IDR": 2, "INR": 2, "LKR": 2, "MXN": 2, "MYR": 2, "NGN": 2, "NOK": 2, "NPR": 2, "NZD": 2,
+	"PHP": 2, "PKR": 2, "PLN": 2, "QAR": 2, "SAR": 2, "SEK": 2, "SGD": 2, "THB": 2, "TRY": 2, "TWD": 2, "UAH": 2,
+	"USD": 2, "ZAR": 2,
+}
+
+// MinorUnits returns the number of fraction digits of an uppercase currency code, false if the currency isn't supported.
+func MinorUnits(currency string) (int, bool) {
+	digits, ok := minorUnits[currency]
+	return digits, ok
+}
+
+// New returns an amount of minor units of currency, returns error if the currency isn't supported.
+func New(amount int64, currency string) (Money, error) {
+	if _, ok := minorUnits[currency]; !ok {
+		return Money{}, fmt.Errorf("unsupported currency: %q", currency)
+	}
+
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// Decimal formats the amount in major units with the currency's fraction digits, e.g. 799.99, 1500(JPY), 0.050(KWD).
+func (m Money) Decimal() string {
+	digits := minorUnits[m.Currency]
+
+	s := strconv.FormatInt(m.Amount, 10)
+
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+
+	if digits == 0 {
+		return sign + s
+	}
+
+	if len(s) <= digits {
+		s = strings.Repeat("0", digits-len(s)+1) + s
+	}
+
+	return sign + s[:len(s)-digits] + "." + s[len(s)-digits:]
+}
+
+// String formats money as decimal amount and currency, e.g. 799.99 USD.
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
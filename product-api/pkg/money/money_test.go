@@ -0,0 +1,55 @@
+package money
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		name  string
+		money Money
+		want  string
+	}{
+		{"Hundredths", Money{79999, "USD"}, "799.99"},
+		{"Whole amount keeps fraction digits", Money{80000, "USD"}, "800.00"},
+		{"Less than one major unit", Money{5, "EUR"}, "0.05"},
+		{"Zero", Money{0, "BDT"}, "0.00"},
+		{"No minor unit", Money{1500, "JPY"}, "1500"},
+		{"Thousandths", Money{50, "KWD"}, "0.050"},
+		{"Negative", Money{-1050, "USD"}, "-10.50"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.money.Decimal())
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	m, err := New(79999, "USD")
+	require.NoError(t, err)
+	assert.Equal(t, "799.99 USD", m.String())
+
+	_, err = New(100, "usd")
+	require.Error(t, err)
+
+	_, err = New(100, "XYZ")
+	require.Error(t, err)
+}
+
+func TestMinorUnits(t *testing.T) {
+	digits, ok := MinorUnits("JPY")
+	assert.True(t, ok)
+	assert.Equal(t, 0, digits)
+
+	digits, ok = MinorUnits("BHD")
+	assert.True(t, ok)
+	assert.Equal(t, 3, digits)
+
+	_, ok = MinorUnits("ABC")
+	assert.False(t, ok)
+}
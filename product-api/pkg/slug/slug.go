@@ -0,0 +1,132 @@
+package slug
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxLength is the maximum slug length in characters(runes), long names are cut at a word boundary when possible.
+const MaxLength = 100
+
+// latinReplacements transliterates Latin letters that don't decompose into an ASCII letter and a mark.
+var latinReplacements = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ð': "d", 'ł': "l", 'þ': "th", 'ı': "i", '&': "and",
+}
+
+// Make builds a URL slug from a name.
+//   - Latin letters are transliterated to ASCII, "Café Crème" becomes "cafe-creme", "Straße" becomes "strasse".
+//   - Letters of other scripts are kept lowercased with their marks, "স্মার্ট ফোন" becomes "স্মার্ট-ফোন".
+//   - Any run of other characters becomes a single hyphen, leading and trailing hyphens are removed.
+//
+// returns empty string if name has no letters or digits.
+func Make(name string) string {
+	var b strings.Builder
+
+	pendingHyphen := false
+	dropMarks := false
+
+	for _, r := range norm.NFKD.String(name) {
+		r = unicode.ToLower(r)
+
+		switch {
+		case unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Mc, r):
+			// marks of latin letters are accents(é = e + ´), marks of other scripts are part of the spelling
+			if !dropMarks && b.Len() > 0 && !pendingHyphen {
+				b.WriteRune(r)
+			}
+
+			continue
+		case latinReplacements[r] != "":
+			writeWithHyphen(&b, &pendingHyphen, latinReplacements[r])
+			dropMarks = true
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			writeWithHyphen(&b, &pendingHyphen, string(r))
+			dropMarks = r < utf8.RuneSelf || unicode.Is(unicode.Latin, r)
+		default:
+			pendingHyphen = b.Len() > 0
+			dropMarks = true
+		}
+	}
+
+	return truncate(norm.NFC.String(b.String()))
+}
+
+func writeWithHyphen(b *strings.Builder, pendingHyphen *bool, s string) {
+	if *pendingHyphen {
+		b.WriteByte('-')
+		*pendingHyphen = false
+	}
+
+	b.WriteString(s)
+}
+
+func truncate(s string) string {
+	if utf8.RuneCountInString(s) <= MaxLength {
+		return s
+	}
+
+	runes := []rune(s)[:MaxLength]
+	cut := string(runes)
+
+	if i := strings.LastIndexByte(cut, '-'); i > 0 {
+		cut = cut[:i]
+	}
+
+	return strings.TrimRight(cut, "-")
+}
+
+// Valid reports whether s is a well-formed slug, lowercase letters(with marks) and digits in groups separated
+// by single hyphens, at most MaxLength characters.
+func Valid(s string) bool {
+	if s == "" || utf8.RuneCountInString(s) > MaxLength || s != norm.NFC.String(s) {
+		return false
+	}
+
+	for _, group := range strings.Split(s, "-") {
+		if group == "" {
+			return false
+		}
+
+		for i, r := range group {
+			isMark := unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Mc, r)
+			if i == 0 && isMark {
+				return false
+			}
+
+			if !isMark && !unicode.IsDigit(r) && !(unicode.IsLetter(r) && !unicode.IsUpper(r)) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Unique returns base if it's not taken, otherwise the first free of base-2, base-3, ...
+func Unique(base string, taken []string) string {
+	used := make(map[string]bool, len(taken))
+	for _, t := range taken {
+		used[t] = true
+	}
+
+	if !used[base] {
+		return base
+	}
+
+	for n := 2; ; n++ {
+		suffix := fmt.Sprintf("-%d", n)
+
+		candidate := base + suffix
+		if utf8.RuneCountInString(candidate) > MaxLength {
+			candidate = strings.TrimRight(string([]rune(base)[:MaxLength-utf8.RuneCountInString(suffix)]), "-") + suffix
+		}
+
+		if !used[candidate] {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package slug
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"Single word", "Phone", "phone"},
+		{"Camel case kept together", "SoundEquipment", "soundequipment"},
+		{"Spaces and punctuation", "  Phones, Tablets & More!! ", "phones-tablets-and-more"},
+		{"Accents removed", "Café Crème", "cafe-creme"},
+		{"Latin letters without decomposition", "Straße Øl", "strasse-ol"},
+		{"Digits", "Galaxy S24 Ultra", "galaxy-s24-ultra"},
+		{"Full width letters", "ＴＷＳ", "tws"},
+		{"Bengali keeps vowel signs", "স্মার্ট ফোন", "স্মার্ট-ফোন"},
+		{"Only symbols", "***", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Make(tt.input)
+			assert.Equal(t, tt.want, got)
+
+			if got != "" {
+				assert.True(t, Valid(got), "made slug must be valid: %s", got)
+			}
+		})
+	}
+}
+
+func TestMakeTruncates(t *testing.T) {
+	got := Make(strings.Repeat("word ", 40))
+
+	assert.LessOrEqual(t, len([]rune(got)), MaxLength)
+	assert.False(t, strings.HasSuffix(got, "-"))
+	assert.True(t, strings.HasSuffix(got, "word"))
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"gaming", true},
+		{"gaming-phones-2024", true},
+		{"স্মার্ট-ফোন", true},
+		{"", false},
+		{"Gaming", false},
+		{"gaming--phones", false},
+		{"-gaming", false},
+		{"gaming-", false},
+		{"gaming phones", false},
+		{"gaming/phones", false},
+		{strings.Repeat("a", MaxLength+1), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.want, Valid(tt.input))
+		})
+	}
+}
+
+func TestUnique(t *testing.T) {
+	assert.Equal(t, "gaming", Unique("gaming", nil))
+	assert.Equal(t, "gaming", Unique("gaming", []string{"gaming-2"}))
+	assert.Equal(t, "gaming-2", Unique("gaming", []string{"gaming"}))
+	assert.Equal(t, "gaming-4", Unique("gaming", []string{"gaming", "gaming-2", "gaming-3"}))
+
+	long := strings.Repeat("a", MaxLength)
+	got := Unique(long, []string{long})
+	assert.Len(t, []rune(got), MaxLength)
+	assert.True(t, strings.HasSuffix(got, "-2"))
+}
@@ -0,0 +1,118 @@
+package thumbnail
+
+import (
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+)
+
+// JPEGQuality of encoded jpeg thumbnails.
+const JPEGQuality = 85
+
+// Fit scales img down so its longest side is at most maxSide, keeping the aspect ratio.
+// Pixels are area averaged(box filter) with premultiplied alpha, so downscaling doesn't alias or darken edges.
+// Images that already fit are returned as they are, they're never upscaled.
+func Fit(img image.Image, maxSide int) image.Image {
+	b := img.Bounds()
+	srcW, srcH := b.Dx(), b.Dy()
+
+	if maxSide < 1 || (srcW <= maxSide && srcH <= maxSide) {
+		return img
+	}
+
+	dstW, dstH := maxSide, maxSide
+	if srcW >= srcH {
+		dstH = max(1, int(math.Round(float64(srcH)*float64(maxSide)/float64(srcW))))
+	} else {
+		dstW = max(1, int(math.Round(float64(srcW)*float64(maxSide)/float64(srcH))))
+	}
+
+	return Resize(img, dstW, dstH)
+}
+
+// Resize scales img to exactly width x height with area averaging, meant for downscaling.
+func Resize(img image.Image, width, height int) *image.RGBA {
+	b := img.Bounds()
+	src := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+
+	srcW, srcH := b.Dx(), b.Dy()
+
+	// horizontal pass, rows of src averaged into width columns
+	xWeights := boxWeights(srcW, width)
+	rows := make([]float64, width*srcH*4)
+
+	for y := 0; y < srcH; y++ {
+		for x, weights := range xWeights {
+			out := rows[(y*width+x)*4 : (y*width+x)*4+4]
+
+			for _, w := range weights {
+				in := src.Pix[y*src.Stride+w.index*4 : y*src.Stride+w.index*4+4]
+				for c := range out {
+					out[c] += float64(in[c]) * w.weight
+				}
+			}
+		}
+	}
+
+	// vertical pass, columns averaged into height rows
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	yWeights := boxWeights(srcH, height)
+
+	for y, weights := range yWeights {
+		for x := 0; x < width; x++ {
+			var sum [4]float64
+
+			for _, w := range weights {
+				in := rows[(w.index*width+x)*4 : (w.index*width+x)*4+4]
+				for c := range sum {
+					sum[c] += in[c] * w.weight
+				}
+			}
+
+			out := dst.Pix[y*dst.Stride+x*4 : y*dst.Stride+x*4+4]
+			for c := range out {
+				out[c] = uint8(math.Min(255, math.Round(sum[c])))
+			}
+		}
+	}
+
+	return dst
+}
+
+// Encode writes img as jpeg if contentType is image/jpeg, otherwise as png, which keeps transparency.
+func Encode(w io.Writer, img image.Image, contentType string) error {
+	if contentType == "image/jpeg" {
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: JPEGQuality})
+	}
+
+	return png.Encode(w, img)
+}
+
+type sourceWeight struct {
+	index  int
+	weight float64
+}
+
+// boxWeights returns for each of dstLen pixels the source pixels it covers and their share of its area,
+// shares of a pixel add up to 1.
+func boxWeights(srcLen, dstLen int) [][]sourceWeight {
+	scale := float64(srcLen) / float64(dstLen)
+	weights := make([][]sourceWeight, dstLen)
+
+	for i := range weights {
+		start, end := float64(i)*scale, float64(i+1)*scale
+
+		for j := int(start); j < srcLen && float64(j) < end; j++ {
+			covered := math.Min(end, float64(j+1)) - math.Max(start, float64(j))
+			if covered > 0 {
+				weights[i] = append(weights[i], sourceWeight{index: j, weight: covered / scale})
+			}
+		}
+	}
+
+	return weights
+}
@@ -0,0 +1,68 @@
+package thumbnail
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		maxSide       int
+		wantW, wantH  int
+	}{
+		{"Landscape", 1600, 900, 800, 800, 450},
+		{"Portrait", 300, 1200, 600, 150, 600},
+		{"Already fits", 100, 50, 200, 100, 50},
+		{"Thin line keeps a pixel", 4000, 1, 100, 100, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Fit(image.NewRGBA(image.Rect(0, 0, tt.width, tt.height)), tt.maxSide)
+			assert.Equal(t, tt.wantW, got.Bounds().Dx())
+			assert.Equal(t, tt.wantH, got.Bounds().Dy())
+		})
+	}
+}
+
+// TestResizeAveragesArea makes sure a 2x2 checkerboard of black and white becomes mid gray,
+// and a transparent pixel doesn't darken an opaque one.
+func TestResizeAveragesArea(t *testing.T) {
+	checker := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	checker.Set(0, 0, color.White)
+	checker.Set(1, 1, color.White)
+	checker.Set(1, 0, color.Black)
+	checker.Set(0, 1, color.Black)
+
+	got := Resize(checker, 1, 1).RGBAAt(0, 0)
+	assert.Equal(t, color.RGBA{R: 128, G: 128, B: 128, A: 255}, got)
+
+	halfTransparent := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	halfTransparent.Set(0, 0, color.NRGBA{R: 255, A: 255})
+
+	got = Resize(halfTransparent, 1, 1).RGBAAt(0, 0)
+	assert.Equal(t, color.RGBA{R: 128, A: 128}, got, "premultiplied red at half opacity")
+}
+
+func TestEncode(t *testing.T) {
+	img := Fit(image.NewRGBA(image.Rect(0, 0, 40, 20)), 10)
+
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, img, "image/jpeg"))
+	_, err := jpeg.Decode(&buf)
+	require.NoError(t, err)
+
+	buf.Reset()
+	require.NoError(t, Encode(&buf, img, "image/png"))
+	_, err = png.Decode(&buf)
+	require.NoError(t, err)
+}
@@ -26,15 +26,13 @@ func Start(srv *http.Server, dbClient *sql.DB, l *slog.Logger) {
 	userRepositoryDB := domain.NewUserRepositoryDB(dbClient, l)
 	uh := UserHandlers{service.NewUserService(userRepositoryDB), l}
 
+	// trace id, logger, recovery and error rendering middlewares, registered before routes so every handler uses them,
+	// recovery comes after the trace id and the logger, so panics are rendered with the trace id and logged as 500s
+	r.Use(lib.TraceID(), gin.LoggerWithFormatter(lib.Logger), gin.CustomRecovery(lib.Recover(l)), lib.ErrorHandler(l))
+
 	// route url mappings
 	setUsersAPIRoutes(r, uh)
 
-	// custom logger middleware
-	r.Use(gin.LoggerWithFormatter(lib.Logger))
-
-	// custom recovery middleware
-	r.Use(gin.CustomRecovery(lib.Recover))
-
 	// start server
 	go func() {
 		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
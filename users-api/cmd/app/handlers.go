@@ -24,7 +24,7 @@ func (us *UserHandlers) createUserHandler(c *gin.Context) {
 	var newUserRequest domain.NewUserRequestDTO
 	if err := c.ShouldBindJSON(&newUserRequest); err != nil {
 		us.l.Error("failed to bind create user req dto", "err", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "bad request"})
+		_ = c.Error(lib.NewError(http.StatusBadRequest, lib.CodeInvalidJSONBody, nil).Wrap(err))
 
 		return
 	}
@@ -34,7 +34,7 @@ func (us *UserHandlers) createUserHandler(c *gin.Context) {
 
 	userResponse, err := us.service.NewUser(timeoutCtx, newUserRequest)
 	if err != nil {
-		c.JSON(err.StatusCode(), err)
+		_ = c.Error(err)
 		return
 	}
 
@@ -51,7 +51,7 @@ func (us *UserHandlers) updateUserHandler(c *gin.Context) {
 	var updateUserRequest domain.UpdateUserRequestDTO
 	if err := c.ShouldBindJSON(&updateUserRequest); err != nil {
 		us.l.Error("failed to bind update user req dto", "err", err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": "bad request"})
+		_ = c.Error(lib.NewError(http.StatusBadRequest, lib.CodeInvalidJSONBody, nil).Wrap(err))
 
 		return
 	}
@@ -63,7 +63,7 @@ func (us *UserHandlers) updateUserHandler(c *gin.Context) {
 	userResponse, err := us.service.UpdateUser(timeoutCtx, updateUserRequest)
 
 	if err != nil {
-		c.JSON(err.StatusCode(), err)
+		_ = c.Error(err)
 		return
 	}
 
@@ -85,7 +85,7 @@ func (us *UserHandlers) GetUsersHandler(c *gin.Context) {
 	users, pageInfo, err := us.service.GetAllUsers(timeoutCtx, opts)
 
 	if err != nil {
-		c.JSON(err.StatusCode(), err)
+		_ = c.Error(err)
 		return
 	}
 
@@ -10,3 +10,10 @@ const (
 	ErrCheckUserByEmail      = "unexpected error on checking user exists"
 	ErrUserAlreadyExistEmail = "user already exists with this email"
 )
+
+// stable machine-readable error codes, messages are in lib/locales catalog and documented in docs/errors.md
+const (
+	ErrCodeUserEmailExists = "user_email_exists"
+	ErrCodeUserNotFound    = "user_not_found"
+	ErrCodeUsersNotFound   = "users_not_found"
+)
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
 
 	"github.com/ashtishad/ecommerce/lib"
 )
@@ -112,7 +113,7 @@ func (d *UserRepositoryDB) findByQuery(ctx context.Context, query string, arg in
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			d.l.Error(ErrUserNotFound, "arg", arg, "err", err.Error())
-			return nil, lib.NewNotFoundError(lib.UnexpectedDatabaseErr)
+			return nil, lib.NewError(http.StatusNotFound, ErrCodeUserNotFound, nil).Wrap(err)
 		}
 
 		d.l.Error(ErrScanningData, "err", err.Error())
@@ -149,7 +150,7 @@ func (d *UserRepositoryDB) checkUserExistWithEmail(ctx context.Context, email st
 
 	if exists {
 		d.l.Error(ErrUserAlreadyExistEmail, "email", email)
-		return lib.NewDBFieldConflictError(ErrUserAlreadyExistEmail)
+		return lib.NewError(http.StatusConflict, ErrCodeUserEmailExists, nil)
 	}
 
 	return nil
@@ -237,7 +238,7 @@ func (d *UserRepositoryDB) FindAll(ctx context.Context, opts FindAllUsersOptions
 
 	if userCount == 0 {
 		d.l.Info("base query", "sql", baseQuery)
-		return nil, nil, lib.NewNotFoundError(ErrUsersNotFound)
+		return nil, nil, lib.NewError(http.StatusNotFound, ErrCodeUsersNotFound, nil)
 	}
 
 	if userCount < opts.PageSize {
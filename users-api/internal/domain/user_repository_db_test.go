@@ -126,7 +126,7 @@ func TestFindUserByID(t *testing.T) {
 
 		user, apiErr := repo.findByID(context.Background(), 2)
 		require.NotNil(t, apiErr)
-		require.Equal(t, lib.UnexpectedDatabaseErr, apiErr.AsMessage())
+		require.Equal(t, ErrCodeUserNotFound, apiErr.ErrorCode())
 		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
 		require.Nil(t, user)
 	})
@@ -178,7 +178,7 @@ func TestFindUserByUUID(t *testing.T) {
 
 		user, apiErr := repo.findByUUID(context.Background(), UserUUID)
 		require.NotNil(t, apiErr)
-		require.Equal(t, lib.UnexpectedDatabaseErr, apiErr.AsMessage())
+		require.Equal(t, ErrCodeUserNotFound, apiErr.ErrorCode())
 		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
 		require.Nil(t, user)
 	})
@@ -6,97 +6,93 @@ import (
 	"regexp"
 	"strconv"
 
+	"github.com/ashtishad/ecommerce/lib"
 	"github.com/ashtishad/ecommerce/users-api/internal/domain"
 	"github.com/ashtishad/ecommerce/users-api/pkg/constants"
+	"github.com/ashtishad/ecommerce/users-api/pkg/validate"
 )
 
-// validateCreateUserInput validates the input for creating a new user.
+var fullNameOpts = validate.FullNameOptions{
+	MinLength: constants.FullNameMinLength,
+	MaxLength: constants.FullNameMaxLength,
+}
+
+// validateCreateUserInput validates the input for creating a new user,
+// collects every failed field instead of returning on the first one.
 //   - Email: Must consist of alphanumeric characters, dots, underscores, percent signs, plus signs,
 //     and dashes before the @ symbol.
 //     After the @ symbol, there must be a top-level domain of at least
 //     two alphabetical characters.
-//   - FullName: Can only consist of alphabetical characters (both uppercase and lowercase) and spaces.
+//   - FullName: Unicode letters(any script) with spaces, apostrophes and hyphens between them,
+//     between constants.FullNameMinLength and constants.FullNameMaxLength characters after NFC normalization.
 //   - Phone: Must consist only of digits and must be between 10 and 15 characters in length.
 //   - SignUpOption: Must be either 'general' or 'google'.
-func validateCreateUserInput(input domain.NewUserRequestDTO) error {
-	if matched := regexp.MustCompile(constants.EmailRegex).MatchString(input.Email); !matched {
-		return fmt.Errorf("invalid email, you entered %s", input.Email)
-	}
+func validateCreateUserInput(input domain.NewUserRequestDTO) lib.ValidationErrors {
+	var fieldErrs lib.ValidationErrors
 
-	if len(input.Password) < 8 {
-		return errors.New("password must be at least 8 characters long")
-	}
+	validateEmail(&fieldErrs, input.Email)
 
-	if matched := regexp.MustCompile(constants.FullNameRegex).MatchString(input.FullName); !matched {
-		return fmt.Errorf("full name can only contain letters and spaces, you entered : %s", input.FullName)
+	if len(input.Password) < 8 {
+		fieldErrs.Add("password", lib.FieldCodeTooShort, "password must be at least 8 characters long")
 	}
 
-	if matched := regexp.MustCompile(constants.PhoneRegex).MatchString(input.Phone); !matched {
-		return fmt.Errorf("phone must contain 10 to 15 digits, you entered: %s", input.Phone)
-	}
+	validateFullName(&fieldErrs, input.FullName)
+	validatePhone(&fieldErrs, input.Phone)
 
 	if matched := regexp.MustCompile(constants.SignUpOptionRegex).MatchString(input.SignUpOption); !matched {
-		return fmt.Errorf("signUpOption must be 'general' or 'google', you entered: %s", input.SignUpOption)
+		fieldErrs.Add("signUpOption", lib.FieldCodeInvalidValue,
+			fmt.Sprintf("signUpOption must be 'general' or 'google', you entered: %s", input.SignUpOption))
 	}
 
-	if matched := regexp.MustCompile(constants.TimezoneRegex).MatchString(input.Timezone); !matched {
-		return fmt.Errorf("timezone will be in 'UTC' or 'asia/dhaka' format, you entered: %s", input.Timezone)
-	}
+	validateTimezone(&fieldErrs, input.Timezone)
 
-	return nil
+	return fieldErrs
 }
 
-// validateUpdateUserInput validates the input for creating a new user.
+// validateUpdateUserInput validates the input for updating a user,
+// collects every failed field instead of returning on the first one.
 //   - Email: Must consist of alphanumeric characters, dots, underscores, percent signs, plus signs,
 //     and dashes before the @ symbol.
 //     After the @ symbol, there must be a top-level domain of at least
 //     two alphabetical characters.
-//   - FullName: Can only consist of alphabetical characters (both uppercase and lowercase) and spaces.
+//   - FullName: Unicode letters(any script) with spaces, apostrophes and hyphens between them,
+//     between constants.FullNameMinLength and constants.FullNameMaxLength characters after NFC normalization.
 //   - Phone: Must consist only of digits and must be between 10 and 15 characters in length.
 //   - User_id: Must be an uuid.
-func validateUpdateUserInput(input domain.UpdateUserRequestDTO) error {
-	if matched := regexp.MustCompile(constants.UUIDRegex).MatchString(input.UserUUID); !matched {
-		return fmt.Errorf("invalid uuid, you entered %s", input.UserUUID)
-	}
-
-	if matched := regexp.MustCompile(constants.EmailRegex).MatchString(input.Email); !matched {
-		return fmt.Errorf("invalid email, you entered %s", input.Email)
-	}
-
-	if matched := regexp.MustCompile(constants.FullNameRegex).MatchString(input.FullName); !matched {
-		return fmt.Errorf("full name can only contain letters and spaces, you entered : %s", input.FullName)
-	}
+func validateUpdateUserInput(input domain.UpdateUserRequestDTO) lib.ValidationErrors {
+	var fieldErrs lib.ValidationErrors
 
-	if matched := regexp.MustCompile(constants.PhoneRegex).MatchString(input.Phone); !matched {
-		return fmt.Errorf("phone must contain 10 to 15 digits, you entered: %s", input.Phone)
+	if matched := regexp.MustCompile(constants.UUIDRegex).MatchString(input.UserUUID); !matched {
+		fieldErrs.Add("userId", lib.FieldCodeInvalidFormat, fmt.Sprintf("invalid uuid, you entered %s", input.UserUUID))
 	}
 
-	if matched := regexp.MustCompile(constants.TimezoneRegex).MatchString(input.Timezone); !matched {
-		return fmt.Errorf("timezone will be in 'UTC' or 'asia/dhaka' format, you entered: %s", input.Timezone)
-	}
+	validateEmail(&fieldErrs, input.Email)
+	validateFullName(&fieldErrs, input.FullName)
+	validatePhone(&fieldErrs, input.Phone)
+	validateTimezone(&fieldErrs, input.Timezone)
 
-	return nil
+	return fieldErrs
 }
 
 // validateFindAllUsersOpts validates query params, and returns domain.FindAllUsersOptions
-// sets default pageSize and status if provided empty
-func validateFindAllUsersOpts(input domain.FindAllUsersOptionsDTO) (*domain.FindAllUsersOptions, error) {
+// sets default pageSize and status if provided empty, collects every invalid query param.
+func validateFindAllUsersOpts(input domain.FindAllUsersOptionsDTO) (*domain.FindAllUsersOptions, lib.ValidationErrors) {
+	var fieldErrs lib.ValidationErrors
+
 	opts := &domain.FindAllUsersOptions{
 		PageSize: constants.DefaultPageSize,
 		Status:   constants.UserStatusActive,
 	}
 
 	if input.Timezone != "" {
-		if matched := regexp.MustCompile(constants.TimezoneRegex).MatchString(input.Timezone); !matched {
-			return nil, fmt.Errorf("timezone must be in 'UTC' or 'Asia/Dhaka' format, you entered: %s", input.Timezone)
-		}
-
+		validateTimezone(&fieldErrs, input.Timezone)
 		opts.Timezone = input.Timezone
 	}
 
 	if input.Status != "" {
 		if matched := regexp.MustCompile(constants.StatusRegex).MatchString(input.Status); !matched {
-			return nil, fmt.Errorf("user status must be 'active', 'inactive', or 'deleted', you entered: %s", input.Status)
+			fieldErrs.Add("status", lib.FieldCodeInvalidValue,
+				fmt.Sprintf("user status must be 'active', 'inactive', or 'deleted', you entered: %s", input.Status))
 		}
 
 		opts.Status = input.Status
@@ -104,7 +100,8 @@ func validateFindAllUsersOpts(input domain.FindAllUsersOptionsDTO) (*domain.Find
 
 	if input.SignUpOption != "" {
 		if matched := regexp.MustCompile(constants.SignUpOptionRegex).MatchString(input.SignUpOption); !matched {
-			return nil, fmt.Errorf("signUpOption must be 'general' or 'google', you entered: %s", input.SignUpOption)
+			fieldErrs.Add("signUpOption", lib.FieldCodeInvalidValue,
+				fmt.Sprintf("signUpOption must be 'general' or 'google', you entered: %s", input.SignUpOption))
 		}
 
 		opts.SignUpOption = input.SignUpOption
@@ -113,7 +110,8 @@ func validateFindAllUsersOpts(input domain.FindAllUsersOptionsDTO) (*domain.Find
 	if input.FromIDStr != "" {
 		fromID, err := strconv.Atoi(input.FromIDStr)
 		if err != nil || fromID < 0 {
-			return nil, fmt.Errorf("invalid FromID: must be a non-negative decimal number, you entered: %s", input.FromIDStr)
+			fieldErrs.Add("fromID", lib.FieldCodeInvalidValue,
+				fmt.Sprintf("invalid FromID: must be a non-negative decimal number, you entered: %s", input.FromIDStr))
 		}
 
 		opts.FromID = fromID
@@ -122,11 +120,53 @@ func validateFindAllUsersOpts(input domain.FindAllUsersOptionsDTO) (*domain.Find
 	if input.PageSizeStr != "" {
 		pageSize, err := strconv.Atoi(input.PageSizeStr)
 		if err != nil || pageSize < 20 || pageSize > 100 {
-			return nil, fmt.Errorf("invalid PageSize: must be between 20 and 100, you entered: %s", input.PageSizeStr)
+			fieldErrs.Add("pageSize", lib.FieldCodeOutOfRange,
+				fmt.Sprintf("invalid PageSize: must be between 20 and 100, you entered: %s", input.PageSizeStr))
 		}
 
 		opts.PageSize = pageSize
 	}
 
+	if fieldErrs.HasErrors() {
+		return nil, fieldErrs
+	}
+
 	return opts, nil
 }
+
+func validateEmail(fieldErrs *lib.ValidationErrors, email string) {
+	if matched := regexp.MustCompile(constants.EmailRegex).MatchString(email); !matched {
+		fieldErrs.Add("email", lib.FieldCodeInvalidFormat, fmt.Sprintf("invalid email, you entered %s", email))
+	}
+}
+
+func validatePhone(fieldErrs *lib.ValidationErrors, phone string) {
+	if matched := regexp.MustCompile(constants.PhoneRegex).MatchString(phone); !matched {
+		fieldErrs.Add("phone", lib.FieldCodeInvalidFormat, fmt.Sprintf("phone must contain 10 to 15 digits, you entered: %s", phone))
+	}
+}
+
+func validateTimezone(fieldErrs *lib.ValidationErrors, timezone string) {
+	if matched := regexp.MustCompile(constants.TimezoneRegex).MatchString(timezone); !matched {
+		fieldErrs.Add("timezone", lib.FieldCodeInvalidFormat,
+			fmt.Sprintf("timezone will be in 'UTC' or 'asia/dhaka' format, you entered: %s", timezone))
+	}
+}
+
+// validateFullName maps validate package errors to field error codes.
+func validateFullName(fieldErrs *lib.ValidationErrors, fullName string) {
+	_, err := validate.FullName(fullName, fullNameOpts)
+
+	switch {
+	case err == nil:
+		return
+	case errors.Is(err, validate.ErrFullNameEmpty):
+		fieldErrs.Add("fullName", lib.FieldCodeRequired, err.Error())
+	case errors.Is(err, validate.ErrFullNameTooShort):
+		fieldErrs.Add("fullName", lib.FieldCodeTooShort, err.Error())
+	case errors.Is(err, validate.ErrFullNameTooLong):
+		fieldErrs.Add("fullName", lib.FieldCodeTooLong, err.Error())
+	default:
+		fieldErrs.Add("fullName", lib.FieldCodeInvalidFormat, err.Error())
+	}
+}
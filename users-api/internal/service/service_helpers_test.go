@@ -0,0 +1,135 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/ashtishad/ecommerce/lib"
+	"github.com/ashtishad/ecommerce/users-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCreateUserInputFullName(t *testing.T) {
+	tests := []struct {
+		name     string
+		fullName string
+		wantErr  bool
+	}{
+		{name: "Latin", fullName: "Keanu Reeves", wantErr: false},
+		{name: "Accented", fullName: "José Zoë", wantErr: false},
+		{name: "Apostrophe", fullName: "Conan O'Brien", wantErr: false},
+		{name: "Vietnamese", fullName: "Nguyễn Văn An", wantErr: false},
+		{name: "Bengali", fullName: "আশতিয়াক আহমেদ", wantErr: false},
+		{name: "Digits", fullName: "Keanu 2", wantErr: true},
+		{name: "Empty", fullName: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := domain.NewUserRequestDTO{
+				Email:        "keanu_reeves@outlook.com",
+				Password:     "1234567890",
+				FullName:     tt.fullName,
+				Phone:        "12344567897000",
+				SignUpOption: "general",
+				Timezone:     "Asia/Dhaka",
+			}
+
+			fieldErrs := validateCreateUserInput(input)
+			if tt.wantErr {
+				require.Len(t, fieldErrs, 1)
+				assert.Equal(t, "fullName", fieldErrs[0].Field)
+			} else {
+				assert.Empty(t, fieldErrs)
+			}
+		})
+	}
+}
+
+func TestValidateUpdateUserInputFullName(t *testing.T) {
+	tests := []struct {
+		name     string
+		fullName string
+		wantErr  bool
+	}{
+		{name: "Hyphenated", fullName: "Jean-Luc Picard", wantErr: false},
+		{name: "Cyrillic", fullName: "Фёдор Достоевский", wantErr: false},
+		{name: "Trailing hyphen", fullName: "Jean-", wantErr: true},
+		{name: "Symbols", fullName: "John@Wick", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := domain.UpdateUserRequestDTO{
+				UserUUID: "31034fb3-d555-4d9c-bcbb-2719933930a3",
+				Email:    "john_wick@outlook.com",
+				FullName: tt.fullName,
+				Phone:    "017237475757",
+				Timezone: "Asia/Dhaka",
+			}
+
+			fieldErrs := validateUpdateUserInput(input)
+			if tt.wantErr {
+				require.Len(t, fieldErrs, 1)
+				assert.Equal(t, "fullName", fieldErrs[0].Field)
+			} else {
+				assert.Empty(t, fieldErrs)
+			}
+		})
+	}
+}
+
+func TestValidateCreateUserInputCollectsAllFieldErrors(t *testing.T) {
+	input := domain.NewUserRequestDTO{
+		Email:        "invalid-email",
+		Password:     "short",
+		FullName:     "",
+		Phone:        "12",
+		SignUpOption: "facebook",
+		Timezone:     "Asia/Dhaka",
+	}
+
+	want := []struct {
+		field string
+		code  string
+	}{
+		{"email", lib.FieldCodeInvalidFormat},
+		{"password", lib.FieldCodeTooShort},
+		{"fullName", lib.FieldCodeRequired},
+		{"phone", lib.FieldCodeInvalidFormat},
+		{"signUpOption", lib.FieldCodeInvalidValue},
+	}
+
+	fieldErrs := validateCreateUserInput(input)
+	require.Len(t, fieldErrs, len(want))
+
+	for i, w := range want {
+		assert.Equal(t, w.field, fieldErrs[i].Field)
+		assert.Equal(t, w.code, fieldErrs[i].Code)
+		assert.NotEmpty(t, fieldErrs[i].Message)
+	}
+}
+
+func TestValidateFindAllUsersOpts(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		opts, fieldErrs := validateFindAllUsersOpts(domain.FindAllUsersOptionsDTO{})
+		require.Empty(t, fieldErrs)
+		assert.Equal(t, 20, opts.PageSize)
+		assert.Equal(t, "active", opts.Status)
+	})
+
+	t.Run("Collects all invalid query params", func(t *testing.T) {
+		opts, fieldErrs := validateFindAllUsersOpts(domain.FindAllUsersOptionsDTO{
+			FromIDStr:    "-1",
+			PageSizeStr:  "1000",
+			Status:       "unknown",
+			SignUpOption: "general",
+		})
+		assert.Nil(t, opts)
+		require.Len(t, fieldErrs, 3)
+		assert.Equal(t, "status", fieldErrs[0].Field)
+		assert.Equal(t, "fromID", fieldErrs[1].Field)
+		assert.Equal(t, "pageSize", fieldErrs[2].Field)
+		assert.Equal(t, lib.FieldCodeOutOfRange, fieldErrs[2].Code)
+	})
+}
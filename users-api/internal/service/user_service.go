@@ -8,6 +8,7 @@ import (
 	"github.com/ashtishad/ecommerce/users-api/internal/domain"
 	"github.com/ashtishad/ecommerce/users-api/pkg/constants"
 	"github.com/ashtishad/ecommerce/users-api/pkg/hashpassword"
+	"github.com/ashtishad/ecommerce/users-api/pkg/validate"
 )
 
 type UserService interface {
@@ -28,8 +29,8 @@ func NewUserService(repository domain.UserRepository) *DefaultUserService {
 // then Calls the repository to save(create/update) the new user, get the user model if everything okay, otherwise returns error
 // Finally returns UserResponseDTO.
 func (service *DefaultUserService) NewUser(ctx context.Context, req domain.NewUserRequestDTO) (*domain.UserResponseDTO, lib.APIError) {
-	if err := validateCreateUserInput(req); err != nil {
-		return nil, lib.NewBadRequestError("invalid create user input").Wrap(err)
+	if fieldErrs := validateCreateUserInput(req); fieldErrs.HasErrors() {
+		return nil, lib.NewValidationError("invalid create user input", fieldErrs)
 	}
 
 	salt, err := hashpassword.GenerateSalt()
@@ -42,7 +43,7 @@ func (service *DefaultUserService) NewUser(ctx context.Context, req domain.NewUs
 	user := domain.User{
 		Email:        strings.ToLower(req.Email),
 		PasswordHash: hashedPassword,
-		FullName:     req.FullName,
+		FullName:     validate.NormalizeFullName(req.FullName),
 		Phone:        req.Phone,
 		SignUpOption: req.SignUpOption,
 		Status:       constants.UserStatusActive,
@@ -60,14 +61,14 @@ func (service *DefaultUserService) NewUser(ctx context.Context, req domain.NewUs
 }
 
 func (service *DefaultUserService) UpdateUser(ctx context.Context, req domain.UpdateUserRequestDTO) (*domain.UserResponseDTO, lib.APIError) {
-	if err := validateUpdateUserInput(req); err != nil {
-		return nil, lib.NewBadRequestError("invalid update user input").Wrap(err)
+	if fieldErrs := validateUpdateUserInput(req); fieldErrs.HasErrors() {
+		return nil, lib.NewValidationError("invalid update user input", fieldErrs)
 	}
 
 	user := domain.User{
 		UserUUID: req.UserUUID,
 		Email:    strings.ToLower(req.Email),
-		FullName: req.FullName,
+		FullName: validate.NormalizeFullName(req.FullName),
 		Phone:    req.Phone,
 		Status:   constants.UserStatusActive,
 		Timezone: strings.ToLower(req.Timezone),
@@ -84,9 +85,9 @@ func (service *DefaultUserService) UpdateUser(ctx context.Context, req domain.Up
 }
 
 func (service *DefaultUserService) GetAllUsers(ctx context.Context, request domain.FindAllUsersOptionsDTO) ([]domain.UserResponseDTO, *domain.NextPageInfo, lib.APIError) {
-	opts, err := validateFindAllUsersOpts(request)
-	if err != nil {
-		return nil, nil, lib.NewBadRequestError("invalid query params").Wrap(err)
+	opts, fieldErrs := validateFindAllUsersOpts(request)
+	if fieldErrs.HasErrors() {
+		return nil, nil, lib.NewValidationError("invalid query params", fieldErrs)
 	}
 
 	users, nextPageInfo, apiErr := service.repo.FindAll(ctx, *opts)
@@ -0,0 +1,107 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// zero width joiners are part of the spelling in scripts like Bengali and Persian.
+const (
+	zeroWidthNonJoiner = '\u200c'
+	zeroWidthJoiner    = '\u200d'
+)
+
+var (
+	ErrFullNameEmpty       = errors.New("full name cannot be empty")
+	ErrFullNameTooShort    = errors.New("full name is too short")
+	ErrFullNameTooLong     = errors.New("full name is too long")
+	ErrFullNameInvalidRune = errors.New("full name can only contain letters, spaces, apostrophes and hyphens")
+	ErrFullNameSeparator   = errors.New("full name must start and end with a letter and can't repeat separators")
+)
+
+// FullNameOptions holds the configurable limits for full name validation,
+// lengths are counted in characters(runes) after normalization.
+type FullNameOptions struct {
+	MinLength int
+	MaxLength int
+}
+
+// NormalizeFullName converts name to Unicode NFC form, trims surrounding spaces
+// and collapses any run of inner whitespace into a single space.
+// so, "Zoë  Smith" and "Zoë Smith" are stored the same way.
+func NormalizeFullName(name string) string {
+	return strings.Join(strings.Fields(norm.NFC.String(name)), " ")
+}
+
+// FullName validates a normalized full name against the given options, and returns the normalized name.
+//   - Letters: Any Unicode letter, followed by optional combining marks(e.g. Bengali vowel signs, Vietnamese tones).
+//   - Separators: Spaces, apostrophes(' or ’) and hyphens(- or ‐) are allowed only between letters,
+//     so "O'Brien" and "Jean-Luc" are valid, but "'Brien", "Jean--Luc" or "Anna-" are not.
+//   - Length: Must be between opts.MinLength and opts.MaxLength characters, zero value disables a limit.
+func FullName(name string, opts FullNameOptions) (string, error) {
+	normalized := NormalizeFullName(name)
+	if normalized == "" {
+		return "", ErrFullNameEmpty
+	}
+
+	length := utf8.RuneCountInString(normalized)
+
+	if opts.MinLength > 0 && length < opts.MinLength {
+		return "", fmt.Errorf("%w: must be at least %d characters, you entered: %s", ErrFullNameTooShort, opts.MinLength, name)
+	}
+
+	if opts.MaxLength > 0 && length > opts.MaxLength {
+		return "", fmt.Errorf("%w: must be at most %d characters, you entered %d", ErrFullNameTooLong, opts.MaxLength, length)
+	}
+
+	if err := checkFullNameRunes(normalized); err != nil {
+		return "", fmt.Errorf("%w, you entered: %s", err, name)
+	}
+
+	return normalized, nil
+}
+
+// checkFullNameRunes walks through the normalized name and makes sure every separator
+// sits between two letters, and combining marks or joiners are attached to a letter.
+func checkFullNameRunes(name string) error {
+	prevIsLetter := false
+
+	for _, r := range name {
+		switch {
+		case unicode.IsLetter(r):
+			prevIsLetter = true
+		case unicode.Is(unicode.M, r), r == zeroWidthNonJoiner, r == zeroWidthJoiner:
+			if !prevIsLetter {
+				return ErrFullNameSeparator
+			}
+		case isFullNameSeparator(r):
+			if !prevIsLetter {
+				return ErrFullNameSeparator
+			}
+
+			prevIsLetter = false
+		default:
+			return ErrFullNameInvalidRune
+		}
+	}
+
+	if !prevIsLetter {
+		return ErrFullNameSeparator
+	}
+
+	return nil
+}
+
+func isFullNameSeparator(r rune) bool {
+	switch r {
+	case ' ', '\'', '’', '-', '‐':
+		return true
+	default:
+		return false
+	}
+}
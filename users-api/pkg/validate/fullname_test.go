@@ -0,0 +1,106 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testOpts = FullNameOptions{MinLength: 2, MaxLength: 255}
+
+func TestFullName(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		opts    FullNameOptions
+		want    string
+		wantErr error
+	}{
+		// valid latin and international names
+		{name: "Plain ASCII", input: "John Wick", opts: testOpts, want: "John Wick"},
+		{name: "Latin acute accent", input: "José Álvarez", opts: testOpts, want: "José Álvarez"},
+		{name: "Latin diaeresis", input: "Zoë Saldaña", opts: testOpts, want: "Zoë Saldaña"},
+		{name: "Apostrophe", input: "Conan O'Brien", opts: testOpts, want: "Conan O'Brien"},
+		{name: "Typographic apostrophe", input: "D’Angelo", opts: testOpts, want: "D’Angelo"},
+		{name: "Hyphenated", input: "Jean-Luc Picard", opts: testOpts, want: "Jean-Luc Picard"},
+		{name: "Unicode hyphen", input: "Marie‐Claire", opts: testOpts, want: "Marie‐Claire"},
+		{name: "Vietnamese", input: "Nguyễn Thị Minh Khai", opts: testOpts, want: "Nguyễn Thị Minh Khai"},
+		{name: "Bengali with vowel signs", input: "আশতিয়াক আহমেদ", opts: testOpts, want: "আশতিয়াক আহমেদ"},
+		{name: "Devanagari", input: "अमिताभ बच्चन", opts: testOpts, want: "अमिताभ बच्चन"},
+		{name: "Arabic", input: "محمد علي", opts: testOpts, want: "محمد علي"},
+		{name: "Persian with zero width non joiner", input: "می‌خواهم", opts: testOpts, want: "می‌خواهم"},
+		{name: "Cyrillic", input: "Фёдор Достоевский", opts: testOpts, want: "Фёдор Достоевский"},
+		{name: "Greek", input: "Αλέξανδρος", opts: testOpts, want: "Αλέξανδρος"},
+		{name: "Chinese", input: "王小明", opts: testOpts, want: "王小明"},
+		{name: "Japanese", input: "やまだ たろう", opts: testOpts, want: "やまだ たろう"},
+		{name: "German eszett", input: "Claus Weiß", opts: testOpts, want: "Claus Weiß"},
+		{name: "Polish", input: "Łukasz Żółć", opts: testOpts, want: "Łukasz Żółć"},
+
+		// normalization
+		{name: "Decomposed is converted to NFC", input: "Zoe\u0308", opts: testOpts, want: "Zoë"},
+		{name: "Surrounding spaces are trimmed", input: "  John Wick  ", opts: testOpts, want: "John Wick"},
+		{name: "Inner whitespace is collapsed", input: "John \t  Wick", opts: testOpts, want: "John Wick"},
+
+		// length limits
+		{name: "Empty", input: "", opts: testOpts, wantErr: ErrFullNameEmpty},
+		{name: "Only spaces", input: "   ", opts: testOpts, wantErr: ErrFullNameEmpty},
+		{name: "Too short", input: "J", opts: testOpts, wantErr: ErrFullNameTooShort},
+		{name: "Single CJK character is too short", input: "王", opts: testOpts, wantErr: ErrFullNameTooShort},
+		{name: "Exactly max length", input: strings.Repeat("ä", 255), opts: testOpts, want: strings.Repeat("ä", 255)},
+		{name: "Too long counts runes not bytes", input: strings.Repeat("ä", 256), opts: testOpts, wantErr: ErrFullNameTooLong},
+		{name: "Custom max length", input: "Jean-Luc Picard", opts: FullNameOptions{MaxLength: 10}, wantErr: ErrFullNameTooLong},
+		{name: "Zero options disable limits", input: "J", opts: FullNameOptions{}, want: "J"},
+
+		// invalid characters
+		{name: "Digits", input: "John Wick 4", opts: testOpts, wantErr: ErrFullNameInvalidRune},
+		{name: "Symbols", input: "John@Wick", opts: testOpts, wantErr: ErrFullNameInvalidRune},
+		{name: "Emoji", input: "John 😀", opts: testOpts, wantErr: ErrFullNameInvalidRune},
+		{name: "Period", input: "John Jr.", opts: testOpts, wantErr: ErrFullNameInvalidRune},
+		{name: "Control character", input: "John\u0000Wick", opts: testOpts, wantErr: ErrFullNameInvalidRune},
+
+		// separator placement
+		{name: "Leading apostrophe", input: "'Brien", opts: testOpts, wantErr: ErrFullNameSeparator},
+		{name: "Trailing hyphen", input: "Anna-", opts: testOpts, wantErr: ErrFullNameSeparator},
+		{name: "Double hyphen", input: "Jean--Luc", opts: testOpts, wantErr: ErrFullNameSeparator},
+		{name: "Hyphen followed by space", input: "Jean- Luc", opts: testOpts, wantErr: ErrFullNameSeparator},
+		{name: "Leading combining mark", input: "\u0301Anna", opts: testOpts, wantErr: ErrFullNameSeparator},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FullName(tt.input, tt.opts)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, got)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNormalizeFullName(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "Already normalized", input: "José", want: "José"},
+		{name: "Decomposed acute accent", input: "Jose\u0301", want: "José"},
+		{name: "Decomposed Vietnamese tones", input: "Nguye\u0302\u0303n", want: "Nguyễn"},
+		{name: "Trim and collapse", input: "\n Zoë \t Smith \n", want: "Zoë Smith"},
+		{name: "Empty", input: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NormalizeFullName(tt.input))
+		})
+	}
+}